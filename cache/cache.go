@@ -0,0 +1,470 @@
+// Package cache provides a caching decorator for reaper.Client, for callers that read the same resources over and
+// over, such as controllers calling GetCluster and RepairSchedulesForCluster on every reconcile.
+//
+// Reads are cached per resource, each with its own time to live; concurrent identical reads are coalesced into a
+// single request to Reaper. Mutations made through the decorator invalidate the resources they affect, so that a
+// caller never reads back stale data after its own changes:
+//
+//	client := cache.NewClient(reaperClient, cache.WithTTL(cache.ResourceSchedules, time.Minute))
+//	cluster, err := client.GetCluster(ctx, "cluster-1") // hits Reaper
+//	cluster, err = client.GetCluster(ctx, "cluster-1")  // served from the cache
+//
+// Changes made by other clients are only seen once the cached entries expire. Cached values are shared by all callers
+// and must not be modified.
+package cache
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"math"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/k8ssandra/reaper-client-go/reaper"
+	"golang.org/x/sync/singleflight"
+)
+
+// Resource identifies a group of cached reads sharing the same time to live, and invalidated together.
+type Resource string
+
+const (
+	// ResourceClusters covers GetClusterNames, GetCluster, which includes the topology of the cluster, and
+	// ClusterSchema, as well as GetClusters, GetClustersSync and AllClusters, which are built on them.
+	ResourceClusters Resource = "clusters"
+
+	// ResourceSchedules covers RepairSchedules, RepairSchedulesForCluster and RepairSchedule.
+	ResourceSchedules Resource = "schedules"
+
+	// ResourceRuns covers RepairRuns and RepairRun. Repair runs progress constantly, so they are not cached by
+	// default.
+	ResourceRuns Resource = "runs"
+)
+
+// Resources lists all resources, in a stable order.
+var Resources = []Resource{ResourceClusters, ResourceSchedules, ResourceRuns}
+
+const (
+	DefaultClusterTTL  = 30 * time.Second
+	DefaultScheduleTTL = 30 * time.Second
+	DefaultRunTTL      = time.Duration(0)
+)
+
+// Stats counts the cache accesses of a resource.
+type Stats struct {
+	// Hits counts the reads served from the cache.
+	Hits uint64
+
+	// Misses counts the reads that were sent to Reaper.
+	Misses uint64
+
+	// Shared counts the reads that waited for an identical read already in flight, instead of sending their own
+	// request to Reaper.
+	Shared uint64
+
+	// Invalidations counts the mutations that invalidated the resource.
+	Invalidations uint64
+}
+
+type Option func(*Client)
+
+// WithTTL sets how long the reads of the given resource are cached. A zero or negative ttl disables caching for the
+// resource; reads are still coalesced.
+func WithTTL(resource Resource, ttl time.Duration) Option {
+	return func(c *Client) {
+		if r, found := c.resources[resource]; found {
+			r.ttl = ttl
+		}
+	}
+}
+
+// WithConcurrency sets the maximum number of clusters fetched at once by GetClusters and GetClustersSync on a cache
+// miss. Defaults to min(5, NUM_CPUS), like reaper.WithConcurrency. Values < 1 are ignored.
+func WithConcurrency(concurrency int) Option {
+	return func(c *Client) {
+		if concurrency > 0 {
+			c.concurrency = concurrency
+		}
+	}
+}
+
+// Client is a reaper.Client caching the reads of the client it decorates. The methods it doesn't cache, such as
+// IsReaperUp and the iterators over repair runs and segments, are passed through.
+type Client struct {
+	reaper.Client
+
+	group       singleflight.Group
+	now         func() time.Time
+	concurrency int
+
+	mu        sync.Mutex
+	resources map[Resource]*resourceCache
+}
+
+type resourceCache struct {
+	ttl     time.Duration
+	entries map[string]entry
+
+	// generation is incremented by every invalidation, so that reads in flight during an invalidation don't store
+	// what they fetched.
+	generation uint64
+	stats      Stats
+}
+
+type entry struct {
+	value   interface{}
+	expires time.Time
+}
+
+// NewClient returns a Client caching the reads of client.
+func NewClient(client reaper.Client, options ...Option) *Client {
+	c := &Client{
+		Client:      client,
+		now:         time.Now,
+		concurrency: int(math.Min(5, float64(runtime.NumCPU()))),
+		resources: map[Resource]*resourceCache{
+			ResourceClusters:  {ttl: DefaultClusterTTL},
+			ResourceSchedules: {ttl: DefaultScheduleTTL},
+			ResourceRuns:      {ttl: DefaultRunTTL},
+		},
+	}
+	for _, option := range options {
+		option(c)
+	}
+	for _, r := range c.resources {
+		r.entries = map[string]entry{}
+	}
+	return c
+}
+
+// Stats returns the cache statistics of every resource.
+func (c *Client) Stats() map[Resource]Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := make(map[Resource]Stats, len(c.resources))
+	for resource, r := range c.resources {
+		stats[resource] = r.stats
+	}
+	return stats
+}
+
+// Invalidate drops the cached reads of the given resources, or of all resources if none is given. Use it after
+// changes made by other clients, when waiting for the entries to expire is not an option.
+func (c *Client) Invalidate(resources ...Resource) {
+	if len(resources) == 0 {
+		resources = Resources
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, resource := range resources {
+		r, found := c.resources[resource]
+		if !found {
+			continue
+		}
+		r.entries = map[string]entry{}
+		r.generation++
+		r.stats.Invalidations++
+	}
+}
+
+// get returns the cached value of key if it is still fresh, and fetches it otherwise. The fetch is shared by all the
+// callers asking for the same key in the meantime; it runs without the cancellation of ctx so that a caller giving up
+// doesn't fail the others, but each caller still returns as soon as its own ctx is done. Errors are not cached.
+func get[T any](
+	ctx context.Context,
+	c *Client,
+	resource Resource,
+	key string,
+	fetch func(context.Context) (T, error),
+) (T, error) {
+	var zero T
+	key = fmt.Sprint(resource, "/", key)
+	c.mu.Lock()
+	r := c.resources[resource]
+	if e, found := r.entries[key]; found {
+		if c.now().Before(e.expires) {
+			r.stats.Hits++
+			c.mu.Unlock()
+			return e.value.(T), nil
+		}
+		delete(r.entries, key)
+	}
+	c.mu.Unlock()
+
+	fetched := false
+	results := c.group.DoChan(key, func() (interface{}, error) {
+		fetched = true
+		c.mu.Lock()
+		generation := r.generation
+		r.stats.Misses++
+		c.mu.Unlock()
+		value, err := fetch(context.WithoutCancel(ctx))
+		if err == nil && r.ttl > 0 {
+			c.mu.Lock()
+			if generation == r.generation {
+				r.entries[key] = entry{value: value, expires: c.now().Add(r.ttl)}
+			}
+			c.mu.Unlock()
+		}
+		return value, err
+	})
+	select {
+	case result := <-results:
+		// the caller that fetched is told that the result was shared as well
+		if result.Shared && !fetched {
+			c.mu.Lock()
+			r.stats.Shared++
+			c.mu.Unlock()
+		}
+		if result.Err != nil {
+			return zero, result.Err
+		}
+		return result.Val.(T), nil
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+func (c *Client) GetClusterNames(ctx context.Context) ([]string, error) {
+	return get(ctx, c, ResourceClusters, "names", c.Client.GetClusterNames)
+}
+
+func (c *Client) GetCluster(ctx context.Context, name string) (*reaper.Cluster, error) {
+	return get(ctx, c, ResourceClusters, "cluster/"+name, func(ctx context.Context) (*reaper.Cluster, error) {
+		return c.Client.GetCluster(ctx, name)
+	})
+}
+
+func (c *Client) ClusterSchema(ctx context.Context, cluster string) (reaper.ClusterSchema, error) {
+	return get(ctx, c, ResourceClusters, "schema/"+cluster, func(ctx context.Context) (reaper.ClusterSchema, error) {
+		return c.Client.ClusterSchema(ctx, cluster)
+	})
+}
+
+func (c *Client) GetClusters(ctx context.Context) <-chan reaper.GetClusterResult {
+	clusterNames, err := c.GetClusterNames(ctx)
+	if err != nil {
+		results := make(chan reaper.GetClusterResult)
+		close(results)
+		return results
+	}
+	results := make(chan reaper.GetClusterResult, len(clusterNames))
+	slots := make(chan struct{}, c.concurrency)
+	var wg sync.WaitGroup
+	for _, clusterName := range clusterNames {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				results <- reaper.GetClusterResult{Error: fmt.Errorf("failed to get cluster %s: %w", name, ctx.Err())}
+				return
+			}
+			cluster, err := c.GetCluster(ctx, name)
+			<-slots
+			results <- reaper.GetClusterResult{Cluster: cluster, Error: err}
+		}(clusterName)
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
+
+func (c *Client) GetClustersSync(ctx context.Context) ([]*reaper.Cluster, error) {
+	clusters := make([]*reaper.Cluster, 0)
+	for result := range c.GetClusters(ctx) {
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		clusters = append(clusters, result.Cluster)
+	}
+	return clusters, nil
+}
+
+func (c *Client) AllClusters(ctx context.Context) iter.Seq2[*reaper.Cluster, error] {
+	return func(yield func(*reaper.Cluster, error) bool) {
+		clusterNames, err := c.GetClusterNames(ctx)
+		if err != nil {
+			yield(nil, fmt.Errorf("failed to get cluster names: %w", err))
+			return
+		}
+		for _, name := range clusterNames {
+			if !yield(c.GetCluster(ctx, name)) {
+				return
+			}
+		}
+	}
+}
+
+func (c *Client) AddCluster(ctx context.Context, cluster string, seed string) error {
+	defer c.Invalidate(ResourceClusters)
+	return c.Client.AddCluster(ctx, cluster, seed)
+}
+
+func (c *Client) DeleteCluster(ctx context.Context, cluster string) error {
+	// the schedules and runs of the cluster are deleted along with it
+	defer c.Invalidate()
+	return c.Client.DeleteCluster(ctx, cluster)
+}
+
+func (c *Client) RepairRuns(
+	ctx context.Context,
+	searchOptions *reaper.RepairRunSearchOptions,
+) (map[uuid.UUID]*reaper.RepairRun, error) {
+	key := "search"
+	if searchOptions != nil {
+		key = fmt.Sprintf("search/%+v", *searchOptions)
+	}
+	return get(ctx, c, ResourceRuns, key, func(ctx context.Context) (map[uuid.UUID]*reaper.RepairRun, error) {
+		return c.Client.RepairRuns(ctx, searchOptions)
+	})
+}
+
+func (c *Client) RepairRun(ctx context.Context, repairRunId uuid.UUID) (*reaper.RepairRun, error) {
+	return get(ctx, c, ResourceRuns, repairRunId.String(), func(ctx context.Context) (*reaper.RepairRun, error) {
+		return c.Client.RepairRun(ctx, repairRunId)
+	})
+}
+
+func (c *Client) CreateRepairRun(
+	ctx context.Context,
+	cluster string,
+	keyspace string,
+	owner string,
+	options *reaper.RepairRunCreateOptions,
+) (uuid.UUID, error) {
+	defer c.Invalidate(ResourceRuns)
+	return c.Client.CreateRepairRun(ctx, cluster, keyspace, owner, options)
+}
+
+func (c *Client) UpdateRepairRun(ctx context.Context, repairRunId uuid.UUID, newIntensity reaper.Intensity) error {
+	defer c.Invalidate(ResourceRuns)
+	return c.Client.UpdateRepairRun(ctx, repairRunId, newIntensity)
+}
+
+func (c *Client) StartRepairRun(ctx context.Context, repairRunId uuid.UUID) error {
+	defer c.Invalidate(ResourceRuns)
+	return c.Client.StartRepairRun(ctx, repairRunId)
+}
+
+func (c *Client) PauseRepairRun(ctx context.Context, repairRunId uuid.UUID) error {
+	defer c.Invalidate(ResourceRuns)
+	return c.Client.PauseRepairRun(ctx, repairRunId)
+}
+
+func (c *Client) ResumeRepairRun(ctx context.Context, repairRunId uuid.UUID) error {
+	defer c.Invalidate(ResourceRuns)
+	return c.Client.ResumeRepairRun(ctx, repairRunId)
+}
+
+func (c *Client) AbortRepairRun(ctx context.Context, repairRunId uuid.UUID) error {
+	defer c.Invalidate(ResourceRuns)
+	return c.Client.AbortRepairRun(ctx, repairRunId)
+}
+
+func (c *Client) AbortRepairRunSegment(ctx context.Context, repairRunId uuid.UUID, segmentId uuid.UUID) error {
+	defer c.Invalidate(ResourceRuns)
+	return c.Client.AbortRepairRunSegment(ctx, repairRunId, segmentId)
+}
+
+func (c *Client) DeleteRepairRun(ctx context.Context, repairRunId uuid.UUID, owner string) error {
+	defer c.Invalidate(ResourceRuns)
+	return c.Client.DeleteRepairRun(ctx, repairRunId, owner)
+}
+
+func (c *Client) PurgeRepairRuns(ctx context.Context) (int, error) {
+	defer c.Invalidate(ResourceRuns)
+	return c.Client.PurgeRepairRuns(ctx)
+}
+
+func (c *Client) PauseRepairRuns(
+	ctx context.Context,
+	searchOptions *reaper.RepairRunSearchOptions,
+) (map[uuid.UUID]*reaper.BulkResult, error) {
+	defer c.Invalidate(ResourceRuns)
+	return c.Client.PauseRepairRuns(ctx, searchOptions)
+}
+
+func (c *Client) ResumeRepairRuns(
+	ctx context.Context,
+	searchOptions *reaper.RepairRunSearchOptions,
+) (map[uuid.UUID]*reaper.BulkResult, error) {
+	defer c.Invalidate(ResourceRuns)
+	return c.Client.ResumeRepairRuns(ctx, searchOptions)
+}
+
+func (c *Client) AbortRepairRuns(
+	ctx context.Context,
+	searchOptions *reaper.RepairRunSearchOptions,
+) (map[uuid.UUID]*reaper.BulkResult, error) {
+	defer c.Invalidate(ResourceRuns)
+	return c.Client.AbortRepairRuns(ctx, searchOptions)
+}
+
+func (c *Client) RepairSchedules(ctx context.Context) ([]reaper.RepairSchedule, error) {
+	return get(ctx, c, ResourceSchedules, "all", c.Client.RepairSchedules)
+}
+
+func (c *Client) RepairSchedulesForCluster(ctx context.Context, clusterName string) ([]reaper.RepairSchedule, error) {
+	fetch := func(ctx context.Context) ([]reaper.RepairSchedule, error) {
+		return c.Client.RepairSchedulesForCluster(ctx, clusterName)
+	}
+	return get(ctx, c, ResourceSchedules, "cluster/"+clusterName, fetch)
+}
+
+func (c *Client) RepairSchedule(ctx context.Context, repairScheduleId uuid.UUID) (*reaper.RepairSchedule, error) {
+	fetch := func(ctx context.Context) (*reaper.RepairSchedule, error) {
+		return c.Client.RepairSchedule(ctx, repairScheduleId)
+	}
+	return get(ctx, c, ResourceSchedules, repairScheduleId.String(), fetch)
+}
+
+func (c *Client) CreateRepairSchedule(
+	ctx context.Context,
+	cluster string,
+	keyspace string,
+	owner string,
+	daysBetween int,
+	options *reaper.RepairScheduleCreateOptions,
+) (uuid.UUID, error) {
+	defer c.Invalidate(ResourceSchedules)
+	return c.Client.CreateRepairSchedule(ctx, cluster, keyspace, owner, daysBetween, options)
+}
+
+func (c *Client) StartRepairSchedule(ctx context.Context, repairScheduleId uuid.UUID) error {
+	// starting a schedule creates a repair run
+	defer c.Invalidate(ResourceSchedules, ResourceRuns)
+	return c.Client.StartRepairSchedule(ctx, repairScheduleId)
+}
+
+func (c *Client) PauseRepairSchedule(ctx context.Context, repairScheduleId uuid.UUID) error {
+	defer c.Invalidate(ResourceSchedules)
+	return c.Client.PauseRepairSchedule(ctx, repairScheduleId)
+}
+
+func (c *Client) ResumeRepairSchedule(ctx context.Context, repairScheduleId uuid.UUID) error {
+	defer c.Invalidate(ResourceSchedules)
+	return c.Client.ResumeRepairSchedule(ctx, repairScheduleId)
+}
+
+func (c *Client) DeleteRepairSchedule(ctx context.Context, repairScheduleId uuid.UUID, owner string) error {
+	defer c.Invalidate(ResourceSchedules)
+	return c.Client.DeleteRepairSchedule(ctx, repairScheduleId, owner)
+}
+
+func (c *Client) PauseRepairSchedules(ctx context.Context, cluster string) (map[uuid.UUID]*reaper.BulkResult, error) {
+	defer c.Invalidate(ResourceSchedules)
+	return c.Client.PauseRepairSchedules(ctx, cluster)
+}
+
+func (c *Client) ResumeRepairSchedules(ctx context.Context, cluster string) (map[uuid.UUID]*reaper.BulkResult, error) {
+	defer c.Invalidate(ResourceSchedules)
+	return c.Client.ResumeRepairSchedules(ctx, cluster)
+}
+
+var _ reaper.Client = (*Client)(nil)
@@ -0,0 +1,244 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/k8ssandra/reaper-client-go/reaper"
+	"github.com/k8ssandra/reaper-client-go/reapermock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newClient(t *testing.T, options ...Option) (*Client, *reapermock.Mock, *fakeClock) {
+	mock := reapermock.NewMock(t)
+	clock := &fakeClock{now: time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)}
+	client := NewClient(mock, options...)
+	client.now = clock.Now
+	return client, mock, clock
+}
+
+func TestTTL(t *testing.T) {
+	client, mock, clock := newClient(t, WithTTL(ResourceSchedules, time.Minute))
+	mock.OnGetCluster().With("cluster-1").Return(&reaper.Cluster{Name: "cluster-1"}, nil)
+	mock.OnRepairSchedulesForCluster().With("cluster-1").Return([]reaper.RepairSchedule{{Owner: "Alice"}}, nil)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		cluster, err := client.GetCluster(ctx, "cluster-1")
+		require.NoError(t, err)
+		assert.Equal(t, "cluster-1", cluster.Name)
+		schedules, err := client.RepairSchedulesForCluster(ctx, "cluster-1")
+		require.NoError(t, err)
+		assert.Len(t, schedules, 1)
+	}
+	assert.Equal(t, 1, mock.CallCount("GetCluster"))
+	assert.Equal(t, 1, mock.CallCount("RepairSchedulesForCluster"))
+
+	clock.Advance(DefaultClusterTTL)
+	_, err := client.GetCluster(ctx, "cluster-1")
+	require.NoError(t, err)
+	_, err = client.RepairSchedulesForCluster(ctx, "cluster-1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, mock.CallCount("GetCluster"), "the cluster expired")
+	assert.Equal(t, 1, mock.CallCount("RepairSchedulesForCluster"), "the schedules have a longer ttl")
+
+	assert.Equal(t, map[Resource]Stats{
+		ResourceClusters:  {Hits: 2, Misses: 2},
+		ResourceSchedules: {Hits: 3, Misses: 1},
+		ResourceRuns:      {},
+	}, client.Stats())
+}
+
+func TestNotCached(t *testing.T) {
+	client, mock, _ := newClient(t, WithTTL(ResourceClusters, 0))
+	runId := uuid.New()
+	mock.OnRepairRun().With(runId).Return(&reaper.RepairRun{Id: runId}, nil)
+	mock.OnGetCluster().With("broken").Return(nil, errors.New("boom"))
+	mock.OnGetCluster().With("cluster-1").Return(&reaper.Cluster{Name: "cluster-1"}, nil)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err := client.RepairRun(ctx, runId)
+		require.NoError(t, err)
+		_, err = client.GetCluster(ctx, "cluster-1")
+		require.NoError(t, err)
+		_, err = client.GetCluster(ctx, "broken")
+		assert.EqualError(t, err, "boom")
+	}
+	assert.Equal(t, 2, mock.CallCount("RepairRun"), "runs are not cached by default")
+	assert.Equal(t, 4, mock.CallCount("GetCluster"), "errors are never cached")
+}
+
+func TestConcurrentReadsAreCoalesced(t *testing.T) {
+	client, mock, _ := newClient(t)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	mock.OnGetClusterNames().Run(func(ctx context.Context) ([]string, error) {
+		close(started)
+		<-release
+		return []string{"cluster-1"}, nil
+	})
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	results := make(chan []string, 5)
+	read := func() {
+		defer wg.Done()
+		names, err := client.GetClusterNames(ctx)
+		assert.NoError(t, err)
+		results <- names
+	}
+	wg.Add(1)
+	go read()
+	<-started
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go read()
+	}
+	// a caller giving up doesn't cancel the request shared by the others
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err := client.GetClusterNames(canceled)
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	close(release)
+	wg.Wait()
+	close(results)
+	for names := range results {
+		assert.Equal(t, []string{"cluster-1"}, names)
+	}
+	assert.Equal(t, 1, mock.CallCount("GetClusterNames"))
+	// the callers arriving once the names are cached count as hits
+	stats := client.Stats()[ResourceClusters]
+	assert.Equal(t, uint64(1), stats.Misses)
+	assert.Equal(t, uint64(4), stats.Shared+stats.Hits)
+}
+
+func TestGetClustersConcurrency(t *testing.T) {
+	client, mock, _ := newClient(t, WithConcurrency(2))
+	names := []string{"cluster-1", "cluster-2", "cluster-3", "cluster-4", "cluster-5", "cluster-6"}
+	mock.OnGetClusterNames().Return(names, nil)
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	mock.OnGetCluster().Run(func(ctx context.Context, name string) (*reaper.Cluster, error) {
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		return &reaper.Cluster{Name: name}, nil
+	})
+
+	clusters, err := client.GetClustersSync(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, clusters, len(names))
+	assert.Equal(t, 2, maxInFlight, "at most 2 clusters are fetched at once on a cache miss")
+}
+
+func TestMutationsInvalidate(t *testing.T) {
+	client, mock, _ := newClient(t)
+	scheduleId := uuid.New()
+	mock.OnGetClusterNames().Return([]string{"cluster-1"}, nil)
+	mock.OnGetCluster().Return(&reaper.Cluster{Name: "cluster-1"}, nil)
+	mock.OnClusterSchema().With("cluster-1").Return(reaper.ClusterSchema{"ks": {"table1"}}, nil)
+	mock.OnRepairSchedules().Return([]reaper.RepairSchedule{{Id: scheduleId.String()}}, nil)
+	mock.OnRepairSchedule().With(scheduleId).Return(&reaper.RepairSchedule{Id: scheduleId.String()}, nil)
+	mock.OnAddCluster().Return(nil)
+	mock.OnCreateRepairRun().Return(uuid.New(), nil)
+	mock.OnPauseRepairSchedule().Return(errors.New("already paused"))
+	ctx := context.Background()
+
+	read := func() {
+		clusters, err := client.GetClustersSync(ctx)
+		require.NoError(t, err)
+		assert.Len(t, clusters, 1)
+		_, err = client.ClusterSchema(ctx, "cluster-1")
+		require.NoError(t, err)
+		_, err = client.RepairSchedules(ctx)
+		require.NoError(t, err)
+		_, err = client.RepairSchedule(ctx, scheduleId)
+		require.NoError(t, err)
+	}
+	read()
+	read()
+	assert.Equal(t, 1, mock.CallCount("GetClusterNames"))
+	assert.Equal(t, 1, mock.CallCount("RepairSchedules"))
+
+	require.NoError(t, client.AddCluster(ctx, "cluster-2", "seed"))
+	read()
+	assert.Equal(t, 2, mock.CallCount("GetClusterNames"))
+	assert.Equal(t, 2, mock.CallCount("GetCluster"))
+	assert.Equal(t, 2, mock.CallCount("ClusterSchema"))
+	assert.Equal(t, 1, mock.CallCount("RepairSchedules"))
+
+	_, err := client.CreateRepairRun(ctx, "cluster-1", "ks", "Alice", nil)
+	require.NoError(t, err)
+	read()
+	assert.Equal(t, 2, mock.CallCount("GetClusterNames"), "runs are independent of clusters")
+	assert.Equal(t, 1, mock.CallCount("RepairSchedules"))
+
+	// failed mutations invalidate too, as they may have been applied partially
+	assert.Error(t, client.PauseRepairSchedule(ctx, scheduleId))
+	read()
+	assert.Equal(t, 2, mock.CallCount("RepairSchedules"))
+	assert.Equal(t, 2, mock.CallCount("RepairSchedule"))
+
+	stats := client.Stats()
+	assert.Equal(t, uint64(1), stats[ResourceClusters].Invalidations)
+	assert.Equal(t, uint64(1), stats[ResourceSchedules].Invalidations)
+	assert.Equal(t, uint64(1), stats[ResourceRuns].Invalidations)
+}
+
+func TestReadInFlightDuringMutation(t *testing.T) {
+	client, mock, _ := newClient(t)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	mock.OnGetCluster().Run(func(ctx context.Context, name string) (*reaper.Cluster, error) {
+		close(started)
+		<-release
+		return &reaper.Cluster{Name: name}, nil
+	}).Once()
+	mock.OnGetCluster().Return(&reaper.Cluster{Name: "cluster-1"}, nil)
+	mock.OnDeleteCluster().Return(nil)
+	ctx := context.Background()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := client.GetCluster(ctx, "cluster-1")
+		assert.NoError(t, err)
+	}()
+	<-started
+	require.NoError(t, client.DeleteCluster(ctx, "cluster-1"))
+	close(release)
+	<-done
+
+	_, err := client.GetCluster(ctx, "cluster-1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, mock.CallCount("GetCluster"), "what was read before the mutation is not cached")
+}
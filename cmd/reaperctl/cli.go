@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/k8ssandra/reaper-client-go/config"
+	"github.com/k8ssandra/reaper-client-go/reaper"
+	"github.com/k8ssandra/reaper-client-go/render"
+)
+
+// cli holds the state shared by all commands.
+type cli struct {
+	stdout  io.Writer
+	stderr  io.Writer
+	getenv  func(string) string
+	globals globalOptions
+}
+
+type globalOptions struct {
+	config                string
+	context               string
+	url                   string
+	username              string
+	password              string
+	passwordFile          string
+	token                 string
+	caFile                string
+	certFile              string
+	keyFile               string
+	insecureSkipTLSVerify bool
+	timeout               time.Duration
+	output                string
+	columns               string
+	sortBy                string
+	noHeaders             bool
+}
+
+// bind registers the global flags in fs. The current values are used as defaults, so that flags parsed by a parent
+// command are not reset by its subcommands.
+func (g *globalOptions) bind(fs *flag.FlagSet) {
+	fs.StringVar(&g.config, "config", g.config, "configuration file of the Reaper contexts (env REAPER_CONFIG, default ~/.reaper/config)")
+	fs.StringVar(&g.context, "context", g.context, "context of the configuration file to use (env REAPER_CONTEXT)")
+	fs.StringVar(&g.url, "url", g.url, "base URL of Reaper, e.g. http://localhost:8080 (env REAPER_URL)")
+	fs.StringVar(&g.username, "username", g.username, "user to log in as, if authentication is enabled (env REAPER_USERNAME)")
+	fs.StringVar(&g.password, "password", g.password, "password of the user (env REAPER_PASSWORD)")
+	fs.StringVar(&g.passwordFile, "password-file", g.passwordFile, "file containing the password of the user (env REAPER_PASSWORD_FILE)")
+	fs.StringVar(&g.token, "token", g.token, "JWT to authenticate with instead of a username (env REAPER_TOKEN)")
+	fs.StringVar(&g.caFile, "ca-file", g.caFile, "PEM file of the certificate authorities to trust")
+	fs.StringVar(&g.certFile, "cert-file", g.certFile, "PEM file of the client certificate, for mutual TLS")
+	fs.StringVar(&g.keyFile, "key-file", g.keyFile, "PEM file of the client key, for mutual TLS")
+	fs.BoolVar(&g.insecureSkipTLSVerify, "insecure-skip-tls-verify", g.insecureSkipTLSVerify, "do not verify the certificate of Reaper")
+	fs.DurationVar(&g.timeout, "timeout", g.timeout, "timeout of each request to Reaper (default 10s)")
+	fs.StringVar(&g.output, "o", g.output, "output format: table, wide, json, yaml or template=<text> (shorthand)")
+	fs.StringVar(&g.output, "output", g.output, "output format: table, wide, json, yaml or template=<text>")
+	fs.StringVar(&g.columns, "columns", g.columns, "comma-separated columns of tables, e.g. id,state,progress")
+	fs.StringVar(&g.sortBy, "sort-by", g.sortBy, "comma-separated columns to sort by; prefix a column with - to sort in descending order")
+	fs.BoolVar(&g.noHeaders, "no-headers", g.noHeaders, "do not print the header line of tables")
+}
+
+// override applies the connection flags on top of a context of the configuration file. Like environment variables,
+// a password overrides a password file and vice versa, and a token overrides credentials.
+func (g *globalOptions) override(c *config.Context) {
+	if g.url != "" {
+		c.URL = g.url
+	}
+	if g.username != "" {
+		c.Username, c.Token = g.username, ""
+	}
+	if g.password != "" {
+		c.Password, c.PasswordFile = g.password, ""
+	}
+	if g.passwordFile != "" {
+		c.Password, c.PasswordFile = "", g.passwordFile
+	}
+	if g.token != "" {
+		c.Username, c.Password, c.PasswordFile, c.Token = "", "", "", g.token
+	}
+	if g.caFile != "" {
+		c.CertificateAuthority = g.caFile
+	}
+	if g.certFile != "" {
+		c.ClientCertificate = g.certFile
+	}
+	if g.keyFile != "" {
+		c.ClientKey = g.keyFile
+	}
+	if g.insecureSkipTLSVerify {
+		c.InsecureSkipTLSVerify = true
+	}
+	if g.timeout != 0 {
+		c.Timeout = config.Duration(g.timeout)
+	}
+	if c.UserAgent == "" {
+		c.UserAgent = "reaperctl"
+	}
+}
+
+// client returns a client for the Reaper designated by the configuration file, the environment and the global flags,
+// in increasing order of precedence. The client is logged in if credentials were given.
+func (c *cli) client(ctx context.Context) (reaper.Client, error) {
+	var cfg *config.Config
+	var err error
+	if c.globals.config != "" {
+		cfg, err = config.Load(c.globals.config)
+	} else {
+		cfg, err = config.LoadDefault()
+	}
+	if err != nil {
+		return nil, err
+	}
+	current, err := cfg.Resolve(c.globals.context, c.getenv)
+	if err != nil {
+		return nil, &usageError{command: "reaperctl", message: err.Error()}
+	}
+	c.globals.override(current)
+	if current.URL == "" {
+		return nil, &usageError{command: "reaperctl", message: "the URL of Reaper is required: use --url, REAPER_URL or a context"}
+	}
+	if err := current.Validate(); err != nil {
+		return nil, &usageError{command: "reaperctl", message: strings.ReplaceAll(err.Error(), "\n", "; ")}
+	}
+	return current.Client(ctx)
+}
+
+// printer returns the printer for the output flags.
+func (c *cli) printer() (*render.Printer, error) {
+	options, err := render.ParseOutput(c.globals.output)
+	if err == nil {
+		options.Columns = splitList(c.globals.columns)
+		options.SortBy = splitList(c.globals.sortBy)
+		options.NoHeaders = c.globals.noHeaders
+		var printer *render.Printer
+		if printer, err = render.NewPrinter(options); err == nil {
+			return printer, nil
+		}
+	}
+	return nil, &usageError{command: "reaperctl", message: err.Error()}
+}
+
+// printObjects writes objects with the printer for the output flags, e.g.
+// printObjects(cli, (*render.Printer).RepairRuns, runs).
+func printObjects[T any](c *cli, write func(*render.Printer, io.Writer, T) error, v T) error {
+	printer, err := c.printer()
+	if err != nil {
+		return err
+	}
+	err = write(printer, c.stdout, v)
+	if errors.Is(err, render.ErrUnknownColumn) {
+		return &usageError{command: "reaperctl", message: err.Error()}
+	}
+	return err
+}
+
+// printResult writes the result of a command: message if the output format is meant for humans, v otherwise.
+func (c *cli) printResult(v interface{}, format string, args ...interface{}) error {
+	printer, err := c.printer()
+	if err != nil {
+		return err
+	}
+	return printer.Result(c.stdout, v, fmt.Sprintf(format, args...))
+}
+
+// printf writes a confirmation message, unless the output format is meant for machines.
+func (c *cli) printf(format string, args ...interface{}) {
+	if printer, err := c.printer(); err == nil && !printer.Machine() {
+		_, _ = fmt.Fprintf(c.stdout, format+"\n", args...)
+	}
+}
+
+func parseId(kind string, arg string) (uuid.UUID, error) {
+	id, err := uuid.Parse(arg)
+	if err != nil {
+		return uuid.Nil, &usageError{command: "reaperctl", message: fmt.Sprintf("invalid %s id %q", kind, arg)}
+	}
+	return id, nil
+}
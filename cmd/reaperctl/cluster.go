@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/k8ssandra/reaper-client-go/compliance"
+	"github.com/k8ssandra/reaper-client-go/render"
+)
+
+func clusterCommand() *command {
+	return &command{
+		name:    "cluster",
+		aliases: []string{"clusters"},
+		summary: "Manage the Cassandra clusters registered in Reaper",
+		commands: []*command{
+			{
+				name:    "list",
+				summary: "List the registered clusters",
+				run:     listClusters,
+			},
+			{
+				name:    "get",
+				summary: "Show a cluster",
+				args:    "<cluster>",
+				nargs:   1,
+				run:     getCluster,
+			},
+			{
+				name:    "nodes",
+				summary: "List the nodes of a cluster and their state",
+				args:    "<cluster>",
+				nargs:   1,
+				run:     listClusterNodes,
+			},
+			addClusterCommand(),
+			clusterComplianceCommand(),
+			{
+				name:    "delete",
+				summary: "Unregister a cluster from Reaper",
+				args:    "<cluster>",
+				nargs:   1,
+				run:     deleteCluster,
+			},
+		},
+	}
+}
+
+func listClusters(ctx context.Context, cli *cli, _ []string) error {
+	client, err := cli.client(ctx)
+	if err != nil {
+		return err
+	}
+	clusters, err := client.GetClustersSync(ctx)
+	if err != nil {
+		return err
+	}
+	return printObjects(cli, (*render.Printer).Clusters, clusters)
+}
+
+func getCluster(ctx context.Context, cli *cli, args []string) error {
+	client, err := cli.client(ctx)
+	if err != nil {
+		return err
+	}
+	cluster, err := client.GetCluster(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return printObjects(cli, (*render.Printer).Cluster, cluster)
+}
+
+func listClusterNodes(ctx context.Context, cli *cli, args []string) error {
+	client, err := cli.client(ctx)
+	if err != nil {
+		return err
+	}
+	cluster, err := client.GetCluster(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return printObjects(cli, (*render.Printer).Endpoints, cluster.Endpoints())
+}
+
+func addClusterCommand() *command {
+	var seed string
+	return &command{
+		name:    "add",
+		summary: "Register a cluster in Reaper",
+		args:    "<cluster>",
+		nargs:   1,
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&seed, "seed", "", "comma-separated list of seed nodes of the cluster (required)")
+		},
+		run: func(ctx context.Context, cli *cli, args []string) error {
+			if seed == "" {
+				return &usageError{command: "reaperctl cluster add", message: "--seed is required"}
+			}
+			client, err := cli.client(ctx)
+			if err != nil {
+				return err
+			}
+			if err := client.AddCluster(ctx, args[0], seed); err != nil {
+				return err
+			}
+			cli.printf("cluster %s added", args[0])
+			return nil
+		},
+	}
+}
+
+func deleteCluster(ctx context.Context, cli *cli, args []string) error {
+	client, err := cli.client(ctx)
+	if err != nil {
+		return err
+	}
+	if err := client.DeleteCluster(ctx, args[0]); err != nil {
+		return err
+	}
+	cli.printf("cluster %s deleted", args[0])
+	return nil
+}
+
+func clusterComplianceCommand() *command {
+	var gcGraceSeconds int64
+	var ignoredKeyspaces string
+	return &command{
+		name:    "compliance",
+		summary: "Report the tables not fully repaired within gc_grace_seconds, or not scheduled",
+		args:    "<cluster>",
+		nargs:   1,
+		flags: func(fs *flag.FlagSet) {
+			fs.Int64Var(&gcGraceSeconds, "gc-grace-seconds", int64(compliance.DefaultGcGrace/time.Second),
+				"maximum age of the last full repair of every table, in seconds")
+			fs.StringVar(&ignoredKeyspaces, "ignore-keyspaces", strings.Join(compliance.DefaultIgnoredKeyspaces, ","),
+				"comma-separated list of keyspaces left out of the report")
+		},
+		run: func(ctx context.Context, cli *cli, args []string) error {
+			if gcGraceSeconds <= 0 {
+				return &usageError{command: "reaperctl cluster compliance", message: "--gc-grace-seconds must be positive"}
+			}
+			client, err := cli.client(ctx)
+			if err != nil {
+				return err
+			}
+			report, err := compliance.Analyze(ctx, client, args[0], compliance.Options{
+				GcGrace:          time.Duration(gcGraceSeconds) * time.Second,
+				IgnoredKeyspaces: splitList(ignoredKeyspaces),
+			})
+			if err != nil {
+				return err
+			}
+			return cli.printResult(report, "%s", complianceSummary(report))
+		},
+	}
+}
+
+// complianceSummary describes a compliance report in a line, followed by a line per table that is exceeded or not
+// scheduled.
+func complianceSummary(report *compliance.Report) string {
+	lines := []string{report.String()}
+	for _, keyspace := range report.Keyspaces {
+		for _, table := range keyspace.Tables {
+			if !table.Exceeded && table.Scheduled {
+				continue
+			}
+			status := "never repaired"
+			if table.DaysSinceRepair != nil {
+				status = fmt.Sprintf("repaired %.2f days ago", *table.DaysSinceRepair)
+			}
+			if table.Exceeded {
+				status += ", exceeds gc_grace"
+			}
+			if !table.Scheduled {
+				status += ", not scheduled"
+			}
+			lines = append(lines, fmt.Sprintf("  %s.%s: %s", table.Keyspace, table.Table, status))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
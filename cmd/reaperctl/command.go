@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var errHelp = flag.ErrHelp
+
+// usageError is returned when the command line is invalid.
+type usageError struct {
+	command string
+	message string
+}
+
+func (e *usageError) Error() string {
+	return fmt.Sprintf("%s (see '%s --help')", e.message, e.command)
+}
+
+// command is either a group of subcommands, or a leaf command with a run function.
+type command struct {
+	name    string
+	aliases []string
+	summary string
+
+	// Usage of the positional arguments of a leaf command, e.g. "<cluster> <keyspace>".
+	args string
+
+	// The number of positional arguments of a leaf command, or -1 for any number.
+	nargs int
+
+	// Registers the flags of a leaf command.
+	flags func(fs *flag.FlagSet)
+
+	run func(ctx context.Context, cli *cli, args []string) error
+
+	commands []*command
+}
+
+func (c *command) execute(ctx context.Context, cli *cli, args []string) error {
+	return c.executeAs(ctx, cli, args, c.name)
+}
+
+// executeAs executes the command, whose full name is path, e.g. "reaperctl run create".
+func (c *command) executeAs(ctx context.Context, cli *cli, args []string, path string) error {
+	fs := c.flagSet(cli, path)
+	if len(c.commands) > 0 {
+		// Global flags are accepted before the subcommand name; everything after it belongs to the subcommand
+		if err := fs.Parse(args); err != nil {
+			return c.parseError(cli, path, err)
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return &usageError{command: path, message: "missing command"}
+		}
+		if args[0] == "help" {
+			return c.help(cli, args[1:], path)
+		}
+		sub := c.find(args[0])
+		if sub == nil {
+			return &usageError{command: path, message: fmt.Sprintf("unknown command %q", args[0])}
+		}
+		return sub.executeAs(ctx, cli, args[1:], path+" "+sub.name)
+	}
+	positional, err := parseInterleaved(fs, args)
+	if err != nil {
+		return c.parseError(cli, path, err)
+	}
+	if c.nargs >= 0 && len(positional) != c.nargs {
+		return &usageError{
+			command: path,
+			message: fmt.Sprintf("expected %d argument(s) %s, got %d", c.nargs, c.args, len(positional)),
+		}
+	}
+	return c.run(ctx, cli, positional)
+}
+
+func (c *command) parseError(cli *cli, path string, err error) error {
+	if errors.Is(err, flag.ErrHelp) {
+		c.printUsage(cli.stdout, path)
+		return errHelp
+	}
+	return &usageError{command: path, message: err.Error()}
+}
+
+// help prints the usage of the subcommand designated by args, e.g. "reaperctl help run create".
+func (c *command) help(cli *cli, args []string, path string) error {
+	target := c
+	for _, name := range args {
+		sub := target.find(name)
+		if sub == nil {
+			return &usageError{command: path, message: fmt.Sprintf("unknown command %q", name)}
+		}
+		target, path = sub, path+" "+sub.name
+	}
+	target.printUsage(cli.stdout, path)
+	return errHelp
+}
+
+func (c *command) find(name string) *command {
+	for _, sub := range c.commands {
+		if sub.name == name {
+			return sub
+		}
+		for _, alias := range sub.aliases {
+			if alias == name {
+				return sub
+			}
+		}
+	}
+	return nil
+}
+
+func (c *command) flagSet(cli *cli, path string) *flag.FlagSet {
+	fs := flag.NewFlagSet(path, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	cli.globals.bind(fs)
+	if c.flags != nil {
+		c.flags(fs)
+	}
+	return fs
+}
+
+func (c *command) printUsage(w io.Writer, path string) {
+	if len(c.commands) > 0 {
+		_, _ = fmt.Fprintf(w, "Usage: %s [flags] <command>\n\n%s.\n\nCommands:\n", path, c.summary)
+		for _, sub := range c.commands {
+			_, _ = fmt.Fprintf(w, "  %-10s %s\n", sub.name, sub.summary)
+		}
+	} else {
+		_, _ = fmt.Fprintf(w, "Usage: %s [flags] %s\n\n%s.\n", path, c.args, c.summary)
+		if c.flags != nil {
+			fs := flag.NewFlagSet(path, flag.ContinueOnError)
+			c.flags(fs)
+			_, _ = fmt.Fprintln(w, "\nFlags:")
+			fs.SetOutput(w)
+			fs.PrintDefaults()
+		}
+	}
+	fs := flag.NewFlagSet(path, flag.ContinueOnError)
+	(&globalOptions{}).bind(fs)
+	_, _ = fmt.Fprintln(w, "\nGlobal flags:")
+	fs.SetOutput(w)
+	fs.PrintDefaults()
+	if len(c.commands) > 0 {
+		_, _ = fmt.Fprintf(w, "\nRun '%s <command> --help' for the usage of a command.\n", path)
+	}
+}
+
+// parseInterleaved parses args with fs, allowing flags after positional arguments, and returns the positional
+// arguments. Arguments after "--" are never parsed as flags.
+func parseInterleaved(fs *flag.FlagSet, args []string) ([]string, error) {
+	var rest []string
+	for i, arg := range args {
+		if arg == "--" {
+			args, rest = args[:i], args[i+1:]
+			break
+		}
+	}
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return append(positional, rest...), nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// splitList splits a comma-separated flag value, ignoring empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
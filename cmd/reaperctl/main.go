@@ -0,0 +1,71 @@
+// Command reaperctl manages clusters, repair runs and repair schedules of a Reaper for Apache Cassandra instance.
+//
+// Usage:
+//
+//	reaperctl [global flags] <resource> <command> [flags] [arguments]
+//
+// Resources are cluster, run and schedule; run "reaperctl help" for the full list of commands. The Reaper to manage is
+// read from the contexts of the configuration file of the config package, ~/.reaper/config by default, and can be
+// overridden with the environment variables documented there, such as REAPER_URL, and with global flags.
+//
+// reaperctl exits with status 0 on success, 1 on errors, 2 on invalid usage, and 3 when the requested resource does
+// not exist.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/k8ssandra/reaper-client-go/reaper"
+)
+
+const (
+	exitOK       = 0
+	exitError    = 1
+	exitUsage    = 2
+	exitNotFound = 3
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run executes the command line args and returns the exit code.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	cli := &cli{stdout: stdout, stderr: stderr, getenv: os.Getenv}
+	err := rootCommand().execute(ctx, cli, args)
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, errHelp):
+		return exitOK
+	case errors.As(err, new(*usageError)):
+		_, _ = fmt.Fprintf(stderr, "reaperctl: %v\n", err)
+		return exitUsage
+	case reaper.IsNotFound(err):
+		_, _ = fmt.Fprintf(stderr, "reaperctl: %v\n", err)
+		return exitNotFound
+	default:
+		_, _ = fmt.Fprintf(stderr, "reaperctl: %v\n", err)
+		return exitError
+	}
+}
+
+func rootCommand() *command {
+	return &command{
+		name:    "reaperctl",
+		summary: "Manage Reaper for Apache Cassandra",
+		commands: []*command{
+			clusterCommand(),
+			repairRunCommand(),
+			repairScheduleCommand(),
+		},
+	}
+}
@@ -0,0 +1,412 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/k8ssandra/reaper-client-go/reapertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	username = "reaperUser"
+	password = "reaperPass"
+)
+
+// reaperctl runs the command line args against server and returns the exit code and outputs.
+type reaperctl func(args ...string) (int, string, string)
+
+func newReaperctl(t *testing.T) (*reapertest.Server, reaperctl) {
+	for _, key := range []string{"REAPER_CONFIG", "REAPER_CONTEXT", "REAPER_URL", "REAPER_USERNAME", "REAPER_PASSWORD",
+		"REAPER_PASSWORD_FILE", "REAPER_TOKEN"} {
+		t.Setenv(key, "")
+	}
+	t.Setenv("HOME", t.TempDir())
+	server := reapertest.NewServer(reapertest.WithCredentials(username, password))
+	t.Cleanup(server.Close)
+	server.AddCassandraCluster(reapertest.NewCassandraCluster("cluster-1", 3).WithKeyspace("ks", 3, "table1", "table2"))
+	global := []string{"--url", server.URL().String(), "--username", username, "--password", password}
+	return server, func(args ...string) (int, string, string) {
+		var stdout, stderr bytes.Buffer
+		code := run(context.Background(), append(append([]string(nil), global...), args...), &stdout, &stderr)
+		return code, stdout.String(), stderr.String()
+	}
+}
+
+func TestClusterCommands(t *testing.T) {
+	_, reaperctl := newReaperctl(t)
+
+	code, stdout, stderr := reaperctl("cluster", "add", "cluster-1", "--seed", "cluster-1-node-0")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "cluster cluster-1 added\n", stdout)
+
+	code, stdout, _ = reaperctl("cluster", "list")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "NAME       NODES  UP  DATACENTERS  VERSION  LOAD\ncluster-1  3      3   datacenter1  4.0.0    3.0 MiB\n", stdout)
+
+	code, stdout, _ = reaperctl("clusters", "list", "-o", "template={{.Name}}")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "cluster-1\n", stdout)
+
+	code, stdout, _ = reaperctl("cluster", "get", "cluster-1", "-o", "json")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, `"Seeds": [`)
+
+	code, stdout, _ = reaperctl("cluster", "nodes", "cluster-1", "-o", "wide")
+	assert.Equal(t, exitOK, code)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	require.Len(t, lines, 4)
+	assert.Regexp(t, `^ENDPOINT\s+DATACENTER\s+RACK\s+STATUS\s+VERSION\s+LOAD\s+HOST ID\s+TOKENS\s+SEVERITY$`, lines[0])
+	assert.Regexp(t, `^cluster-1-node-0\s+datacenter1\s+rack1\s+NORMAL\s+4.0.0\s+1.0 MiB\s+\S+\s+\S+\s+0$`, lines[1])
+
+	code, _, stderr = reaperctl("cluster", "get", "unknown")
+	assert.Equal(t, exitNotFound, code)
+	assert.Contains(t, stderr, `cluster with name "unknown" not found`)
+
+	code, _, stderr = reaperctl("cluster", "add", "cluster-2")
+	assert.Equal(t, exitUsage, code)
+	assert.Equal(t, "reaperctl: --seed is required (see 'reaperctl cluster add --help')\n", stderr)
+
+	code, stdout, _ = reaperctl("cluster", "delete", "cluster-1")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "cluster cluster-1 deleted\n", stdout)
+
+	code, _, _ = reaperctl("cluster", "delete", "cluster-1")
+	assert.Equal(t, exitNotFound, code)
+}
+
+func TestRepairRunCommands(t *testing.T) {
+	_, reaperctl := newReaperctl(t)
+	code, _, stderr := reaperctl("cluster", "add", "cluster-1", "--seed", "cluster-1-node-0")
+	require.Equal(t, exitOK, code, stderr)
+
+	// flags are accepted after positional arguments
+	code, stdout, stderr := reaperctl("run", "create", "cluster-1", "ks", "--owner", "alice", "--tables", "table1",
+		"--segments-per-node", "2", "--intensity", "0.5", "--parallelism", "parallel", "-o", "json")
+	require.Equal(t, exitOK, code, stderr)
+	var created struct {
+		Id string `json:"id"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &created))
+	id := created.Id
+
+	code, stdout, _ = reaperctl("run", "get", id, "--columns", "state,tables,owner,parallelism", "--no-headers")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "NOT_STARTED  table1  alice  PARALLEL\n", stdout)
+
+	code, stdout, _ = reaperctl("run", "get", id, "-o", "yaml")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "\nstate: NOT_STARTED\n")
+
+	for _, step := range []struct{ command, output string }{
+		{"start", "started"},
+		{"pause", "paused"},
+		{"resume", "resumed"},
+	} {
+		code, stdout, stderr = reaperctl("run", step.command, id)
+		require.Equal(t, exitOK, code, stderr)
+		assert.Equal(t, "repair run "+id+" "+step.output+"\n", stdout)
+	}
+
+	code, stdout, _ = reaperctl("run", "list", "--state", "running", "--cluster", "cluster-1")
+	assert.Equal(t, exitOK, code)
+	assert.Regexp(t, `^ID\s+CLUSTER\s+KEYSPACE\s+STATE\s+PROGRESS\s+INTENSITY\s+OWNER\n`+id+`\s+cluster-1\s+ks\s+RUNNING\s+\d+/6\s+0.5\s+alice\n$`, stdout)
+
+	code, stdout, _ = reaperctl("run", "list", "--state", "DONE")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "ID  CLUSTER  KEYSPACE  STATE  PROGRESS  INTENSITY  OWNER\n", stdout)
+
+	code, stdout, _ = reaperctl("run", "segments", id)
+	assert.Equal(t, exitOK, code)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	assert.Len(t, lines, 7)
+	assert.Regexp(t, `^ID\s+STATE\s+START TOKEN\s+END TOKEN\s+COORDINATOR\s+FAILS$`, lines[0])
+	assert.Contains(t, lines[1], "-9223372036854775808")
+
+	code, stdout, _ = reaperctl("run", "segments", id, "--sort-by", "-start-token", "--columns", "start-token", "--no-headers")
+	assert.Equal(t, exitOK, code)
+	assert.True(t, strings.HasSuffix(stdout, "\n-9223372036854775808\n"), stdout)
+
+	code, _, _ = reaperctl("run", "abort", id)
+	assert.Equal(t, exitOK, code)
+	code, _, stderr = reaperctl("run", "delete", id, "--owner", "bob")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "is not owned by the user you defined: bob")
+	code, stdout, _ = reaperctl("run", "purge")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "1 repair run(s) purged\n", stdout)
+
+	code, _, _ = reaperctl("run", "get", id)
+	assert.Equal(t, exitNotFound, code)
+	code, _, stderr = reaperctl("run", "get", "not-a-uuid")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, `invalid repair run id "not-a-uuid"`)
+	code, _, stderr = reaperctl("run", "get")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "expected 1 argument(s) <run id>, got 0")
+	code, _, stderr = reaperctl("run", "create", "cluster-1", "ks", "--intensity", "2", "--threads", "8")
+	assert.Equal(t, exitUsage, code)
+	assert.Equal(t, "reaperctl: invalid options: Intensity must be in (0, 1], got 2; RepairThreadCount must be between 1 "+
+		"and 4, got 8 (see 'reaperctl run create --help')\n", stderr)
+}
+
+func TestWatchCommand(t *testing.T) {
+	_, reaperctl := newReaperctl(t)
+	code, _, stderr := reaperctl("cluster", "add", "cluster-1", "--seed", "cluster-1-node-0")
+	require.Equal(t, exitOK, code, stderr)
+	code, stdout, stderr := reaperctl("run", "create", "cluster-1", "ks", "--owner", "alice", "--segments-per-node", "2",
+		"-o", "template={{.id}}")
+	require.Equal(t, exitOK, code, stderr)
+	id := strings.TrimSpace(stdout)
+	code, _, stderr = reaperctl("run", "start", id)
+	require.Equal(t, exitOK, code, stderr)
+
+	// the output is not a terminal: a line is printed per change, until the run is done
+	code, stdout, stderr = reaperctl("run", "watch", id, "--interval", "10ms")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, " run "+id+" cluster-1/ks DONE 6/6 (100%) intensity 0.9\n")
+	assert.Contains(t, stdout, " DONE coordinator cluster-1-node-")
+
+	code, stdout, stderr = reaperctl("run", "watch", "--cluster", "cluster-1", "--state", "done", "--exit")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, " run "+id+" cluster-1/ks DONE 6/6 (100%)")
+
+	code, _, stderr = reaperctl("run", "watch", id, id)
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "expected at most 1 argument [run id], got 2")
+}
+
+func TestExportCommand(t *testing.T) {
+	_, reaperctl := newReaperctl(t)
+	code, _, stderr := reaperctl("cluster", "add", "cluster-1", "--seed", "cluster-1-node-0")
+	require.Equal(t, exitOK, code, stderr)
+	code, stdout, stderr := reaperctl("run", "create", "cluster-1", "ks", "--owner", "alice", "--segments-per-node", "2",
+		"-o", "template={{.id}}")
+	require.Equal(t, exitOK, code, stderr)
+	id := strings.TrimSpace(stdout)
+
+	code, stdout, stderr = reaperctl("run", "export", "--cluster", "cluster-1", "--segments")
+	require.Equal(t, exitOK, code, stderr)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	require.Len(t, lines, 8, "the header, the run and its 6 segments")
+	assert.True(t, strings.HasPrefix(lines[0], "kind,run_id,segment_id,cluster,keyspace,"))
+	assert.True(t, strings.HasPrefix(lines[1], "run,"+id+",,cluster-1,ks,"))
+	assert.True(t, strings.HasPrefix(lines[2], "segment,"+id+","))
+
+	code, stdout, stderr = reaperctl("run", "export", "--state", "running", "--format", "jsonl")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Empty(t, stdout)
+
+	code, _, stderr = reaperctl("run", "export", "--format", "xml")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, `unknown export format "xml"`)
+}
+
+func TestClusterComplianceCommand(t *testing.T) {
+	_, reaperctl := newReaperctl(t)
+	code, _, stderr := reaperctl("cluster", "add", "cluster-1", "--seed", "cluster-1-node-0")
+	require.Equal(t, exitOK, code, stderr)
+	code, _, stderr = reaperctl("schedule", "create", "cluster-1", "ks", "--tables", "table1")
+	require.Equal(t, exitOK, code, stderr)
+
+	code, stdout, stderr := reaperctl("cluster", "compliance", "cluster-1")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "cluster cluster-1: 2/2 tables exceed gc_grace, 1 not scheduled\n"+
+		"  ks.table1: never repaired, exceeds gc_grace\n"+
+		"  ks.table2: never repaired, exceeds gc_grace, not scheduled\n", stdout)
+
+	code, stdout, stderr = reaperctl("cluster", "compliance", "cluster-1", "--gc-grace-seconds", "3600", "-o", "json")
+	require.Equal(t, exitOK, code, stderr)
+	var report struct {
+		Compliant bool `json:"compliant"`
+		Keyspaces []struct {
+			Tables []struct {
+				GcGraceSeconds int64 `json:"gc_grace_seconds"`
+			} `json:"tables"`
+		} `json:"keyspaces"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &report))
+	assert.False(t, report.Compliant)
+	require.Len(t, report.Keyspaces, 1)
+	assert.Equal(t, int64(3600), report.Keyspaces[0].Tables[0].GcGraceSeconds)
+
+	code, _, _ = reaperctl("cluster", "compliance", "unknown")
+	assert.Equal(t, exitNotFound, code)
+	code, _, stderr = reaperctl("cluster", "compliance", "cluster-1", "--gc-grace-seconds", "0")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "--gc-grace-seconds must be positive")
+}
+
+func TestRepairScheduleCommands(t *testing.T) {
+	_, reaperctl := newReaperctl(t)
+	code, _, stderr := reaperctl("cluster", "add", "cluster-1", "--seed", "cluster-1-node-0")
+	require.Equal(t, exitOK, code, stderr)
+
+	code, stdout, stderr := reaperctl("schedule", "create", "cluster-1", "ks", "--days-between", "3",
+		"--trigger-time", "2030-01-01T00:00:00Z", "-o", "json")
+	require.Equal(t, exitOK, code, stderr)
+	var created struct {
+		Id string `json:"id"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &created))
+	id := created.Id
+
+	code, stdout, _ = reaperctl("schedules", "list", "--cluster", "cluster-1")
+	assert.Equal(t, exitOK, code)
+	assert.Regexp(t, `(?m)^`+id+`\s+cluster-1\s+ks\s+ACTIVE\s+3\s+\S+\s+reaperctl$`, stdout)
+
+	code, stdout, _ = reaperctl("schedule", "get", id, "-o", "json")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, `"scheduled_days_between": 3`)
+
+	code, _, _ = reaperctl("schedule", "delete", id)
+	assert.Equal(t, exitError, code, "active schedules cannot be deleted")
+	code, stdout, _ = reaperctl("schedule", "pause", id)
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "repair schedule "+id+" paused\n", stdout)
+	code, _, stderr = reaperctl("schedule", "delete", id)
+	assert.Equal(t, exitOK, code, stderr)
+	code, _, _ = reaperctl("schedule", "get", id)
+	assert.Equal(t, exitNotFound, code)
+}
+
+func TestSchedulePlanCommand(t *testing.T) {
+	_, reaperctl := newReaperctl(t)
+	code, _, stderr := reaperctl("cluster", "add", "cluster-1", "--seed", "cluster-1-node-0")
+	require.Equal(t, exitOK, code, stderr)
+
+	code, stdout, stderr := reaperctl("schedule", "plan", "cluster-1")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, "cluster cluster-1: 0 overlaps, 0 conflicts, 0 collisions, 1 gaps\n"+
+		"  gap: ks all tables not scheduled\n"+
+		"suggested timetable:\n")
+	assert.Regexp(t, `(?m)^  \S+  ks  new schedule every 7 days$`, stdout)
+
+	for _, schedule := range [][]string{
+		{"--tables", "table1", "--trigger-time", "2030-01-01T00:00:00Z"},
+		{"--trigger-time", "2030-01-01T00:30:00Z"},
+	} {
+		args := append([]string{"schedule", "create", "cluster-1", "ks", "--days-between", "3"}, schedule...)
+		code, _, stderr = reaperctl(args...)
+		require.Equal(t, exitOK, code, stderr)
+	}
+	code, stdout, stderr = reaperctl("schedule", "plan", "cluster-1", "--window", "1h", "-o", "json")
+	require.Equal(t, exitOK, code, stderr)
+	var report struct {
+		Clean    bool `json:"clean"`
+		Overlaps []struct {
+			Tables []string `json:"tables"`
+		} `json:"overlaps"`
+		Collisions []struct {
+			First string `json:"first"`
+			Last  string `json:"last"`
+		} `json:"collisions"`
+		Gaps      []interface{} `json:"gaps"`
+		Timetable []struct {
+			Suggested string `json:"suggested"`
+		} `json:"timetable"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &report))
+	assert.False(t, report.Clean)
+	require.Len(t, report.Overlaps, 1)
+	assert.Equal(t, []string{"table1"}, report.Overlaps[0].Tables)
+	require.Len(t, report.Collisions, 1)
+	assert.Equal(t, "2030-01-01T00:00:00Z", report.Collisions[0].First)
+	assert.Equal(t, "2030-01-01T00:30:00Z", report.Collisions[0].Last)
+	assert.Empty(t, report.Gaps)
+	require.Len(t, report.Timetable, 2)
+	assert.Equal(t, "2030-01-01T00:00:00Z", report.Timetable[0].Suggested)
+	assert.Equal(t, "2030-01-01T01:00:00Z", report.Timetable[1].Suggested)
+
+	code, _, _ = reaperctl("schedule", "plan", "unknown")
+	assert.Equal(t, exitNotFound, code)
+	code, _, stderr = reaperctl("schedule", "plan", "cluster-1", "--days-between", "0")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "--window and --days-between must be positive")
+}
+
+func TestGlobalFlags(t *testing.T) {
+	server, _ := newReaperctl(t)
+	var stdout, stderr bytes.Buffer
+
+	code := run(context.Background(), []string{"cluster", "list"}, &stdout, &stderr)
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr.String(), "the URL of Reaper is required")
+
+	// global flags are accepted at any level, and read from the environment
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte(password+"\n"), 0600))
+	t.Setenv("REAPER_URL", server.URL().String())
+	stderr.Reset()
+	code = run(context.Background(), []string{"cluster", "--username", username, "list", "--password-file", passwordFile}, &stdout, &stderr)
+	assert.Equal(t, exitOK, code, stderr.String())
+
+	stderr.Reset()
+	code = run(context.Background(), []string{"--username", username, "--password", "wrong", "cluster", "list"}, &stdout, &stderr)
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr.String(), "failed to log in as reaperUser")
+
+	stderr.Reset()
+	code = run(context.Background(), []string{"cluster", "list", "--ca-file", "/nonexistent"}, &stdout, &stderr)
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr.String(), "failed to read certificate authority")
+
+	// contexts are read from the configuration file, and can be overridden by flags
+	t.Setenv("REAPER_URL", "")
+	configFile := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(configFile, []byte(`
+current-context: broken
+contexts:
+- name: broken
+  url: http://localhost:1
+- name: test
+  url: `+server.URL().String()+`
+  username: `+username+`
+  password-file: password
+`), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(configFile), "password"), []byte(password), 0600))
+	stderr.Reset()
+	code = run(context.Background(), []string{"--config", configFile, "--context", "test", "cluster", "list"}, &stdout, &stderr)
+	assert.Equal(t, exitOK, code, stderr.String())
+
+	t.Setenv("REAPER_CONFIG", configFile)
+	stderr.Reset()
+	code = run(context.Background(), []string{"cluster", "list", "--url", server.URL().String()}, &stdout, &stderr)
+	assert.Equal(t, exitOK, code, stderr.String())
+
+	stderr.Reset()
+	code = run(context.Background(), []string{"cluster", "list", "--context", "unknown"}, &stdout, &stderr)
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr.String(), `context "unknown" not found, expected one of broken, test`)
+
+	stderr.Reset()
+	code = run(context.Background(), []string{"cluster", "list", "--context", "test", "--url", "localhost:8080"}, &stdout, &stderr)
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr.String(), `invalid url "localhost:8080"`)
+
+	stderr.Reset()
+	code = run(context.Background(), []string{"--bogus"}, &stdout, &stderr)
+	assert.Equal(t, exitUsage, code)
+	assert.Equal(t, "reaperctl: flag provided but not defined: -bogus (see 'reaperctl --help')\n", stderr.String())
+
+	stderr.Reset()
+	code = run(context.Background(), []string{"cluster", "list", "-o", "xml"}, &stdout, &stderr)
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr.String(), `unknown output format "xml"`)
+
+	stderr.Reset()
+	code = run(context.Background(), []string{"cluster", "list", "--sort-by", "bogus"}, &stdout, &stderr)
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr.String(), `unknown column "bogus"`)
+
+	stdout.Reset()
+	code = run(context.Background(), []string{"help", "run", "create"}, &stdout, &stderr)
+	assert.Equal(t, exitOK, code)
+	assert.True(t, strings.HasPrefix(stdout.String(), "Usage: reaperctl run create [flags] <cluster> <keyspace>\n"))
+}
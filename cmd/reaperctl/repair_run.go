@@ -0,0 +1,331 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/k8ssandra/reaper-client-go/export"
+	"github.com/k8ssandra/reaper-client-go/reaper"
+	"github.com/k8ssandra/reaper-client-go/render"
+	"github.com/k8ssandra/reaper-client-go/watch"
+)
+
+func repairRunCommand() *command {
+	return &command{
+		name:    "run",
+		aliases: []string{"runs", "repair-run"},
+		summary: "Manage repair runs",
+		commands: []*command{
+			listRepairRunsCommand(),
+			{
+				name:    "get",
+				summary: "Show a repair run",
+				args:    "<run id>",
+				nargs:   1,
+				run:     getRepairRun,
+			},
+			createRepairRunCommand(),
+			repairRunStateCommand("start", "Start a repair run", "started", reaper.Client.StartRepairRun),
+			repairRunStateCommand("pause", "Pause a running repair run", "paused", reaper.Client.PauseRepairRun),
+			repairRunStateCommand("resume", "Resume a paused repair run", "resumed", reaper.Client.ResumeRepairRun),
+			repairRunStateCommand("abort", "Abort a repair run", "aborted", reaper.Client.AbortRepairRun),
+			{
+				name:    "segments",
+				summary: "List the segments of a repair run",
+				args:    "<run id>",
+				nargs:   1,
+				run:     listRepairRunSegments,
+			},
+			watchRepairRunsCommand(),
+			exportRepairRunsCommand(),
+			deleteRepairRunCommand(),
+			{
+				name:    "purge",
+				summary: "Delete the repair runs that are DONE, in ERROR or ABORTED",
+				run:     purgeRepairRuns,
+			},
+		},
+	}
+}
+
+func listRepairRunsCommand() *command {
+	var options reaper.RepairRunSearchOptions
+	var states string
+	return &command{
+		name:    "list",
+		summary: "List repair runs",
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&options.Cluster, "cluster", "", "only list repair runs of this cluster")
+			fs.StringVar(&options.Keyspace, "keyspace", "", "only list repair runs of this keyspace")
+			fs.StringVar(&states, "state", "", "only list repair runs in these comma-separated states")
+		},
+		run: func(ctx context.Context, cli *cli, _ []string) error {
+			for _, state := range splitList(states) {
+				options.States = append(options.States, reaper.RepairRunState(strings.ToUpper(state)))
+			}
+			client, err := cli.client(ctx)
+			if err != nil {
+				return err
+			}
+			runs, err := client.RepairRuns(ctx, &options)
+			if err != nil {
+				return err
+			}
+			list := make([]*reaper.RepairRun, 0, len(runs))
+			for _, run := range runs {
+				list = append(list, run)
+			}
+			return printObjects(cli, (*render.Printer).RepairRuns, list)
+		},
+	}
+}
+
+func getRepairRun(ctx context.Context, cli *cli, args []string) error {
+	id, err := parseId("repair run", args[0])
+	if err != nil {
+		return err
+	}
+	client, err := cli.client(ctx)
+	if err != nil {
+		return err
+	}
+	run, err := client.RepairRun(ctx, id)
+	if err != nil {
+		return err
+	}
+	return printObjects(cli, (*render.Printer).RepairRun, run)
+}
+
+func createRepairRunCommand() *command {
+	var options reaper.RepairRunCreateOptions
+	var owner, parallelism, tables, ignoredTables, nodes, datacenters string
+	var start bool
+	return &command{
+		name:    "create",
+		summary: "Create a repair run of a keyspace",
+		args:    "<cluster> <keyspace>",
+		nargs:   2,
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&owner, "owner", "reaperctl", "owner of the repair run")
+			fs.StringVar(&options.Cause, "cause", "", "why the repair run was created")
+			fs.StringVar(&tables, "tables", "", "comma-separated tables to repair; defaults to all tables")
+			fs.StringVar(&ignoredTables, "ignored-tables", "", "comma-separated tables not to repair")
+			fs.IntVar(&options.SegmentCountPerNode, "segments-per-node", 0, "number of segments per node, between 1 and 1000")
+			fs.StringVar(&parallelism, "parallelism", "", "SEQUENTIAL, PARALLEL or DATACENTER_AWARE")
+			fs.Float64Var(&options.Intensity, "intensity", 0, "intensity of the repair, in (0, 1]")
+			fs.BoolVar(&options.IncrementalRepair, "incremental", false, "run an incremental repair")
+			fs.StringVar(&nodes, "nodes", "", "comma-separated nodes whose tokens should be repaired")
+			fs.StringVar(&datacenters, "datacenters", "", "comma-separated datacenters to repair")
+			fs.IntVar(&options.RepairThreadCount, "threads", 0, "number of repair threads, between 1 and 4")
+			fs.BoolVar(&start, "start", false, "start the repair run once created")
+		},
+		run: func(ctx context.Context, cli *cli, args []string) error {
+			options.RepairParallelism = reaper.RepairParallelism(strings.ToUpper(parallelism))
+			options.Tables = splitList(tables)
+			options.IgnoredTables = splitList(ignoredTables)
+			options.Nodes = splitList(nodes)
+			options.Datacenters = splitList(datacenters)
+			if err := options.Validate(); err != nil {
+				return &usageError{command: "reaperctl run create", message: err.Error()}
+			}
+			client, err := cli.client(ctx)
+			if err != nil {
+				return err
+			}
+			id, err := client.CreateRepairRun(ctx, args[0], args[1], owner, &options)
+			if err != nil {
+				return err
+			}
+			if start {
+				if err := client.StartRepairRun(ctx, id); err != nil {
+					return err
+				}
+			}
+			result := map[string]interface{}{"id": id, "started": start}
+			if start {
+				return cli.printResult(result, "repair run %v created and started", id)
+			}
+			return cli.printResult(result, "repair run %v created", id)
+		},
+	}
+}
+
+func repairRunStateCommand(
+	name string,
+	summary string,
+	done string,
+	change func(reaper.Client, context.Context, uuid.UUID) error,
+) *command {
+	return &command{
+		name:    name,
+		summary: summary,
+		args:    "<run id>",
+		nargs:   1,
+		run: func(ctx context.Context, cli *cli, args []string) error {
+			id, err := parseId("repair run", args[0])
+			if err != nil {
+				return err
+			}
+			client, err := cli.client(ctx)
+			if err != nil {
+				return err
+			}
+			if err := change(client, ctx, id); err != nil {
+				return err
+			}
+			cli.printf("repair run %v %s", id, done)
+			return nil
+		},
+	}
+}
+
+func listRepairRunSegments(ctx context.Context, cli *cli, args []string) error {
+	id, err := parseId("repair run", args[0])
+	if err != nil {
+		return err
+	}
+	client, err := cli.client(ctx)
+	if err != nil {
+		return err
+	}
+	segments, err := client.RepairRunSegments(ctx, id)
+	if err != nil {
+		return err
+	}
+	list := make([]*reaper.RepairSegment, 0, len(segments))
+	for _, segment := range segments {
+		list = append(list, segment)
+	}
+	return printObjects(cli, (*render.Printer).RepairSegments, list)
+}
+
+func watchRepairRunsCommand() *command {
+	var options watch.Options
+	var search reaper.RepairRunSearchOptions
+	var states string
+	var plain bool
+	return &command{
+		name:    "watch",
+		summary: "Show the progress of repair runs live, or of the segments of a single run",
+		args:    "[run id]",
+		nargs:   -1,
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&search.Cluster, "cluster", "", "only watch repair runs of this cluster")
+			fs.StringVar(&search.Keyspace, "keyspace", "", "only watch repair runs of this keyspace")
+			fs.StringVar(&states, "state", "", "only watch repair runs in these comma-separated states")
+			fs.DurationVar(&options.Interval, "interval", 2*time.Second, "time between refreshes")
+			fs.BoolVar(&options.ExitWhenDone, "exit", false, "exit once all the watched runs are terminated; always set when watching a single run")
+			fs.IntVar(&options.MaxSegments, "max-segments", 20, "maximum number of segments shown when watching a single run")
+			fs.BoolVar(&plain, "plain", false, "print a line per change instead of redrawing the terminal")
+		},
+		run: func(ctx context.Context, cli *cli, args []string) error {
+			switch len(args) {
+			case 0:
+				for _, state := range splitList(states) {
+					search.States = append(search.States, reaper.RepairRunState(strings.ToUpper(state)))
+				}
+				options.Search = &search
+			case 1:
+				id, err := parseId("repair run", args[0])
+				if err != nil {
+					return err
+				}
+				options.RunId = id
+				options.ExitWhenDone = true
+			default:
+				return &usageError{
+					command: "reaperctl run watch",
+					message: fmt.Sprintf("expected at most 1 argument [run id], got %d", len(args)),
+				}
+			}
+			if plain {
+				options.Mode = watch.ModeLines
+			}
+			client, err := cli.client(ctx)
+			if err != nil {
+				return err
+			}
+			err = watch.NewWatcher(client, cli.stdout, options).Run(ctx)
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+			return err
+		},
+	}
+}
+
+func exportRepairRunsCommand() *command {
+	var options export.Options
+	var states, format string
+	return &command{
+		name:    "export",
+		summary: "Export the history of repair runs, e.g. for compliance reports",
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&options.Cluster, "cluster", "", "only export repair runs of this cluster")
+			fs.StringVar(&options.Keyspace, "keyspace", "", "only export repair runs of this keyspace")
+			fs.StringVar(&states, "state", "", "only export repair runs in these comma-separated states")
+			fs.BoolVar(&options.Segments, "segments", false, "also export a record per segment of every repair run")
+			fs.StringVar(&format, "format", string(export.FormatCsv), "output format: csv, jsonl or columnar")
+		},
+		run: func(ctx context.Context, cli *cli, _ []string) error {
+			for _, state := range splitList(states) {
+				options.States = append(options.States, reaper.RepairRunState(strings.ToUpper(state)))
+			}
+			w, err := export.NewWriter(cli.stdout, export.Format(format))
+			if err != nil {
+				return &usageError{command: "reaperctl run export", message: err.Error()}
+			}
+			client, err := cli.client(ctx)
+			if err != nil {
+				return err
+			}
+			_, err = export.Export(ctx, client, w, options)
+			return err
+		},
+	}
+}
+
+func deleteRepairRunCommand() *command {
+	var owner string
+	return &command{
+		name:    "delete",
+		summary: "Delete a repair run that is not running",
+		args:    "<run id>",
+		nargs:   1,
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&owner, "owner", "reaperctl", "owner of the repair run")
+		},
+		run: func(ctx context.Context, cli *cli, args []string) error {
+			id, err := parseId("repair run", args[0])
+			if err != nil {
+				return err
+			}
+			client, err := cli.client(ctx)
+			if err != nil {
+				return err
+			}
+			if err := client.DeleteRepairRun(ctx, id, owner); err != nil {
+				return err
+			}
+			cli.printf("repair run %v deleted", id)
+			return nil
+		},
+	}
+}
+
+func purgeRepairRuns(ctx context.Context, cli *cli, _ []string) error {
+	client, err := cli.client(ctx)
+	if err != nil {
+		return err
+	}
+	purged, err := client.PurgeRepairRuns(ctx)
+	if err != nil {
+		return err
+	}
+	return cli.printResult(map[string]int{"purged": purged}, "%d repair run(s) purged", purged)
+}
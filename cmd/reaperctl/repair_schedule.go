@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/k8ssandra/reaper-client-go/planner"
+	"github.com/k8ssandra/reaper-client-go/reaper"
+	"github.com/k8ssandra/reaper-client-go/render"
+)
+
+func repairScheduleCommand() *command {
+	return &command{
+		name:    "schedule",
+		aliases: []string{"schedules", "repair-schedule"},
+		summary: "Manage repair schedules",
+		commands: []*command{
+			listRepairSchedulesCommand(),
+			{
+				name:    "get",
+				summary: "Show a repair schedule",
+				args:    "<schedule id>",
+				nargs:   1,
+				run:     getRepairSchedule,
+			},
+			createRepairScheduleCommand(),
+			repairScheduleStateCommand("start", "Trigger a repair run of a schedule now", "started", reaper.Client.StartRepairSchedule),
+			repairScheduleStateCommand("pause", "Pause a repair schedule", "paused", reaper.Client.PauseRepairSchedule),
+			repairScheduleStateCommand("resume", "Resume a paused repair schedule", "resumed", reaper.Client.ResumeRepairSchedule),
+			deleteRepairScheduleCommand(),
+			planRepairSchedulesCommand(),
+		},
+	}
+}
+
+func listRepairSchedulesCommand() *command {
+	var cluster string
+	return &command{
+		name:    "list",
+		summary: "List repair schedules",
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&cluster, "cluster", "", "only list repair schedules of this cluster")
+		},
+		run: func(ctx context.Context, cli *cli, _ []string) error {
+			client, err := cli.client(ctx)
+			if err != nil {
+				return err
+			}
+			var schedules []reaper.RepairSchedule
+			if cluster == "" {
+				schedules, err = client.RepairSchedules(ctx)
+			} else {
+				schedules, err = client.RepairSchedulesForCluster(ctx, cluster)
+			}
+			if err != nil {
+				return err
+			}
+			return printObjects(cli, (*render.Printer).RepairSchedules, schedules)
+		},
+	}
+}
+
+func getRepairSchedule(ctx context.Context, cli *cli, args []string) error {
+	id, err := parseId("repair schedule", args[0])
+	if err != nil {
+		return err
+	}
+	client, err := cli.client(ctx)
+	if err != nil {
+		return err
+	}
+	schedule, err := client.RepairSchedule(ctx, id)
+	if err != nil {
+		return err
+	}
+	return printObjects(cli, (*render.Printer).RepairSchedule, schedule)
+}
+
+func createRepairScheduleCommand() *command {
+	var options reaper.RepairScheduleCreateOptions
+	var owner, parallelism, tables, ignoredTables, nodes, datacenters, triggerTime string
+	var daysBetween int
+	return &command{
+		name:    "create",
+		summary: "Create a repair schedule of a keyspace",
+		args:    "<cluster> <keyspace>",
+		nargs:   2,
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&owner, "owner", "reaperctl", "owner of the repair schedule")
+			fs.IntVar(&daysBetween, "days-between", 7, "number of days between repair runs")
+			fs.StringVar(&triggerTime, "trigger-time", "", "RFC 3339 time of the first repair run; defaults to the next day")
+			fs.StringVar(&tables, "tables", "", "comma-separated tables to repair; defaults to all tables")
+			fs.StringVar(&ignoredTables, "ignored-tables", "", "comma-separated tables not to repair")
+			fs.IntVar(&options.SegmentCountPerNode, "segments-per-node", 0, "number of segments per node, between 1 and 1000")
+			fs.StringVar(&parallelism, "parallelism", "", "SEQUENTIAL, PARALLEL or DATACENTER_AWARE")
+			fs.Float64Var(&options.Intensity, "intensity", 0, "intensity of the repairs, in (0, 1]")
+			fs.BoolVar(&options.IncrementalRepair, "incremental", false, "run incremental repairs")
+			fs.StringVar(&nodes, "nodes", "", "comma-separated nodes whose tokens should be repaired")
+			fs.StringVar(&datacenters, "datacenters", "", "comma-separated datacenters to repair")
+			fs.IntVar(&options.RepairThreadCount, "threads", 0, "number of repair threads, between 1 and 4")
+		},
+		run: func(ctx context.Context, cli *cli, args []string) error {
+			if triggerTime != "" {
+				t, err := time.Parse(time.RFC3339, triggerTime)
+				if err != nil {
+					return &usageError{command: "reaperctl schedule create", message: fmt.Sprintf("invalid --trigger-time: %v", err)}
+				}
+				options.TriggerTime = &t
+			}
+			options.RepairParallelism = reaper.RepairParallelism(strings.ToUpper(parallelism))
+			options.Tables = splitList(tables)
+			options.IgnoredTables = splitList(ignoredTables)
+			options.Nodes = splitList(nodes)
+			options.Datacenters = splitList(datacenters)
+			client, err := cli.client(ctx)
+			if err != nil {
+				return err
+			}
+			id, err := client.CreateRepairSchedule(ctx, args[0], args[1], owner, daysBetween, &options)
+			if err != nil {
+				return err
+			}
+			return cli.printResult(map[string]interface{}{"id": id}, "repair schedule %v created", id)
+		},
+	}
+}
+
+func repairScheduleStateCommand(
+	name string,
+	summary string,
+	done string,
+	change func(reaper.Client, context.Context, uuid.UUID) error,
+) *command {
+	return &command{
+		name:    name,
+		summary: summary,
+		args:    "<schedule id>",
+		nargs:   1,
+		run: func(ctx context.Context, cli *cli, args []string) error {
+			id, err := parseId("repair schedule", args[0])
+			if err != nil {
+				return err
+			}
+			client, err := cli.client(ctx)
+			if err != nil {
+				return err
+			}
+			if err := change(client, ctx, id); err != nil {
+				return err
+			}
+			cli.printf("repair schedule %v %s", id, done)
+			return nil
+		},
+	}
+}
+
+func deleteRepairScheduleCommand() *command {
+	var owner string
+	return &command{
+		name:    "delete",
+		summary: "Delete a paused repair schedule",
+		args:    "<schedule id>",
+		nargs:   1,
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&owner, "owner", "reaperctl", "owner of the repair schedule")
+		},
+		run: func(ctx context.Context, cli *cli, args []string) error {
+			id, err := parseId("repair schedule", args[0])
+			if err != nil {
+				return err
+			}
+			client, err := cli.client(ctx)
+			if err != nil {
+				return err
+			}
+			if err := client.DeleteRepairSchedule(ctx, id, owner); err != nil {
+				return err
+			}
+			cli.printf("repair schedule %v deleted", id)
+			return nil
+		},
+	}
+}
+
+func planRepairSchedulesCommand() *command {
+	var options planner.Options
+	return &command{
+		name:    "plan",
+		summary: "Check the schedules of a cluster for overlaps, conflicts and gaps, and suggest a staggered timetable",
+		args:    "<cluster>",
+		nargs:   1,
+		flags: func(fs *flag.FlagSet) {
+			fs.DurationVar(&options.Window, "window", planner.DefaultWindow,
+				"minimum time between two activations, and between the activations of the timetable")
+			fs.IntVar(&options.DaysBetween, "days-between", planner.DefaultDaysBetween,
+				"days between repairs of the schedules suggested for unscheduled keyspaces")
+		},
+		run: func(ctx context.Context, cli *cli, args []string) error {
+			if options.Window <= 0 || options.DaysBetween <= 0 {
+				return &usageError{
+					command: "reaperctl schedule plan",
+					message: "--window and --days-between must be positive",
+				}
+			}
+			client, err := cli.client(ctx)
+			if err != nil {
+				return err
+			}
+			report, err := planner.Analyze(ctx, client, args[0], options)
+			if err != nil {
+				return err
+			}
+			return cli.printResult(report, "%s", planSummary(report))
+		},
+	}
+}
+
+// planSummary describes a planner report in a line, followed by a line per finding and per slot of the timetable.
+func planSummary(report *planner.Report) string {
+	lines := []string{report.String()}
+	for _, overlap := range report.Overlaps {
+		lines = append(lines, fmt.Sprintf("  overlap: %s tables %s repaired by %s", overlap.Keyspace,
+			strings.Join(overlap.Tables, ","), strings.Join(overlap.ScheduleIds, ",")))
+	}
+	for _, conflict := range report.Conflicts {
+		lines = append(lines, fmt.Sprintf("  conflict: %s %s differs among %s (%s)", conflict.Keyspace,
+			conflict.Setting, strings.Join(conflict.ScheduleIds, ","), strings.Join(conflict.Values, ",")))
+	}
+	for _, collision := range report.Collisions {
+		lines = append(lines, fmt.Sprintf("  collision: %s activated between %s and %s", strings.Join(collision.ScheduleIds, ","),
+			collision.First.Format(time.RFC3339), collision.Last.Format(time.RFC3339)))
+	}
+	for _, gap := range report.Gaps {
+		tables := "all tables"
+		if gap.Partial {
+			tables = "tables " + strings.Join(gap.Tables, ",")
+		}
+		lines = append(lines, fmt.Sprintf("  gap: %s %s not scheduled", gap.Keyspace, tables))
+	}
+	lines = append(lines, "suggested timetable:")
+	for _, slot := range report.Timetable {
+		schedule := slot.ScheduleId
+		if schedule == "" {
+			schedule = "new schedule"
+		}
+		lines = append(lines, fmt.Sprintf("  %s  %s  %s every %d days", slot.Suggested.Format(time.RFC3339),
+			slot.Keyspace, schedule, slot.DaysBetween))
+	}
+	return strings.Join(lines, "\n")
+}
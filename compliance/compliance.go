@@ -0,0 +1,262 @@
+// Package compliance checks that every table of a cluster is fully repaired within gc_grace_seconds, the delay after
+// which Cassandra purges tombstones: a replica that missed a delete and isn't repaired in time resurrects the deleted
+// data.
+//
+// Analyze combines the schema of the cluster, its DONE repair runs and its repair schedules into a Report, listing
+// for every table the end of its last successful full repair, whether an active schedule covers it and whether the
+// repair is older than the gc_grace threshold. Reports marshal to JSON, for alerting:
+//
+//	report, err := compliance.Analyze(ctx, client, "cluster-1", compliance.Options{})
+//	if !report.Compliant {
+//		json.NewEncoder(os.Stdout).Encode(report)
+//	}
+package compliance
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/k8ssandra/reaper-client-go/reaper"
+)
+
+// DefaultGcGrace is the default gc_grace_seconds of Cassandra tables, 10 days.
+const DefaultGcGrace = 864000 * time.Second
+
+// DefaultIgnoredKeyspaces are the keyspaces that are local to every node or virtual, and never repaired.
+var DefaultIgnoredKeyspaces = []string{"system", "system_schema", "system_views", "system_virtual_schema"}
+
+type Options struct {
+	// GcGrace is the threshold beyond which a table is reported as exceeded. Reaper doesn't expose the
+	// gc_grace_seconds of tables, so it should match the gc_grace_seconds of the cluster. Defaults to DefaultGcGrace.
+	GcGrace time.Duration
+
+	// GcGraceOverrides replaces GcGrace for a keyspace, or a table as "keyspace.table". Tables take precedence over
+	// their keyspace.
+	GcGraceOverrides map[string]time.Duration
+
+	// IgnoredKeyspaces are left out of the report. Defaults to DefaultIgnoredKeyspaces when nil.
+	IgnoredKeyspaces []string
+
+	// Now is the time the days since the last repairs are computed from. Defaults to the current time.
+	Now time.Time
+}
+
+// TableStatus is the repair status of a table.
+type TableStatus struct {
+	Keyspace string `json:"keyspace"`
+	Table    string `json:"table"`
+
+	// The end of the last DONE full repair run covering the table, and that run; nil if the table was never repaired.
+	LastRepair      *time.Time `json:"last_repair"`
+	LastRepairRunId *uuid.UUID `json:"last_repair_run_id"`
+
+	// The days elapsed since LastRepair, rounded to the hundredth; nil if the table was never repaired.
+	DaysSinceRepair *float64 `json:"days_since_repair"`
+
+	// The threshold the table is checked against.
+	GcGraceSeconds int64 `json:"gc_grace_seconds"`
+
+	// Scheduled is true if at least one active schedule of full repairs covers the table.
+	Scheduled   bool     `json:"scheduled"`
+	ScheduleIds []string `json:"schedule_ids"`
+
+	// Exceeded is true if the table was not repaired within GcGraceSeconds, or never repaired.
+	Exceeded bool `json:"exceeded"`
+}
+
+// KeyspaceStatus aggregates the status of the tables of a keyspace.
+type KeyspaceStatus struct {
+	Keyspace string `json:"keyspace"`
+
+	// The oldest LastRepair of the tables, and its days since; nil if any table was never repaired.
+	LastRepair      *time.Time `json:"last_repair"`
+	DaysSinceRepair *float64   `json:"days_since_repair"`
+
+	// Scheduled is true if every table is scheduled.
+	Scheduled bool `json:"scheduled"`
+
+	// Exceeded is true if any table is exceeded.
+	Exceeded bool `json:"exceeded"`
+
+	Tables []*TableStatus `json:"tables"`
+}
+
+// Report is the repair status of the keyspaces of a cluster, sorted by name.
+type Report struct {
+	Cluster     string    `json:"cluster"`
+	GeneratedAt time.Time `json:"generated_at"`
+
+	// Compliant is true if no table is exceeded.
+	Compliant bool `json:"compliant"`
+
+	Tables            int `json:"tables"`
+	ExceededTables    int `json:"exceeded_tables"`
+	UnscheduledTables int `json:"unscheduled_tables"`
+
+	Keyspaces []*KeyspaceStatus `json:"keyspaces"`
+}
+
+// Analyze reports the repair status of the tables of cluster.
+//
+// Only full repairs of whole keyspaces or tables count: incremental runs and schedules, and those restricted to some
+// nodes or datacenters, are ignored since they don't guarantee that every replica was repaired.
+func Analyze(ctx context.Context, client reaper.Client, cluster string, options Options) (*Report, error) {
+	schema, err := client.ClusterSchema(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	schedules, err := client.RepairSchedulesForCluster(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	now := options.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	report := &Report{Cluster: cluster, GeneratedAt: now.UTC()}
+
+	ignored := options.IgnoredKeyspaces
+	if ignored == nil {
+		ignored = DefaultIgnoredKeyspaces
+	}
+	tables := map[string]*TableStatus{}
+	for _, keyspace := range schema.Keyspaces() {
+		if contains(ignored, keyspace) {
+			continue
+		}
+		keyspaceStatus := &KeyspaceStatus{Keyspace: keyspace}
+		report.Keyspaces = append(report.Keyspaces, keyspaceStatus)
+		names := append([]string(nil), schema[keyspace]...)
+		sort.Strings(names)
+		for _, table := range names {
+			status := &TableStatus{
+				Keyspace:       keyspace,
+				Table:          table,
+				GcGraceSeconds: int64(options.gcGrace(keyspace, table) / time.Second),
+			}
+			tables[keyspace+"."+table] = status
+			keyspaceStatus.Tables = append(keyspaceStatus.Tables, status)
+		}
+	}
+
+	for _, schedule := range schedules {
+		if schedule.State != string(reaper.RepairScheduleStateActive) || !full(schedule.IncrementalRepair, schedule.Nodes,
+			schedule.Datacenters) {
+			continue
+		}
+		for _, table := range schema.Targets(schedule.KeyspaceName, schedule.Tables, schedule.IgnoredTables) {
+			if status, found := tables[schedule.KeyspaceName+"."+table]; found {
+				status.Scheduled = true
+				status.ScheduleIds = append(status.ScheduleIds, schedule.Id)
+			}
+		}
+	}
+
+	searchOptions := &reaper.RepairRunSearchOptions{Cluster: cluster, States: []reaper.RepairRunState{
+		reaper.RepairRunStateDone,
+	}}
+	for run, err := range client.AllRepairRuns(ctx, searchOptions) {
+		if err != nil {
+			return nil, err
+		}
+		if run.Ended.IsZero() || !full(run.IncrementalRepair, run.Nodes, run.Datacenters) {
+			continue
+		}
+		for _, table := range schema.Targets(run.Keyspace, run.Tables, run.IgnoredTables) {
+			status, found := tables[run.Keyspace+"."+table]
+			if !found || (status.LastRepair != nil && !run.Ended.After(*status.LastRepair)) {
+				continue
+			}
+			ended, runId := run.Ended.UTC(), run.Id
+			status.LastRepair, status.LastRepairRunId = &ended, &runId
+		}
+	}
+
+	for _, keyspaceStatus := range report.Keyspaces {
+		keyspaceStatus.Scheduled = true
+		var oldest *time.Time
+		neverRepaired := false
+		for _, status := range keyspaceStatus.Tables {
+			if status.LastRepair == nil {
+				status.Exceeded = true
+				neverRepaired = true
+			} else {
+				status.DaysSinceRepair = daysSince(now, *status.LastRepair)
+				status.Exceeded = now.Sub(*status.LastRepair) > time.Duration(status.GcGraceSeconds)*time.Second
+				if oldest == nil || status.LastRepair.Before(*oldest) {
+					oldest = status.LastRepair
+				}
+			}
+			keyspaceStatus.Scheduled = keyspaceStatus.Scheduled && status.Scheduled
+			keyspaceStatus.Exceeded = keyspaceStatus.Exceeded || status.Exceeded
+			report.Tables++
+			if status.Exceeded {
+				report.ExceededTables++
+			}
+			if !status.Scheduled {
+				report.UnscheduledTables++
+			}
+		}
+		if oldest != nil && !neverRepaired {
+			keyspaceStatus.LastRepair = oldest
+			keyspaceStatus.DaysSinceRepair = daysSince(now, *oldest)
+		}
+	}
+	report.Compliant = report.ExceededTables == 0
+	return report, nil
+}
+
+// Exceeded returns the tables not repaired within their gc_grace threshold.
+func (r *Report) Exceeded() []*TableStatus {
+	var exceeded []*TableStatus
+	for _, keyspace := range r.Keyspaces {
+		for _, table := range keyspace.Tables {
+			if table.Exceeded {
+				exceeded = append(exceeded, table)
+			}
+		}
+	}
+	return exceeded
+}
+
+func (o Options) gcGrace(keyspace string, table string) time.Duration {
+	if gcGrace, found := o.GcGraceOverrides[keyspace+"."+table]; found {
+		return gcGrace
+	}
+	if gcGrace, found := o.GcGraceOverrides[keyspace]; found {
+		return gcGrace
+	}
+	if o.GcGrace > 0 {
+		return o.GcGrace
+	}
+	return DefaultGcGrace
+}
+
+// full returns true if a repair run or schedule repairs every replica of its tables.
+func full(incremental bool, nodes []string, datacenters []string) bool {
+	return !incremental && len(nodes) == 0 && len(datacenters) == 0
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func daysSince(now time.Time, t time.Time) *float64 {
+	days := math.Round(now.Sub(t).Hours()/24*100) / 100
+	return &days
+}
+
+// String summarizes the report in one line.
+func (r *Report) String() string {
+	return fmt.Sprintf("cluster %s: %d/%d tables exceed gc_grace, %d not scheduled", r.Cluster, r.ExceededTables,
+		r.Tables, r.UnscheduledTables)
+}
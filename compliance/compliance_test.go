@@ -0,0 +1,124 @@
+package compliance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/k8ssandra/reaper-client-go/reaper"
+	"github.com/k8ssandra/reaper-client-go/reapermock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2021, 3, 20, 12, 0, 0, 0, time.UTC)
+
+func daysAgo(days float64) time.Time {
+	return now.Add(-time.Duration(days * float64(24*time.Hour)))
+}
+
+func TestAnalyze(t *testing.T) {
+	mock := reapermock.NewMock(t)
+	mock.OnClusterSchema().With("cluster-1").Return(reaper.ClusterSchema{
+		"system":   {"local", "peers"},
+		"ks1":      {"table2", "table1"},
+		"ks2":      {"table1", "table2"},
+		"ks3":      {"table1"},
+		"ks_empty": {},
+	}, nil)
+	scheduleId := uuid.New().String()
+	mock.OnRepairSchedulesForCluster().With("cluster-1").Return([]reaper.RepairSchedule{
+		{Id: scheduleId, State: string(reaper.RepairScheduleStateActive), KeyspaceName: "ks1", DaysBetween: 7},
+		{Id: uuid.New().String(), State: string(reaper.RepairScheduleStatePaused), KeyspaceName: "ks2"},
+		{Id: uuid.New().String(), State: string(reaper.RepairScheduleStateActive), KeyspaceName: "ks3",
+			IncrementalRepair: true},
+	}, nil)
+	recentId, oldId := uuid.New(), uuid.New()
+	mock.OnAllRepairRuns().Return(reapermock.Sequence([]*reaper.RepairRun{
+		{Id: oldId, Keyspace: "ks1", State: reaper.RepairRunStateDone, Ended: daysAgo(12)},
+		{Id: recentId, Keyspace: "ks1", State: reaper.RepairRunStateDone, Tables: []string{"table1"}, Ended: daysAgo(2)},
+		{Id: uuid.New(), Keyspace: "ks2", State: reaper.RepairRunStateDone, IgnoredTables: []string{"table2"},
+			Ended: daysAgo(1)},
+		{Id: uuid.New(), Keyspace: "ks2", State: reaper.RepairRunStateDone, Datacenters: []string{"dc1"},
+			Ended: daysAgo(1)},
+		{Id: uuid.New(), Keyspace: "ks3", State: reaper.RepairRunStateDone, IncrementalRepair: true, Ended: daysAgo(1)},
+		{Id: uuid.New(), Keyspace: "dropped", State: reaper.RepairRunStateDone, Ended: daysAgo(1)},
+	}, nil))
+
+	report, err := Analyze(context.Background(), mock, "cluster-1", Options{
+		Now:              now,
+		GcGraceOverrides: map[string]time.Duration{"ks1.table2": 14 * 24 * time.Hour},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "cluster-1", report.Cluster)
+	assert.Equal(t, now, report.GeneratedAt)
+	assert.False(t, report.Compliant)
+	assert.Equal(t, 5, report.Tables)
+	assert.Equal(t, 2, report.ExceededTables)
+	assert.Equal(t, 3, report.UnscheduledTables)
+	require.Len(t, report.Keyspaces, 4, "the system keyspace is ignored")
+
+	ks1 := report.Keyspaces[0]
+	assert.Equal(t, "ks1", ks1.Keyspace)
+	assert.True(t, ks1.Scheduled)
+	assert.False(t, ks1.Exceeded)
+	require.NotNil(t, ks1.LastRepair)
+	assert.Equal(t, daysAgo(12), *ks1.LastRepair, "the oldest repair of the keyspace")
+	require.Len(t, ks1.Tables, 2)
+	table1, table2 := ks1.Tables[0], ks1.Tables[1]
+	assert.Equal(t, "table1", table1.Table)
+	assert.Equal(t, recentId, *table1.LastRepairRunId)
+	assert.Equal(t, 2.0, *table1.DaysSinceRepair)
+	assert.Equal(t, int64(864000), table1.GcGraceSeconds)
+	assert.Equal(t, []string{scheduleId}, table1.ScheduleIds)
+	assert.Equal(t, oldId, *table2.LastRepairRunId)
+	assert.Equal(t, 12.0, *table2.DaysSinceRepair)
+	assert.Equal(t, int64(14*86400), table2.GcGraceSeconds)
+	assert.False(t, table2.Exceeded, "12 days is within the overridden gc_grace")
+
+	ks2 := report.Keyspaces[1]
+	assert.False(t, ks2.Scheduled, "paused schedules don't count")
+	assert.True(t, ks2.Exceeded)
+	assert.Nil(t, ks2.LastRepair, "table2 was never repaired")
+	assert.False(t, ks2.Tables[0].Exceeded)
+	assert.True(t, ks2.Tables[1].Exceeded, "table2 was ignored, the other run was restricted to a datacenter")
+	assert.Nil(t, ks2.Tables[1].LastRepair)
+
+	ks3 := report.Keyspaces[2]
+	assert.False(t, ks3.Scheduled, "incremental schedules don't count")
+	assert.True(t, ks3.Tables[0].Exceeded, "incremental runs don't count")
+
+	empty := report.Keyspaces[3]
+	assert.Equal(t, "ks_empty", empty.Keyspace)
+	assert.Empty(t, empty.Tables)
+	assert.False(t, empty.Exceeded)
+
+	exceeded := report.Exceeded()
+	require.Len(t, exceeded, 2)
+	assert.Equal(t, "ks2", exceeded[0].Keyspace)
+	assert.Equal(t, "ks3", exceeded[1].Keyspace)
+
+	data, err := json.Marshal(report.Keyspaces[1].Tables[1])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"keyspace":"ks2","table":"table2","last_repair":null,"last_repair_run_id":null,
+		"days_since_repair":null,"gc_grace_seconds":864000,"scheduled":false,"schedule_ids":null,"exceeded":true}`,
+		string(data))
+	assert.Equal(t, "cluster cluster-1: 2/5 tables exceed gc_grace, 3 not scheduled", report.String())
+}
+
+func TestAnalyzeErrors(t *testing.T) {
+	mock := reapermock.NewMock(t)
+	mock.OnClusterSchema().With("unknown").Return(nil, errors.New("not found"))
+	mock.OnClusterSchema().With("cluster-1").Return(reaper.ClusterSchema{"ks1": {"table1"}}, nil)
+	mock.OnRepairSchedulesForCluster().Return(nil, nil)
+	mock.OnAllRepairRuns().Return(reapermock.Sequence[*reaper.RepairRun](nil, errors.New("boom")))
+	ctx := context.Background()
+
+	_, err := Analyze(ctx, mock, "unknown", Options{})
+	assert.EqualError(t, err, "not found")
+	_, err = Analyze(ctx, mock, "cluster-1", Options{})
+	assert.EqualError(t, err, "boom")
+}
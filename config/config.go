@@ -0,0 +1,177 @@
+// Package config loads the connection settings of Reaper instances from a YAML file of named contexts, in the spirit
+// of kubeconfig files, and builds clients from them:
+//
+//	current-context: prod-eu
+//	contexts:
+//	- name: prod-eu
+//	  url: https://reaper.prod-eu.example.com
+//	  username: operator
+//	  password-file: secrets/prod-eu
+//	  certificate-authority: ca/prod.pem
+//	  timeout: 30s
+//	- name: staging
+//	  url: http://reaper.staging.example.com:8080
+//	  token: eyJhbGciOiJIUzI1NiJ9...
+//
+// Relative file paths are resolved against the directory of the file, and "~/" against the home directory of the
+// user. The file is read from $REAPER_CONFIG, or ~/.reaper/config by default.
+//
+// Resolve selects a context and applies the following environment variables on top of it, so that a single setting
+// can be overridden without editing the file:
+//
+//	REAPER_CONTEXT                     the context to use instead of current-context
+//	REAPER_URL                         url
+//	REAPER_USERNAME                    username
+//	REAPER_PASSWORD                    password
+//	REAPER_PASSWORD_FILE               password-file
+//	REAPER_TOKEN                       token
+//	REAPER_CERTIFICATE_AUTHORITY       certificate-authority
+//	REAPER_CLIENT_CERTIFICATE          client-certificate
+//	REAPER_CLIENT_KEY                  client-key
+//	REAPER_INSECURE_SKIP_TLS_VERIFY    insecure-skip-tls-verify
+//	REAPER_USER_AGENT                  user-agent
+//	REAPER_TIMEOUT                     timeout
+//
+// A typical program only needs:
+//
+//	cfg, err := config.LoadDefault()
+//	current, err := cfg.Resolve("", os.Getenv)
+//	client, err := current.Client(ctx)
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// EnvConfig is the environment variable holding the path of the configuration file.
+const EnvConfig = "REAPER_CONFIG"
+
+// Config is the content of a configuration file.
+type Config struct {
+	// The context used when none is explicitly selected.
+	CurrentContext string `yaml:"current-context,omitempty"`
+
+	Contexts []*Context `yaml:"contexts"`
+
+	// The file the configuration was loaded from, if any.
+	path string
+}
+
+// DefaultPath returns the path of the configuration file: $REAPER_CONFIG if set, ~/.reaper/config otherwise.
+func DefaultPath() (string, error) {
+	if path := os.Getenv(EnvConfig); path != "" {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the configuration file: %w", err)
+	}
+	return filepath.Join(home, ".reaper", "config"), nil
+}
+
+// LoadDefault loads the configuration file at DefaultPath. A missing file is not an error, unless it was set
+// explicitly with $REAPER_CONFIG: an empty configuration is returned, so that contexts can be defined with environment
+// variables only.
+func LoadDefault() (*Config, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	config, err := Load(path)
+	if errors.Is(err, os.ErrNotExist) && os.Getenv(EnvConfig) == "" {
+		return &Config{}, nil
+	}
+	return config, err
+}
+
+// Load loads the configuration file at path.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration file: %w", err)
+	}
+	config, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration file %s: %w", path, err)
+	}
+	config.path = path
+	for _, context := range config.Contexts {
+		context.resolvePaths(filepath.Dir(path))
+	}
+	return config, nil
+}
+
+// Parse parses the content of a configuration file. Relative file paths are left untouched.
+func Parse(data []byte) (*Config, error) {
+	config := &Config{}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, err
+	}
+	names := map[string]bool{}
+	for i, context := range config.Contexts {
+		switch {
+		case context == nil || context.Name == "":
+			return nil, fmt.Errorf("context #%d has no name", i+1)
+		case names[context.Name]:
+			return nil, fmt.Errorf("duplicate context %q", context.Name)
+		}
+		names[context.Name] = true
+	}
+	if config.CurrentContext != "" && !names[config.CurrentContext] {
+		return nil, fmt.Errorf("current context %q is not defined", config.CurrentContext)
+	}
+	return config, nil
+}
+
+// Path returns the file the configuration was loaded from, or "" if it was not loaded from a file.
+func (c *Config) Path() string {
+	return c.path
+}
+
+// Context returns a copy of the context with the given name, or of the current context if name is empty. If name is
+// empty and there is no current context, an empty context is returned.
+func (c *Config) Context(name string) (*Context, error) {
+	if name == "" {
+		name = c.CurrentContext
+	}
+	if name == "" {
+		return &Context{}, nil
+	}
+	for _, context := range c.Contexts {
+		if context.Name == name {
+			copied := *context
+			return &copied, nil
+		}
+	}
+	names := make([]string, len(c.Contexts))
+	for i, context := range c.Contexts {
+		names[i] = context.Name
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("context %q not found: no context is defined", name)
+	}
+	return nil, fmt.Errorf("context %q not found, expected one of %s", name, strings.Join(names, ", "))
+}
+
+// Resolve returns the context to use: the context with the given name if not empty, the context named by
+// REAPER_CONTEXT otherwise, or the current context. The environment variables listed in the package documentation
+// are applied on top of it. getenv is usually os.Getenv.
+func (c *Config) Resolve(name string, getenv func(string) string) (*Context, error) {
+	if name == "" {
+		name = getenv("REAPER_CONTEXT")
+	}
+	context, err := c.Context(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := context.applyEnv(getenv); err != nil {
+		return nil, err
+	}
+	return context, nil
+}
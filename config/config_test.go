@@ -0,0 +1,249 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/k8ssandra/reaper-client-go/reapertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sample = `
+current-context: prod
+contexts:
+- name: prod
+  url: https://reaper.prod.example.com
+  username: operator
+  password-file: secrets/prod
+  certificate-authority: /etc/ssl/reaper.pem
+  timeout: 30s
+- name: staging
+  url: http://reaper.staging.example.com:8080
+  token: staging-jwt
+  user-agent: my-operator
+`
+
+func TestParse(t *testing.T) {
+	config, err := Parse([]byte(sample))
+	require.NoError(t, err)
+	assert.Equal(t, "prod", config.CurrentContext)
+	require.Len(t, config.Contexts, 2)
+	assert.Equal(t, &Context{
+		Name:                 "prod",
+		URL:                  "https://reaper.prod.example.com",
+		Username:             "operator",
+		PasswordFile:         "secrets/prod",
+		CertificateAuthority: "/etc/ssl/reaper.pem",
+		Timeout:              Duration(30 * time.Second),
+	}, config.Contexts[0])
+	assert.Equal(t, "staging-jwt", config.Contexts[1].Token)
+
+	for _, test := range []struct {
+		name string
+		data string
+		err  string
+	}{
+		{"UnknownField", "contexts:\n- name: a\n  uri: http://a\n", "field uri not found"},
+		{"NoName", "contexts:\n- url: http://a\n", "context #1 has no name"},
+		{"Duplicate", "contexts:\n- name: a\n- name: a\n", `duplicate context "a"`},
+		{"UnknownCurrent", "current-context: b\ncontexts:\n- name: a\n", `current context "b" is not defined`},
+		{"InvalidTimeout", "contexts:\n- name: a\n  timeout: soon\n", `invalid duration "soon"`},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Parse([]byte(test.data))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.err)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config")
+	require.NoError(t, os.WriteFile(path, []byte(sample), 0600))
+
+	config, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, path, config.Path())
+	// relative paths are resolved against the directory of the file
+	assert.Equal(t, filepath.Join(dir, "secrets", "prod"), config.Contexts[0].PasswordFile)
+	assert.Equal(t, "/etc/ssl/reaper.pem", config.Contexts[0].CertificateAuthority)
+
+	_, err = Load(filepath.Join(dir, "missing"))
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
+
+func TestLoadDefault(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(EnvConfig, "")
+	config, err := LoadDefault()
+	require.NoError(t, err, "a missing default file is not an error")
+	assert.Empty(t, config.Contexts)
+
+	path := filepath.Join(t.TempDir(), "config")
+	t.Setenv(EnvConfig, path)
+	_, err = LoadDefault()
+	assert.Error(t, err, "a missing file set explicitly is an error")
+
+	require.NoError(t, os.WriteFile(path, []byte(sample), 0600))
+	config, err = LoadDefault()
+	require.NoError(t, err)
+	assert.Len(t, config.Contexts, 2)
+}
+
+func TestResolve(t *testing.T) {
+	config, err := Parse([]byte(sample))
+	require.NoError(t, err)
+	env := map[string]string{}
+	getenv := func(key string) string { return env[key] }
+
+	current, err := config.Resolve("", getenv)
+	require.NoError(t, err)
+	assert.Equal(t, "prod", current.Name)
+
+	current, err = config.Resolve("staging", getenv)
+	require.NoError(t, err)
+	assert.Equal(t, "staging", current.Name)
+
+	env["REAPER_CONTEXT"] = "staging"
+	current, err = config.Resolve("", getenv)
+	require.NoError(t, err)
+	assert.Equal(t, "staging", current.Name)
+
+	_, err = config.Resolve("dev", getenv)
+	assert.EqualError(t, err, `context "dev" not found, expected one of prod, staging`)
+
+	env = map[string]string{
+		"REAPER_URL":                      "https://other.example.com",
+		"REAPER_PASSWORD":                 "secret",
+		"REAPER_INSECURE_SKIP_TLS_VERIFY": "true",
+		"REAPER_TIMEOUT":                  "1m",
+	}
+	current, err = config.Resolve("prod", getenv)
+	require.NoError(t, err)
+	assert.Equal(t, "https://other.example.com", current.URL)
+	assert.Equal(t, "operator", current.Username)
+	assert.Equal(t, "secret", current.Password)
+	assert.Empty(t, current.PasswordFile, "the password overrides the password file")
+	assert.True(t, current.InsecureSkipTLSVerify)
+	assert.Equal(t, Duration(time.Minute), current.Timeout)
+	assert.Equal(t, "secrets/prod", config.Contexts[0].PasswordFile, "the configuration is not modified")
+
+	env = map[string]string{"REAPER_TOKEN": "jwt"}
+	current, err = config.Resolve("prod", getenv)
+	require.NoError(t, err)
+	assert.Equal(t, "jwt", current.Token)
+	assert.Empty(t, current.Username, "the token overrides the credentials")
+
+	env = map[string]string{"REAPER_TIMEOUT": "soon"}
+	_, err = config.Resolve("prod", getenv)
+	assert.Error(t, err)
+
+	// without a configuration file, contexts can be defined with environment variables only
+	env = map[string]string{"REAPER_URL": "http://localhost:8080"}
+	current, err = (&Config{}).Resolve("", getenv)
+	require.NoError(t, err)
+	assert.Equal(t, &Context{URL: "http://localhost:8080"}, current)
+	assert.NoError(t, current.Validate())
+}
+
+func TestValidate(t *testing.T) {
+	err := (&Context{
+		URL:          "localhost:8080",
+		Password:     "secret",
+		PasswordFile: "secret.txt",
+		ClientKey:    "key.pem",
+		Timeout:      -1,
+	}).Validate()
+	require.Error(t, err)
+	assert.Equal(t, []string{
+		`invalid url "localhost:8080": expected an absolute http or https URL`,
+		"password and password-file are mutually exclusive",
+		"a password is set but username is not",
+		"client-certificate and client-key must be set together",
+		"invalid timeout -1ns: must not be negative",
+	}, strings.Split(err.Error(), "\n"))
+
+	assert.EqualError(t, (&Context{}).Validate(), "url is required")
+	assert.EqualError(t, (&Context{URL: "http://a", Username: "u", Token: "t"}).Validate(),
+		"token and username are mutually exclusive")
+	assert.NoError(t, (&Context{URL: "http://a", Username: "u", PasswordFile: "p"}).Validate())
+}
+
+func TestClient(t *testing.T) {
+	server := reapertest.NewServer(reapertest.WithCredentials("reaperUser", "reaperPass"), reapertest.WithJwtInLoginBody())
+	defer server.Close()
+	ctx := context.Background()
+
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("reaperPass\n"), 0600))
+	client, err := (&Context{URL: server.URL().String(), Username: "reaperUser", PasswordFile: passwordFile}).Client(ctx)
+	require.NoError(t, err)
+	_, err = client.GetClusterNames(ctx)
+	assert.NoError(t, err)
+
+	_, err = (&Context{Name: "prod", URL: server.URL().String(), Username: "reaperUser", Password: "wrong"}).Client(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to log in as reaperUser")
+
+	_, err = (&Context{Name: "prod"}).Client(ctx)
+	assert.EqualError(t, err, `invalid context "prod": url is required`)
+
+	// a token obtained out of band is used as is
+	res, err := http.PostForm(server.URL().String()+"/login", url.Values{"username": {"reaperUser"}, "password": {"reaperPass"}})
+	require.NoError(t, err)
+	var login struct {
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&login))
+	_ = res.Body.Close()
+	client, err = (&Context{URL: server.URL().String(), Token: login.Token}).Client(ctx)
+	require.NoError(t, err)
+	_, err = client.GetClusterNames(ctx)
+	assert.NoError(t, err)
+}
+
+func TestClientTLS(t *testing.T) {
+	var userAgent string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.UserAgent()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	client, err := (&Context{URL: server.URL}).Client(ctx)
+	require.NoError(t, err)
+	_, err = client.IsReaperUp(ctx)
+	assert.Error(t, err, "the certificate of the server is not trusted")
+
+	ca := filepath.Join(t.TempDir(), "ca.pem")
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(ca, certificate, 0600))
+	client, err = (&Context{URL: server.URL, CertificateAuthority: ca, UserAgent: "my-operator"}).Client(ctx)
+	require.NoError(t, err)
+	up, err := client.IsReaperUp(ctx)
+	require.NoError(t, err)
+	assert.True(t, up)
+	assert.Equal(t, "my-operator", userAgent)
+
+	client, err = (&Context{URL: server.URL, InsecureSkipTLSVerify: true}).Client(ctx)
+	require.NoError(t, err)
+	_, err = client.IsReaperUp(ctx)
+	assert.NoError(t, err)
+
+	_, err = (&Context{URL: server.URL, CertificateAuthority: filepath.Join(t.TempDir(), "missing.pem")}).Client(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read certificate authority")
+}
@@ -0,0 +1,260 @@
+package config
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/k8ssandra/reaper-client-go/reaper"
+)
+
+// DefaultTimeout is the timeout of requests when a context does not set one. It is the same as reaper.NewClient's.
+const DefaultTimeout = 10 * time.Second
+
+// Context holds the settings to connect to a Reaper instance.
+type Context struct {
+	Name string `yaml:"name"`
+
+	// The base URL of Reaper, e.g. http://localhost:8080. Required.
+	URL string `yaml:"url"`
+
+	// The user to log in as, if authentication is enabled in Reaper. Its password is either given inline with
+	// Password, or read from PasswordFile, with trailing newlines removed.
+	Username     string `yaml:"username,omitempty"`
+	Password     string `yaml:"password,omitempty"`
+	PasswordFile string `yaml:"password-file,omitempty"`
+
+	// A JWT to authenticate with instead of logging in with a username.
+	Token string `yaml:"token,omitempty"`
+
+	// PEM file of the certificate authorities to trust, instead of the system ones.
+	CertificateAuthority string `yaml:"certificate-authority,omitempty"`
+
+	// PEM files of the client certificate and key, for mutual TLS.
+	ClientCertificate string `yaml:"client-certificate,omitempty"`
+	ClientKey         string `yaml:"client-key,omitempty"`
+
+	InsecureSkipTLSVerify bool `yaml:"insecure-skip-tls-verify,omitempty"`
+
+	UserAgent string `yaml:"user-agent,omitempty"`
+
+	// The timeout of each request, e.g. 30s. Defaults to DefaultTimeout.
+	Timeout Duration `yaml:"timeout,omitempty"`
+}
+
+// Duration is a time.Duration written as a string in configuration files, e.g. 1m30s.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return time.Duration(d).String(), nil
+}
+
+// Validate checks that the context can be used to build a client. All the problems found are reported, joined in a
+// single error.
+func (c *Context) Validate() error {
+	var errs []error
+	if c.URL == "" {
+		errs = append(errs, errors.New("url is required"))
+	} else if u, err := url.Parse(c.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("invalid url %q: expected an absolute http or https URL", c.URL))
+	}
+	if c.Password != "" && c.PasswordFile != "" {
+		errs = append(errs, errors.New("password and password-file are mutually exclusive"))
+	}
+	if (c.Password != "" || c.PasswordFile != "") && c.Username == "" {
+		errs = append(errs, errors.New("a password is set but username is not"))
+	}
+	if c.Token != "" && c.Username != "" {
+		errs = append(errs, errors.New("token and username are mutually exclusive"))
+	}
+	if (c.ClientCertificate == "") != (c.ClientKey == "") {
+		errs = append(errs, errors.New("client-certificate and client-key must be set together"))
+	}
+	if c.Timeout < 0 {
+		errs = append(errs, fmt.Errorf("invalid timeout %v: must not be negative", time.Duration(c.Timeout)))
+	}
+	return errors.Join(errs...)
+}
+
+// ClientOptions returns the options to pass to reaper.NewClient to connect to the Reaper of the context. It does not
+// log in: use Client for that.
+func (c *Context) ClientOptions() ([]reaper.ClientCreateOption, error) {
+	transport, err := c.transport()
+	if err != nil {
+		return nil, err
+	}
+	timeout := time.Duration(c.Timeout)
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	options := []reaper.ClientCreateOption{
+		reaper.WithHttpClient(&http.Client{Transport: transport, Timeout: timeout}),
+	}
+	if c.UserAgent != "" {
+		options = append(options, reaper.WithUserAgent(c.UserAgent))
+	}
+	if c.Token != "" {
+		options = append(options, reaper.WithJwt(c.Token))
+	}
+	return options, nil
+}
+
+// Client validates the context and returns a client for its Reaper, logged in if a username is set. Additional
+// options are applied after the ones of the context, e.g. to set the concurrency of the client.
+func (c *Context) Client(ctx context.Context, options ...reaper.ClientCreateOption) (reaper.Client, error) {
+	if err := c.Validate(); err != nil {
+		if c.Name != "" {
+			return nil, fmt.Errorf("invalid context %q: %w", c.Name, err)
+		}
+		return nil, err
+	}
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return nil, err
+	}
+	contextOptions, err := c.ClientOptions()
+	if err != nil {
+		return nil, err
+	}
+	client := reaper.NewClient(u, append(contextOptions, options...)...)
+	if c.Username != "" {
+		password, err := c.password()
+		if err != nil {
+			return nil, err
+		}
+		if err := client.Login(ctx, c.Username, password); err != nil {
+			return nil, fmt.Errorf("failed to log in as %s: %w", c.Username, err)
+		}
+	}
+	return client, nil
+}
+
+func (c *Context) password() (string, error) {
+	if c.PasswordFile == "" {
+		return c.Password, nil
+	}
+	data, err := os.ReadFile(c.PasswordFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read password file: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func (c *Context) transport() (http.RoundTripper, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if c.CertificateAuthority == "" && c.ClientCertificate == "" && !c.InsecureSkipTLSVerify {
+		return transport, nil
+	}
+	config := &tls.Config{InsecureSkipVerify: c.InsecureSkipTLSVerify}
+	if c.CertificateAuthority != "" {
+		pem, err := os.ReadFile(c.CertificateAuthority)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read certificate authority: %w", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", c.CertificateAuthority)
+		}
+	}
+	if c.ClientCertificate != "" {
+		certificate, err := tls.LoadX509KeyPair(c.ClientCertificate, c.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+	transport.TLSClientConfig = config
+	return transport, nil
+}
+
+// applyEnv overrides the settings of the context with the environment variables listed in the package documentation.
+// Setting a password overrides the password file of the context and vice versa; the same goes for the token and the
+// username.
+func (c *Context) applyEnv(getenv func(string) string) error {
+	if v := getenv("REAPER_URL"); v != "" {
+		c.URL = v
+	}
+	if v := getenv("REAPER_USERNAME"); v != "" {
+		c.Username, c.Token = v, ""
+	}
+	if v := getenv("REAPER_PASSWORD"); v != "" {
+		c.Password, c.PasswordFile = v, ""
+	}
+	if v := getenv("REAPER_PASSWORD_FILE"); v != "" {
+		c.Password, c.PasswordFile = "", v
+	}
+	if v := getenv("REAPER_TOKEN"); v != "" {
+		c.Username, c.Password, c.PasswordFile, c.Token = "", "", "", v
+	}
+	if v := getenv("REAPER_CERTIFICATE_AUTHORITY"); v != "" {
+		c.CertificateAuthority = v
+	}
+	if v := getenv("REAPER_CLIENT_CERTIFICATE"); v != "" {
+		c.ClientCertificate = v
+	}
+	if v := getenv("REAPER_CLIENT_KEY"); v != "" {
+		c.ClientKey = v
+	}
+	if v := getenv("REAPER_INSECURE_SKIP_TLS_VERIFY"); v != "" {
+		insecure, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid REAPER_INSECURE_SKIP_TLS_VERIFY %q: %w", v, err)
+		}
+		c.InsecureSkipTLSVerify = insecure
+	}
+	if v := getenv("REAPER_USER_AGENT"); v != "" {
+		c.UserAgent = v
+	}
+	if v := getenv("REAPER_TIMEOUT"); v != "" {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid REAPER_TIMEOUT %q: %w", v, err)
+		}
+		c.Timeout = Duration(timeout)
+	}
+	return nil
+}
+
+// resolvePaths makes the file paths of the context absolute: "~/" is expanded to the home directory of the user, and
+// relative paths are resolved against dir.
+func (c *Context) resolvePaths(dir string) {
+	for _, path := range []*string{&c.PasswordFile, &c.CertificateAuthority, &c.ClientCertificate, &c.ClientKey} {
+		*path = resolvePath(dir, *path)
+	}
+}
+
+func resolvePath(dir, path string) string {
+	switch {
+	case path == "" || filepath.IsAbs(path):
+		return path
+	case strings.HasPrefix(path, "~/"):
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+		return path
+	default:
+		return filepath.Join(dir, path)
+	}
+}
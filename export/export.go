@@ -0,0 +1,183 @@
+// Package export writes the repair history of Reaper as flat records, one per repair run and optionally one per
+// segment, for compliance reports and analytics:
+//
+//   - csv: a header line, then one line per record;
+//   - jsonl: JSON Lines, one object per record;
+//   - columnar: JSON Lines too, but the first line describes the columns and every following line is a row group
+//     holding the values of up to 1000 records, column by column, like the row groups of a Parquet file.
+//
+// Records are streamed from Reaper to the output as they are decoded: only the columnar format buffers a row group.
+//
+//	writer, err := export.NewWriter(os.Stdout, export.FormatCsv)
+//	count, err := export.Export(ctx, client, writer, export.Options{Cluster: "cluster-1", Segments: true})
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/k8ssandra/reaper-client-go/reaper"
+)
+
+const (
+	KindRun     = "run"
+	KindSegment = "segment"
+)
+
+type Options struct {
+	// Only export the repair runs of this cluster.
+	Cluster string
+
+	// Only export the repair runs of this keyspace.
+	Keyspace string
+
+	// Only export the repair runs in these states.
+	States []reaper.RepairRunState
+
+	// Segments adds a record for every segment of every repair run, right after the record of the run.
+	Segments bool
+}
+
+// Record is a repair run or one of its segments. Segment records repeat the fields of their run, so that every record
+// can be processed on its own; the fields of the run that don't apply to a segment are left empty.
+type Record struct {
+	// KindRun or KindSegment.
+	Kind string `json:"kind"`
+
+	RunId     uuid.UUID  `json:"run_id"`
+	SegmentId *uuid.UUID `json:"segment_id"`
+	Cluster   string     `json:"cluster"`
+	Keyspace  string     `json:"keyspace"`
+	Tables    []string   `json:"tables"`
+
+	// The state of the run, or of the segment.
+	State       string `json:"state"`
+	Cause       string `json:"cause"`
+	Owner       string `json:"owner"`
+	Incremental bool   `json:"incremental"`
+
+	Start *time.Time `json:"start"`
+	End   *time.Time `json:"end"`
+
+	// The duration between Start and End, in seconds, if both are known.
+	DurationSeconds *float64 `json:"duration_seconds"`
+
+	// The total and repaired segments of a run; 0 for segments.
+	Segments         int `json:"segments"`
+	SegmentsRepaired int `json:"segments_repaired"`
+
+	// The coordinator, failures and token range of a segment; empty for runs.
+	Coordinator string `json:"coordinator"`
+	FailCount   int    `json:"fail_count"`
+	StartToken  string `json:"start_token"`
+	EndToken    string `json:"end_token"`
+}
+
+// Export walks the repair runs matching options, and writes their records to w as they are received. Returns the
+// number of records written, including those written before an error. w is flushed, even when an error is returned,
+// but not closed.
+func Export(ctx context.Context, client reaper.Client, w Writer, options Options) (count int, err error) {
+	defer func() {
+		if flushErr := w.Flush(); flushErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to flush records: %w", flushErr))
+		}
+	}()
+	searchOptions := &reaper.RepairRunSearchOptions{
+		Cluster:  options.Cluster,
+		Keyspace: options.Keyspace,
+		States:   options.States,
+	}
+	for run, err := range client.AllRepairRuns(ctx, searchOptions) {
+		if err != nil {
+			return count, err
+		}
+		if err := w.Write(runRecord(run)); err != nil {
+			return count, fmt.Errorf("failed to write repair run %v: %w", run.Id, err)
+		}
+		count++
+		if !options.Segments {
+			continue
+		}
+		for segment, err := range client.AllRepairRunSegments(ctx, run.Id) {
+			if err != nil {
+				return count, err
+			}
+			if err := w.Write(segmentRecord(run, segment)); err != nil {
+				return count, fmt.Errorf("failed to write segment %v of repair run %v: %w", segment.Id, run.Id, err)
+			}
+			count++
+		}
+	}
+	return count, nil
+}
+
+func runRecord(run *reaper.RepairRun) *Record {
+	record := &Record{
+		Kind:             KindRun,
+		RunId:            run.Id,
+		Cluster:          run.Cluster,
+		Keyspace:         run.Keyspace,
+		Tables:           run.Tables,
+		State:            string(run.State),
+		Cause:            run.Cause,
+		Owner:            run.Owner,
+		Incremental:      run.IncrementalRepair,
+		Segments:         run.TotalSegments,
+		SegmentsRepaired: run.SegmentsRepaired,
+	}
+	record.setTimes(timeOrNil(run.Started), timeOrNil(run.Ended))
+	return record
+}
+
+func segmentRecord(run *reaper.RepairRun, segment *reaper.RepairSegment) *Record {
+	segmentId := segment.Id
+	record := &Record{
+		Kind:        KindSegment,
+		RunId:       run.Id,
+		SegmentId:   &segmentId,
+		Cluster:     run.Cluster,
+		Keyspace:    run.Keyspace,
+		Tables:      run.Tables,
+		State:       string(segment.State),
+		Cause:       run.Cause,
+		Owner:       run.Owner,
+		Incremental: run.IncrementalRepair,
+		Coordinator: segment.Coordinator,
+		FailCount:   segment.FailCount,
+	}
+	if segment.TokenRange != nil && segment.TokenRange.BaseRange != nil {
+		if start := segment.TokenRange.BaseRange.Start; start != nil {
+			record.StartToken = start.String()
+		}
+		if end := segment.TokenRange.BaseRange.End; end != nil {
+			record.EndToken = end.String()
+		}
+	}
+	record.setTimes(segment.StartTime, segment.EndTime)
+	return record
+}
+
+func (r *Record) setTimes(start *time.Time, end *time.Time) {
+	if start != nil {
+		utc := start.UTC()
+		r.Start = &utc
+	}
+	if end != nil {
+		utc := end.UTC()
+		r.End = &utc
+	}
+	if r.Start != nil && r.End != nil {
+		seconds := r.End.Sub(*r.Start).Seconds()
+		r.DurationSeconds = &seconds
+	}
+}
+
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,212 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/k8ssandra/reaper-client-go/reaper"
+	"github.com/k8ssandra/reaper-client-go/reapermock"
+	"github.com/k8ssandra/reaper-client-go/reapertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExport(t *testing.T) {
+	server := reapertest.NewServer(reapertest.WithSegmentDuration(time.Millisecond))
+	defer server.Close()
+	server.AddCassandraCluster(reapertest.NewCassandraCluster("cluster-1", 3).
+		WithKeyspace("ks1", 3, "table1", "table2").
+		WithKeyspace("ks2", 3, "table1"))
+	client := reaper.NewClient(server.URL())
+	ctx := context.Background()
+	require.NoError(t, client.AddCluster(ctx, "cluster-1", "cluster-1-node-0"))
+	options := &reaper.RepairRunCreateOptions{Cause: "compliance", SegmentCountPerNode: 2}
+	doneId, err := client.CreateRepairRun(ctx, "cluster-1", "ks1", "Alice", options)
+	require.NoError(t, err)
+	_, err = client.CreateRepairRun(ctx, "cluster-1", "ks2", "Alice", options)
+	require.NoError(t, err)
+	require.NoError(t, client.StartRepairRun(ctx, doneId))
+	require.Eventually(t, func() bool {
+		run, err := client.RepairRun(ctx, doneId)
+		return err == nil && run.State == reaper.RepairRunStateDone
+	}, 5*time.Second, 10*time.Millisecond)
+
+	var out bytes.Buffer
+	w, err := NewWriter(&out, FormatJsonLines)
+	require.NoError(t, err)
+	done := []reaper.RepairRunState{reaper.RepairRunStateDone}
+	count, err := Export(ctx, client, w, Options{States: done, Segments: true})
+	require.NoError(t, err)
+	assert.Equal(t, 7, count, "1 run and its 6 segments")
+
+	var records []*Record
+	decoder := json.NewDecoder(&out)
+	for decoder.More() {
+		var record Record
+		require.NoError(t, decoder.Decode(&record))
+		records = append(records, &record)
+	}
+	require.Len(t, records, 7)
+	run := records[0]
+	assert.Equal(t, KindRun, run.Kind)
+	assert.Equal(t, doneId, run.RunId)
+	assert.Nil(t, run.SegmentId)
+	assert.Equal(t, "ks1", run.Keyspace)
+	assert.Equal(t, []string{"table1", "table2"}, run.Tables)
+	assert.Equal(t, "DONE", run.State)
+	assert.Equal(t, "compliance", run.Cause)
+	assert.Equal(t, 6, run.Segments)
+	assert.Equal(t, 6, run.SegmentsRepaired)
+	require.NotNil(t, run.Start)
+	require.NotNil(t, run.End)
+	require.NotNil(t, run.DurationSeconds)
+	assert.InDelta(t, run.End.Sub(*run.Start).Seconds(), *run.DurationSeconds, 0.001)
+	for _, segment := range records[1:] {
+		assert.Equal(t, KindSegment, segment.Kind)
+		assert.Equal(t, doneId, segment.RunId)
+		assert.NotNil(t, segment.SegmentId)
+		assert.Equal(t, "ks1", segment.Keyspace)
+		assert.Equal(t, "DONE", segment.State)
+		assert.NotEmpty(t, segment.Coordinator)
+		assert.NotEmpty(t, segment.StartToken)
+		assert.NotNil(t, segment.DurationSeconds)
+	}
+
+	out.Reset()
+	w, err = NewWriter(&out, FormatCsv)
+	require.NoError(t, err)
+	count, err = Export(ctx, client, w, Options{Cluster: "cluster-1", Keyspace: "ks2"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[1], "run,"))
+	assert.Contains(t, lines[1], ",cluster-1,ks2,table1,NOT_STARTED,compliance,Alice,false,,,,6,0,")
+}
+
+func TestExportError(t *testing.T) {
+	mock := reapermock.NewMock(t)
+	runId := uuid.MustParse("10000000-0000-0000-0000-000000000001")
+	mock.OnAllRepairRuns().Return(reapermock.Sequence([]*reaper.RepairRun{
+		{Id: runId, Cluster: "cluster-1", Keyspace: "ks", State: reaper.RepairRunStateDone},
+	}, errors.New("boom")))
+
+	var out bytes.Buffer
+	w, err := NewWriter(&out, FormatCsv)
+	require.NoError(t, err)
+	count, err := Export(context.Background(), mock, w, Options{})
+	assert.EqualError(t, err, "boom")
+	assert.Equal(t, 1, count)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2, "the records written before the error are flushed")
+	assert.True(t, strings.HasPrefix(lines[1], "run,"+runId.String()))
+}
+
+func testRecords() []*Record {
+	start := time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(90 * time.Second)
+	duration := 90.0
+	segmentId := uuid.MustParse("20000000-0000-0000-0000-000000000001")
+	return []*Record{
+		{
+			Kind:             KindRun,
+			RunId:            uuid.MustParse("10000000-0000-0000-0000-000000000001"),
+			Cluster:          "cluster-1",
+			Keyspace:         "ks",
+			Tables:           []string{"table1", "table2"},
+			State:            "DONE",
+			Cause:            "weekly, as usual",
+			Owner:            "Alice",
+			Start:            &start,
+			End:              &end,
+			DurationSeconds:  &duration,
+			Segments:         2,
+			SegmentsRepaired: 2,
+		},
+		{
+			Kind:        KindSegment,
+			RunId:       uuid.MustParse("10000000-0000-0000-0000-000000000001"),
+			SegmentId:   &segmentId,
+			Cluster:     "cluster-1",
+			Keyspace:    "ks",
+			State:       "RUNNING",
+			Start:       &start,
+			Coordinator: "node-1",
+			FailCount:   1,
+			StartToken:  "-9223372036854775808",
+			EndToken:    "0",
+		},
+	}
+}
+
+func TestCsv(t *testing.T) {
+	var out bytes.Buffer
+	w, err := NewWriter(&out, FormatCsv)
+	require.NoError(t, err)
+	require.NoError(t, w.Flush())
+	header := "kind,run_id,segment_id,cluster,keyspace,tables,state,cause,owner,incremental,start,end,duration_seconds," +
+		"segments,segments_repaired,coordinator,fail_count,start_token,end_token\n"
+	assert.Equal(t, header, out.String(), "the header is written even without records")
+
+	for _, record := range testRecords() {
+		require.NoError(t, w.Write(record))
+	}
+	require.NoError(t, w.Flush())
+	assert.Equal(t, header+
+		"run,10000000-0000-0000-0000-000000000001,,cluster-1,ks,table1 table2,DONE,\"weekly, as usual\",Alice,false,"+
+		"2021-03-01T10:00:00Z,2021-03-01T10:01:30Z,90,2,2,,0,,\n"+
+		"segment,10000000-0000-0000-0000-000000000001,20000000-0000-0000-0000-000000000001,cluster-1,ks,,RUNNING,,,"+
+		"false,2021-03-01T10:00:00Z,,,0,0,node-1,1,-9223372036854775808,0\n",
+		out.String())
+}
+
+func TestColumnar(t *testing.T) {
+	var out bytes.Buffer
+	w := NewColumnarWriter(&out, 1)
+	for _, record := range testRecords() {
+		require.NoError(t, w.Write(record))
+	}
+	require.NoError(t, w.Flush())
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3, "the schema, then a row group per record")
+	var schema struct {
+		Schema []Column `json:"schema"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &schema))
+	require.Len(t, schema.Schema, len(Columns))
+	assert.Equal(t, Column{Name: "start", Type: ColumnTimestamp, Nullable: true}, schema.Schema[10])
+
+	var groups []RowGroup
+	for _, line := range lines[1:] {
+		var group RowGroup
+		require.NoError(t, json.Unmarshal([]byte(line), &group))
+		require.Len(t, group.Columns, len(Columns))
+		assert.Equal(t, 1, group.Rows)
+		groups = append(groups, group)
+	}
+	assert.Equal(t, []interface{}{"run"}, groups[0].Columns[0])
+	assert.Equal(t, []interface{}{nil}, groups[0].Columns[2])
+	assert.Equal(t, []interface{}{"2021-03-01T10:01:30Z"}, groups[0].Columns[11])
+	assert.Equal(t, []interface{}{90.0}, groups[0].Columns[12])
+	assert.Equal(t, []interface{}{nil}, groups[1].Columns[11])
+	assert.Equal(t, []interface{}{"node-1"}, groups[1].Columns[15])
+
+	out.Reset()
+	w = NewColumnarWriter(&out, 10)
+	for _, record := range testRecords() {
+		require.NoError(t, w.Write(record))
+	}
+	assert.Equal(t, 1, strings.Count(out.String(), "\n"), "only the schema is written before the row group is full")
+	require.NoError(t, w.Flush())
+	assert.Contains(t, out.String(), `{"rows":2,"columns":[["run","segment"],`)
+
+	_, err := NewWriter(&out, "parquet")
+	assert.EqualError(t, err, `unknown export format "parquet", expected one of [csv jsonl columnar]`)
+}
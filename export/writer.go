@@ -0,0 +1,237 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatCsv       = Format("csv")
+	FormatJsonLines = Format("jsonl")
+	FormatColumnar  = Format("columnar")
+)
+
+const DefaultRowGroupSize = 1000
+
+// Formats lists the supported formats.
+var Formats = []Format{FormatCsv, FormatJsonLines, FormatColumnar}
+
+// Writer writes records in a given format.
+type Writer interface {
+	Write(record *Record) error
+
+	// Flush writes the records still buffered. It doesn't close the underlying io.Writer.
+	Flush() error
+}
+
+// NewWriter returns a Writer writing records to w in the given format.
+func NewWriter(w io.Writer, format Format) (Writer, error) {
+	switch format {
+	case FormatCsv:
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	case FormatJsonLines:
+		return &jsonLinesWriter{encoder: json.NewEncoder(w)}, nil
+	case FormatColumnar:
+		return NewColumnarWriter(w, DefaultRowGroupSize), nil
+	default:
+		return nil, fmt.Errorf("unknown export format %q, expected one of %v", format, Formats)
+	}
+}
+
+// ColumnType is the type of the values of a column in the columnar format.
+type ColumnType string
+
+const (
+	ColumnString     = ColumnType("string")
+	ColumnStringList = ColumnType("list<string>")
+	ColumnInt        = ColumnType("int")
+	ColumnDouble     = ColumnType("double")
+	ColumnBool       = ColumnType("bool")
+	ColumnTimestamp  = ColumnType("timestamp")
+)
+
+// Column describes a field of Record, in the header of CSV files and in the schema of columnar files.
+type Column struct {
+	Name string     `json:"name"`
+	Type ColumnType `json:"type"`
+
+	// Nullable is true if the column has no value for some records, as opposed to an empty value.
+	Nullable bool `json:"nullable"`
+
+	value func(*Record) interface{}
+}
+
+// Columns lists the columns of Record, in the order they are written.
+var Columns = []Column{
+	{Name: "kind", Type: ColumnString, value: func(r *Record) interface{} { return r.Kind }},
+	{Name: "run_id", Type: ColumnString, value: func(r *Record) interface{} { return r.RunId.String() }},
+	{Name: "segment_id", Type: ColumnString, Nullable: true, value: func(r *Record) interface{} {
+		if r.SegmentId == nil {
+			return nil
+		}
+		return r.SegmentId.String()
+	}},
+	{Name: "cluster", Type: ColumnString, value: func(r *Record) interface{} { return r.Cluster }},
+	{Name: "keyspace", Type: ColumnString, value: func(r *Record) interface{} { return r.Keyspace }},
+	{Name: "tables", Type: ColumnStringList, value: func(r *Record) interface{} { return r.Tables }},
+	{Name: "state", Type: ColumnString, value: func(r *Record) interface{} { return r.State }},
+	{Name: "cause", Type: ColumnString, value: func(r *Record) interface{} { return r.Cause }},
+	{Name: "owner", Type: ColumnString, value: func(r *Record) interface{} { return r.Owner }},
+	{Name: "incremental", Type: ColumnBool, value: func(r *Record) interface{} { return r.Incremental }},
+	{Name: "start", Type: ColumnTimestamp, Nullable: true, value: func(r *Record) interface{} { return deref(r.Start) }},
+	{Name: "end", Type: ColumnTimestamp, Nullable: true, value: func(r *Record) interface{} { return deref(r.End) }},
+	{Name: "duration_seconds", Type: ColumnDouble, Nullable: true, value: func(r *Record) interface{} {
+		return deref(r.DurationSeconds)
+	}},
+	{Name: "segments", Type: ColumnInt, value: func(r *Record) interface{} { return r.Segments }},
+	{Name: "segments_repaired", Type: ColumnInt, value: func(r *Record) interface{} { return r.SegmentsRepaired }},
+	{Name: "coordinator", Type: ColumnString, value: func(r *Record) interface{} { return r.Coordinator }},
+	{Name: "fail_count", Type: ColumnInt, value: func(r *Record) interface{} { return r.FailCount }},
+	{Name: "start_token", Type: ColumnString, value: func(r *Record) interface{} { return r.StartToken }},
+	{Name: "end_token", Type: ColumnString, value: func(r *Record) interface{} { return r.EndToken }},
+}
+
+// deref returns the value p points to, or an untyped nil if p is nil.
+func deref[T any](p *T) interface{} {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+// csvWriter writes a header, then one line per record. Lists are joined with spaces, timestamps are formatted as
+// RFC 3339 and missing values are empty.
+type csvWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (c *csvWriter) Write(record *Record) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	line := make([]string, len(Columns))
+	for i, column := range Columns {
+		line[i] = formatCsv(column.value(record))
+	}
+	return c.w.Write(line)
+}
+
+// Flush writes the buffered lines. The header is written even if there is no record at all.
+func (c *csvWriter) Flush() error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) writeHeader() error {
+	if c.headerWritten {
+		return nil
+	}
+	c.headerWritten = true
+	header := make([]string, len(Columns))
+	for i, column := range Columns {
+		header[i] = column.Name
+	}
+	return c.w.Write(header)
+}
+
+func formatCsv(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []string:
+		return strings.Join(v, " ")
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+type jsonLinesWriter struct {
+	encoder *json.Encoder
+}
+
+func (j *jsonLinesWriter) Write(record *Record) error {
+	return j.encoder.Encode(record)
+}
+
+func (j *jsonLinesWriter) Flush() error {
+	return nil
+}
+
+// RowGroup is a line of the columnar format following the schema: the values of Rows records, one array per column,
+// in the order of the schema.
+type RowGroup struct {
+	Rows    int             `json:"rows"`
+	Columns [][]interface{} `json:"columns"`
+}
+
+type columnarWriter struct {
+	encoder       *json.Encoder
+	rowGroupSize  int
+	schemaWritten bool
+	group         RowGroup
+}
+
+// NewColumnarWriter returns a Writer in the columnar format, buffering rowGroupSize records per row group.
+func NewColumnarWriter(w io.Writer, rowGroupSize int) Writer {
+	if rowGroupSize < 1 {
+		rowGroupSize = DefaultRowGroupSize
+	}
+	return &columnarWriter{encoder: json.NewEncoder(w), rowGroupSize: rowGroupSize}
+}
+
+func (c *columnarWriter) Write(record *Record) error {
+	if err := c.writeSchema(); err != nil {
+		return err
+	}
+	if c.group.Columns == nil {
+		c.group.Columns = make([][]interface{}, len(Columns))
+	}
+	for i, column := range Columns {
+		c.group.Columns[i] = append(c.group.Columns[i], column.value(record))
+	}
+	c.group.Rows++
+	if c.group.Rows >= c.rowGroupSize {
+		return c.Flush()
+	}
+	return nil
+}
+
+// Flush writes the current row group, if not empty. The schema is written even if there is no record at all.
+func (c *columnarWriter) Flush() error {
+	if err := c.writeSchema(); err != nil {
+		return err
+	}
+	if c.group.Rows == 0 {
+		return nil
+	}
+	err := c.encoder.Encode(c.group)
+	c.group = RowGroup{}
+	return err
+}
+
+func (c *columnarWriter) writeSchema() error {
+	if c.schemaWritten {
+		return nil
+	}
+	c.schemaWritten = true
+	return c.encoder.Encode(struct {
+		Schema []Column `json:"schema"`
+	}{Columns})
+}
@@ -7,10 +7,10 @@ require (
 	github.com/google/uuid v1.2.0
 	github.com/stretchr/testify v1.5.1
 	golang.org/x/sync v0.0.0-20210220032951-036812b2e83c
+	gopkg.in/yaml.v2 v2.4.0
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v2 v2.4.0 // indirect
 )
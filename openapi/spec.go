@@ -0,0 +1,316 @@
+// Package openapi provides an OpenAPI 3 description of the Reaper REST API used by reaper-client-go, and validators
+// that check HTTP requests, HTTP responses and Go types against it.
+//
+// The description lives in reaper.yaml and is embedded in the package. Contract tests use it to make sure that the
+// client only builds requests Reaper accepts, and only decodes responses into types that match what Reaper returns:
+//
+//	transport := openapi.NewValidatingTransport(openapi.Reaper(), nil)
+//	client := reaper.NewClient(u, reaper.WithHttpClient(&http.Client{Transport: transport}))
+//	... exercise the client ...
+//	assert.Empty(t, transport.Violations())
+//
+// Only the subset of OpenAPI used by reaper.yaml is supported: local $ref, path, query and form parameters, and the
+// type, format, enum, nullable, minimum, maximum, items, properties, required and additionalProperties keywords.
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+//go:embed reaper.yaml
+var reaperYaml []byte
+
+var reaperSpec = mustParse(reaperYaml)
+
+// Reaper returns the description of the Reaper REST API embedded in this package. The returned spec is shared and must
+// not be modified.
+func Reaper() *Spec {
+	return reaperSpec
+}
+
+// ReaperYaml returns the raw YAML of the embedded description of the Reaper REST API.
+func ReaperYaml() []byte {
+	return append([]byte(nil), reaperYaml...)
+}
+
+type Spec struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+}
+
+type PathItem struct {
+	Parameters []*Parameter `json:"parameters"`
+	Get        *Operation   `json:"get"`
+	Head       *Operation   `json:"head"`
+	Post       *Operation   `json:"post"`
+	Put        *Operation   `json:"put"`
+	Delete     *Operation   `json:"delete"`
+	Options    *Operation   `json:"options"`
+}
+
+type Operation struct {
+	OperationId string               `json:"operationId"`
+	Parameters  []*Parameter         `json:"parameters"`
+	RequestBody *RequestBody         `json:"requestBody"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type Parameter struct {
+	Ref         string  `json:"$ref"`
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description"`
+	Required    bool    `json:"required"`
+	Deprecated  bool    `json:"deprecated"`
+	Style       string  `json:"style"`
+	Explode     *bool   `json:"explode"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Ref         string                `json:"$ref"`
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas    map[string]*Schema    `json:"schemas"`
+	Parameters map[string]*Parameter `json:"parameters"`
+	Responses  map[string]*Response  `json:"responses"`
+}
+
+type Schema struct {
+	Ref                  string                `json:"$ref"`
+	Description          string                `json:"description"`
+	Type                 string                `json:"type"`
+	Format               string                `json:"format"`
+	Enum                 []interface{}         `json:"enum"`
+	Nullable             bool                  `json:"nullable"`
+	Minimum              *float64              `json:"minimum"`
+	Maximum              *float64              `json:"maximum"`
+	ExclusiveMinimum     bool                  `json:"exclusiveMinimum"`
+	ExclusiveMaximum     bool                  `json:"exclusiveMaximum"`
+	Items                *Schema               `json:"items"`
+	Properties           map[string]*Schema    `json:"properties"`
+	Required             []string              `json:"required"`
+	AdditionalProperties *AdditionalProperties `json:"additionalProperties"`
+}
+
+// AdditionalProperties is either a boolean or a schema. A nil *AdditionalProperties allows any additional property.
+type AdditionalProperties struct {
+	Allowed bool
+	Schema  *Schema
+}
+
+func (a *AdditionalProperties) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &a.Allowed); err == nil {
+		return nil
+	}
+	a.Allowed = true
+	return json.Unmarshal(data, &a.Schema)
+}
+
+// Parse parses an OpenAPI description in YAML or JSON and resolves the references to parameters and responses.
+// References to schemas are resolved lazily, to support recursive schemas.
+func Parse(data []byte) (*Spec, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI description: %w", err)
+	}
+	// yaml.v2 decodes mappings as map[interface{}]interface{}, which encoding/json cannot marshal
+	converted, err := json.Marshal(convertYaml(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI description: %w", err)
+	}
+	spec := &Spec{}
+	if err := json.Unmarshal(converted, spec); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI description: %w", err)
+	}
+	if err := spec.resolve(); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+func mustParse(data []byte) *Spec {
+	spec, err := Parse(data)
+	if err != nil {
+		panic(err)
+	}
+	return spec
+}
+
+func convertYaml(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[fmt.Sprint(key)] = convertYaml(value)
+		}
+		return m
+	case []interface{}:
+		for i := range v {
+			v[i] = convertYaml(v[i])
+		}
+	}
+	return v
+}
+
+func (s *Spec) resolve() error {
+	for template, item := range s.Paths {
+		if err := s.resolveParameters(item.Parameters); err != nil {
+			return fmt.Errorf("path %s: %w", template, err)
+		}
+		for method, op := range item.operations() {
+			if err := s.resolveParameters(op.Parameters); err != nil {
+				return fmt.Errorf("%s %s: %w", method, template, err)
+			}
+			for status, response := range op.Responses {
+				if response.Ref == "" {
+					continue
+				}
+				resolved, found := s.Components.Responses[strings.TrimPrefix(response.Ref, "#/components/responses/")]
+				if !found {
+					return fmt.Errorf("%s %s: unresolved reference %s", method, template, response.Ref)
+				}
+				op.Responses[status] = resolved
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Spec) resolveParameters(parameters []*Parameter) error {
+	for i, parameter := range parameters {
+		if parameter.Ref == "" {
+			continue
+		}
+		resolved, found := s.Components.Parameters[strings.TrimPrefix(parameter.Ref, "#/components/parameters/")]
+		if !found {
+			return fmt.Errorf("unresolved reference %s", parameter.Ref)
+		}
+		parameters[i] = resolved
+	}
+	return nil
+}
+
+// Schema returns the schema named name in the components of the spec, or nil if there is no such schema.
+func (s *Spec) Schema(name string) *Schema {
+	return s.Components.Schemas[name]
+}
+
+// deref follows the $ref of a schema, if any.
+func (s *Spec) deref(schema *Schema) (*Schema, error) {
+	for schema != nil && schema.Ref != "" {
+		resolved, found := s.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+		if !found {
+			return nil, fmt.Errorf("unresolved reference %s", schema.Ref)
+		}
+		schema = resolved
+	}
+	return schema, nil
+}
+
+func (p *PathItem) operations() map[string]*Operation {
+	operations := make(map[string]*Operation)
+	for method, op := range map[string]*Operation{
+		http.MethodGet:     p.Get,
+		http.MethodHead:    p.Head,
+		http.MethodPost:    p.Post,
+		http.MethodPut:     p.Put,
+		http.MethodDelete:  p.Delete,
+		http.MethodOptions: p.Options,
+	} {
+		if op != nil {
+			operations[method] = op
+		}
+	}
+	return operations
+}
+
+// Operation returns the operation of the spec matching the given method and escaped request path, along with the template of
+// the matched path and the values of its path parameters. When several templates match, the one with the most
+// literal segments wins, so that /repair_run/purge is preferred over /repair_run/{id}.
+func (s *Spec) Operation(method, path string) (op *Operation, template string, pathParams map[string]string, err error) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	bestScore := -1
+	var item *PathItem
+	for candidate, candidateItem := range s.Paths {
+		params, score, matches := matchPath(strings.Split(strings.Trim(candidate, "/"), "/"), segments)
+		if matches && score > bestScore {
+			bestScore, item, template, pathParams = score, candidateItem, candidate, params
+		}
+	}
+	if item == nil {
+		return nil, "", nil, fmt.Errorf("no path of the spec matches %s", path)
+	}
+	op = item.operations()[method]
+	if op == nil {
+		return nil, template, nil, fmt.Errorf("method %s is not allowed on %s", method, template)
+	}
+	return op, template, pathParams, nil
+}
+
+// parameters returns the parameters of an operation, including those inherited from its path.
+func (s *Spec) parameters(template string, op *Operation) []*Parameter {
+	parameters := append([]*Parameter(nil), op.Parameters...)
+outer:
+	for _, inherited := range s.Paths[template].Parameters {
+		for _, parameter := range op.Parameters {
+			if parameter.Name == inherited.Name && parameter.In == inherited.In {
+				continue outer
+			}
+		}
+		parameters = append(parameters, inherited)
+	}
+	return parameters
+}
+
+func matchPath(template, segments []string) (map[string]string, int, bool) {
+	if len(template) != len(segments) {
+		return nil, 0, false
+	}
+	params := make(map[string]string)
+	score := 0
+	for i, segment := range template {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			if segments[i] == "" {
+				return nil, 0, false
+			}
+			value, err := url.PathUnescape(segments[i])
+			if err != nil {
+				return nil, 0, false
+			}
+			params[segment[1:len(segment)-1]] = value
+		} else if segment == segments[i] {
+			score++
+		} else {
+			return nil, 0, false
+		}
+	}
+	return params, score, true
+}
@@ -0,0 +1,125 @@
+package openapi_test
+
+import (
+	"math/big"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/k8ssandra/reaper-client-go/openapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReaper(t *testing.T) {
+	spec := openapi.Reaper()
+	assert.Equal(t, "3.0.3", spec.OpenAPI)
+	for template, item := range spec.Paths {
+		for _, op := range []*openapi.Operation{item.Get, item.Head, item.Post, item.Put, item.Delete} {
+			if op == nil {
+				continue
+			}
+			assert.NotEmpty(t, op.OperationId, template)
+			assert.NotEmpty(t, op.Responses, template)
+			for _, parameter := range op.Parameters {
+				assert.Empty(t, parameter.Ref, "unresolved parameter in %s", template)
+				assert.NotEmpty(t, parameter.Name, template)
+			}
+		}
+	}
+	assert.NotNil(t, spec.Schema("RepairRun"))
+
+	parsed, err := openapi.Parse(openapi.ReaperYaml())
+	require.NoError(t, err)
+	assert.Equal(t, spec, parsed, "the embedded description parses into the same spec")
+}
+
+func TestParse(t *testing.T) {
+	_, err := openapi.Parse([]byte("openapi: 3.0.3\npaths:\n  /x:\n    get:\n      parameters:\n        - $ref: '#/components/parameters/Missing'\n"))
+	assert.Error(t, err)
+	_, err = openapi.Parse([]byte("openapi: [3"))
+	assert.Error(t, err)
+}
+
+func TestOperation(t *testing.T) {
+	spec := openapi.Reaper()
+	tests := []struct {
+		method, path, operationId string
+		pathParams                map[string]string
+	}{
+		{http.MethodGet, "/ping", "getPing", map[string]string{}},
+		{http.MethodPost, "/repair_run/purge", "purgeRepairRuns", map[string]string{}},
+		{http.MethodGet, "/repair_run/abc", "getRepairRun", map[string]string{"id": "abc"}},
+		{http.MethodGet, "/cluster/my%2Fcluster", "getCluster", map[string]string{"cluster_name": "my/cluster"}},
+		{http.MethodPost, "/repair_schedule/start/abc", "startRepairSchedule", map[string]string{"id": "abc"}},
+		{http.MethodGet, "/repair_schedule/cluster/c1", "getRepairSchedulesForCluster", map[string]string{"cluster_name": "c1"}},
+	}
+	for _, tt := range tests {
+		op, _, pathParams, err := spec.Operation(tt.method, tt.path)
+		if assert.NoError(t, err, tt.path) {
+			assert.Equal(t, tt.operationId, op.OperationId)
+			assert.Equal(t, tt.pathParams, pathParams)
+		}
+	}
+	_, _, _, err := spec.Operation(http.MethodGet, "/snapshot/cluster/c1")
+	assert.Error(t, err)
+	_, _, _, err = spec.Operation(http.MethodPatch, "/repair_run")
+	assert.Error(t, err)
+}
+
+func TestCheckType(t *testing.T) {
+	spec := openapi.Reaper()
+
+	type endpoint struct {
+		Endpoint string
+		Load     float64
+	}
+	type gossip struct {
+		SourceNode string `json:"sourceNode"`
+		Endpoints  map[string]map[string][]endpoint
+	}
+	type embedded struct {
+		Name string `json:"name"`
+	}
+	type cluster struct {
+		embedded
+		Seeds       []string `json:"seed_hosts,omitempty"`
+		NodesStatus struct {
+			EndpointStates []gossip `json:"endpointStates"`
+		} `json:"nodes_status"`
+		ignored string
+		Skipped chan int `json:"-"`
+	}
+	assert.NoError(t, spec.CheckType("Cluster", reflect.TypeOf(cluster{})))
+
+	type tokenRange struct {
+		Start *big.Int `json:"start"`
+		End   *big.Int `json:"end"`
+	}
+	assert.NoError(t, spec.CheckType("TokenRange", reflect.TypeOf(&tokenRange{})))
+
+	type run struct {
+		Id        uuid.UUID  `json:"id"`
+		StartTime *time.Time `json:"start_time"`
+		State     string     `json:"state"`
+	}
+	assert.NoError(t, spec.CheckType("RepairRun", reflect.TypeOf(run{})))
+
+	type wrong struct {
+		Id        string    `json:"id"`
+		State     int       `json:"state"`
+		Owner     uuid.UUID `json:"owner"`
+		Undefined string    `json:"undefined"`
+	}
+	err := spec.CheckType("RepairRun", reflect.TypeOf(wrong{}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "RepairRun.state: int cannot decode string")
+	assert.Contains(t, err.Error(), `RepairRun.owner: uuid.UUID expects format uuid, got ""`)
+	assert.Contains(t, err.Error(), `field Undefined ("undefined") is not described`)
+	assert.Equal(t, 3, strings.Count(err.Error(), ";")+1)
+
+	assert.Error(t, spec.CheckType("Unknown", reflect.TypeOf(run{})))
+}
@@ -0,0 +1,88 @@
+package openapi
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ValidatingTransport is an http.RoundTripper that checks every request it sends and every response it receives
+// against a spec. Violations do not fail the exchange: they are collected, to be asserted at the end of a test.
+type ValidatingTransport struct {
+	spec      *Spec
+	transport http.RoundTripper
+
+	// BasePath is the path of the Reaper API root in request URLs, if Reaper is not served at the root of its host.
+	BasePath string
+
+	mu         sync.Mutex
+	violations []error
+}
+
+// NewValidatingTransport returns a transport validating exchanges against spec, and sending requests with transport.
+// If transport is nil, http.DefaultTransport is used.
+func NewValidatingTransport(spec *Spec, transport http.RoundTripper) *ValidatingTransport {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &ValidatingTransport{spec: spec, transport: transport}
+}
+
+func (t *ValidatingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	validated := t.relative(req)
+	if err := t.spec.ValidateRequest(validated); err != nil {
+		t.report(err)
+	}
+	res, err := t.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	if err := t.spec.ValidateResponse(validated, res, body); err != nil {
+		t.report(err)
+	}
+	return res, nil
+}
+
+// Violations returns the violations of the spec found so far, as *ValidationError values.
+func (t *ValidatingTransport) Violations() []error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]error(nil), t.violations...)
+}
+
+// Reset forgets the violations found so far.
+func (t *ValidatingTransport) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.violations = nil
+}
+
+func (t *ValidatingTransport) report(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.violations = append(t.violations, err)
+}
+
+// relative returns a shallow copy of req whose URL path is relative to BasePath.
+func (t *ValidatingTransport) relative(req *http.Request) *http.Request {
+	basePath := strings.TrimSuffix(t.BasePath, "/")
+	if basePath == "" {
+		return req
+	}
+	clone := req.Clone(req.Context())
+	u := *req.URL
+	u.Path = strings.TrimPrefix(u.Path, basePath)
+	if u.RawPath != "" {
+		u.RawPath = strings.TrimPrefix(u.RawPath, basePath)
+	}
+	clone.URL = &u
+	return clone
+}
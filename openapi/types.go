@@ -0,0 +1,169 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	uuidType        = reflect.TypeOf(uuid.UUID{})
+	timeType        = reflect.TypeOf(time.Time{})
+	bigIntType      = reflect.TypeOf(big.Int{})
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// CheckType checks that a Go type can decode JSON values described by the named schema of the spec: every field
+// decoded by encoding/json must be described by the schema with a compatible type. Properties of the schema that the
+// type ignores are fine. Like encoding/json, field names are matched case-insensitively.
+//
+// Types implementing json.Unmarshaler are not inspected, except uuid.UUID, time.Time and big.Int which are expected to
+// be described as UUID strings, date-time strings and integers respectively.
+func (s *Spec) CheckType(schemaName string, t reflect.Type) error {
+	schema := s.Schema(schemaName)
+	if schema == nil {
+		return fmt.Errorf("no schema named %s", schemaName)
+	}
+	problems := s.checkType(schema, t, schemaName, map[reflect.Type]bool{})
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("type %v does not match schema %s: %s", t, schemaName, strings.Join(problems, "; "))
+}
+
+func (s *Spec) checkType(schema *Schema, t reflect.Type, at string, visiting map[reflect.Type]bool) []string {
+	schema, err := s.deref(schema)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	if schema == nil {
+		return nil
+	}
+	t = indirect(t)
+	expect := func(types ...string) []string {
+		for _, expected := range types {
+			if schema.Type == expected {
+				return nil
+			}
+		}
+		return []string{fmt.Sprintf("%s: %v cannot decode %s", at, t, describeSchema(schema))}
+	}
+	switch {
+	case t == uuidType:
+		if problems := expect("string"); problems != nil || schema.Format == "uuid" {
+			return problems
+		}
+		return []string{fmt.Sprintf("%s: uuid.UUID expects format uuid, got %q", at, schema.Format)}
+	case t == timeType:
+		if problems := expect("string"); problems != nil || schema.Format == "date-time" {
+			return problems
+		}
+		return []string{fmt.Sprintf("%s: time.Time expects format date-time, got %q", at, schema.Format)}
+	case t == bigIntType:
+		return expect("integer")
+	case reflect.PtrTo(t).Implements(unmarshalerType):
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return expect("string")
+	case reflect.Bool:
+		return expect("boolean")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return expect("integer")
+	case reflect.Float32, reflect.Float64:
+		return expect("number", "integer")
+	case reflect.Interface:
+		return nil
+	case reflect.Slice, reflect.Array:
+		if problems := expect("array"); problems != nil {
+			return problems
+		}
+		return s.checkType(schema.Items, t.Elem(), at+"[]", visiting)
+	case reflect.Map:
+		if problems := expect("object"); problems != nil {
+			return problems
+		}
+		if schema.AdditionalProperties == nil || schema.AdditionalProperties.Schema == nil {
+			return []string{fmt.Sprintf("%s: %v expects an object with additionalProperties", at, t)}
+		}
+		return s.checkType(schema.AdditionalProperties.Schema, t.Elem(), at+"[*]", visiting)
+	case reflect.Struct:
+		if problems := expect("object"); problems != nil {
+			return problems
+		}
+		if visiting[t] {
+			return nil
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+		var problems []string
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.Anonymous && field.Tag.Get("json") == "" && indirect(field.Type).Kind() == reflect.Struct {
+				// Fields of embedded structs are promoted
+				problems = append(problems, s.checkType(schema, field.Type, at, visiting)...)
+				continue
+			}
+			name, skip := jsonName(field)
+			if skip {
+				continue
+			}
+			property := findProperty(schema, name)
+			if property == nil {
+				problems = append(problems, fmt.Sprintf("%s: field %s (%q) is not described", at, field.Name, name))
+				continue
+			}
+			problems = append(problems, s.checkType(property, field.Type, at+"."+name, visiting)...)
+		}
+		return problems
+	}
+	return []string{fmt.Sprintf("%s: unsupported type %v", at, t)}
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// jsonName returns the name of the JSON property a struct field is decoded from, following encoding/json rules.
+func jsonName(field reflect.StructField) (name string, skip bool) {
+	if !field.IsExported() {
+		return "", true
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	if name, _, _ = strings.Cut(tag, ","); name == "" {
+		name = field.Name
+	}
+	return name, false
+}
+
+func findProperty(schema *Schema, name string) *Schema {
+	if property, found := schema.Properties[name]; found {
+		return property
+	}
+	for candidate, property := range schema.Properties {
+		if strings.EqualFold(candidate, name) {
+			return property
+		}
+	}
+	return nil
+}
+
+func describeSchema(schema *Schema) string {
+	if schema.Format != "" {
+		return fmt.Sprintf("%s (%s)", schema.Type, schema.Format)
+	}
+	return schema.Type
+}
@@ -0,0 +1,382 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ValidationError lists the differences between an HTTP request or response and the spec.
+type ValidationError struct {
+	// The request method and path, e.g. "GET /repair_run/3a6c3a00-...".
+	Request string
+
+	// The response status code, or 0 if the request itself is invalid.
+	Status int
+
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Status == 0 {
+		return fmt.Sprintf("request %s violates the spec: %s", e.Request, strings.Join(e.Problems, "; "))
+	}
+	return fmt.Sprintf(
+		"response %d to %s violates the spec: %s", e.Status, e.Request, strings.Join(e.Problems, "; "))
+}
+
+// ValidateRequest checks that the method, path, query and body of a request are described by the spec. The request
+// path must be relative to the root of the Reaper API. The body, if any, must be readable through req.GetBody, as is
+// the case for requests created by http.NewRequest with a bytes or strings reader.
+func (s *Spec) ValidateRequest(req *http.Request) error {
+	name := req.Method + " " + req.URL.Path
+	op, template, pathParams, err := s.Operation(req.Method, req.URL.EscapedPath())
+	if err != nil {
+		return &ValidationError{Request: name, Problems: []string{err.Error()}}
+	}
+	var problems []string
+	query := req.URL.Query()
+	declared := make(map[string]bool)
+	for _, parameter := range s.parameters(template, op) {
+		switch parameter.In {
+		case "path":
+			problems = append(problems, s.validateParameter(parameter, []string{pathParams[parameter.Name]})...)
+		case "query":
+			declared[parameter.Name] = true
+			values, present := query[parameter.Name]
+			if !present {
+				if parameter.Required {
+					problems = append(problems, fmt.Sprintf("missing required query parameter %s", parameter.Name))
+				}
+				continue
+			}
+			problems = append(problems, s.validateParameter(parameter, values)...)
+		}
+	}
+	for _, name := range sortedKeys(query) {
+		if !declared[name] {
+			problems = append(problems, fmt.Sprintf("unknown query parameter %s", name))
+		}
+	}
+	problems = append(problems, s.validateRequestBody(req, op)...)
+	if len(problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Request: name, Problems: problems}
+}
+
+func (s *Spec) validateRequestBody(req *http.Request, op *Operation) []string {
+	var body []byte
+	if req.GetBody != nil {
+		reader, err := req.GetBody()
+		if err != nil {
+			return []string{fmt.Sprintf("failed to read request body: %v", err)}
+		}
+		var buf bytes.Buffer
+		_, err = buf.ReadFrom(reader)
+		_ = reader.Close()
+		if err != nil {
+			return []string{fmt.Sprintf("failed to read request body: %v", err)}
+		}
+		body = buf.Bytes()
+	}
+	if op.RequestBody == nil {
+		if len(body) > 0 {
+			return []string{"the operation does not accept a request body"}
+		}
+		return nil
+	}
+	if len(body) == 0 {
+		if op.RequestBody.Required {
+			return []string{"missing required request body"}
+		}
+		return nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	content, found := op.RequestBody.Content[mediaType]
+	if !found {
+		return []string{fmt.Sprintf("undeclared request content type %q", mediaType)}
+	}
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return []string{fmt.Sprintf("malformed form body: %v", err)}
+		}
+		return s.validateForm(content.Schema, form)
+	case "application/json":
+		return s.validateJson(content.Schema, body, "body")
+	}
+	return nil
+}
+
+// validateForm checks form fields against an object schema. Form values are strings, and are validated like query
+// parameters.
+func (s *Spec) validateForm(schema *Schema, form url.Values) []string {
+	schema, err := s.deref(schema)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	var problems []string
+	for _, name := range schema.Required {
+		if _, found := form[name]; !found {
+			problems = append(problems, fmt.Sprintf("missing required form field %s", name))
+		}
+	}
+	for _, name := range sortedKeys(form) {
+		property, found := schema.Properties[name]
+		if !found {
+			if schema.AdditionalProperties != nil && !schema.AdditionalProperties.Allowed {
+				problems = append(problems, fmt.Sprintf("unknown form field %s", name))
+			}
+			continue
+		}
+		problems = append(problems, s.validateParameter(&Parameter{Name: name, In: "form", Schema: property}, form[name])...)
+	}
+	return problems
+}
+
+// validateParameter checks the raw values of a path, query or form parameter. Arrays are expected in the form style
+// without explode, i.e. as a single comma-separated value.
+func (s *Spec) validateParameter(parameter *Parameter, values []string) []string {
+	schema, err := s.deref(parameter.Schema)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	at := fmt.Sprintf("%s parameter %s", parameter.In, parameter.Name)
+	if len(values) > 1 {
+		return []string{fmt.Sprintf("%s: repeated %d times", at, len(values))}
+	}
+	if schema != nil && schema.Type == "array" {
+		var problems []string
+		for i, item := range strings.Split(values[0], ",") {
+			problems = append(problems, s.validateString(schema.Items, item, fmt.Sprintf("%s[%d]", at, i))...)
+		}
+		return problems
+	}
+	return s.validateString(schema, values[0], at)
+}
+
+// validateString checks a value serialized as a string, like a parameter or a text/plain body, against a primitive
+// schema.
+func (s *Spec) validateString(schema *Schema, value string, at string) []string {
+	schema, err := s.deref(schema)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	if schema == nil {
+		return nil
+	}
+	var decoded interface{} = value
+	switch schema.Type {
+	case "integer", "number":
+		decoded = json.Number(strings.TrimSpace(value))
+	case "boolean":
+		b, err := strconv.ParseBool(value)
+		if err != nil || (value != "true" && value != "false") {
+			return []string{fmt.Sprintf("%s: %q is not a boolean", at, value)}
+		}
+		decoded = b
+	}
+	return s.validateValue(schema, decoded, at)
+}
+
+func (s *Spec) validateJson(schema *Schema, body []byte, at string) []string {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return []string{fmt.Sprintf("%s: malformed JSON: %v", at, err)}
+	}
+	return s.validateValue(schema, value, at)
+}
+
+// validateValue checks a decoded JSON value against a schema. Numbers must be decoded as json.Number.
+func (s *Spec) validateValue(schema *Schema, value interface{}, at string) []string {
+	schema, err := s.deref(schema)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	if schema == nil {
+		return nil
+	}
+	if value == nil {
+		if schema.Nullable {
+			return nil
+		}
+		return []string{fmt.Sprintf("%s: null is not allowed", at)}
+	}
+	var problems []string
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected an object, got %s", at, describe(value))}
+		}
+		for _, name := range schema.Required {
+			if _, found := object[name]; !found {
+				problems = append(problems, fmt.Sprintf("%s: missing required property %s", at, name))
+			}
+		}
+		for _, name := range sortedKeys(object) {
+			if property, found := schema.Properties[name]; found {
+				problems = append(problems, s.validateValue(property, object[name], at+"."+name)...)
+			} else if additional := schema.AdditionalProperties; additional != nil {
+				if !additional.Allowed {
+					problems = append(problems, fmt.Sprintf("%s: unknown property %s", at, name))
+				} else if additional.Schema != nil {
+					problems = append(problems, s.validateValue(additional.Schema, object[name], at+"."+name)...)
+				}
+			}
+		}
+		return problems
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected an array, got %s", at, describe(value))}
+		}
+		for i, item := range array {
+			problems = append(problems, s.validateValue(schema.Items, item, fmt.Sprintf("%s[%d]", at, i))...)
+		}
+		return problems
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected a string, got %s", at, describe(value))}
+		}
+		switch schema.Format {
+		case "uuid":
+			if _, err := uuid.Parse(str); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %q is not a UUID", at, str))
+			}
+		case "date-time":
+			if _, err := time.Parse(time.RFC3339, str); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %q is not a RFC 3339 date-time", at, str))
+			}
+		}
+	case "integer":
+		number, ok := value.(json.Number)
+		if _, isInt := new(big.Int).SetString(string(number), 10); !ok || !isInt {
+			return []string{fmt.Sprintf("%s: expected an integer, got %s", at, describe(value))}
+		}
+		problems = append(problems, checkRange(schema, number, at)...)
+	case "number":
+		number, ok := value.(json.Number)
+		if _, err := number.Float64(); !ok || err != nil {
+			return []string{fmt.Sprintf("%s: expected a number, got %s", at, describe(value))}
+		}
+		problems = append(problems, checkRange(schema, number, at)...)
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return []string{fmt.Sprintf("%s: expected a boolean, got %s", at, describe(value))}
+		}
+	}
+	if len(schema.Enum) > 0 {
+		allowed := false
+		for _, candidate := range schema.Enum {
+			allowed = allowed || fmt.Sprint(candidate) == fmt.Sprint(value)
+		}
+		if !allowed {
+			problems = append(problems, fmt.Sprintf("%s: %v is not one of %v", at, value, schema.Enum))
+		}
+	}
+	return problems
+}
+
+func checkRange(schema *Schema, number json.Number, at string) []string {
+	// Token ranges do not fit in a float64 without losing precision, but the bounds of the spec do
+	f, _ := new(big.Float).SetString(string(number))
+	if schema.Minimum != nil {
+		cmp := f.Cmp(big.NewFloat(*schema.Minimum))
+		if cmp < 0 || (cmp == 0 && schema.ExclusiveMinimum) {
+			return []string{fmt.Sprintf("%s: %s is below the minimum %v", at, number, *schema.Minimum)}
+		}
+	}
+	if schema.Maximum != nil {
+		cmp := f.Cmp(big.NewFloat(*schema.Maximum))
+		if cmp > 0 || (cmp == 0 && schema.ExclusiveMaximum) {
+			return []string{fmt.Sprintf("%s: %s is above the maximum %v", at, number, *schema.Maximum)}
+		}
+	}
+	return nil
+}
+
+// ValidateResponse checks that the status code, content type and body of a response to req are described by the
+// spec. Since the body of res may have been consumed already, it is passed separately.
+func (s *Spec) ValidateResponse(req *http.Request, res *http.Response, body []byte) error {
+	validationError := &ValidationError{Request: req.Method + " " + req.URL.Path, Status: res.StatusCode}
+	op, _, _, err := s.Operation(req.Method, req.URL.EscapedPath())
+	if err != nil {
+		validationError.Problems = []string{err.Error()}
+		return validationError
+	}
+	response, found := op.Responses[strconv.Itoa(res.StatusCode)]
+	if !found {
+		response, found = op.Responses["default"]
+	}
+	if !found {
+		validationError.Problems = []string{"undeclared status code"}
+		return validationError
+	}
+	validationError.Problems = s.validateResponseBody(response, res.Header.Get("Content-Type"), body)
+	if len(validationError.Problems) == 0 {
+		return nil
+	}
+	return validationError
+}
+
+func (s *Spec) validateResponseBody(response *Response, contentType string, body []byte) []string {
+	if len(response.Content) == 0 {
+		if len(body) > 0 {
+			return []string{"the response is not expected to have a body"}
+		}
+		return nil
+	}
+	if contentType == "" && len(body) == 0 {
+		return nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	content, found := response.Content[mediaType]
+	if !found {
+		return []string{fmt.Sprintf("undeclared response content type %q", contentType)}
+	}
+	if content.Schema == nil {
+		return nil
+	}
+	if mediaType == "application/json" {
+		return s.validateJson(content.Schema, body, "body")
+	}
+	return s.validateString(content.Schema, string(body), "body")
+}
+
+func describe(value interface{}) string {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	case string:
+		return strconv.Quote(value)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,308 @@
+package openapi_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/k8ssandra/reaper-client-go/openapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const runId = "3a6c3a00-5c2f-11eb-9b58-0242ac120002"
+
+func TestValidateRequest(t *testing.T) {
+	spec := openapi.Reaper()
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		body     string
+		problems []string
+	}{
+		{name: "ping", method: http.MethodHead, target: "/ping"},
+		{
+			name:   "create repair run",
+			method: http.MethodPost,
+			target: "/repair_run?clusterName=c1&keyspace=ks&owner=me&tables=t1,t2&segmentCount=10&segmentCountPerNode=10" +
+				"&repairParallelism=PARALLEL&intensity=0.5&incrementalRepair=true",
+		},
+		{
+			name:   "missing and unknown parameters",
+			method: http.MethodPost,
+			target: "/repair_run?clusterName=c1&keyspace=ks&keyspaceName=ks",
+			problems: []string{
+				"missing required query parameter owner",
+				"unknown query parameter keyspaceName",
+			},
+		},
+		{
+			name:   "invalid parameter values",
+			method: http.MethodPost,
+			target: "/repair_run?clusterName=c1&keyspace=ks&owner=me&intensity=0&repairParallelism=FAST" +
+				"&incrementalRepair=yes&repairThreadCount=1.5",
+			problems: []string{
+				"query parameter repairParallelism: FAST is not one of [SEQUENTIAL PARALLEL DATACENTER_AWARE]",
+				"query parameter intensity: 0 is below the minimum 0",
+				`query parameter incrementalRepair: "yes" is not a boolean`,
+				`query parameter repairThreadCount: expected an integer, got 1.5`,
+			},
+		},
+		{
+			name:     "comma-separated array",
+			method:   http.MethodGet,
+			target:   "/repair_run?state=RUNNING,FINISHED",
+			problems: []string{"query parameter state[1]: FINISHED is not one of"},
+		},
+		{
+			name:     "repeated parameter",
+			method:   http.MethodGet,
+			target:   "/repair_run?state=RUNNING&state=PAUSED",
+			problems: []string{"query parameter state: repeated 2 times"},
+		},
+		{
+			name:     "invalid path parameters",
+			method:   http.MethodPut,
+			target:   "/repair_run/" + runId + "/state/DONE",
+			problems: []string{"path parameter state: DONE is not one of [RUNNING PAUSED ABORTED]"},
+		},
+		{
+			name:     "invalid uuid",
+			method:   http.MethodGet,
+			target:   "/repair_run/42",
+			problems: []string{`path parameter id: "42" is not a UUID`},
+		},
+		{name: "login", method: http.MethodPost, target: "/login", body: "password=p&rememberMe=false&username=u"},
+		{
+			name:     "invalid login form",
+			method:   http.MethodPost,
+			target:   "/login",
+			body:     "username=u&remember=false",
+			problems: []string{"missing required form field password", "unknown form field remember"},
+		},
+		{
+			name:     "unexpected body",
+			method:   http.MethodPost,
+			target:   "/repair_run/purge",
+			body:     "a=b",
+			problems: []string{"the operation does not accept a request body"},
+		},
+		{
+			name:     "unknown operation",
+			method:   http.MethodPost,
+			target:   "/cluster",
+			problems: []string{"method POST is not allowed on /cluster"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req *http.Request
+			if tt.body == "" {
+				req = httptest.NewRequest(tt.method, tt.target, nil)
+			} else {
+				req = httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+				req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(strings.NewReader(tt.body)), nil }
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+			err := spec.ValidateRequest(req)
+			assertProblems(t, err, tt.problems)
+		})
+	}
+}
+
+func TestValidateResponse(t *testing.T) {
+	spec := openapi.Reaper()
+	run := `{"id":"` + runId + `","cluster_name":"c1","keyspace_name":"ks","state":"RUNNING","intensity":0.5,` +
+		`"duration":null,"start_time":"2021-01-22T10:00:00Z","column_families":["t1"]}`
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		status      int
+		contentType string
+		body        string
+		problems    []string
+	}{
+		{
+			name:        "repair run",
+			method:      http.MethodGet,
+			target:      "/repair_run/" + runId,
+			status:      http.StatusOK,
+			contentType: "application/json",
+			body:        run,
+		},
+		{
+			name:        "invalid repair run",
+			method:      http.MethodGet,
+			target:      "/repair_run/" + runId,
+			status:      http.StatusOK,
+			contentType: "application/json",
+			body:        `{"id":"42","cluster_name":"c1","state":"FINISHED","intensity":"high","end_time":"yesterday","nodes":null}`,
+			problems: []string{
+				"body: missing required property keyspace_name",
+				`body.end_time: "yesterday" is not a RFC 3339 date-time`,
+				`body.id: "42" is not a UUID`,
+				`body.intensity: expected a number, got "high"`,
+				"body.nodes: null is not allowed",
+				"body.state: FINISHED is not one of",
+			},
+		},
+		{
+			name:        "repair runs",
+			method:      http.MethodGet,
+			target:      "/repair_run?state=RUNNING",
+			status:      http.StatusOK,
+			contentType: "application/json; charset=utf-8",
+			body:        "[" + run + "," + run + "]",
+		},
+		{
+			name:        "token ranges",
+			method:      http.MethodGet,
+			target:      "/repair_run/" + runId + "/segments",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			body: `[{"id":"` + runId + `","runId":"` + runId + `","state":"DONE","startTime":1611309600000,` +
+				`"tokenRange":{"baseRange":{"start":-9223372036854775808,"end":9223372036854775807}},` +
+				`"replicas":{"node-0":"dc1"}}]`,
+		},
+		{
+			name:        "invalid token range",
+			method:      http.MethodGet,
+			target:      "/repair_run/" + runId + "/segments",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			body: `[{"id":"` + runId + `","runId":"` + runId + `","state":"DONE",` +
+				`"tokenRange":{"baseRange":{"start":"0","end":1.5}},"replicas":{"node-0":1}}]`,
+			problems: []string{
+				`body[0].replicas.node-0: expected a string, got 1`,
+				`body[0].tokenRange.baseRange.end: expected an integer, got 1.5`,
+				`body[0].tokenRange.baseRange.start: expected an integer, got "0"`,
+			},
+		},
+		{
+			name:        "error",
+			method:      http.MethodGet,
+			target:      "/repair_run/" + runId,
+			status:      http.StatusNotFound,
+			contentType: "text/plain",
+			body:        "repair run not found",
+		},
+		{
+			name:     "undeclared status",
+			method:   http.MethodGet,
+			target:   "/repair_run/" + runId,
+			status:   http.StatusTeapot,
+			problems: []string{"undeclared status code"},
+		},
+		{
+			name:        "undeclared content type",
+			method:      http.MethodGet,
+			target:      "/cluster",
+			status:      http.StatusOK,
+			contentType: "text/html",
+			body:        "<html></html>",
+			problems:    []string{`undeclared response content type "text/html"`},
+		},
+		{name: "no content", method: http.MethodDelete, target: "/cluster/c1", status: http.StatusAccepted},
+		{
+			name:     "unexpected body",
+			method:   http.MethodDelete,
+			target:   "/cluster/c1",
+			status:   http.StatusAccepted,
+			body:     "deleted",
+			problems: []string{"the response is not expected to have a body"},
+		},
+		{name: "login with cookie", method: http.MethodPost, target: "/login", status: http.StatusOK},
+		{
+			name:        "purge",
+			method:      http.MethodPost,
+			target:      "/repair_run/purge",
+			status:      http.StatusOK,
+			contentType: "text/plain",
+			body:        "3",
+		},
+		{
+			name:        "malformed JSON",
+			method:      http.MethodGet,
+			target:      "/cluster",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			body:        `["c1"`,
+			problems:    []string{"body: malformed JSON"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			res := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			if tt.contentType != "" {
+				res.Header.Set("Content-Type", tt.contentType)
+			}
+			err := spec.ValidateResponse(req, res, []byte(tt.body))
+			assertProblems(t, err, tt.problems)
+		})
+	}
+}
+
+func TestValidatingTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/reaper/cluster":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`["c1",2]`))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	transport := openapi.NewValidatingTransport(openapi.Reaper(), nil)
+	transport.BasePath = "/reaper/"
+	client := &http.Client{Transport: transport}
+
+	res, err := client.Get(server.URL + "/reaper/ping")
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	assert.Empty(t, transport.Violations())
+
+	res, err = client.Get(server.URL + "/reaper/cluster?verbose=true")
+	require.NoError(t, err)
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Equal(t, `["c1",2]`, string(body), "the response body must still be readable")
+
+	violations := transport.Violations()
+	require.Len(t, violations, 2)
+	var validationError *openapi.ValidationError
+	require.True(t, errors.As(violations[0], &validationError))
+	assert.Equal(t, "GET /cluster", validationError.Request)
+	assert.Equal(t, 0, validationError.Status)
+	assert.Equal(t, []string{"unknown query parameter verbose"}, validationError.Problems)
+	require.True(t, errors.As(violations[1], &validationError))
+	assert.Equal(t, http.StatusOK, validationError.Status)
+	assert.Equal(t, []string{"body[1]: expected a string, got 2"}, validationError.Problems)
+	assert.EqualError(t, violations[1], "response 200 to GET /cluster violates the spec: body[1]: expected a string, got 2")
+
+	transport.Reset()
+	assert.Empty(t, transport.Violations())
+}
+
+// assertProblems checks that err is a *ValidationError whose problems start with the expected ones, in order.
+func assertProblems(t *testing.T, err error, expected []string) {
+	t.Helper()
+	if len(expected) == 0 {
+		assert.NoError(t, err)
+		return
+	}
+	var validationError *openapi.ValidationError
+	require.True(t, errors.As(err, &validationError), "expected a validation error, got %v", err)
+	if assert.Len(t, validationError.Problems, len(expected), "%v", validationError.Problems) {
+		for i, problem := range validationError.Problems {
+			assert.True(t, strings.HasPrefix(problem, expected[i]), "expected %q to start with %q", problem, expected[i])
+		}
+	}
+}
@@ -0,0 +1,236 @@
+// Package orchestrator repairs whole clusters, keyspace by keyspace, on top of the Reaper client.
+//
+// The orchestrator creates and starts one repair run per keyspace, limits the number of concurrent runs per cluster,
+// waits for the runs to finish, retries failed runs and produces a final report. Every repair run it creates is
+// tagged with the plan owner and a cause derived from the plan name; when the orchestrating process restarts, running
+// the same plan again adopts the existing runs instead of creating new ones.
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/k8ssandra/reaper-client-go/reaper"
+)
+
+type Orchestrator struct {
+	client reaper.Client
+}
+
+func NewOrchestrator(client reaper.Client) *Orchestrator {
+	return &Orchestrator{client: client}
+}
+
+// Run executes the plan and blocks until all keyspaces have been processed or the context is cancelled. Failures to
+// repair individual keyspaces are reported in the returned report; an error is only returned if the plan is invalid
+// or the context was cancelled, in which case the report describes the progress made so far. Cancelling the context
+// does not stop the repair runs already started: running the same plan again will adopt them.
+func (o *Orchestrator) Run(ctx context.Context, plan *Plan) (*Report, error) {
+	if err := plan.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid repair plan: %w", err)
+	}
+	report := &Report{Plan: plan.Name, Started: time.Now()}
+	results := make([][]RunResult, len(plan.Targets))
+	var wg sync.WaitGroup
+	for i, target := range plan.Targets {
+		wg.Add(1)
+		go func(i int, target Target) {
+			defer wg.Done()
+			results[i] = o.repairCluster(ctx, plan, target)
+		}(i, target)
+	}
+	wg.Wait()
+	for _, clusterResults := range results {
+		report.Results = append(report.Results, clusterResults...)
+	}
+	report.Finished = time.Now()
+	return report, ctx.Err()
+}
+
+func (o *Orchestrator) repairCluster(ctx context.Context, plan *Plan, target Target) []RunResult {
+	keyspaces := plan.keyspaces(target)
+	results := make([]RunResult, len(keyspaces))
+	for i, keyspace := range keyspaces {
+		results[i] = RunResult{Cluster: target.Cluster, Keyspace: keyspace}
+	}
+	existing, err := o.existingRuns(ctx, plan, target.Cluster)
+	if err != nil {
+		for i := range results {
+			results[i].Err = err
+		}
+		return results
+	}
+	// Runs left active by a previous process hold a slot already: adopt them first so that the concurrency limit is
+	// honored after a restart.
+	order := make([]int, 0, len(keyspaces))
+	for i, keyspace := range keyspaces {
+		if run := existing[keyspace]; run != nil && isActive(run.State) {
+			order = append(order, i)
+		}
+	}
+	for i, keyspace := range keyspaces {
+		if run := existing[keyspace]; run == nil || !isActive(run.State) {
+			order = append(order, i)
+		}
+	}
+	slots := make(chan struct{}, plan.maxConcurrentRuns())
+	var wg sync.WaitGroup
+	for _, i := range order {
+		result := &results[i]
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			result.Err = ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			o.repairKeyspace(ctx, plan, result, existing[result.Keyspace])
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// existingRuns returns the repair runs created for this plan by previous orchestrator processes, keyed by keyspace.
+// When a keyspace has several runs, active runs are preferred over completed ones, and completed ones over failed
+// ones.
+func (o *Orchestrator) existingRuns(ctx context.Context, plan *Plan, cluster string) (map[string]*reaper.RepairRun, error) {
+	runs, err := o.client.RepairRuns(ctx, &reaper.RepairRunSearchOptions{Cluster: cluster})
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up existing repair runs of cluster %s: %w", cluster, err)
+	}
+	existing := make(map[string]*reaper.RepairRun)
+	for _, run := range runs {
+		if run.Owner != plan.Owner || run.Cause != plan.cause(cluster, run.Keyspace) {
+			continue
+		}
+		if current := existing[run.Keyspace]; current == nil || adoptionPriority(run.State) > adoptionPriority(current.State) {
+			existing[run.Keyspace] = run
+		}
+	}
+	return existing, nil
+}
+
+func (o *Orchestrator) repairKeyspace(ctx context.Context, plan *Plan, result *RunResult, existing *reaper.RepairRun) {
+	result.Started = time.Now()
+	defer func() { result.Finished = time.Now() }()
+
+	start := true
+	if existing != nil {
+		result.RunId = existing.Id
+		result.State = existing.State
+		result.Adopted = true
+		switch existing.State {
+		case reaper.RepairRunStateDone:
+			return
+		case reaper.RepairRunStateAborted, reaper.RepairRunStateDeleted:
+			result.Err = fmt.Errorf("repair run %v was %s by a previous process or an operator", existing.Id, existing.State)
+			return
+		case reaper.RepairRunStateRunning, reaper.RepairRunStatePaused:
+			// Paused runs are left paused: they are waited for until an operator resumes them.
+			start = false
+		}
+	} else {
+		id, err := o.client.CreateRepairRun(ctx, result.Cluster, result.Keyspace, plan.Owner, plan.createOptions(result.Cluster, result.Keyspace))
+		if err != nil {
+			result.Err = err
+			return
+		}
+		result.RunId = id
+		result.State = reaper.RepairRunStateNotStarted
+	}
+
+	maxAttempts := plan.Retry.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	for {
+		if start {
+			result.Attempts++
+			if err := o.client.StartRepairRun(ctx, result.RunId); err != nil {
+				result.Err = err
+				return
+			}
+		}
+		state, err := o.waitForRun(ctx, plan, result.RunId)
+		if err != nil {
+			result.Err = err
+			return
+		}
+		result.State = state
+		switch state {
+		case reaper.RepairRunStateDone:
+			return
+		case reaper.RepairRunStateError:
+			if result.Attempts >= maxAttempts {
+				result.Err = fmt.Errorf("repair run %v failed after %d attempts", result.RunId, result.Attempts)
+				return
+			}
+			select {
+			case <-time.After(plan.Retry.Backoff):
+			case <-ctx.Done():
+				result.Err = ctx.Err()
+				return
+			}
+			start = true
+		default:
+			result.Err = fmt.Errorf("repair run %v ended in state %s", result.RunId, state)
+			return
+		}
+	}
+}
+
+// waitForRun polls the repair run until it reaches a terminal state. Errors while polling are tolerated until the
+// context is cancelled.
+func (o *Orchestrator) waitForRun(ctx context.Context, plan *Plan, runId uuid.UUID) (reaper.RepairRunState, error) {
+	ticker := time.NewTicker(plan.pollInterval())
+	defer ticker.Stop()
+	var lastErr error
+	for {
+		run, err := o.client.RepairRun(ctx, runId)
+		if err == nil && isTerminated(run.State) {
+			return run.State, nil
+		}
+		lastErr = err
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			if lastErr != nil {
+				return "", fmt.Errorf("%w (last error: %v)", ctx.Err(), lastErr)
+			}
+			return "", ctx.Err()
+		}
+	}
+}
+
+func isActive(state reaper.RepairRunState) bool {
+	return state == reaper.RepairRunStateRunning || state == reaper.RepairRunStatePaused
+}
+
+func isTerminated(state reaper.RepairRunState) bool {
+	return state == reaper.RepairRunStateDone ||
+		state == reaper.RepairRunStateError ||
+		state == reaper.RepairRunStateAborted ||
+		state == reaper.RepairRunStateDeleted
+}
+
+func adoptionPriority(state reaper.RepairRunState) int {
+	switch state {
+	case reaper.RepairRunStateRunning, reaper.RepairRunStatePaused:
+		return 4
+	case reaper.RepairRunStateNotStarted:
+		return 3
+	case reaper.RepairRunStateDone:
+		return 2
+	case reaper.RepairRunStateError:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,227 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/k8ssandra/reaper-client-go/reaper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient simulates repair runs: a started run completes after a few polls, or fails if its keyspace is configured
+// to fail. Methods not used by the orchestrator are not implemented and panic.
+type fakeClient struct {
+	reaper.Client
+	mu             sync.Mutex
+	runs           map[uuid.UUID]*reaper.RepairRun
+	polls          map[uuid.UUID]int
+	failures       map[string]int
+	created        []string
+	running        map[string]int
+	maxRunning     map[string]int
+	createFailures map[string]bool
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		runs:           map[uuid.UUID]*reaper.RepairRun{},
+		polls:          map[uuid.UUID]int{},
+		failures:       map[string]int{},
+		running:        map[string]int{},
+		maxRunning:     map[string]int{},
+		createFailures: map[string]bool{},
+	}
+}
+
+func (c *fakeClient) addRun(cluster, keyspace, owner, cause string, state reaper.RepairRunState) *reaper.RepairRun {
+	run := &reaper.RepairRun{Id: uuid.New(), Cluster: cluster, Keyspace: keyspace, Owner: owner, Cause: cause, State: state}
+	c.runs[run.Id] = run
+	if state == reaper.RepairRunStateRunning {
+		c.running[cluster]++
+	}
+	return run
+}
+
+func (c *fakeClient) RepairRuns(_ context.Context, options *reaper.RepairRunSearchOptions) (map[uuid.UUID]*reaper.RepairRun, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	runs := map[uuid.UUID]*reaper.RepairRun{}
+	for id, run := range c.runs {
+		if run.Cluster == options.Cluster {
+			copied := *run
+			runs[id] = &copied
+		}
+	}
+	return runs, nil
+}
+
+func (c *fakeClient) CreateRepairRun(_ context.Context, cluster, keyspace, owner string, options *reaper.RepairRunCreateOptions) (uuid.UUID, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.createFailures[keyspace] {
+		return uuid.Nil, fmt.Errorf("keyspace %s doesn't exist", keyspace)
+	}
+	c.created = append(c.created, cluster+"/"+keyspace)
+	return c.addRun(cluster, keyspace, owner, options.Cause, reaper.RepairRunStateNotStarted).Id, nil
+}
+
+func (c *fakeClient) StartRepairRun(_ context.Context, id uuid.UUID) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	run := c.runs[id]
+	run.State = reaper.RepairRunStateRunning
+	c.polls[id] = 0
+	c.running[run.Cluster]++
+	if c.running[run.Cluster] > c.maxRunning[run.Cluster] {
+		c.maxRunning[run.Cluster] = c.running[run.Cluster]
+	}
+	return nil
+}
+
+func (c *fakeClient) RepairRun(_ context.Context, id uuid.UUID) (*reaper.RepairRun, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	run := c.runs[id]
+	if run.State == reaper.RepairRunStateRunning {
+		c.polls[id]++
+		if c.polls[id] >= 3 {
+			c.running[run.Cluster]--
+			if c.failures[run.Keyspace] > 0 {
+				c.failures[run.Keyspace]--
+				run.State = reaper.RepairRunStateError
+			} else {
+				run.State = reaper.RepairRunStateDone
+			}
+		}
+	}
+	copied := *run
+	return &copied, nil
+}
+
+func newPlan(targets ...Target) *Plan {
+	return &Plan{
+		Name:         "weekly-1",
+		Owner:        "orchestrator",
+		Targets:      targets,
+		PollInterval: time.Millisecond,
+		Retry:        RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond},
+	}
+}
+
+func TestRun(t *testing.T) {
+	client := newFakeClient()
+	client.failures["ks_flaky"] = 1
+	client.failures["ks_broken"] = 5
+	client.createFailures["ks_missing"] = true
+	plan := newPlan(
+		Target{Cluster: "cluster-1", Keyspaces: []string{"ks_c", "ks_flaky", "ks_a", "ks_b"}},
+		Target{Cluster: "cluster-2", Keyspaces: []string{"ks_broken", "ks_missing"}},
+	)
+	plan.Order = OrderAlphabetical
+	plan.MaxConcurrentRunsPerCluster = 2
+	plan.Options = &reaper.RepairRunCreateOptions{Intensity: 0.5, Cause: "overwritten"}
+
+	report, err := NewOrchestrator(client).Run(context.Background(), plan)
+	require.NoError(t, err)
+	require.Len(t, report.Results, 6)
+
+	keyspaces := make([]string, 0)
+	for _, result := range report.Results {
+		keyspaces = append(keyspaces, result.Cluster+"/"+result.Keyspace)
+	}
+	assert.Equal(t, []string{
+		"cluster-1/ks_a", "cluster-1/ks_b", "cluster-1/ks_c", "cluster-1/ks_flaky",
+		"cluster-2/ks_broken", "cluster-2/ks_missing",
+	}, keyspaces)
+	var created []string
+	for _, keyspace := range client.created {
+		if strings.HasPrefix(keyspace, "cluster-1/") {
+			created = append(created, keyspace)
+		}
+	}
+	require.Len(t, created, 4)
+	// two runs are created concurrently, then the next ones as slots free up
+	assert.ElementsMatch(t, []string{"cluster-1/ks_a", "cluster-1/ks_b"}, created[:2], "keyspaces must be created in plan order")
+
+	assert.True(t, report.Results[0].Succeeded())
+	assert.Equal(t, 1, report.Results[0].Attempts)
+	assert.True(t, report.Results[3].Succeeded(), "flaky keyspace must succeed on retry")
+	assert.Equal(t, 2, report.Results[3].Attempts)
+	assert.False(t, report.Results[4].Succeeded())
+	assert.Equal(t, reaper.RepairRunStateError, report.Results[4].State)
+	assert.Contains(t, report.Results[4].Err.Error(), "failed after 2 attempts")
+	assert.Contains(t, report.Results[5].Err.Error(), "doesn't exist")
+	assert.Len(t, report.Failed(), 2)
+	assert.False(t, report.Succeeded())
+	assert.Contains(t, report.String(), "Plan weekly-1: 4/6 keyspaces repaired")
+
+	assert.LessOrEqual(t, client.maxRunning["cluster-1"], 2)
+	assert.Equal(t, 1, client.maxRunning["cluster-2"])
+	for _, run := range client.runs {
+		assert.Equal(t, "orchestrator", run.Owner)
+		assert.Equal(t, fmt.Sprintf("orchestrator:weekly-1:%s/%s", run.Cluster, run.Keyspace), run.Cause)
+	}
+}
+
+func TestRunResumesAfterRestart(t *testing.T) {
+	client := newFakeClient()
+	plan := newPlan(Target{Cluster: "cluster-1", Keyspaces: []string{"ks_done", "ks_new", "ks_running", "ks_error"}})
+	done := client.addRun("cluster-1", "ks_done", "orchestrator", plan.cause("cluster-1", "ks_done"), reaper.RepairRunStateDone)
+	running := client.addRun("cluster-1", "ks_running", "orchestrator", plan.cause("cluster-1", "ks_running"), reaper.RepairRunStateRunning)
+	failed := client.addRun("cluster-1", "ks_error", "orchestrator", plan.cause("cluster-1", "ks_error"), reaper.RepairRunStateError)
+	// runs from other plans or owners must be ignored
+	client.addRun("cluster-1", "ks_new", "orchestrator", "orchestrator:weekly-0:cluster-1/ks_new", reaper.RepairRunStateDone)
+	client.addRun("cluster-1", "ks_new", "alice", plan.cause("cluster-1", "ks_new"), reaper.RepairRunStateDone)
+
+	report, err := NewOrchestrator(client).Run(context.Background(), plan)
+	require.NoError(t, err)
+	assert.True(t, report.Succeeded(), report.String())
+	assert.Equal(t, []string{"cluster-1/ks_new"}, client.created)
+	assert.Equal(t, 1, client.maxRunning["cluster-1"], "adopted running run must hold the only slot")
+
+	results := map[string]RunResult{}
+	for _, result := range report.Results {
+		results[result.Keyspace] = result
+	}
+	assert.Equal(t, done.Id, results["ks_done"].RunId)
+	assert.True(t, results["ks_done"].Adopted)
+	assert.Equal(t, 0, results["ks_done"].Attempts)
+	assert.Equal(t, running.Id, results["ks_running"].RunId)
+	assert.Equal(t, 0, results["ks_running"].Attempts)
+	assert.Equal(t, failed.Id, results["ks_error"].RunId)
+	assert.Equal(t, 1, results["ks_error"].Attempts, "failed run must be restarted, not recreated")
+	assert.False(t, results["ks_new"].Adopted)
+}
+
+func TestRunCancelled(t *testing.T) {
+	client := newFakeClient()
+	plan := newPlan(Target{Cluster: "cluster-1", Keyspaces: []string{"ks1", "ks2"}})
+	plan.PollInterval = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	report, err := NewOrchestrator(client).Run(ctx, plan)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	require.Len(t, report.Results, 2)
+	for _, result := range report.Results {
+		assert.True(t, errors.Is(result.Err, context.DeadlineExceeded), "%v", result.Err)
+	}
+}
+
+func TestPlanValidate(t *testing.T) {
+	assert.NoError(t, newPlan(Target{Cluster: "c", Keyspaces: []string{"ks"}}).Validate())
+	assert.Error(t, newPlan().Validate())
+	assert.Error(t, newPlan(Target{Cluster: "c"}).Validate())
+	assert.Error(t, newPlan(Target{Cluster: "c", Keyspaces: []string{"ks"}}, Target{Cluster: "c", Keyspaces: []string{"ks"}}).Validate())
+	plan := newPlan(Target{Cluster: "c", Keyspaces: []string{"ks"}})
+	plan.Order = "random"
+	assert.Error(t, plan.Validate())
+	_, err := NewOrchestrator(newFakeClient()).Run(context.Background(), &Plan{})
+	assert.Error(t, err)
+}
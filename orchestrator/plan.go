@@ -0,0 +1,138 @@
+package orchestrator
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/k8ssandra/reaper-client-go/reaper"
+)
+
+// Order defines in which order the keyspaces of a cluster are repaired.
+type Order string
+
+const (
+	// OrderAsListed repairs keyspaces in the order they are listed in the plan.
+	OrderAsListed = Order("AS_LISTED")
+
+	// OrderAlphabetical repairs keyspaces in alphabetical order.
+	OrderAlphabetical = Order("ALPHABETICAL")
+)
+
+// Target designates the keyspaces to repair in a cluster.
+type Target struct {
+	Cluster   string
+	Keyspaces []string
+}
+
+// RetryPolicy defines how repair runs ending in state ERROR are retried. Retrying a run restarts it, and Reaper picks
+// up where it left off. Aborted runs are never retried, since aborting is an explicit operator decision.
+type RetryPolicy struct {
+
+	// The maximum number of times a run is started, including the first attempt. Values <= 1 disable retries.
+	MaxAttempts int
+
+	// How long to wait before restarting a failed run.
+	Backoff time.Duration
+}
+
+// Plan describes a cluster-wide repair orchestration.
+type Plan struct {
+
+	// Uniquely identifies the orchestration. The name is stored in the Cause of every repair run created by the
+	// orchestrator, which allows a restarted orchestrator to find and adopt the runs of a previous process. Re-using a
+	// name means that keyspaces already repaired under that name will be skipped, so names should include a date or
+	// a generation number, e.g. "weekly-2021-02-14".
+	Name string
+
+	// The owner of the repair runs created by the orchestrator.
+	Owner string
+
+	Targets []Target
+
+	// Options used to create each repair run. The Cause field is overwritten by the orchestrator.
+	Options *reaper.RepairRunCreateOptions
+
+	// The maximum number of repair runs running concurrently in each cluster. Clusters are always repaired in
+	// parallel. Defaults to 1.
+	MaxConcurrentRunsPerCluster int
+
+	// Defaults to OrderAsListed.
+	Order Order
+
+	Retry RetryPolicy
+
+	// How often the state of the repair runs is polled. Defaults to 10 seconds.
+	PollInterval time.Duration
+}
+
+// Validate checks that the plan can be executed.
+func (p *Plan) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("plan name is mandatory")
+	}
+	if p.Owner == "" {
+		return fmt.Errorf("plan owner is mandatory")
+	}
+	if len(p.Targets) == 0 {
+		return fmt.Errorf("plan %s has no targets", p.Name)
+	}
+	clusters := make(map[string]bool, len(p.Targets))
+	for _, target := range p.Targets {
+		if target.Cluster == "" {
+			return fmt.Errorf("plan %s has a target without cluster", p.Name)
+		}
+		if clusters[target.Cluster] {
+			return fmt.Errorf("plan %s targets cluster %s more than once", p.Name, target.Cluster)
+		}
+		clusters[target.Cluster] = true
+		if len(target.Keyspaces) == 0 {
+			return fmt.Errorf("plan %s has no keyspaces for cluster %s", p.Name, target.Cluster)
+		}
+	}
+	switch p.Order {
+	case "", OrderAsListed, OrderAlphabetical:
+	default:
+		return fmt.Errorf("unknown order %s", p.Order)
+	}
+	if p.MaxConcurrentRunsPerCluster < 0 {
+		return fmt.Errorf("max concurrent runs per cluster must be >= 0")
+	}
+	return nil
+}
+
+// cause returns the cause identifying the repair runs of this plan for the given cluster and keyspace.
+func (p *Plan) cause(cluster, keyspace string) string {
+	return fmt.Sprintf("orchestrator:%s:%s/%s", p.Name, cluster, keyspace)
+}
+
+func (p *Plan) keyspaces(target Target) []string {
+	keyspaces := append([]string(nil), target.Keyspaces...)
+	if p.Order == OrderAlphabetical {
+		sort.Strings(keyspaces)
+	}
+	return keyspaces
+}
+
+func (p *Plan) maxConcurrentRuns() int {
+	if p.MaxConcurrentRunsPerCluster <= 0 {
+		return 1
+	}
+	return p.MaxConcurrentRunsPerCluster
+}
+
+func (p *Plan) pollInterval() time.Duration {
+	if p.PollInterval <= 0 {
+		return 10 * time.Second
+	}
+	return p.PollInterval
+}
+
+func (p *Plan) createOptions(cluster, keyspace string) *reaper.RepairRunCreateOptions {
+	options := &reaper.RepairRunCreateOptions{}
+	if p.Options != nil {
+		*options = *p.Options
+	}
+	options.Cause = p.cause(cluster, keyspace)
+	return options
+}
@@ -0,0 +1,93 @@
+package orchestrator
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/k8ssandra/reaper-client-go/reaper"
+)
+
+// RunResult is the outcome of the repair of one keyspace.
+type RunResult struct {
+	Cluster  string
+	Keyspace string
+
+	// The id of the repair run, or uuid.Nil if the run could not be created.
+	RunId uuid.UUID
+
+	// The last known state of the repair run.
+	State reaper.RepairRunState
+
+	// How many times the run was started by this process.
+	Attempts int
+
+	// True if the run was created by a previous orchestrator process and adopted by this one.
+	Adopted bool
+
+	Started  time.Time
+	Finished time.Time
+
+	// Non-nil if the keyspace could not be repaired.
+	Err error
+}
+
+// Succeeded returns true if the repair run completed.
+func (r RunResult) Succeeded() bool {
+	return r.Err == nil && r.State == reaper.RepairRunStateDone
+}
+
+func (r RunResult) String() string {
+	s := fmt.Sprintf("%s/%s: %s", r.Cluster, r.Keyspace, r.State)
+	if r.RunId != uuid.Nil {
+		s += fmt.Sprintf(" (run %v", r.RunId)
+		if r.Adopted {
+			s += ", adopted"
+		}
+		s += fmt.Sprintf(", %d attempts, %v)", r.Attempts, r.Finished.Sub(r.Started).Round(time.Second))
+	}
+	if r.Err != nil {
+		s += fmt.Sprintf(": %v", r.Err)
+	}
+	return s
+}
+
+// Report is the final outcome of an orchestration. Results are listed per cluster, in the order of the targets of the
+// plan, and then in the order of the plan's keyspaces, after applying its Order, whatever the order the keyspaces were
+// processed in.
+type Report struct {
+	Plan     string
+	Started  time.Time
+	Finished time.Time
+	Results  []RunResult
+}
+
+// Succeeded returns true if all keyspaces were repaired.
+func (r *Report) Succeeded() bool {
+	return len(r.Failed()) == 0
+}
+
+// Failed returns the results of the keyspaces that could not be repaired.
+func (r *Report) Failed() []RunResult {
+	var failed []RunResult
+	for _, result := range r.Results {
+		if !result.Succeeded() {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+func (r *Report) String() string {
+	var sb strings.Builder
+	failed := len(r.Failed())
+	fmt.Fprintf(&sb, "Plan %s: %d/%d keyspaces repaired in %v\n",
+		r.Plan, len(r.Results)-failed, len(r.Results), r.Finished.Sub(r.Started).Round(time.Second))
+	for _, result := range r.Results {
+		sb.WriteString("  ")
+		sb.WriteString(result.String())
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,366 @@
+// Package planner reviews the repair schedules of a cluster against its schema, and suggests a staggered timetable.
+//
+// Check reports:
+//   - overlaps: tables repaired by more than one schedule;
+//   - conflicts: schedules of the same keyspace with different parallelism or incremental settings;
+//   - collisions: schedules activated at about the same time, which compete for the same nodes;
+//   - gaps: keyspaces, or some of their tables, that no schedule repairs.
+//
+// The timetable spreads the next activations of the schedules, and of schedules to create for the gaps, a Window
+// apart. Only active schedules are considered: paused schedules repair nothing.
+//
+//	report, err := planner.Analyze(ctx, client, "cluster-1", planner.Options{Window: time.Hour})
+//	for _, slot := range report.Timetable {
+//		fmt.Println(slot.Keyspace, slot.Suggested)
+//	}
+package planner
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/k8ssandra/reaper-client-go/compliance"
+	"github.com/k8ssandra/reaper-client-go/reaper"
+)
+
+const (
+	// DefaultWindow is the default time between two activations below which they collide.
+	DefaultWindow = 2 * time.Hour
+
+	// DefaultDaysBetween is the default interval suggested for the schedules to create for gaps.
+	DefaultDaysBetween = 7
+)
+
+const (
+	SettingRepairParallelism = "repair_parallelism"
+	SettingIncrementalRepair = "incremental_repair"
+)
+
+type Options struct {
+	// Window is both the minimum time between two activations, below which they collide, and the time between the
+	// activations of the timetable. Defaults to DefaultWindow.
+	Window time.Duration
+
+	// Start is the first activation of the timetable. Defaults to the earliest next activation of the schedules, or
+	// to the next hour if there is none.
+	Start time.Time
+
+	// DaysBetween is the interval of the schedules suggested for gaps. Defaults to DefaultDaysBetween.
+	DaysBetween int
+
+	// IgnoredKeyspaces are never reported as gaps. Defaults to compliance.DefaultIgnoredKeyspaces when nil.
+	IgnoredKeyspaces []string
+
+	// Now is used to compute the default start. Defaults to the current time.
+	Now time.Time
+}
+
+// Overlap is a pair of schedules repairing the same tables.
+type Overlap struct {
+	Keyspace    string   `json:"keyspace"`
+	Tables      []string `json:"tables"`
+	ScheduleIds []string `json:"schedule_ids"`
+}
+
+// Conflict is a setting with different values among the schedules of a keyspace. Values are in the order of
+// ScheduleIds.
+type Conflict struct {
+	Keyspace    string   `json:"keyspace"`
+	Setting     string   `json:"setting"`
+	ScheduleIds []string `json:"schedule_ids"`
+	Values      []string `json:"values"`
+}
+
+// Collision is a group of schedules whose next activations are less than a window apart from one another.
+type Collision struct {
+	ScheduleIds []string  `json:"schedule_ids"`
+	First       time.Time `json:"first"`
+	Last        time.Time `json:"last"`
+}
+
+// Gap is a keyspace whose tables are not all repaired by a schedule.
+type Gap struct {
+	Keyspace string `json:"keyspace"`
+
+	// The tables not repaired; all the tables of the keyspace unless Partial.
+	Tables  []string `json:"tables"`
+	Partial bool     `json:"partial"`
+}
+
+// Slot is an activation of the suggested timetable, either of an existing schedule or of a schedule to create for a
+// gap, in which case ScheduleId is empty and Current is nil.
+type Slot struct {
+	ScheduleId  string     `json:"schedule_id"`
+	Keyspace    string     `json:"keyspace"`
+	Tables      []string   `json:"tables"`
+	DaysBetween int        `json:"days_between"`
+	Current     *time.Time `json:"current"`
+	Suggested   time.Time  `json:"suggested"`
+}
+
+// Report is the outcome of the review of the schedules of a cluster.
+type Report struct {
+	Cluster string `json:"cluster"`
+
+	// Clean is true if there are no overlaps, conflicts, collisions nor gaps.
+	Clean bool `json:"clean"`
+
+	Overlaps   []Overlap   `json:"overlaps"`
+	Conflicts  []Conflict  `json:"conflicts"`
+	Collisions []Collision `json:"collisions"`
+	Gaps       []Gap       `json:"gaps"`
+	Timetable  []Slot      `json:"timetable"`
+}
+
+// Analyze fetches the schema and the schedules of cluster, and checks them.
+func Analyze(ctx context.Context, client reaper.Client, cluster string, options Options) (*Report, error) {
+	schema, err := client.ClusterSchema(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	schedules, err := client.RepairSchedulesForCluster(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	return Check(cluster, schema, schedules, options), nil
+}
+
+// Check reviews the schedules of a cluster against its schema.
+func Check(cluster string, schema reaper.ClusterSchema, schedules []reaper.RepairSchedule, options Options) *Report {
+	options = options.withDefaults()
+	active := make([]reaper.RepairSchedule, 0, len(schedules))
+	for _, schedule := range schedules {
+		if schedule.State == string(reaper.RepairScheduleStateActive) {
+			active = append(active, schedule)
+		}
+	}
+	sort.SliceStable(active, func(i, j int) bool {
+		if active[i].KeyspaceName != active[j].KeyspaceName {
+			return active[i].KeyspaceName < active[j].KeyspaceName
+		}
+		return active[i].Id < active[j].Id
+	})
+
+	report := &Report{Cluster: cluster}
+	report.Overlaps = overlaps(schema, active)
+	report.Conflicts = conflicts(active)
+	report.Collisions = collisions(active, options.Window)
+	report.Gaps = gaps(schema, active, options.IgnoredKeyspaces)
+	report.Timetable = timetable(active, report.Gaps, options)
+	report.Clean = len(report.Overlaps) == 0 && len(report.Conflicts) == 0 && len(report.Collisions) == 0 &&
+		len(report.Gaps) == 0
+	return report
+}
+
+func (o Options) withDefaults() Options {
+	if o.Window <= 0 {
+		o.Window = DefaultWindow
+	}
+	if o.DaysBetween <= 0 {
+		o.DaysBetween = DefaultDaysBetween
+	}
+	if o.IgnoredKeyspaces == nil {
+		o.IgnoredKeyspaces = compliance.DefaultIgnoredKeyspaces
+	}
+	if o.Now.IsZero() {
+		o.Now = time.Now()
+	}
+	return o
+}
+
+// overlaps returns the pairs of schedules of the same keyspace sharing tables. schedules are sorted by keyspace.
+func overlaps(schema reaper.ClusterSchema, schedules []reaper.RepairSchedule) []Overlap {
+	var result []Overlap
+	for i, a := range schedules {
+		targets := schema.Targets(a.KeyspaceName, a.Tables, a.IgnoredTables)
+		for _, b := range schedules[i+1:] {
+			if b.KeyspaceName != a.KeyspaceName {
+				break
+			}
+			shared := intersect(targets, schema.Targets(b.KeyspaceName, b.Tables, b.IgnoredTables))
+			if len(shared) > 0 {
+				result = append(result, Overlap{
+					Keyspace:    a.KeyspaceName,
+					Tables:      shared,
+					ScheduleIds: []string{a.Id, b.Id},
+				})
+			}
+		}
+	}
+	return result
+}
+
+// conflicts returns the settings that differ among the schedules of a keyspace. schedules are sorted by keyspace.
+func conflicts(schedules []reaper.RepairSchedule) []Conflict {
+	settings := []struct {
+		name  string
+		value func(reaper.RepairSchedule) string
+	}{
+		{SettingRepairParallelism, func(s reaper.RepairSchedule) string { return s.RepairParallelism }},
+		{SettingIncrementalRepair, func(s reaper.RepairSchedule) string { return strconv.FormatBool(s.IncrementalRepair) }},
+	}
+	var result []Conflict
+	for start := 0; start < len(schedules); {
+		end := start + 1
+		for end < len(schedules) && schedules[end].KeyspaceName == schedules[start].KeyspaceName {
+			end++
+		}
+		keyspace := schedules[start:end]
+		for _, setting := range settings {
+			conflict := Conflict{Keyspace: keyspace[0].KeyspaceName, Setting: setting.name}
+			distinct := map[string]bool{}
+			for _, schedule := range keyspace {
+				value := setting.value(schedule)
+				if value == "" {
+					// Not returned by Reaper, so unknown rather than different.
+					continue
+				}
+				distinct[value] = true
+				conflict.ScheduleIds = append(conflict.ScheduleIds, schedule.Id)
+				conflict.Values = append(conflict.Values, value)
+			}
+			if len(distinct) > 1 {
+				result = append(result, conflict)
+			}
+		}
+		start = end
+	}
+	return result
+}
+
+// collisions groups the schedules whose next activations are less than window apart, transitively.
+func collisions(schedules []reaper.RepairSchedule, window time.Duration) []Collision {
+	byActivation := make([]reaper.RepairSchedule, 0, len(schedules))
+	for _, schedule := range schedules {
+		if !schedule.NextActivation.IsZero() {
+			byActivation = append(byActivation, schedule)
+		}
+	}
+	sort.SliceStable(byActivation, func(i, j int) bool {
+		return byActivation[i].NextActivation.Before(byActivation[j].NextActivation)
+	})
+	var result []Collision
+	for start := 0; start < len(byActivation); {
+		end := start + 1
+		for end < len(byActivation) && byActivation[end].NextActivation.Sub(byActivation[end-1].NextActivation) < window {
+			end++
+		}
+		if end-start > 1 {
+			collision := Collision{
+				First: byActivation[start].NextActivation.UTC(),
+				Last:  byActivation[end-1].NextActivation.UTC(),
+			}
+			for _, schedule := range byActivation[start:end] {
+				collision.ScheduleIds = append(collision.ScheduleIds, schedule.Id)
+			}
+			result = append(result, collision)
+		}
+		start = end
+	}
+	return result
+}
+
+// gaps returns the keyspaces of schema with tables that no schedule repairs.
+func gaps(schema reaper.ClusterSchema, schedules []reaper.RepairSchedule, ignoredKeyspaces []string) []Gap {
+	scheduled := map[string]map[string]bool{}
+	for _, schedule := range schedules {
+		if scheduled[schedule.KeyspaceName] == nil {
+			scheduled[schedule.KeyspaceName] = map[string]bool{}
+		}
+		for _, table := range schema.Targets(schedule.KeyspaceName, schedule.Tables, schedule.IgnoredTables) {
+			scheduled[schedule.KeyspaceName][table] = true
+		}
+	}
+	var result []Gap
+	for _, keyspace := range schema.Keyspaces() {
+		if contains(ignoredKeyspaces, keyspace) {
+			continue
+		}
+		tables := append([]string(nil), schema[keyspace]...)
+		sort.Strings(tables)
+		gap := Gap{Keyspace: keyspace}
+		for _, table := range tables {
+			if !scheduled[keyspace][table] {
+				gap.Tables = append(gap.Tables, table)
+			}
+		}
+		if scheduled[keyspace] == nil || len(gap.Tables) > 0 {
+			gap.Partial = len(gap.Tables) < len(tables)
+			result = append(result, gap)
+		}
+	}
+	return result
+}
+
+// timetable assigns an activation to every schedule, in the order of their next activations, then to a schedule per
+// gap, options.Window apart.
+func timetable(schedules []reaper.RepairSchedule, gaps []Gap, options Options) []Slot {
+	slots := make([]Slot, 0, len(schedules)+len(gaps))
+	for _, schedule := range schedules {
+		slot := Slot{
+			ScheduleId:  schedule.Id,
+			Keyspace:    schedule.KeyspaceName,
+			Tables:      schedule.Tables,
+			DaysBetween: schedule.DaysBetween,
+		}
+		if !schedule.NextActivation.IsZero() {
+			current := schedule.NextActivation.UTC()
+			slot.Current = &current
+		}
+		slots = append(slots, slot)
+	}
+	// Schedules without a next activation go last, the others keep their relative order.
+	sort.SliceStable(slots, func(i, j int) bool {
+		a, b := slots[i].Current, slots[j].Current
+		return a != nil && (b == nil || a.Before(*b))
+	})
+	for _, gap := range gaps {
+		slot := Slot{Keyspace: gap.Keyspace, DaysBetween: options.DaysBetween}
+		if gap.Partial {
+			slot.Tables = gap.Tables
+		}
+		slots = append(slots, slot)
+	}
+
+	start := options.Start
+	if start.IsZero() {
+		if len(slots) > 0 && slots[0].Current != nil {
+			start = *slots[0].Current
+		} else {
+			start = options.Now.Truncate(time.Hour).Add(time.Hour)
+		}
+	}
+	for i := range slots {
+		slots[i].Suggested = start.Add(time.Duration(i) * options.Window).UTC()
+	}
+	return slots
+}
+
+func intersect(a []string, b []string) []string {
+	var result []string
+	for _, value := range a {
+		if contains(b, value) {
+			result = append(result, value)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// String summarizes the report in one line.
+func (r *Report) String() string {
+	return fmt.Sprintf("cluster %s: %d overlaps, %d conflicts, %d collisions, %d gaps", r.Cluster, len(r.Overlaps),
+		len(r.Conflicts), len(r.Collisions), len(r.Gaps))
+}
@@ -0,0 +1,135 @@
+package planner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/k8ssandra/reaper-client-go/reaper"
+	"github.com/k8ssandra/reaper-client-go/reapermock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	now    = time.Date(2021, 3, 1, 9, 30, 0, 0, time.UTC)
+	schema = reaper.ClusterSchema{
+		"system": {"local"},
+		"ks1":    {"table1", "table2", "table3"},
+		"ks2":    {"table1", "table2"},
+		"ks3":    {"table1"},
+	}
+)
+
+func scheduleId(n int) string {
+	return fmt.Sprintf("00000000-0000-0000-0000-%012d", n)
+}
+
+func TestCheck(t *testing.T) {
+	at := func(hours float64) time.Time { return now.Add(time.Duration(hours * float64(time.Hour))) }
+	schedules := []reaper.RepairSchedule{
+		{Id: scheduleId(1), State: string(reaper.RepairScheduleStateActive), KeyspaceName: "ks1", DaysBetween: 7,
+			RepairParallelism: string(reaper.RepairParallelismDatacenterAware), NextActivation: at(10)},
+		{Id: scheduleId(2), State: string(reaper.RepairScheduleStateActive), KeyspaceName: "ks1", DaysBetween: 1,
+			Tables: []string{"table2", "table3"}, RepairParallelism: string(reaper.RepairParallelismParallel),
+			NextActivation: at(10.5)},
+		{Id: scheduleId(3), State: string(reaper.RepairScheduleStateActive), KeyspaceName: "ks2", DaysBetween: 7,
+			Tables: []string{"table1"}, NextActivation: at(1)},
+		{Id: scheduleId(4), State: string(reaper.RepairScheduleStatePaused), KeyspaceName: "ks3", NextActivation: at(1)},
+		{Id: scheduleId(5), State: string(reaper.RepairScheduleStateActive), KeyspaceName: "ks1", DaysBetween: 7,
+			IncrementalRepair: true, Tables: []string{"table1"}, IgnoredTables: []string{"table1"},
+			NextActivation: at(11)},
+	}
+
+	report := Check("cluster-1", schema, schedules, Options{Now: now})
+	assert.False(t, report.Clean)
+	assert.Equal(t, []Overlap{
+		{Keyspace: "ks1", Tables: []string{"table2", "table3"}, ScheduleIds: []string{scheduleId(1), scheduleId(2)}},
+	}, report.Overlaps, "schedule 5 ignores the only table it targets")
+	assert.Equal(t, []Conflict{
+		{
+			Keyspace:    "ks1",
+			Setting:     SettingRepairParallelism,
+			ScheduleIds: []string{scheduleId(1), scheduleId(2)},
+			Values:      []string{"DATACENTER_AWARE", "PARALLEL"},
+		},
+		{
+			Keyspace:    "ks1",
+			Setting:     SettingIncrementalRepair,
+			ScheduleIds: []string{scheduleId(1), scheduleId(2), scheduleId(5)},
+			Values:      []string{"false", "false", "true"},
+		},
+	}, report.Conflicts)
+	assert.Equal(t, []Collision{
+		{ScheduleIds: []string{scheduleId(1), scheduleId(2), scheduleId(5)}, First: at(10), Last: at(11)},
+	}, report.Collisions, "the paused schedule doesn't collide with schedule 3")
+	assert.Equal(t, []Gap{
+		{Keyspace: "ks2", Tables: []string{"table2"}, Partial: true},
+		{Keyspace: "ks3", Tables: []string{"table1"}},
+	}, report.Gaps)
+
+	require.Len(t, report.Timetable, 6)
+	var order []string
+	for i, slot := range report.Timetable {
+		assert.Equal(t, at(1+2*float64(i)), slot.Suggested, "starting at the earliest activation, 2 hours apart")
+		if slot.ScheduleId != "" {
+			order = append(order, slot.ScheduleId)
+		} else {
+			order = append(order, slot.Keyspace)
+			assert.Nil(t, slot.Current)
+			assert.Equal(t, DefaultDaysBetween, slot.DaysBetween)
+		}
+	}
+	assert.Equal(t, []string{scheduleId(3), scheduleId(1), scheduleId(2), scheduleId(5), "ks2", "ks3"}, order)
+	assert.Equal(t, []string{"table2"}, report.Timetable[4].Tables)
+	assert.Nil(t, report.Timetable[5].Tables, "the whole keyspace")
+	assert.Equal(t, "cluster cluster-1: 1 overlaps, 2 conflicts, 1 collisions, 2 gaps", report.String())
+
+	data, err := json.Marshal(report.Timetable[5])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"schedule_id":"","keyspace":"ks3","tables":null,"days_between":7,"current":null,
+		"suggested":"2021-03-01T20:30:00Z"}`, string(data))
+}
+
+func TestCheckClean(t *testing.T) {
+	start := time.Date(2021, 3, 2, 0, 0, 0, 0, time.UTC)
+	schedules := []reaper.RepairSchedule{
+		{Id: scheduleId(1), State: string(reaper.RepairScheduleStateActive), KeyspaceName: "ks1", NextActivation: start},
+		{Id: scheduleId(2), State: string(reaper.RepairScheduleStateActive), KeyspaceName: "ks2",
+			NextActivation: start.Add(time.Hour)},
+		{Id: scheduleId(3), State: string(reaper.RepairScheduleStateActive), KeyspaceName: "ks3"},
+	}
+	report := Check("cluster-1", schema, schedules, Options{Window: time.Hour, Now: now})
+	assert.True(t, report.Clean)
+	assert.Empty(t, report.Overlaps)
+	assert.Empty(t, report.Conflicts)
+	assert.Empty(t, report.Collisions, "activations a window apart don't collide")
+	assert.Empty(t, report.Gaps)
+	require.Len(t, report.Timetable, 3)
+	assert.Equal(t, scheduleId(3), report.Timetable[2].ScheduleId, "schedules without activation go last")
+	assert.Equal(t, start.Add(2*time.Hour), report.Timetable[2].Suggested)
+
+	report = Check("cluster-1", reaper.ClusterSchema{"ks1": {"table1"}}, nil, Options{Now: now})
+	require.Len(t, report.Timetable, 1)
+	assert.Equal(t, time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC), report.Timetable[0].Suggested, "the next hour")
+}
+
+func TestAnalyze(t *testing.T) {
+	mock := reapermock.NewMock(t)
+	mock.OnClusterSchema().With("cluster-1").Return(schema, nil)
+	mock.OnClusterSchema().With("unknown").Return(nil, errors.New("not found"))
+	mock.OnRepairSchedulesForCluster().With("cluster-1").Return([]reaper.RepairSchedule{
+		{Id: scheduleId(1), State: string(reaper.RepairScheduleStateActive), KeyspaceName: "ks1"},
+	}, nil)
+	ctx := context.Background()
+
+	report, err := Analyze(ctx, mock, "cluster-1", Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "cluster-1", report.Cluster)
+	assert.Len(t, report.Gaps, 2)
+	_, err = Analyze(ctx, mock, "unknown", Options{})
+	assert.EqualError(t, err, "not found")
+}
@@ -0,0 +1,204 @@
+package reaper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of the circuit breaker enabled with WithCircuitBreaker.
+type CircuitState string
+
+const (
+	// CircuitClosed lets all requests through.
+	CircuitClosed CircuitState = "closed"
+
+	// CircuitOpen fails all requests immediately with a CircuitOpenError.
+	CircuitOpen CircuitState = "open"
+
+	// CircuitHalfOpen checks whether Reaper is back up with IsReaperUp; other requests keep failing in the meantime.
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+const (
+	DefaultFailureThreshold = 5
+	DefaultOpenTimeout      = 30 * time.Second
+)
+
+// ErrCircuitOpen matches, with errors.Is, the errors returned while the circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitOpenError is the error returned, without sending any request, while the circuit breaker is open.
+type CircuitOpenError struct {
+	// The number of consecutive failures that opened the circuit, 0 if it was reopened by a failed probe.
+	Failures int
+
+	// The error of the last failure.
+	LastError error
+
+	// When the next probe will be allowed.
+	RetryAt time.Time
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%v until %v, last error: %v", ErrCircuitOpen, e.RetryAt.Format(time.RFC3339), e.LastError)
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+func (e *CircuitOpenError) Unwrap() error {
+	return e.LastError
+}
+
+type CircuitBreakerOptions struct {
+	// FailureThreshold is the number of consecutive failures opening the circuit. Failures are requests that could
+	// not be sent or got no response, and responses with a 5xx status; responses with a 4xx status are successes,
+	// since Reaper is up. Defaults to DefaultFailureThreshold.
+	FailureThreshold int
+
+	// OpenTimeout is how long the circuit stays open before a request is allowed to probe Reaper. Defaults to
+	// DefaultOpenTimeout.
+	OpenTimeout time.Duration
+
+	// OnStateChange, if set, is called on every state change, e.g. to report Reaper as degraded while the circuit is
+	// not closed. It is called synchronously by the request causing the change.
+	OnStateChange func(from CircuitState, to CircuitState)
+}
+
+// WithCircuitBreaker fails requests fast while Reaper is down, instead of letting each of them time out. Once
+// FailureThreshold consecutive requests have failed, the circuit opens and requests fail immediately with a
+// CircuitOpenError. After OpenTimeout, the next request probes Reaper with IsReaperUp: the circuit closes and the
+// request is sent if Reaper is up, and it stays open for another OpenTimeout otherwise.
+func WithCircuitBreaker(options CircuitBreakerOptions) ClientCreateOption {
+	return func(client *client) {
+		client.breaker = newCircuitBreaker(options)
+	}
+}
+
+type circuitBreaker struct {
+	options CircuitBreakerOptions
+	now     func() time.Time
+
+	mu        sync.Mutex
+	state     CircuitState
+	failures  int
+	lastError error
+	retryAt   time.Time
+}
+
+func newCircuitBreaker(options CircuitBreakerOptions) *circuitBreaker {
+	if options.FailureThreshold < 1 {
+		options.FailureThreshold = DefaultFailureThreshold
+	}
+	if options.OpenTimeout <= 0 {
+		options.OpenTimeout = DefaultOpenTimeout
+	}
+	return &circuitBreaker{options: options, now: time.Now, state: CircuitClosed}
+}
+
+type probeKey struct{}
+
+// serverErrorExpectedKey marks the context of requests to endpoints known to answer 500 even when they succeed, such
+// as DELETE /repair_run/{id}. Their 500 responses are not counted by the circuit breaker.
+type serverErrorExpectedKey struct{}
+
+func expectServerError(ctx context.Context) context.Context {
+	return context.WithValue(ctx, serverErrorExpectedKey{}, true)
+}
+
+// allow returns nil if a request can be sent, and a CircuitOpenError otherwise. When the circuit is open and due for a
+// probe, the calling request runs probe first; the probe itself bypasses the circuit breaker.
+func (b *circuitBreaker) allow(ctx context.Context, probe func(context.Context) (bool, error)) error {
+	if ctx.Value(probeKey{}) != nil {
+		return nil
+	}
+	b.mu.Lock()
+	switch {
+	case b.state == CircuitClosed:
+		b.mu.Unlock()
+		return nil
+	case b.state == CircuitHalfOpen || b.now().Before(b.retryAt):
+		err := b.openError()
+		b.mu.Unlock()
+		return err
+	}
+	notify := b.setState(CircuitHalfOpen)
+	b.mu.Unlock()
+	notify()
+
+	up, err := probe(context.WithValue(ctx, probeKey{}, true))
+	if err == nil && !up {
+		err = errors.New("reaper is not up")
+	}
+
+	b.mu.Lock()
+	b.failures = 0
+	if err != nil {
+		b.lastError = err
+		b.retryAt = b.now().Add(b.options.OpenTimeout)
+		err = b.openError()
+		notify = b.setState(CircuitOpen)
+	} else {
+		b.lastError = nil
+		notify = b.setState(CircuitClosed)
+	}
+	b.mu.Unlock()
+	notify()
+	return err
+}
+
+// record counts the outcome of a request sent while the circuit was closed. Requests canceled by the caller are not
+// counted, as they say nothing about Reaper; requests whose deadline was exceeded are. Expected 500 responses, see
+// expectServerError, are not counted either.
+func (b *circuitBreaker) record(ctx context.Context, res *http.Response, err error) {
+	if ctx.Value(probeKey{}) != nil || errors.Is(ctx.Err(), context.Canceled) {
+		return
+	}
+	if res != nil && res.StatusCode == http.StatusInternalServerError && ctx.Value(serverErrorExpectedKey{}) != nil {
+		// the caller checks the outcome with another request, which is counted instead
+		return
+	}
+	if res != nil {
+		if res.StatusCode < http.StatusInternalServerError {
+			err = nil
+		} else if err == nil {
+			err = &HttpError{StatusCode: res.StatusCode, Message: http.StatusText(res.StatusCode)}
+		}
+	}
+	notify := func() {}
+	b.mu.Lock()
+	if b.state == CircuitClosed {
+		if err == nil {
+			b.failures = 0
+		} else {
+			b.failures++
+			b.lastError = err
+			if b.failures >= b.options.FailureThreshold {
+				b.retryAt = b.now().Add(b.options.OpenTimeout)
+				notify = b.setState(CircuitOpen)
+			}
+		}
+	}
+	b.mu.Unlock()
+	notify()
+}
+
+func (b *circuitBreaker) openError() error {
+	return &CircuitOpenError{Failures: b.failures, LastError: b.lastError, RetryAt: b.retryAt}
+}
+
+// setState changes the state, and returns a function notifying OnStateChange of the change, to call once mu is
+// released so that the callback can use the client. Must be called with mu held.
+func (b *circuitBreaker) setState(state CircuitState) func() {
+	from := b.state
+	b.state = state
+	if from == state || b.options.OnStateChange == nil {
+		return func() {}
+	}
+	return func() { b.options.OnStateChange(from, state) }
+}
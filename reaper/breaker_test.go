@@ -0,0 +1,151 @@
+package reaper
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyReaper serves /ping and /cluster/cluster-1 when up, and fails all requests with 503 when down.
+type flakyReaper struct {
+	down     atomic.Bool
+	requests atomic.Int32
+}
+
+func (f *flakyReaper) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.requests.Add(1)
+	switch {
+	case f.down.Load():
+		w.WriteHeader(http.StatusServiceUnavailable)
+	case r.URL.Path == "/ping":
+		w.WriteHeader(http.StatusNoContent)
+	case r.URL.Path == "/cluster/cluster-1":
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name": "cluster-1"}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	backend := &flakyReaper{}
+	server := httptest.NewServer(backend)
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	var mu sync.Mutex
+	var changes []CircuitState
+	c := NewClient(u, WithCircuitBreaker(CircuitBreakerOptions{
+		FailureThreshold: 3,
+		OpenTimeout:      time.Minute,
+		OnStateChange: func(from, to CircuitState) {
+			mu.Lock()
+			defer mu.Unlock()
+			changes = append(changes, to)
+		},
+	}))
+	now := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	c.(*client).breaker.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// 4xx responses and canceled requests are not failures
+	for i := 0; i < 5; i++ {
+		_, err := c.GetCluster(ctx, "unknown")
+		assert.True(t, IsNotFound(err))
+	}
+	backend.down.Store(true)
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	for i := 0; i < 5; i++ {
+		_, err := c.GetCluster(canceled, "cluster-1")
+		assert.True(t, errors.Is(err, context.Canceled))
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err := c.GetCluster(ctx, "cluster-1")
+		assert.False(t, errors.Is(err, ErrCircuitOpen))
+	}
+	requests := backend.requests.Load()
+	_, err := c.GetCluster(ctx, "cluster-1")
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	var openErr *CircuitOpenError
+	require.True(t, errors.As(err, &openErr))
+	assert.Equal(t, 3, openErr.Failures)
+	assert.Equal(t, now.Add(time.Minute), openErr.RetryAt)
+	var httpErr *HttpError
+	require.True(t, errors.As(err, &httpErr), "the last error is wrapped")
+	assert.Equal(t, http.StatusServiceUnavailable, httpErr.StatusCode)
+	_, err = c.IsReaperUp(ctx)
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, requests, backend.requests.Load(), "no request is sent while the circuit is open")
+
+	// the probe fails, the circuit stays open for another minute
+	now = now.Add(time.Minute)
+	_, err = c.GetCluster(ctx, "cluster-1")
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	assert.EqualError(t, err,
+		"failed to get cluster cluster-1: circuit breaker is open until 2021-03-01T00:02:00Z, last error: reaper is not up")
+	assert.Equal(t, requests+1, backend.requests.Load(), "only the probe was sent")
+	now = now.Add(30 * time.Second)
+	_, err = c.GetCluster(ctx, "cluster-1")
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+
+	// the probe succeeds, the request is sent
+	backend.down.Store(false)
+	now = now.Add(30 * time.Second)
+	cluster, err := c.GetCluster(ctx, "cluster-1")
+	require.NoError(t, err)
+	assert.Equal(t, "cluster-1", cluster.Name)
+	assert.Equal(t, requests+3, backend.requests.Load())
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []CircuitState{CircuitOpen, CircuitHalfOpen, CircuitOpen, CircuitHalfOpen, CircuitClosed}, changes)
+}
+
+func TestCircuitBreakerTransportErrors(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	u, _ := url.Parse(server.URL)
+	server.Close()
+	c := NewClient(u, WithCircuitBreaker(CircuitBreakerOptions{FailureThreshold: 2}))
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err := c.RepairSchedules(ctx)
+		require.Error(t, err)
+		assert.False(t, errors.Is(err, ErrCircuitOpen))
+	}
+	_, err := c.RepairSchedules(ctx)
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+}
+
+func TestCircuitBreakerDeleteRepairRunQuirk(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodDelete:
+			// the run is deleted, but Reaper answers 500
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	c := NewClient(u, WithCircuitBreaker(CircuitBreakerOptions{FailureThreshold: 1}))
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, c.DeleteRepairRun(ctx, uuid.New(), "Alice"), "the 500 of a succeeded delete is not a failure")
+	}
+	_, err := c.GetCluster(ctx, "cluster-1")
+	assert.True(t, IsNotFound(err))
+}
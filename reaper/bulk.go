@@ -0,0 +1,151 @@
+package reaper
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// BulkResult is the outcome of a bulk operation for a single repair run or repair schedule.
+type BulkResult struct {
+
+	// The state of the repair run or repair schedule before the operation.
+	State string
+
+	// True if the operation was not attempted because the state was incompatible with it, e.g. pausing a repair run
+	// that is already DONE.
+	Skipped bool
+
+	// Non-nil if the operation was attempted and failed.
+	Err error
+}
+
+func (c *client) PauseRepairRuns(ctx context.Context, searchOptions *RepairRunSearchOptions) (map[uuid.UUID]*BulkResult, error) {
+	results, err := c.bulkRepairRuns(ctx, searchOptions, func(state RepairRunState) bool {
+		return state == RepairRunStateRunning
+	}, c.PauseRepairRun)
+	if err == nil {
+		return results, nil
+	}
+	return nil, fmt.Errorf("failed to pause repair runs: %w", err)
+}
+
+func (c *client) ResumeRepairRuns(ctx context.Context, searchOptions *RepairRunSearchOptions) (map[uuid.UUID]*BulkResult, error) {
+	results, err := c.bulkRepairRuns(ctx, searchOptions, func(state RepairRunState) bool {
+		return state == RepairRunStatePaused
+	}, c.ResumeRepairRun)
+	if err == nil {
+		return results, nil
+	}
+	return nil, fmt.Errorf("failed to resume repair runs: %w", err)
+}
+
+func (c *client) AbortRepairRuns(ctx context.Context, searchOptions *RepairRunSearchOptions) (map[uuid.UUID]*BulkResult, error) {
+	results, err := c.bulkRepairRuns(ctx, searchOptions, func(state RepairRunState) bool {
+		return state == RepairRunStateNotStarted || state.isActive()
+	}, c.AbortRepairRun)
+	if err == nil {
+		return results, nil
+	}
+	return nil, fmt.Errorf("failed to abort repair runs: %w", err)
+}
+
+func (c *client) PauseRepairSchedules(ctx context.Context, cluster string) (map[uuid.UUID]*BulkResult, error) {
+	results, err := c.bulkRepairSchedules(ctx, cluster, RepairScheduleStateActive, c.PauseRepairSchedule)
+	if err == nil {
+		return results, nil
+	}
+	return nil, fmt.Errorf("failed to pause repair schedules: %w", err)
+}
+
+func (c *client) ResumeRepairSchedules(ctx context.Context, cluster string) (map[uuid.UUID]*BulkResult, error) {
+	results, err := c.bulkRepairSchedules(ctx, cluster, RepairScheduleStatePaused, c.ResumeRepairSchedule)
+	if err == nil {
+		return results, nil
+	}
+	return nil, fmt.Errorf("failed to resume repair schedules: %w", err)
+}
+
+func (c *client) bulkRepairRuns(
+	ctx context.Context,
+	searchOptions *RepairRunSearchOptions,
+	compatible func(RepairRunState) bool,
+	operation func(context.Context, uuid.UUID) error,
+) (map[uuid.UUID]*BulkResult, error) {
+	repairRuns, err := c.RepairRuns(ctx, searchOptions)
+	if err != nil {
+		return nil, err
+	}
+	states := make(map[uuid.UUID]string, len(repairRuns))
+	targets := make(map[uuid.UUID]bool, len(repairRuns))
+	for id, repairRun := range repairRuns {
+		states[id] = string(repairRun.State)
+		targets[id] = compatible(repairRun.State)
+	}
+	return c.bulk(ctx, states, targets, operation), nil
+}
+
+func (c *client) bulkRepairSchedules(
+	ctx context.Context,
+	cluster string,
+	compatible RepairScheduleState,
+	operation func(context.Context, uuid.UUID) error,
+) (map[uuid.UUID]*BulkResult, error) {
+	var repairSchedules []RepairSchedule
+	var err error
+	if cluster == "" {
+		repairSchedules, err = c.RepairSchedules(ctx)
+	} else {
+		repairSchedules, err = c.RepairSchedulesForCluster(ctx, cluster)
+	}
+	if err != nil {
+		return nil, err
+	}
+	states := make(map[uuid.UUID]string, len(repairSchedules))
+	targets := make(map[uuid.UUID]bool, len(repairSchedules))
+	for _, repairSchedule := range repairSchedules {
+		id, err := uuid.Parse(repairSchedule.Id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid id of repair schedule %q: %w", repairSchedule.Id, err)
+		}
+		states[id] = repairSchedule.State
+		targets[id] = RepairScheduleState(repairSchedule.State) == compatible
+	}
+	return c.bulk(ctx, states, targets, operation), nil
+}
+
+// bulk applies the operation to all the targeted ids, with at most c.concurrency operations in flight.
+func (c *client) bulk(
+	ctx context.Context,
+	states map[uuid.UUID]string,
+	targets map[uuid.UUID]bool,
+	operation func(context.Context, uuid.UUID) error,
+) map[uuid.UUID]*BulkResult {
+	results := make(map[uuid.UUID]*BulkResult, len(states))
+	slots := make(chan struct{}, c.concurrency)
+	var wg sync.WaitGroup
+	for id, state := range states {
+		result := &BulkResult{State: state, Skipped: !targets[id]}
+		results[id] = result
+		if result.Skipped {
+			continue
+		}
+		wg.Add(1)
+		// each goroutine only writes to its own result
+		go func(id uuid.UUID) {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				result.Err = ctx.Err()
+				return
+			}
+			result.Err = operation(ctx, id)
+			<-slots
+		}(id)
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,123 @@
+package reaper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkRepairRuns(t *testing.T) {
+	running1, running2, paused, done, broken := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	runs := []*RepairRun{
+		{Id: running1, Cluster: "cluster-1", State: RepairRunStateRunning},
+		{Id: running2, Cluster: "cluster-1", State: RepairRunStateRunning},
+		{Id: paused, Cluster: "cluster-1", State: RepairRunStatePaused},
+		{Id: done, Cluster: "cluster-1", State: RepairRunStateDone},
+		{Id: broken, Cluster: "cluster-1", State: RepairRunStateRunning},
+	}
+	var mu sync.Mutex
+	transitions := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repair_run":
+			if r.URL.Query().Get("cluster_name") != "cluster-1" {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(runs)
+		case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/repair_run/"):
+			parts := strings.Split(r.URL.Path, "/")
+			if parts[2] == broken.String() {
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte("JMX connection failed"))
+				return
+			}
+			mu.Lock()
+			transitions[parts[2]] = parts[4]
+			mu.Unlock()
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	reaperClient := NewClient(u, WithConcurrency(2))
+	searchOptions := &RepairRunSearchOptions{Cluster: "cluster-1"}
+
+	results, err := reaperClient.PauseRepairRuns(context.Background(), searchOptions)
+	require.NoError(t, err)
+	require.Len(t, results, 5)
+	assert.Equal(t, &BulkResult{State: "RUNNING"}, results[running1])
+	assert.Equal(t, &BulkResult{State: "RUNNING"}, results[running2])
+	assert.Equal(t, &BulkResult{State: "PAUSED", Skipped: true}, results[paused])
+	assert.Equal(t, &BulkResult{State: "DONE", Skipped: true}, results[done])
+	require.Error(t, results[broken].Err)
+	assert.Contains(t, results[broken].Err.Error(), "JMX connection failed")
+	assert.Equal(t, map[string]string{running1.String(): "PAUSED", running2.String(): "PAUSED"}, transitions)
+
+	transitions = map[string]string{}
+	results, err = reaperClient.ResumeRepairRuns(context.Background(), searchOptions)
+	require.NoError(t, err)
+	assert.True(t, results[running1].Skipped)
+	assert.False(t, results[paused].Skipped)
+	assert.Equal(t, map[string]string{paused.String(): "RUNNING"}, transitions)
+
+	transitions = map[string]string{}
+	results, err = reaperClient.AbortRepairRuns(context.Background(), searchOptions)
+	require.NoError(t, err)
+	assert.True(t, results[done].Skipped)
+	assert.Len(t, transitions, 3)
+	assert.Equal(t, "ABORTED", transitions[paused.String()])
+
+	_, err = reaperClient.PauseRepairRuns(context.Background(), &RepairRunSearchOptions{Cluster: "cluster-2"})
+	assert.Error(t, err)
+}
+
+func TestBulkRepairSchedules(t *testing.T) {
+	active, paused := uuid.New(), uuid.New()
+	var mu sync.Mutex
+	transitions := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && (r.URL.Path == "/repair_schedule/cluster/cluster-1" || r.URL.Path == "/repair_schedule"):
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprintf(w, `[{"id": "%v", "state": "ACTIVE"}, {"id": "%v", "state": "PAUSED"}]`, active, paused)
+		case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/repair_schedule/"):
+			mu.Lock()
+			transitions[strings.TrimPrefix(r.URL.Path, "/repair_schedule/")] = r.URL.Query().Get("state")
+			mu.Unlock()
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	reaperClient := NewClient(u)
+
+	results, err := reaperClient.PauseRepairSchedules(context.Background(), "cluster-1")
+	require.NoError(t, err)
+	assert.Equal(t, &BulkResult{State: "ACTIVE"}, results[active])
+	assert.Equal(t, &BulkResult{State: "PAUSED", Skipped: true}, results[paused])
+	assert.Equal(t, map[string]string{active.String(): "PAUSED"}, transitions)
+
+	transitions = map[string]string{}
+	results, err = reaperClient.ResumeRepairSchedules(context.Background(), "")
+	require.NoError(t, err)
+	assert.True(t, results[active].Skipped)
+	assert.Equal(t, map[string]string{paused.String(): "ACTIVE"}, transitions)
+}
@@ -0,0 +1,245 @@
+package reaper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AuthMode is how Reaper authenticates clients.
+type AuthMode string
+
+const (
+	// AuthModeUnknown means authentication is required, but the login flow is not known yet. It is detected by the
+	// first successful Login.
+	AuthModeUnknown = AuthMode("")
+
+	// AuthModeNone means Reaper doesn't require authentication.
+	AuthModeNone = AuthMode("none")
+
+	// AuthModeSession means /login sets a JSESSIONID cookie, to be exchanged for a JWT on /jwt.
+	AuthModeSession = AuthMode("session")
+
+	// AuthModeJwt means /login returns the JWT directly in its body, like recent Reaper versions.
+	AuthModeJwt = AuthMode("jwt")
+)
+
+// capabilitiesRetryDelay is how long methods detecting the capabilities of Reaper on their own wait before trying again
+// after a failed detection.
+var capabilitiesRetryDelay = time.Minute
+
+// The first Reaper version accepting "segmentCountPerNode".
+var segmentCountPerNodeVersion = Version{1, 2, 0}
+
+// The routes probed to detect optional features. Path parameters are placeholders: only the existence of the route
+// is checked.
+const (
+	snapshotsRoute       = "/snapshot/cluster/probe"
+	diagEventsRoute      = "/diag_event/subscription"
+	percentRepairedRoute = "/repair_schedule/probe/00000000-0000-0000-0000-000000000000/percent_repaired"
+)
+
+// Capabilities describes the version of a Reaper instance and the features it supports.
+type Capabilities struct {
+	// The version reported by Reaper, e.g. "3.5.0", or empty if Reaper doesn't report its version.
+	Version string
+
+	AuthMode AuthMode
+
+	// Whether Reaper computes the percentage of data repaired by incremental repairs.
+	PercentRepaired bool
+
+	// Whether Reaper can subscribe to the diagnostic events of Cassandra 4.0+.
+	DiagEvents bool
+
+	// Whether Reaper exposes the snapshot endpoints.
+	Snapshots bool
+}
+
+// Version is a parsed Reaper version: major, minor and patch numbers.
+type Version [3]int
+
+// ParseVersion parses versions such as "3.5.0", "2.2" or "3.6.0-SNAPSHOT". Pre-release and build suffixes are ignored.
+func ParseVersion(s string) (Version, error) {
+	var version Version
+	core, _, _ := strings.Cut(strings.TrimPrefix(strings.TrimSpace(s), "v"), "-")
+	core, _, _ = strings.Cut(core, "+")
+	parts := strings.Split(core, ".")
+	if len(parts) > len(version) {
+		return version, fmt.Errorf("invalid version %q", s)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return version, fmt.Errorf("invalid version %q", s)
+		}
+		version[i] = n
+	}
+	return version, nil
+}
+
+// AtLeast returns true if v is the same as or more recent than other.
+func (v Version) AtLeast(other Version) bool {
+	for i := range v {
+		if v[i] != other[i] {
+			return v[i] > other[i]
+		}
+	}
+	return true
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v[0], v[1], v[2])
+}
+
+// version returns the parsed version of Reaper, and false if it is unknown.
+func (c *Capabilities) version() (Version, bool) {
+	if c == nil || c.Version == "" {
+		return Version{}, false
+	}
+	version, err := ParseVersion(c.Version)
+	return version, err == nil
+}
+
+// segmentCountParams returns the names of the query parameter setting the number of segments per node. Reaper versions
+// before 1.2 only know "segmentCount"; when the version is unknown, both names are sent.
+func (c *Capabilities) segmentCountParams() []string {
+	version, known := c.version()
+	switch {
+	case !known:
+		return []string{"segmentCountPerNode", "segmentCount"}
+	case version.AtLeast(segmentCountPerNodeVersion):
+		return []string{"segmentCountPerNode"}
+	default:
+		return []string{"segmentCount"}
+	}
+}
+
+func (c *client) Capabilities(ctx context.Context) (*Capabilities, error) {
+	if capabilities := c.cachedCapabilities(); capabilities != nil {
+		return capabilities, nil
+	}
+	// the probe runs outside of the lock, so that Login and the methods that don't need to wait for it are not
+	// blocked by a slow Reaper; concurrent callers share the same probe
+	_, err, _ := c.capabilitiesProbe.Do("capabilities", func() (interface{}, error) {
+		if c.cachedCapabilities() != nil {
+			return nil, nil
+		}
+		capabilities, err := c.probeCapabilities(ctx)
+		c.capabilitiesMu.Lock()
+		defer c.capabilitiesMu.Unlock()
+		if err != nil {
+			c.capabilitiesErr = err
+			c.capabilitiesRetryAt = time.Now().Add(capabilitiesRetryDelay)
+			return nil, err
+		}
+		if c.loggedInWith != AuthModeUnknown {
+			// Login completed while probing
+			capabilities.AuthMode = c.loggedInWith
+		}
+		c.capabilities, c.capabilitiesErr = capabilities, nil
+		return nil, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect capabilities: %w", err)
+	}
+	return c.cachedCapabilities(), nil
+}
+
+func (c *client) probeCapabilities(ctx context.Context) (*Capabilities, error) {
+	c.capabilitiesMu.Lock()
+	capabilities := &Capabilities{AuthMode: c.loggedInWith}
+	c.capabilitiesMu.Unlock()
+	res, err := c.doGet(ctx, "/reaper/version", nil, http.StatusOK, http.StatusNotFound)
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.readBodyAsString(res)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusOK {
+		capabilities.Version = strings.Trim(strings.TrimSpace(body), `"`)
+		if _, err := ParseVersion(capabilities.Version); err != nil {
+			return nil, err
+		}
+	}
+	for _, probe := range []struct {
+		route     string
+		supported *bool
+	}{
+		{snapshotsRoute, &capabilities.Snapshots},
+		{diagEventsRoute, &capabilities.DiagEvents},
+		{percentRepairedRoute, &capabilities.PercentRepaired},
+	} {
+		if *probe.supported, err = c.probeRoute(ctx, probe.route); err != nil {
+			return nil, err
+		}
+	}
+	if capabilities.AuthMode == AuthModeUnknown {
+		// the client may be logged in already: find out whether Reaper requires authentication without credentials
+		res, err := c.doGet(unauthenticated(ctx), "/cluster", nil,
+			http.StatusOK, http.StatusUnauthorized, http.StatusForbidden)
+		if err = c.discard(res, err); err != nil {
+			return nil, err
+		}
+		if res.StatusCode == http.StatusOK {
+			capabilities.AuthMode = AuthModeNone
+		}
+	}
+	return capabilities, nil
+}
+
+// probeRoute returns true if Reaper serves the given route, whatever the methods it allows on it.
+func (c *client) probeRoute(ctx context.Context, route string) (bool, error) {
+	res, err := c.doRequest(ctx, http.MethodOptions, route, nil, nil,
+		http.StatusOK, http.StatusNoContent, http.StatusMethodNotAllowed, http.StatusNotFound)
+	if err = c.discard(res, err); err != nil {
+		return false, err
+	}
+	return res.StatusCode != http.StatusNotFound, nil
+}
+
+// probedCapabilities returns the capabilities of Reaper, detecting them on first use, or nil if they can't be detected.
+// After a failed detection, it returns nil without probing Reaper again for capabilitiesRetryDelay, or until the next
+// Login.
+func (c *client) probedCapabilities(ctx context.Context) *Capabilities {
+	c.capabilitiesMu.Lock()
+	failed := c.capabilities == nil && c.capabilitiesErr != nil && time.Now().Before(c.capabilitiesRetryAt)
+	c.capabilitiesMu.Unlock()
+	if failed {
+		return nil
+	}
+	capabilities, err := c.Capabilities(ctx)
+	if err != nil {
+		return nil
+	}
+	return capabilities
+}
+
+// cachedCapabilities returns a copy of the capabilities detected by Capabilities or set with WithCapabilities, or nil,
+// without probing Reaper.
+func (c *client) cachedCapabilities() *Capabilities {
+	c.capabilitiesMu.Lock()
+	defer c.capabilitiesMu.Unlock()
+	if c.capabilities == nil {
+		return nil
+	}
+	capabilities := *c.capabilities
+	return &capabilities
+}
+
+// setAuthMode records the login flow observed by Login. A failed detection of the capabilities is forgotten, since it
+// may have been caused by the missing credentials.
+func (c *client) setAuthMode(mode AuthMode) {
+	c.capabilitiesMu.Lock()
+	defer c.capabilitiesMu.Unlock()
+	c.loggedInWith = mode
+	c.capabilitiesErr = nil
+	if c.capabilities != nil {
+		c.capabilities.AuthMode = mode
+	}
+}
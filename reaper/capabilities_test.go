@@ -0,0 +1,328 @@
+package reaper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/k8ssandra/reaper-client-go/reapertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVersion(t *testing.T) {
+	for s, expected := range map[string]Version{
+		"3.5.0":          {3, 5, 0},
+		"2.2":            {2, 2, 0},
+		"3":              {3, 0, 0},
+		"v1.4.1":         {1, 4, 1},
+		"3.6.0-SNAPSHOT": {3, 6, 0},
+		"3.6.0+abc123":   {3, 6, 0},
+		" 3.5.1\n":       {3, 5, 1},
+	} {
+		version, err := ParseVersion(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, expected, version, s)
+	}
+	for _, s := range []string{"", "3.x", "1.2.3.4", "3..1", "-1.0"} {
+		_, err := ParseVersion(s)
+		assert.Error(t, err, s)
+	}
+	assert.True(t, Version{3, 5, 0}.AtLeast(Version{3, 5, 0}))
+	assert.True(t, Version{3, 5, 0}.AtLeast(Version{2, 9, 9}))
+	assert.False(t, Version{3, 5, 0}.AtLeast(Version{3, 5, 1}))
+	assert.Equal(t, "3.5.0", Version{3, 5, 0}.String())
+}
+
+// countingTransport counts the requests sent to Reaper.
+type countingTransport struct {
+	requests int32
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&t.requests, 1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestCapabilities(t *testing.T) {
+	ctx := context.Background()
+	for _, test := range []struct {
+		name     string
+		options  []reapertest.ServerOption
+		expected Capabilities
+	}{
+		{"Recent", nil, Capabilities{
+			Version:         "3.5.0",
+			AuthMode:        AuthModeNone,
+			PercentRepaired: true,
+			DiagEvents:      true,
+			Snapshots:       true,
+		}},
+		{"Old", []reapertest.ServerOption{
+			reapertest.WithVersion("1.4.0"),
+			reapertest.WithoutFeatures(reapertest.FeatureDiagEvents, reapertest.FeaturePercentRepaired),
+		}, Capabilities{
+			Version:   "1.4.0",
+			AuthMode:  AuthModeNone,
+			Snapshots: true,
+		}},
+		{"Unversioned", []reapertest.ServerOption{
+			reapertest.WithVersion(""),
+			reapertest.WithoutFeatures(reapertest.FeatureSnapshots),
+		}, Capabilities{
+			AuthMode:        AuthModeNone,
+			PercentRepaired: true,
+			DiagEvents:      true,
+		}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			server := reapertest.NewServer(test.options...)
+			defer server.Close()
+			transport := &countingTransport{}
+			client := NewClient(server.URL(), WithHttpClient(&http.Client{Transport: transport}))
+
+			capabilities, err := client.Capabilities(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, &test.expected, capabilities)
+			requests := atomic.LoadInt32(&transport.requests)
+
+			capabilities.Version = "modified"
+			capabilities, err = client.Capabilities(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, &test.expected, capabilities, "capabilities are cached and copied")
+			assert.Equal(t, requests, atomic.LoadInt32(&transport.requests))
+		})
+	}
+
+	t.Run("InvalidVersion", func(t *testing.T) {
+		server := reapertest.NewServer(reapertest.WithVersion("unknown"))
+		defer server.Close()
+		_, err := NewClient(server.URL()).Capabilities(ctx)
+		assert.EqualError(t, err, `failed to detect capabilities: invalid version "unknown"`)
+	})
+
+	for name, options := range map[AuthMode][]reapertest.ServerOption{
+		AuthModeSession: {reapertest.WithCredentials("user", "pass")},
+		AuthModeJwt:     {reapertest.WithCredentials("user", "pass"), reapertest.WithJwtInLoginBody()},
+	} {
+		t.Run(string(name), func(t *testing.T) {
+			server := reapertest.NewServer(options...)
+			defer server.Close()
+			client := NewClient(server.URL())
+			_, err := client.Capabilities(ctx)
+			var httpErr *HttpError
+			if assert.True(t, errors.As(err, &httpErr), "capabilities require authentication") {
+				assert.Equal(t, http.StatusUnauthorized, httpErr.StatusCode)
+			}
+
+			require.NoError(t, client.Login(ctx, "user", "pass"))
+			capabilities, err := client.Capabilities(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, "3.5.0", capabilities.Version)
+			assert.Equal(t, name, capabilities.AuthMode)
+
+			// the detected flow is used for subsequent logins
+			require.NoError(t, client.Login(ctx, "user", "pass"))
+			_, err = client.GetClusterNames(ctx)
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("LoginBeforeProbe", func(t *testing.T) {
+		server := reapertest.NewServer(reapertest.WithCredentials("user", "pass"), reapertest.WithJwtInLoginBody())
+		defer server.Close()
+		client := NewClient(server.URL())
+		require.NoError(t, client.Login(ctx, "user", "pass"))
+		capabilities, err := client.Capabilities(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, AuthModeJwt, capabilities.AuthMode, "the flow observed by Login is remembered")
+	})
+}
+
+func testCapabilities(t *testing.T, client Client) {
+	capabilities, err := client.Capabilities(context.Background())
+	require.NoError(t, err)
+	_, err = ParseVersion(capabilities.Version)
+	assert.NoError(t, err)
+	assert.NotEqual(t, AuthModeNone, capabilities.AuthMode)
+}
+
+func TestCapabilitiesWireFormat(t *testing.T) {
+	var query url.Values
+	var version string
+	var versionRequests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repair_run", "/repair_schedule":
+			query = r.URL.Query()
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id": "a2b1f0e0-6d7c-11eb-9439-0242ac130002"}`))
+		case "/reaper/version":
+			atomic.AddInt32(&versionRequests, 1)
+			if version == "" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte(version))
+		case "/cluster":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`[]`))
+		case "/login":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]string{"token": "jwt"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	ctx := context.Background()
+
+	for _, test := range []struct {
+		reported string
+		preset   string
+		expected []string
+	}{
+		{"", "", []string{"segmentCount", "segmentCountPerNode"}},
+		{"1.0.0", "", []string{"segmentCount"}},
+		{"3.5.0", "", []string{"segmentCountPerNode"}},
+		{"3.5.0", "1.0.0", []string{"segmentCount"}},
+	} {
+		version = test.reported
+		atomic.StoreInt32(&versionRequests, 0)
+		client := NewClient(u)
+		if test.preset != "" {
+			client = NewClient(u, WithCapabilities(Capabilities{Version: test.preset}))
+		}
+		checkQuery := func() {
+			for _, param := range []string{"segmentCount", "segmentCountPerNode"} {
+				if slices.Contains(test.expected, param) {
+					assert.Equal(t, []string{"8"}, query[param], "%s with version %q", param, test.reported)
+				} else {
+					assert.NotContains(t, query, param, "version %q", test.reported)
+				}
+			}
+		}
+		_, err := client.CreateRepairRun(ctx, "cluster-1", "ks", "Alice", &RepairRunCreateOptions{SegmentCountPerNode: 8})
+		require.NoError(t, err)
+		checkQuery()
+		_, err = client.CreateRepairSchedule(ctx, "cluster-1", "ks", "Alice", 7,
+			&RepairScheduleCreateOptions{SegmentCountPerNode: 8})
+		require.NoError(t, err)
+		checkQuery()
+		if test.preset != "" {
+			assert.Zero(t, atomic.LoadInt32(&versionRequests), "preset capabilities are not probed")
+		} else {
+			assert.Equal(t, int32(1), atomic.LoadInt32(&versionRequests), "capabilities are probed once, on first use")
+		}
+	}
+
+	// Reaper returns the JWT in the login body, but the client was told to expect a session cookie
+	client := NewClient(u, WithCapabilities(Capabilities{AuthMode: AuthModeSession}))
+	assert.Error(t, client.Login(ctx, "user", "pass"))
+	assert.NoError(t, NewClient(u, WithCapabilities(Capabilities{AuthMode: AuthModeJwt})).Login(ctx, "user", "pass"))
+}
+
+// TestCapabilitiesProbes checks that every probe goes through the rate limiter, and that only the auth probe is sent
+// without the credentials of the client.
+func TestCapabilitiesProbes(t *testing.T) {
+	var mu sync.Mutex
+	authorizations := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		authorizations[r.Method+" "+r.URL.Path] = r.Header.Get("Authorization")
+		mu.Unlock()
+		switch {
+		case r.Header.Get("Authorization") == "":
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/reaper/version":
+			_, _ = w.Write([]byte("3.5.0"))
+		case r.Method == http.MethodOptions && r.URL.Path != diagEventsRoute:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	var throttled []string
+	client := NewClient(u, WithJwt("jwt"), WithRateLimit(RateLimitOptions{
+		RateLimit:  RateLimit{RequestsPerSecond: 20},
+		OnThrottle: func(event ThrottleEvent) { throttled = append(throttled, event.Method+" "+event.Path) },
+	}))
+
+	capabilities, err := client.Capabilities(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &Capabilities{Version: "3.5.0", PercentRepaired: true, Snapshots: true}, capabilities)
+	assert.ElementsMatch(t, []string{
+		"OPTIONS " + snapshotsRoute,
+		"OPTIONS " + diagEventsRoute,
+		"OPTIONS " + percentRepairedRoute,
+		"GET /cluster",
+	}, throttled, "the first request uses the burst, the others wait for the rate limiter")
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, "Bearer jwt", authorizations["OPTIONS "+diagEventsRoute])
+	assert.Empty(t, authorizations["GET /cluster"], "the auth probe is sent without credentials")
+}
+
+func TestCapabilitiesFailure(t *testing.T) {
+	var versionRequests int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/reaper/version":
+			if atomic.AddInt32(&versionRequests, 1) == 1 {
+				<-release
+			}
+			_, _ = w.Write([]byte("unknown"))
+		case "/repair_run":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id": "a2b1f0e0-6d7c-11eb-9439-0242ac130002"}`))
+		case "/login":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]string{"token": "jwt"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	ctx := context.Background()
+	client := NewClient(u)
+
+	created := make(chan error)
+	go func() {
+		_, err := client.CreateRepairRun(ctx, "cluster-1", "ks", "Alice", &RepairRunCreateOptions{SegmentCountPerNode: 8})
+		created <- err
+	}()
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&versionRequests) == 1 }, time.Second, time.Millisecond)
+	assert.NoError(t, client.Login(ctx, "user", "pass"), "Login doesn't wait for the probe")
+	close(release)
+	assert.NoError(t, <-created, "the request is sent with both parameter names")
+
+	for i := 0; i < 2; i++ {
+		_, err := client.CreateRepairRun(ctx, "cluster-1", "ks", "Alice", &RepairRunCreateOptions{SegmentCountPerNode: 8})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&versionRequests), "a failed detection is not retried right away")
+
+	_, err := client.Capabilities(ctx)
+	assert.EqualError(t, err, `failed to detect capabilities: invalid version "unknown"`)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&versionRequests), "Capabilities always tries again")
+
+	require.NoError(t, client.Login(ctx, "user", "pass"))
+	_, err = client.CreateRepairRun(ctx, "cluster-1", "ks", "Alice", &RepairRunCreateOptions{SegmentCountPerNode: 8})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&versionRequests), "Login forgets the failed detection")
+}
@@ -4,11 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"iter"
+	"math"
 	"net/http"
 	"net/url"
+	"runtime"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"golang.org/x/sync/singleflight"
 )
 
 type Client interface {
@@ -19,7 +24,7 @@ type Client interface {
 	GetCluster(ctx context.Context, name string) (*Cluster, error)
 
 	// Fetches all clusters. This function is async and may return before any or all results are
-	// available. The concurrency is currently determined by min(5, NUM_CPUS).
+	// available. At most min(5, NUM_CPUS) clusters are fetched at once, which can be changed with WithConcurrency.
 	GetClusters(ctx context.Context) <-chan GetClusterResult
 
 	// Fetches all clusters in a synchronous or blocking manner. Note that this function fails
@@ -30,9 +35,22 @@ type Client interface {
 
 	DeleteCluster(ctx context.Context, cluster string) error
 
+	// AllClusters returns the clusters in the order of GetClusterNames, fetching them one at a time as the sequence is
+	// iterated. Errors listing the clusters end the sequence; errors fetching a cluster are yielded with a nil cluster
+	// and the sequence goes on with the next one.
+	AllClusters(ctx context.Context) iter.Seq2[*Cluster, error]
+
+	// ClusterSchema returns the keyspaces of a cluster and their tables, as seen by Reaper.
+	ClusterSchema(ctx context.Context, cluster string) (ClusterSchema, error)
+
 	// RepairRuns returns a list of repair runs, optionally filtering according to the provided search options.
 	RepairRuns(ctx context.Context, searchOptions *RepairRunSearchOptions) (map[uuid.UUID]*RepairRun, error)
 
+	// AllRepairRuns is like RepairRuns, but returns the repair runs in the order of Reaper, decoding them one at a time
+	// as the sequence is iterated instead of loading them all in memory. The sequence ends after yielding an error.
+	// Breaking out of the loop early releases the response.
+	AllRepairRuns(ctx context.Context, searchOptions *RepairRunSearchOptions) iter.Seq2[*RepairRun, error]
+
 	// RepairRun returns a repair run object identified by its id.
 	RepairRun(ctx context.Context, repairRunId uuid.UUID) (*RepairRun, error)
 
@@ -66,6 +84,11 @@ type Client interface {
 	// RepairRunSegments returns the list of segments of a repair run identified by its id.
 	RepairRunSegments(ctx context.Context, repairRunId uuid.UUID) (map[uuid.UUID]*RepairSegment, error)
 
+	// AllRepairRunSegments is like RepairRunSegments, but returns the segments in the order of Reaper, decoding them
+	// one at a time as the sequence is iterated. The sequence ends after yielding an error. Breaking out of the loop
+	// early releases the response.
+	AllRepairRunSegments(ctx context.Context, repairRunId uuid.UUID) iter.Seq2[*RepairSegment, error]
+
 	// AbortRepairRunSegment aborts a running segment and puts it back in NOT_STARTED state. The segment will be
 	// processed again later during the lifetime of the repair run.
 	AbortRepairRunSegment(ctx context.Context, repairRunId uuid.UUID, segmentId uuid.UUID) error
@@ -82,21 +105,97 @@ type Client interface {
 
 	RepairSchedulesForCluster(ctx context.Context, clusterName string) ([]RepairSchedule, error)
 
+	// RepairSchedule returns a repair schedule object identified by its id.
+	RepairSchedule(ctx context.Context, repairScheduleId uuid.UUID) (*RepairSchedule, error)
+
+	// CreateRepairSchedule creates a new repair schedule for the given cluster and keyspace, triggering a repair run
+	// every daysBetween days. Returns the id of the newly-created repair schedule if successful. The owner name can be
+	// any string identifying the owner.
+	CreateRepairSchedule(
+		ctx context.Context,
+		cluster string,
+		keyspace string,
+		owner string,
+		daysBetween int,
+		options *RepairScheduleCreateOptions,
+	) (uuid.UUID, error)
+
+	// StartRepairSchedule triggers a repair run for the repair schedule identified by its id immediately, without
+	// waiting for its next activation.
+	StartRepairSchedule(ctx context.Context, repairScheduleId uuid.UUID) error
+
+	// PauseRepairSchedule pauses a repair schedule identified by its id. No repair runs will be triggered until the
+	// schedule is resumed.
+	PauseRepairSchedule(ctx context.Context, repairScheduleId uuid.UUID) error
+
+	// ResumeRepairSchedule resumes a PAUSED repair schedule identified by its id. Contrary to repair runs, resuming a
+	// repair schedule is not the same as starting it.
+	ResumeRepairSchedule(ctx context.Context, repairScheduleId uuid.UUID) error
+
+	// DeleteRepairSchedule deletes a repair schedule identified by its id. If the given owner does not match the
+	// stored owner, the delete request will fail.
+	DeleteRepairSchedule(ctx context.Context, repairScheduleId uuid.UUID, owner string) error
+
+	// PauseRepairRuns pauses all the RUNNING repair runs matching the provided search options. Runs in other states
+	// are skipped. Returns the outcome of the operation for every matching run, keyed by repair run id.
+	PauseRepairRuns(ctx context.Context, searchOptions *RepairRunSearchOptions) (map[uuid.UUID]*BulkResult, error)
+
+	// ResumeRepairRuns resumes all the PAUSED repair runs matching the provided search options. Runs in other states
+	// are skipped. Returns the outcome of the operation for every matching run, keyed by repair run id.
+	ResumeRepairRuns(ctx context.Context, searchOptions *RepairRunSearchOptions) (map[uuid.UUID]*BulkResult, error)
+
+	// AbortRepairRuns aborts all the NOT_STARTED, RUNNING or PAUSED repair runs matching the provided search options.
+	// Runs in other states are skipped. Returns the outcome of the operation for every matching run, keyed by repair
+	// run id.
+	AbortRepairRuns(ctx context.Context, searchOptions *RepairRunSearchOptions) (map[uuid.UUID]*BulkResult, error)
+
+	// PauseRepairSchedules pauses all the ACTIVE repair schedules of the given cluster, or of all clusters if cluster
+	// is empty. Combined with PauseRepairRuns, this puts a cluster in maintenance mode. Returns the outcome of the
+	// operation for every schedule, keyed by repair schedule id.
+	PauseRepairSchedules(ctx context.Context, cluster string) (map[uuid.UUID]*BulkResult, error)
+
+	// ResumeRepairSchedules resumes all the PAUSED repair schedules of the given cluster, or of all clusters if
+	// cluster is empty. Returns the outcome of the operation for every schedule, keyed by repair schedule id.
+	ResumeRepairSchedules(ctx context.Context, cluster string) (map[uuid.UUID]*BulkResult, error)
+
 	Login(ctx context.Context, username string, password string) error
+
+	// Capabilities detects the version of Reaper and the features it supports, by probing the endpoints of each
+	// feature. The result is cached for the lifetime of the client: once detected, methods use the wire format of the
+	// detected version, e.g. the right query parameters or login flow, instead of trying all of them. Methods whose
+	// wire format depends on the version detect it on their first call, and don't try again for a minute after a
+	// failed detection, or until the next Login. When Reaper requires authentication, call it after Login.
+	Capabilities(ctx context.Context) (*Capabilities, error)
 }
 
 type client struct {
-	baseURL    *url.URL
-	userAgent  string
-	httpClient *http.Client
-	jSessionId *string
-	jwt        *string
+	baseURL     *url.URL
+	userAgent   string
+	httpClient  *http.Client
+	jSessionId  *string
+	jwt         *string
+	concurrency int
+
+	skipValidation bool
+	rateLimiter    *rateLimiter
+	breaker        *circuitBreaker
+
+	capabilitiesMu      sync.Mutex
+	capabilities        *Capabilities
+	capabilitiesErr     error
+	capabilitiesRetryAt time.Time
+	capabilitiesProbe   singleflight.Group
+	loggedInWith        AuthMode
 }
 
 func NewClient(reaperBaseURL *url.URL, options ...ClientCreateOption) Client {
-	client := &client{baseURL: reaperBaseURL, httpClient: &http.Client{
-		Timeout: 10 * time.Second,
-	}}
+	client := &client{
+		baseURL: reaperBaseURL,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		concurrency: int(math.Min(5, float64(runtime.NumCPU()))),
+	}
 	for _, option := range options {
 		option(client)
 	}
@@ -109,16 +208,28 @@ func (c *client) Login(ctx context.Context, username, password string) error {
 	formData["password"] = password
 	formData["rememberMe"] = "false"
 	if resp, err := c.doPost(ctx, "/login", nil, formData); err == nil {
+		// the body is read first, to release the response before requesting /jwt
+		respBody, readErr := c.readBodyAsString(resp)
+		// once the login flow is known, don't look for the other one
+		mode := AuthModeUnknown
+		if capabilities := c.cachedCapabilities(); capabilities != nil {
+			mode = capabilities.AuthMode
+		}
 		cookies := resp.Cookies()
-		for _, cookie := range cookies {
-			if cookie.Name == "JSESSIONID" {
-				c.jSessionId = &cookie.Value
-				return c.getJwt(ctx)
+		if mode != AuthModeJwt {
+			for _, cookie := range cookies {
+				if cookie.Name == "JSESSIONID" {
+					c.jSessionId = &cookie.Value
+					if err := c.getJwt(ctx); err != nil {
+						return err
+					}
+					c.setAuthMode(AuthModeSession)
+					return nil
+				}
 			}
 		}
 		// No JSESSIONID cookie found, check if response body contains JWT token in JSON format
-		respBody, readErr := c.readBodyAsString(resp)
-		if readErr == nil {
+		if readErr == nil && mode != AuthModeSession {
 			var loginResp struct {
 				Token    string   `json:"token"`
 				Username string   `json:"username"`
@@ -126,6 +237,7 @@ func (c *client) Login(ctx context.Context, username, password string) error {
 			}
 			if err := json.Unmarshal([]byte(respBody), &loginResp); err == nil && loginResp.Token != "" {
 				c.jwt = &loginResp.Token
+				c.setAuthMode(AuthModeJwt)
 				return nil
 			}
 		}
@@ -162,3 +274,37 @@ func WithHttpClient(httpClient *http.Client) ClientCreateOption {
 		client.httpClient = httpClient
 	}
 }
+
+// WithJwt authenticates all requests with a JWT obtained out of band, e.g. from a secret store, instead of logging in
+// with Login.
+func WithJwt(jwt string) ClientCreateOption {
+	return func(client *client) {
+		client.jwt = &jwt
+	}
+}
+
+// WithConcurrency sets the maximum number of concurrent requests issued by methods operating on several resources at
+// once, such as GetClusters or PauseRepairRuns. Values < 1 are ignored.
+func WithConcurrency(concurrency int) ClientCreateOption {
+	return func(client *client) {
+		if concurrency > 0 {
+			client.concurrency = concurrency
+		}
+	}
+}
+
+// WithoutValidation disables the validation of options before they are sent to Reaper, e.g. by CreateRepairRun. Use it
+// with Reaper versions that lifted some of the constraints checked by RepairRunCreateOptions.Validate.
+func WithoutValidation() ClientCreateOption {
+	return func(client *client) {
+		client.skipValidation = true
+	}
+}
+
+// WithCapabilities sets the capabilities of Reaper instead of detecting them with Client.Capabilities, e.g. when the
+// version of Reaper is known in advance.
+func WithCapabilities(capabilities Capabilities) ClientCreateOption {
+	return func(client *client) {
+		client.capabilities = &capabilities
+	}
+}
@@ -8,9 +8,12 @@ import (
 	"net/url"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/k8ssandra/reaper-client-go/reapertest"
 	"github.com/k8ssandra/reaper-client-go/testenv"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -29,20 +32,55 @@ func run(client Client, test clientTest) func(*testing.T) {
 	}
 }
 
+// TestClient runs the client test suite against the environment selected by the TESTENV_BACKEND environment variable:
+// docker-compose if installed, or an in-process fake Reaper otherwise.
 func TestClient(t *testing.T) {
 	t.Log("starting test")
 
-	u, _ := url.Parse(reaperURL)
-	client := NewClient(u)
-
+	env, err := testenv.FromEnv()
+	require.NoError(t, err)
 	ctx := context.Background()
+	if _, fake := env.(*testenv.Fake); fake {
+		defer func(interval time.Duration) { pollInterval = interval }(pollInterval)
+		pollInterval = 100 * time.Millisecond
+		defer func() { _ = env.Stop(ctx) }()
+	}
 
-	prepareEnvironment(t, ctx)
+	prepareEnvironment(t, ctx, env)
+	client := newTestClient(t, env)
 	t.Run("Login", run(client, testLogin))
 	t.Run("Ping", run(client, testIsReaperUp))
 
 	registerClusters(t, ctx, client)
+	runClusterTests(t, client)
+
+	createFixtures(t, ctx, env)
+	runRepairRunTests(t, client)
+}
+
+// newTestClient returns a client for the Reaper of env. If the REAPER_CLIENT_RECORD environment variable is set, the
+// exchanges with Reaper are recorded into the golden file it points to. Golden files are only recorded against a real
+// Reaper: replaying the fake would only check the fake against itself.
+func newTestClient(t *testing.T, env testenv.Environment) Client {
+	u := env.ReaperURL()
+	path := os.Getenv("REAPER_CLIENT_RECORD")
+	if path == "" {
+		return NewClient(u)
+	}
+	if _, fake := env.(*testenv.Fake); fake {
+		t.Fatalf("REAPER_CLIENT_RECORD requires a real Reaper, set %s=docker-compose", testenv.BackendEnvVar)
+	}
+	recorder, err := reapertest.NewRecorder(path, reapertest.ModeRecord, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if err := recorder.Save(); err != nil {
+			t.Error(err)
+		}
+	})
+	return NewClient(u, WithHttpClient(&http.Client{Transport: recorder, Timeout: 10 * time.Second}))
+}
 
+func runClusterTests(t *testing.T, client Client) {
 	t.Log("running Cluster resource tests...")
 
 	t.Run("GetClusterNames", run(client, testGetClusterNames))
@@ -51,9 +89,9 @@ func TestClient(t *testing.T) {
 	t.Run("GetClusters", run(client, testGetClusters))
 	t.Run("GetClustersSync", run(client, testGetClustersSync))
 	t.Run("AddDeleteCluster", run(client, testAddDeleteCluster))
+}
 
-	createFixtures(t, ctx)
-
+func runRepairRunTests(t *testing.T, client Client) {
 	t.Log("running RepairRun resource tests...")
 
 	t.Run("GetRepairRun", run(client, testGetRepairRun))
@@ -71,31 +109,30 @@ func TestClient(t *testing.T) {
 	t.Run("GetRepairRunSegments", run(client, testGetRepairRunSegments))
 	t.Run("AbortRepairRunSegments", run(client, testAbortRepairRunSegments))
 	t.Run("PurgeRepairRun", run(client, testPurgeRepairRun))
-
 }
 
-func prepareEnvironment(t *testing.T, parent context.Context) {
-	if err := testenv.ResetServices(t); err != nil {
+func prepareEnvironment(t *testing.T, parent context.Context, env testenv.Environment) {
+	if err := env.Reset(parent); err != nil {
 		t.Fatalf("failed to reset docker services: %s", err)
 	}
 	clusterReadinessGroup, ctx := errgroup.WithContext(parent)
 	t.Log("checking cassandra cluster-1 status...")
 	clusterReadinessGroup.Go(func() error {
-		if err := testenv.WaitForClusterReady(ctx, "cluster-1-node-0", 2); err != nil {
+		if err := env.WaitForClusterReady(ctx, "cluster-1-node-0", 2); err != nil {
 			return fmt.Errorf("cluster-1 readiness check failed: %w", err)
 		}
 		return nil
 	})
 	t.Log("checking cassandra cluster-2 status...")
 	clusterReadinessGroup.Go(func() error {
-		if err := testenv.WaitForClusterReady(ctx, "cluster-2-node-0", 2); err != nil {
+		if err := env.WaitForClusterReady(ctx, "cluster-2-node-0", 2); err != nil {
 			return fmt.Errorf("cluster-2 readiness check failed: %w", err)
 		}
 		return nil
 	})
 	t.Log("checking cassandra cluster-3 status...")
 	clusterReadinessGroup.Go(func() error {
-		if err := testenv.WaitForClusterReady(ctx, "cluster-3-node-0", 1); err != nil {
+		if err := env.WaitForClusterReady(ctx, "cluster-3-node-0", 1); err != nil {
 			return fmt.Errorf("cluster-3 readiness check failed: %w", err)
 		}
 		return nil
@@ -127,7 +164,7 @@ func registerClusters(t *testing.T, parent context.Context, client Client) {
 	}
 }
 
-func createFixtures(t *testing.T, parent context.Context) {
+func createFixtures(t *testing.T, parent context.Context, env testenv.Environment) {
 	scriptsGroup, ctx := errgroup.WithContext(parent)
 	scripts := make(chan *os.File, 2)
 	t.Log("generating CQL scripts...")
@@ -155,34 +192,34 @@ func createFixtures(t *testing.T, parent context.Context) {
 	cqlFixturesGroup, ctx := errgroup.WithContext(parent)
 	t.Log("populating test keyspace in cluster-1...")
 	cqlFixturesGroup.Go(func() error {
-		if err := testenv.WaitForCqlReady(ctx, "cluster-1-node-0"); err != nil {
+		if err := env.WaitForCqlReady(ctx, "cluster-1-node-0"); err != nil {
 			return fmt.Errorf("CQL cluster-1 readiness check failed: %w", err)
-		} else if err = testenv.CreateKeyspace(ctx, "cluster-1-node-0", keyspace, 2); err != nil {
+		} else if err = env.CreateKeyspace(ctx, "cluster-1-node-0", keyspace, 2); err != nil {
 			return fmt.Errorf("failed to create keyspace on cluster-1: %w", err)
-		} else if err = testenv.CreateTable(ctx, "cluster-1-node-0", keyspace, "table1"); err != nil {
+		} else if err = env.CreateTable(ctx, "cluster-1-node-0", keyspace, "table1"); err != nil {
 			return fmt.Errorf("failed to create keyspace on cluster-1: %w", err)
-		} else if err = testenv.CreateTable(ctx, "cluster-1-node-0", keyspace, "table2"); err != nil {
+		} else if err = env.CreateTable(ctx, "cluster-1-node-0", keyspace, "table2"); err != nil {
 			return fmt.Errorf("failed to create keyspace on cluster-1: %w", err)
-		} else if err := testenv.ExecuteCqlScript(ctx, "cluster-1-node-0", script1); err != nil {
+		} else if err := env.ExecuteCqlScript(ctx, "cluster-1-node-0", script1); err != nil {
 			return fmt.Errorf("failed to execute CQL script 1 on cluster-1: %w", err)
-		} else if err := testenv.ExecuteCqlScript(ctx, "cluster-1-node-0", script2); err != nil {
+		} else if err := env.ExecuteCqlScript(ctx, "cluster-1-node-0", script2); err != nil {
 			return fmt.Errorf("failed to execute CQL script 2 on cluster-1: %w", err)
 		}
 		return nil
 	})
 	t.Log("populating test keyspace in cluster-2...")
 	cqlFixturesGroup.Go(func() error {
-		if err := testenv.WaitForCqlReady(ctx, "cluster-2-node-0"); err != nil {
+		if err := env.WaitForCqlReady(ctx, "cluster-2-node-0"); err != nil {
 			return fmt.Errorf("CQL cluster-2 readiness check failed: %s", err)
-		} else if err = testenv.CreateKeyspace(ctx, "cluster-2-node-0", keyspace, 2); err != nil {
+		} else if err = env.CreateKeyspace(ctx, "cluster-2-node-0", keyspace, 2); err != nil {
 			return fmt.Errorf("failed to create keyspace on cluster-2: %s", err)
-		} else if err = testenv.CreateTable(ctx, "cluster-2-node-0", keyspace, "table1"); err != nil {
+		} else if err = env.CreateTable(ctx, "cluster-2-node-0", keyspace, "table1"); err != nil {
 			return fmt.Errorf("failed to create keyspace on cluster-2: %s", err)
-		} else if err = testenv.CreateTable(ctx, "cluster-2-node-0", keyspace, "table2"); err != nil {
+		} else if err = env.CreateTable(ctx, "cluster-2-node-0", keyspace, "table2"); err != nil {
 			return fmt.Errorf("failed to create keyspace on cluster-2: %s", err)
-		} else if err := testenv.ExecuteCqlScript(ctx, "cluster-2-node-0", script1); err != nil {
+		} else if err := env.ExecuteCqlScript(ctx, "cluster-2-node-0", script1); err != nil {
 			return fmt.Errorf("failed to execute CQL script 1 on cluster-2: %s", err)
-		} else if err := testenv.ExecuteCqlScript(ctx, "cluster-2-node-0", script2); err != nil {
+		} else if err := env.ExecuteCqlScript(ctx, "cluster-2-node-0", script2); err != nil {
 			return fmt.Errorf("failed to execute CQL script 2 on cluster-2: %s", err)
 		}
 		return nil
@@ -205,6 +242,7 @@ func TestLoginScenarios(t *testing.T) {
 	t.Run("LoginWithInvalidJsonResponse", testLoginWithInvalidJsonResponse)
 	t.Run("LoginWithJSessionIdButJwtFails", testLoginWithJSessionIdButJwtFails)
 	t.Run("LoginPostFails", testLoginPostFails)
+	t.Run("WithJwt", testWithJwt)
 }
 
 func testLoginWithJSessionIdFlow(t *testing.T) {
@@ -404,3 +442,24 @@ func testLoginPostFails(t *testing.T) {
 	assert.Nil(t, clientImpl.jSessionId)
 	assert.Nil(t, clientImpl.jwt)
 }
+
+func testWithJwt(t *testing.T) {
+	// Mock server that only accepts requests carrying the JWT
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer out-of-band-jwt" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`["cluster-1"]`))
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	reaperClient := NewClient(u, WithJwt("out-of-band-jwt"))
+
+	// No need to log in
+	names, err := reaperClient.GetClusterNames(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cluster-1"}, names)
+}
@@ -3,10 +3,9 @@ package reaper
 import (
 	"context"
 	"fmt"
-	"math"
 	"net/http"
 	"net/url"
-	"runtime"
+	"sort"
 	"sync"
 	"time"
 )
@@ -58,20 +57,57 @@ type GetClusterResult struct {
 }
 
 type RepairSchedule struct {
-	Id                string    `json:"id"`
-	Owner             string    `json:"owner,omitempty"`
-	State             string    `json:"state,omitempty"`
-	Intensity         float64   `json:"intensity,omitempty"`
-	ClusterName       string    `json:"cluster_name,omitempty"`
-	KeyspaceName      string    `json:"keyspace_name,omitempty"`
-	RepairParallelism string    `json:"repair_parallelism,omitempty"`
-	IncrementalRepair bool      `json:"incremental_repair,omitempty"`
-	RepairThreadCount int       `json:"repair_thread_count,omitempty"`
-	RepairUnitId      string    `json:"repair_unit_id,omitempty"`
-	DaysBetween       int       `json:"scheduled_days_between,omitempty"`
-	Created           time.Time `json:"creation_time,omitempty"`
-	Paused            time.Time `json:"pause_time,omitempty"`
-	NextActivation    time.Time `json:"next_activation,omitempty"`
+	Id                  string    `json:"id"`
+	Owner               string    `json:"owner,omitempty"`
+	State               string    `json:"state,omitempty"`
+	Intensity           float64   `json:"intensity,omitempty"`
+	ClusterName         string    `json:"cluster_name,omitempty"`
+	KeyspaceName        string    `json:"keyspace_name,omitempty"`
+	Tables              []string  `json:"column_families,omitempty"`
+	IgnoredTables       []string  `json:"blacklisted_tables,omitempty"`
+	Nodes               []string  `json:"nodes,omitempty"`
+	Datacenters         []string  `json:"datacenters,omitempty"`
+	RepairParallelism   string    `json:"repair_parallelism,omitempty"`
+	IncrementalRepair   bool      `json:"incremental_repair,omitempty"`
+	RepairThreadCount   int       `json:"repair_thread_count,omitempty"`
+	SegmentCountPerNode int       `json:"segment_count_per_node,omitempty"`
+	RepairUnitId        string    `json:"repair_unit_id,omitempty"`
+	DaysBetween         int       `json:"scheduled_days_between,omitempty"`
+	Created             time.Time `json:"creation_time,omitempty"`
+	Paused              time.Time `json:"pause_time,omitempty"`
+	NextActivation      time.Time `json:"next_activation,omitempty"`
+}
+
+// ClusterSchema maps the keyspaces of a cluster to their tables.
+type ClusterSchema map[string][]string
+
+// Keyspaces returns the keyspaces of the schema, sorted.
+func (s ClusterSchema) Keyspaces() []string {
+	keyspaces := make([]string, 0, len(s))
+	for keyspace := range s {
+		keyspaces = append(keyspaces, keyspace)
+	}
+	sort.Strings(keyspaces)
+	return keyspaces
+}
+
+// Targets returns the tables of keyspace repaired by a repair run or schedule targeting tables, or all the tables of
+// the keyspace if empty, except ignoredTables.
+func (s ClusterSchema) Targets(keyspace string, tables []string, ignoredTables []string) []string {
+	if len(tables) == 0 {
+		tables = s[keyspace]
+	}
+	targets := make([]string, 0, len(tables))
+	for _, table := range tables {
+		ignored := false
+		for _, ignoredTable := range ignoredTables {
+			ignored = ignored || table == ignoredTable
+		}
+		if !ignored {
+			targets = append(targets, table)
+		}
+	}
+	return targets
 }
 
 // All the following types are used internally by the client and not part of the public API
@@ -132,12 +168,23 @@ func (c *client) GetCluster(ctx context.Context, name string) (*Cluster, error)
 	return nil, fmt.Errorf("failed to get cluster %s: %w", name, err)
 }
 
+func (c *client) ClusterSchema(ctx context.Context, cluster string) (ClusterSchema, error) {
+	path := "/cluster/" + url.PathEscape(cluster) + "/tables"
+	res, err := c.doGet(ctx, path, nil, http.StatusOK)
+	if err == nil {
+		schema := ClusterSchema{}
+		err = c.readBodyAsJson(res, &schema)
+		if err == nil {
+			return schema, nil
+		}
+	}
+	return nil, fmt.Errorf("failed to get schema of cluster %s: %w", cluster, err)
+}
+
 // GetClusters fetches all clusters. This function is async and may return before any or all results are
-// available. The concurrency is currently determined by min(5, NUM_CPUS).
+// available. At most min(5, NUM_CPUS) clusters are fetched at once, which can be changed with WithConcurrency.
 func (c *client) GetClusters(ctx context.Context) <-chan GetClusterResult {
-	// TODO Make the concurrency configurable
-	concurrency := int(math.Min(5, float64(runtime.NumCPU())))
-	results := make(chan GetClusterResult, concurrency)
+	results := make(chan GetClusterResult, c.concurrency)
 
 	clusterNames, err := c.GetClusterNames(ctx)
 	if err != nil {
@@ -145,6 +192,7 @@ func (c *client) GetClusters(ctx context.Context) <-chan GetClusterResult {
 		return results
 	}
 
+	slots := make(chan struct{}, c.concurrency)
 	var wg sync.WaitGroup
 
 	go func() {
@@ -153,7 +201,14 @@ func (c *client) GetClusters(ctx context.Context) <-chan GetClusterResult {
 			wg.Add(1)
 			go func(name string) {
 				defer wg.Done()
+				select {
+				case slots <- struct{}{}:
+				case <-ctx.Done():
+					results <- GetClusterResult{Error: fmt.Errorf("failed to get cluster %s: %w", name, ctx.Err())}
+					return
+				}
 				cluster, err := c.GetCluster(ctx, name)
+				<-slots
 				result := GetClusterResult{Cluster: cluster, Error: err}
 				results <- result
 			}(clusterName)
@@ -182,7 +237,7 @@ func (c *client) GetClustersSync(ctx context.Context) ([]*Cluster, error) {
 func (c *client) AddCluster(ctx context.Context, cluster string, seed string) error {
 	queryParams := &url.Values{"seedHost": {seed}}
 	path := "/cluster/" + url.PathEscape(cluster)
-	_, err := c.doPut(ctx, path, queryParams, nil, http.StatusCreated, http.StatusNoContent, http.StatusOK)
+	err := c.discard(c.doPut(ctx, path, queryParams, nil, http.StatusCreated, http.StatusNoContent, http.StatusOK))
 	if err == nil {
 		return nil
 	}
@@ -191,7 +246,7 @@ func (c *client) AddCluster(ctx context.Context, cluster string, seed string) er
 
 func (c *client) DeleteCluster(ctx context.Context, cluster string) error {
 	path := "/cluster/" + url.PathEscape(cluster)
-	_, err := c.doDelete(ctx, path, nil, http.StatusAccepted)
+	err := c.discard(c.doDelete(ctx, path, nil, http.StatusAccepted))
 	if err == nil {
 		return nil
 	}
@@ -2,8 +2,17 @@ package reaper
 
 import (
 	"context"
-	"github.com/stretchr/testify/assert"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func testGetClusterNames(t *testing.T, client Client) {
@@ -67,9 +76,59 @@ func testGetClusterNotFound(t *testing.T, client Client) {
 	cluster, err := client.GetCluster(context.TODO(), name)
 	assert.NotNil(t, err)
 	assert.Containsf(t, err.Error(), "cluster with name \"cluster-notfound\" not found", name)
+	assert.True(t, IsNotFound(err))
 	assert.Nil(t, cluster, "expected non-existent cluster to be nil")
 }
 
+func testClusterSchema(t *testing.T, client Client) {
+	schema, err := client.ClusterSchema(context.TODO(), "cluster-1")
+	require.NoError(t, err)
+	assert.Contains(t, schema.Keyspaces(), keyspace)
+	assert.ElementsMatch(t, []string{"table1", "table2"}, schema[keyspace])
+
+	_, err = client.ClusterSchema(context.TODO(), "cluster-notfound")
+	assert.True(t, IsNotFound(err))
+}
+
+func TestClusterSchemaTargets(t *testing.T) {
+	schema := ClusterSchema{"ks1": {"table1", "table2", "table3"}}
+	assert.Equal(t, []string{"table1", "table2", "table3"}, schema.Targets("ks1", nil, nil))
+	assert.Equal(t, []string{"table1", "table3"}, schema.Targets("ks1", nil, []string{"table2"}))
+	assert.Equal(t, []string{"table2"}, schema.Targets("ks1", []string{"table2"}, nil))
+	assert.Empty(t, schema.Targets("unknown", nil, nil))
+}
+
+func TestGetClustersConcurrency(t *testing.T) {
+	names := []string{"c1", "c2", "c3", "c4", "c5", "c6"}
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/cluster" {
+			_ = json.NewEncoder(w).Encode(names)
+			return
+		}
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		_ = json.NewEncoder(w).Encode(map[string]string{"name": strings.TrimPrefix(r.URL.Path, "/cluster/")})
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+
+	clusters, err := NewClient(u, WithConcurrency(2)).GetClustersSync(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, clusters, len(names))
+	assert.Equal(t, 2, maxInFlight)
+}
+
 func testGetClusters(t *testing.T, client Client) {
 	results := make([]GetClusterResult, 0)
 
@@ -0,0 +1,115 @@
+package reaper
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/k8ssandra/reaper-client-go/openapi"
+	"github.com/k8ssandra/reaper-client-go/testenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestContractTypes checks that the types Reaper responses are decoded into match the OpenAPI description of Reaper.
+// RepairSegment is not checked since it has a custom decoder; its payloads are checked by TestContract instead.
+func TestContractTypes(t *testing.T) {
+	spec := openapi.Reaper()
+	for schema, v := range map[string]interface{}{
+		"Cluster":        clusterStatus{},
+		"RepairRun":      RepairRun{},
+		"Segment":        Segment{},
+		"RepairSchedule": RepairSchedule{},
+	} {
+		assert.NoError(t, spec.CheckType(schema, reflect.TypeOf(v)))
+	}
+}
+
+// TestContract runs the client test suite against the environment selected by TESTENV_BACKEND, like TestClient,
+// checking every request the client sends and every response it receives against the OpenAPI description of Reaper.
+// Against docker-compose, it checks the description against the JSON of a real Reaper; against the fake, it only
+// checks that the fake and the client follow the description.
+func TestContract(t *testing.T) {
+	env, err := testenv.FromEnv()
+	require.NoError(t, err)
+	ctx := context.Background()
+	if _, fake := env.(*testenv.Fake); fake {
+		defer func(interval time.Duration) { pollInterval = interval }(pollInterval)
+		pollInterval = 100 * time.Millisecond
+		defer func() { _ = env.Stop(ctx) }()
+	}
+
+	prepareEnvironment(t, ctx, env)
+	transport := openapi.NewValidatingTransport(openapi.Reaper(), nil)
+	client := NewClient(env.ReaperURL(), WithHttpClient(&http.Client{Transport: checkLeaks(t, transport)}))
+	t.Run("Login", run(client, testLogin))
+	t.Run("Ping", run(client, testIsReaperUp))
+	t.Run("Capabilities", run(client, testCapabilities))
+
+	registerClusters(t, ctx, client)
+	runClusterTests(t, client)
+
+	createFixtures(t, ctx, env)
+	t.Run("ClusterSchema", run(client, testClusterSchema))
+	runRepairRunTests(t, client)
+	t.Run("RepairSchedules", run(client, testRepairScheduleContract))
+	t.Run("BulkOperations", run(client, testBulkOperationsContract))
+
+	assertNoViolations(t, transport)
+}
+
+func testRepairScheduleContract(t *testing.T, client Client) {
+	triggerTime := time.Now().Add(time.Hour).Truncate(time.Second)
+	scheduleId, err := client.CreateRepairSchedule(context.Background(), "cluster-1", keyspace, "Alice", 7, &RepairScheduleCreateOptions{
+		Tables:              []string{"table1"},
+		SegmentCountPerNode: 4,
+		RepairParallelism:   RepairParallelismParallel,
+		Intensity:           0.5,
+		TriggerTime:         &triggerTime,
+		RepairThreadCount:   2,
+	})
+	require.NoError(t, err)
+	schedules, err := client.RepairSchedules(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, schedules, 1)
+	_, err = client.RepairSchedulesForCluster(context.Background(), "cluster-1")
+	require.NoError(t, err)
+	schedule, err := client.RepairSchedule(context.Background(), scheduleId)
+	require.NoError(t, err)
+	assert.Equal(t, string(RepairScheduleStateActive), schedule.State)
+	require.NoError(t, client.StartRepairSchedule(context.Background(), scheduleId))
+	require.NoError(t, client.PauseRepairSchedule(context.Background(), scheduleId))
+	require.NoError(t, client.PauseRepairSchedule(context.Background(), scheduleId))
+	require.NoError(t, client.ResumeRepairSchedule(context.Background(), scheduleId))
+	assert.Error(t, client.DeleteRepairSchedule(context.Background(), scheduleId, "Alice"), "active schedules cannot be deleted")
+	require.NoError(t, client.PauseRepairSchedule(context.Background(), scheduleId))
+	require.NoError(t, client.DeleteRepairSchedule(context.Background(), scheduleId, "Alice"))
+	_, err = client.RepairSchedule(context.Background(), scheduleId)
+	assert.Error(t, err)
+}
+
+func testBulkOperationsContract(t *testing.T, client Client) {
+	runId, err := client.CreateRepairRun(context.Background(), "cluster-2", keyspace, "Bob", nil)
+	require.NoError(t, err)
+	require.NoError(t, client.StartRepairRun(context.Background(), runId))
+	results, err := client.PauseRepairRuns(context.Background(), &RepairRunSearchOptions{Cluster: "cluster-2"})
+	require.NoError(t, err)
+	assert.Contains(t, results, runId)
+	_, err = client.ResumeRepairRuns(context.Background(), &RepairRunSearchOptions{Cluster: "cluster-2"})
+	require.NoError(t, err)
+	_, err = client.AbortRepairRuns(context.Background(), &RepairRunSearchOptions{Cluster: "cluster-2"})
+	require.NoError(t, err)
+	_, err = client.PauseRepairSchedules(context.Background(), "")
+	require.NoError(t, err)
+	_, err = client.ResumeRepairSchedules(context.Background(), "cluster-2")
+	require.NoError(t, err)
+}
+
+func assertNoViolations(t *testing.T, transport *openapi.ValidatingTransport) {
+	t.Helper()
+	for _, violation := range transport.Violations() {
+		t.Error(violation)
+	}
+}
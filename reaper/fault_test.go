@@ -0,0 +1,145 @@
+package reaper
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/k8ssandra/reaper-client-go/reapertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFaultyClient returns a client connected to a fake Reaper through a fault-injecting transport. The fake Reaper
+// has cluster-1 and cluster-2 registered, with keyspace ks. The test fails if response bodies are left open, even
+// when requests fail.
+func newFaultyClient(t *testing.T) (Client, *reapertest.FaultTransport) {
+	server := reapertest.NewServer()
+	t.Cleanup(server.Close)
+	faults := reapertest.NewFaultTransport(nil, 1)
+	client := NewClient(server.URL(), WithHttpClient(&http.Client{Transport: checkLeaks(t, faults)}))
+	for _, name := range []string{"cluster-1", "cluster-2"} {
+		server.AddCassandraCluster(reapertest.NewCassandraCluster(name, 2).WithKeyspace("ks", 2, "table1"))
+		require.NoError(t, client.AddCluster(context.Background(), name, name+"-node-0"))
+	}
+	return client, faults
+}
+
+// checkLeaks wraps transport with a reapertest.LeakTransport, and fails the test if response bodies are left open when
+// it ends. Methods such as GetClusters may still be running in the background: they are given some time to complete.
+func checkLeaks(t *testing.T, transport http.RoundTripper) http.RoundTripper {
+	leaks := reapertest.NewLeakTransport(transport)
+	t.Cleanup(func() {
+		for deadline := time.Now().Add(time.Second); leaks.Check() != nil && time.Now().Before(deadline); {
+			time.Sleep(10 * time.Millisecond)
+		}
+		assert.NoError(t, leaks.Check())
+	})
+	return leaks
+}
+
+func TestFaultLatency(t *testing.T) {
+	client, faults := newFaultyClient(t)
+	faults.Inject(reapertest.FaultRule{Pattern: "GET /cluster", Fault: reapertest.Fault{Latency: time.Second}})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := client.GetClusterNames(ctx)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestFaultServerError(t *testing.T) {
+	client, faults := newFaultyClient(t)
+	faults.Inject(reapertest.FaultRule{
+		Pattern: "GET /cluster/*",
+		Fault:   reapertest.Fault{StatusCode: http.StatusInternalServerError, Body: "boom"},
+	})
+	_, err := client.GetCluster(context.Background(), "cluster-1")
+	require.Error(t, err)
+	assert.EqualError(t, err, "failed to get cluster cluster-1: boom (HTTP status 500)")
+	_, err = client.GetClustersSync(context.Background())
+	assert.Error(t, err)
+}
+
+func TestFaultConnectionReset(t *testing.T) {
+	client, faults := newFaultyClient(t)
+	faults.Inject(reapertest.FaultRule{Pattern: "HEAD /ping", Fault: reapertest.Fault{ResetConnection: true}})
+	up, err := client.IsReaperUp(context.Background())
+	assert.False(t, up)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, syscall.ECONNRESET))
+}
+
+func TestFaultMalformedBody(t *testing.T) {
+	client, faults := newFaultyClient(t)
+	runId, err := client.CreateRepairRun(context.Background(), "cluster-1", "ks", "Alice", nil)
+	require.NoError(t, err)
+	faults.Inject(reapertest.FaultRule{Pattern: "GET /repair_run/*", Fault: reapertest.Fault{TruncateBody: true}})
+	run, err := client.RepairRun(context.Background(), runId)
+	assert.Nil(t, run)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to get repair run")
+	assert.Contains(t, err.Error(), "unexpected EOF")
+	faults.Inject(reapertest.FaultRule{Pattern: "GET /repair_run/*/segments", Fault: reapertest.Fault{TruncateBody: true}})
+	_, err = client.RepairRunSegments(context.Background(), runId)
+	assert.Error(t, err)
+}
+
+func TestFaultDeleteRepairRunQuirk(t *testing.T) {
+	client, faults := newFaultyClient(t)
+	run1, err := client.CreateRepairRun(context.Background(), "cluster-1", "ks", "Alice", nil)
+	require.NoError(t, err)
+	run2, err := client.CreateRepairRun(context.Background(), "cluster-2", "ks", "Alice", nil)
+	require.NoError(t, err)
+	faults.Script("DELETE /repair_run/*",
+		// the run is deleted, but Reaper reports an error: the client checks that the run is gone
+		reapertest.Fault{StatusCode: http.StatusInternalServerError, Forward: true},
+		// the run is not deleted
+		reapertest.Fault{StatusCode: http.StatusInternalServerError},
+	)
+	assert.NoError(t, client.DeleteRepairRun(context.Background(), run1, "Alice"))
+	err = client.DeleteRepairRun(context.Background(), run2, "Alice")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "(HTTP status 500)")
+	// the script is exhausted
+	assert.NoError(t, client.DeleteRepairRun(context.Background(), run2, "Alice"))
+}
+
+func TestFaultScriptedSequence(t *testing.T) {
+	client, faults := newFaultyClient(t)
+	runId, err := client.CreateRepairRun(context.Background(), "cluster-1", "ks", "Alice", nil)
+	require.NoError(t, err)
+	faults.Script("PUT /repair_run/*/state/RUNNING",
+		reapertest.Fault{ResetConnection: true},
+		reapertest.Fault{StatusCode: http.StatusServiceUnavailable},
+		reapertest.Fault{Latency: time.Millisecond},
+	)
+	assert.Error(t, client.StartRepairRun(context.Background(), runId))
+	assert.Error(t, client.StartRepairRun(context.Background(), runId))
+	assert.NoError(t, client.StartRepairRun(context.Background(), runId))
+	assert.Error(t, client.StartRepairRun(context.Background(), uuid.New()))
+}
+
+func TestFaultRate(t *testing.T) {
+	client, faults := newFaultyClient(t)
+	faults.Inject(reapertest.FaultRule{
+		Pattern: "/cluster",
+		Rate:    0.5,
+		Fault:   reapertest.Fault{StatusCode: http.StatusBadGateway},
+	})
+	failures := 0
+	for i := 0; i < 100; i++ {
+		if _, err := client.GetClusterNames(context.Background()); err != nil {
+			assert.Contains(t, err.Error(), "(HTTP status 502)")
+			failures++
+		}
+	}
+	assert.InDelta(t, 50, failures, 20)
+	faults.Reset()
+	_, err := client.GetClusterNames(context.Background())
+	assert.NoError(t, err)
+}
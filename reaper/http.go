@@ -3,9 +3,9 @@ package reaper
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -61,6 +61,9 @@ func (c *client) doHead(
 	return c.doRequest(ctx, http.MethodHead, path, queryParams, nil, expectedStatuses...)
 }
 
+// doRequest sends a request to Reaper. If expectedStatuses are given and the response has another status, the body of
+// the response is consumed and closed to build the returned error, along with the response. Otherwise, the caller must
+// consume the body with readBodyAsString, readBodyAsJson or discard, which all close it.
 func (c *client) doRequest(
 	ctx context.Context,
 	method string,
@@ -96,14 +99,41 @@ func (c *client) doRequest(
 	}
 
 	c.addCommonHeaders(req)
-	c.addAuthHeaders(req)
+	if ctx.Value(unauthenticatedKey{}) == nil {
+		c.addAuthHeaders(req)
+	}
+	if c.breaker != nil {
+		if err := c.breaker.allow(ctx, c.IsReaperUp); err != nil {
+			return nil, err
+		}
+	}
+	if c.rateLimiter != nil {
+		release, err := c.rateLimiter.wait(ctx, method, path)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
 	res, err := c.httpClient.Do(req)
 	if err == nil {
 		err = c.checkResponseStatus(res, expectedStatuses...)
 	}
+	if c.breaker != nil {
+		c.breaker.record(ctx, res, err)
+	}
 	return res, err
 }
 
+// discard drains and closes the body of a response whose content is not needed, so that its connection can be
+// reused. It returns err unchanged, for use as c.discard(c.doPut(...)).
+func (c *client) discard(res *http.Response, err error) error {
+	if res != nil {
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+	}
+	return err
+}
+
 func (c *client) mergeParamSources(paramSources ...interface{}) (*url.Values, error) {
 	mergedValues := url.Values{}
 	for _, paramSource := range paramSources {
@@ -161,6 +191,13 @@ func (c *client) addCommonHeaders(req *http.Request) {
 	}
 }
 
+// unauthenticatedKey marks the context of requests sent without the credentials of the client.
+type unauthenticatedKey struct{}
+
+func unauthenticated(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unauthenticatedKey{}, true)
+}
+
 func (c *client) addAuthHeaders(req *http.Request) {
 	if c.jSessionId != nil {
 		req.Header.Set("Cookie", fmt.Sprintf("JSESSIONID=%s", *c.jSessionId))
@@ -172,7 +209,8 @@ func (c *client) addAuthHeaders(req *http.Request) {
 }
 
 func (c *client) readBodyAsString(res *http.Response) (string, error) {
-	b, err := ioutil.ReadAll(res.Body)
+	b, err := io.ReadAll(res.Body)
+	_ = res.Body.Close()
 	if err != nil {
 		return "", err
 	}
@@ -181,8 +219,7 @@ func (c *client) readBodyAsString(res *http.Response) (string, error) {
 
 func (c *client) readBodyAsJson(res *http.Response, v interface{}) error {
 	err := json.NewDecoder(res.Body).Decode(v)
-	_ = res.Body.Close()
-	return err
+	return c.discard(res, err)
 }
 
 func (c *client) checkResponseStatus(res *http.Response, expectedStatuses ...int) error {
@@ -212,7 +249,27 @@ func (c *client) bodyToError(res *http.Response) error {
 	} else {
 		message = http.StatusText(res.StatusCode)
 	}
-	return fmt.Errorf("%s (HTTP status %d)", message, res.StatusCode)
+	return &HttpError{StatusCode: res.StatusCode, Message: message}
+}
+
+// HttpError is the error returned, wrapped, when Reaper responds with an unexpected HTTP status. Use errors.As to
+// inspect it, or IsNotFound.
+type HttpError struct {
+	StatusCode int
+
+	// The error message sent by Reaper, or the status text if Reaper didn't send any.
+	Message string
+}
+
+func (e *HttpError) Error() string {
+	return fmt.Sprintf("%s (HTTP status %d)", e.Message, e.StatusCode)
+}
+
+// IsNotFound returns true if err was caused by Reaper responding 404 Not Found, e.g. when fetching a cluster, repair
+// run or repair schedule that doesn't exist.
+func IsNotFound(err error) bool {
+	var httpErr *HttpError
+	return errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound
 }
 
 type errorPayload struct {
@@ -0,0 +1,82 @@
+package reaper
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/k8ssandra/reaper-client-go/reapertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestResponseBodiesAreClosed calls every method, successfully or not, and checks that no response body is left open.
+func TestResponseBodiesAreClosed(t *testing.T) {
+	for name, options := range map[string][]reapertest.ServerOption{
+		"Session": {reapertest.WithCredentials("user", "pass")},
+		"Jwt":     {reapertest.WithCredentials("user", "pass"), reapertest.WithJwtInLoginBody()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			server := reapertest.NewServer(append(options, reapertest.WithDeleteRepairRunQuirk())...)
+			defer server.Close()
+			server.AddCassandraCluster(reapertest.NewCassandraCluster("cluster-1", 3).WithKeyspace("ks", 3, "table1"))
+			server.AddCassandraCluster(reapertest.NewCassandraCluster("cluster-2", 1))
+			leaks := reapertest.NewLeakTransport(nil)
+			client := NewClient(server.URL(), WithHttpClient(&http.Client{Transport: leaks}))
+			ctx := context.Background()
+
+			up, err := client.IsReaperUp(ctx)
+			require.NoError(t, err)
+			assert.True(t, up)
+			assert.Error(t, client.Login(ctx, "user", "wrong"))
+			require.NoError(t, client.Login(ctx, "user", "pass"))
+			_, err = client.Capabilities(ctx)
+			require.NoError(t, err)
+
+			require.NoError(t, client.AddCluster(ctx, "cluster-1", "cluster-1-node-0"))
+			_, err = client.GetCluster(ctx, "unknown")
+			assert.True(t, IsNotFound(err))
+			_, err = client.GetClustersSync(ctx)
+			require.NoError(t, err)
+			_, err = client.ClusterSchema(ctx, "cluster-1")
+			require.NoError(t, err)
+
+			runId, err := client.CreateRepairRun(ctx, "cluster-1", "ks", "Alice", nil)
+			require.NoError(t, err)
+			require.NoError(t, client.StartRepairRun(ctx, runId))
+			require.NoError(t, client.PauseRepairRun(ctx, runId))
+			require.NoError(t, client.UpdateRepairRun(ctx, runId, 0.5))
+			require.NoError(t, client.ResumeRepairRun(ctx, runId))
+			assert.Error(t, client.ResumeRepairRun(ctx, runId), "the run is already running")
+			segments, err := client.RepairRunSegments(ctx, runId)
+			require.NoError(t, err)
+			for id := range segments {
+				_ = client.AbortRepairRunSegment(ctx, runId, id)
+				break
+			}
+			_, err = client.PauseRepairRuns(ctx, nil)
+			require.NoError(t, err)
+			require.NoError(t, client.AbortRepairRun(ctx, runId))
+			_, err = client.PurgeRepairRuns(ctx)
+			require.NoError(t, err)
+
+			otherRunId, err := client.CreateRepairRun(ctx, "cluster-1", "ks", "Alice", nil)
+			require.NoError(t, err)
+			require.NoError(t, client.DeleteRepairRun(ctx, otherRunId, "Alice"), "the 500 returned by Reaper is ignored")
+
+			scheduleId, err := client.CreateRepairSchedule(ctx, "cluster-1", "ks", "Alice", 7, nil)
+			require.NoError(t, err)
+			require.NoError(t, client.PauseRepairSchedule(ctx, scheduleId))
+			require.NoError(t, client.ResumeRepairSchedule(ctx, scheduleId))
+			require.NoError(t, client.StartRepairSchedule(ctx, scheduleId))
+			assert.Error(t, client.DeleteRepairSchedule(ctx, scheduleId, "Bob"))
+			require.NoError(t, client.PauseRepairSchedule(ctx, scheduleId))
+			require.NoError(t, client.DeleteRepairSchedule(ctx, scheduleId, "Alice"))
+
+			assert.Error(t, client.DeleteCluster(ctx, "cluster-1"), "the schedule started a repair run")
+			require.NoError(t, client.AddCluster(ctx, "cluster-2", "cluster-2-node-0"))
+			require.NoError(t, client.DeleteCluster(ctx, "cluster-2"))
+			assert.NoError(t, leaks.Check())
+		})
+	}
+}
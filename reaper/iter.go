@@ -0,0 +1,103 @@
+package reaper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+func (c *client) AllRepairRuns(ctx context.Context, searchOptions *RepairRunSearchOptions) iter.Seq2[*RepairRun, error] {
+	return func(yield func(*RepairRun, error) bool) {
+		res, err := c.doGet(ctx, "/repair_run", searchOptions, http.StatusOK)
+		if err != nil {
+			yield(nil, fmt.Errorf("failed to get repair runs: %w", err))
+			return
+		}
+		decodeArray(res.Body, func(run *RepairRun, err error) bool {
+			if err != nil {
+				return yield(nil, fmt.Errorf("failed to get repair runs: %w", err))
+			}
+			return yield(run, nil)
+		})
+	}
+}
+
+func (c *client) AllRepairRunSegments(ctx context.Context, repairRunId uuid.UUID) iter.Seq2[*RepairSegment, error] {
+	return func(yield func(*RepairSegment, error) bool) {
+		path := fmt.Sprint("/repair_run/", repairRunId, "/segments")
+		res, err := c.doGet(ctx, path, nil, http.StatusOK)
+		if err != nil {
+			yield(nil, fmt.Errorf("failed to get segments of repair run %v: %w", repairRunId, err))
+			return
+		}
+		decodeArray(res.Body, func(segment *RepairSegment, err error) bool {
+			if err != nil {
+				return yield(nil, fmt.Errorf("failed to get segments of repair run %v: %w", repairRunId, err))
+			}
+			return yield(segment, nil)
+		})
+	}
+}
+
+func (c *client) AllClusters(ctx context.Context) iter.Seq2[*Cluster, error] {
+	return func(yield func(*Cluster, error) bool) {
+		res, err := c.doGet(ctx, "/cluster", nil, http.StatusOK)
+		if err != nil {
+			yield(nil, fmt.Errorf("failed to get cluster names: %w", err))
+			return
+		}
+		// the list of names stays open while the clusters are fetched, one at a time
+		decodeArray(res.Body, func(name string, err error) bool {
+			if err != nil {
+				return yield(nil, fmt.Errorf("failed to get cluster names: %w", err))
+			}
+			return yield(c.GetCluster(ctx, name))
+		})
+	}
+}
+
+// decodeArray decodes the JSON array read from body one element at a time, and passes each of them to yield, in
+// order. It stops at the first decoding error, which is passed to yield with the zero value of T, or as soon as yield
+// returns false. body is closed in all cases; it is drained first only if the array was decoded entirely, so that
+// breaking out of a loop early doesn't read the rest of a large response. Failed requests need no cleanup: doRequest
+// closes their body.
+func decodeArray[T any](body io.ReadCloser, yield func(T, error) bool) {
+	defer func() { _ = body.Close() }()
+	var zero T
+	decoder := json.NewDecoder(body)
+	if err := expectDelim(decoder, '['); err != nil {
+		yield(zero, err)
+		return
+	}
+	for decoder.More() {
+		var v T
+		if err := decoder.Decode(&v); err != nil {
+			yield(zero, err)
+			return
+		}
+		if !yield(v, nil) {
+			return
+		}
+	}
+	if err := expectDelim(decoder, ']'); err != nil {
+		yield(zero, err)
+		return
+	}
+	_, _ = io.Copy(io.Discard, body)
+}
+
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("expected %v, got %v", delim, token)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package reaper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/k8ssandra/reaper-client-go/reapertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// getIds returns the ids of the JSON array returned by Reaper on path, in order.
+func getIds(t *testing.T, server *reapertest.Server, path string) []uuid.UUID {
+	res, err := http.Get(server.URL().String() + path)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	var objects []struct {
+		Id uuid.UUID `json:"id"`
+	}
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&objects))
+	ids := make([]uuid.UUID, len(objects))
+	for i, object := range objects {
+		ids[i] = object.Id
+	}
+	return ids
+}
+
+func TestIterators(t *testing.T) {
+	server := reapertest.NewServer()
+	defer server.Close()
+	server.AddCassandraCluster(reapertest.NewCassandraCluster("cluster-b", 3).WithKeyspace("ks", 3, "table1"))
+	server.AddCassandraCluster(reapertest.NewCassandraCluster("cluster-a", 3).WithKeyspace("ks", 3, "table1"))
+	leaks := reapertest.NewLeakTransport(nil)
+	client := NewClient(server.URL(), WithHttpClient(&http.Client{Transport: leaks}))
+	ctx := context.Background()
+	require.NoError(t, client.AddCluster(ctx, "cluster-b", "cluster-b-node-0"))
+	require.NoError(t, client.AddCluster(ctx, "cluster-a", "cluster-a-node-0"))
+	for i := 0; i < 3; i++ {
+		_, err := client.CreateRepairRun(ctx, "cluster-b", "ks", "Alice", &RepairRunCreateOptions{SegmentCountPerNode: 4})
+		require.NoError(t, err)
+	}
+
+	var runIds []uuid.UUID
+	for run, err := range client.AllRepairRuns(ctx, &RepairRunSearchOptions{Cluster: "cluster-b"}) {
+		require.NoError(t, err)
+		runIds = append(runIds, run.Id)
+	}
+	assert.Equal(t, getIds(t, server, "/repair_run?cluster_name=cluster-b"), runIds, "the order of Reaper is kept")
+
+	var segmentIds []uuid.UUID
+	for segment, err := range client.AllRepairRunSegments(ctx, runIds[0]) {
+		require.NoError(t, err)
+		require.NotNil(t, segment.TokenRange)
+		segmentIds = append(segmentIds, segment.Id)
+	}
+	assert.Len(t, segmentIds, 12)
+	assert.Equal(t, getIds(t, server, fmt.Sprint("/repair_run/", runIds[0], "/segments")), segmentIds)
+
+	names, err := client.GetClusterNames(ctx)
+	require.NoError(t, err)
+	var clusters []string
+	for cluster, err := range client.AllClusters(ctx) {
+		require.NoError(t, err)
+		assert.Len(t, cluster.NodeState.GossipStates, 1)
+		clusters = append(clusters, cluster.Name)
+	}
+	assert.Equal(t, names, clusters)
+
+	// breaking out of the loops early releases the responses
+	for range client.AllRepairRuns(ctx, nil) {
+		break
+	}
+	for range client.AllRepairRunSegments(ctx, runIds[0]) {
+		break
+	}
+	for range client.AllClusters(ctx) {
+		break
+	}
+	assert.NoError(t, leaks.Check())
+
+	for segment, err := range client.AllRepairRunSegments(ctx, uuid.New()) {
+		assert.Nil(t, segment)
+		assert.True(t, IsNotFound(err))
+	}
+	assert.NoError(t, leaks.Check())
+}
+
+func TestIteratorErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/repair_run":
+			if r.URL.Query().Get("cluster_name") == "truncated" {
+				_, _ = w.Write([]byte(`[{"id": "a2b1f0e0-6d7c-11eb-9439-0242ac130002"}, {"id": `))
+			} else {
+				_, _ = w.Write([]byte(`{"message": "not an array"}`))
+			}
+		case "/cluster":
+			_, _ = w.Write([]byte(`["broken", "cluster-1"]`))
+		case "/cluster/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/cluster/cluster-1":
+			_, _ = w.Write([]byte(`{"name": "cluster-1"}`))
+		}
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	client := NewClient(u)
+	ctx := context.Background()
+
+	var errs []error
+	for run, err := range client.AllRepairRuns(ctx, nil) {
+		assert.Nil(t, run)
+		errs = append(errs, err)
+	}
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "failed to get repair runs: expected [, got {")
+
+	var runs []*RepairRun
+	errs = nil
+	for run, err := range client.AllRepairRuns(ctx, &RepairRunSearchOptions{Cluster: "truncated"}) {
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			runs = append(runs, run)
+		}
+	}
+	assert.Len(t, runs, 1, "the runs decoded before the error are yielded")
+	require.Len(t, errs, 1)
+	assert.True(t, strings.HasPrefix(errs[0].Error(), "failed to get repair runs: "))
+
+	// errors fetching a cluster don't end the sequence
+	var clusters []string
+	errs = nil
+	for cluster, err := range client.AllClusters(ctx) {
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			clusters = append(clusters, cluster.Name)
+		}
+	}
+	assert.Equal(t, []string{"cluster-1"}, clusters)
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "failed to get cluster broken")
+}
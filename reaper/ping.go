@@ -7,6 +7,7 @@ import (
 
 func (c *client) IsReaperUp(ctx context.Context) (bool, error) {
 	if resp, err := c.doHead(ctx, "/ping", nil); err == nil {
+		_ = c.discard(resp, nil)
 		return resp.StatusCode == http.StatusNoContent, nil
 	} else {
 		return false, err
@@ -0,0 +1,225 @@
+package reaper
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EndpointClass groups the endpoints of Reaper sharing the same rate limit.
+type EndpointClass string
+
+const (
+	// EndpointClassDefault covers the endpoints not belonging to any other class, such as /ping, /login or the list of
+	// cluster names.
+	EndpointClassDefault EndpointClass = "default"
+
+	// EndpointClassCluster covers /cluster/{name} and the endpoints below it. Reaper reads the gossip state of the
+	// cluster over JMX to serve them, which makes them the most expensive ones.
+	EndpointClassCluster EndpointClass = "cluster"
+
+	// EndpointClassRepairRun covers /repair_run and the endpoints below it.
+	EndpointClassRepairRun EndpointClass = "repair_run"
+
+	// EndpointClassRepairSchedule covers /repair_schedule and the endpoints below it.
+	EndpointClassRepairSchedule EndpointClass = "repair_schedule"
+)
+
+// RateLimit limits the requests sent to Reaper. Zero values mean no limit.
+type RateLimit struct {
+	// RequestsPerSecond is the rate at which requests are allowed to start.
+	RequestsPerSecond float64
+
+	// Burst is the number of requests allowed to start at once, on top of the rate, after a quiet period. Defaults to
+	// 1.
+	Burst int
+
+	// MaxInFlight is the maximum number of requests waiting for their response at any time. A request stops counting
+	// once its response headers are received: reading the body is not limited.
+	MaxInFlight int
+}
+
+// ThrottleEvent describes a request that was delayed by the rate limiter, or that gave up waiting.
+type ThrottleEvent struct {
+	Class  EndpointClass
+	Method string
+	Path   string
+
+	// Delay is how long the request waited before being sent, or before giving up.
+	Delay time.Duration
+
+	// Err is the error of the context if the request gave up waiting, nil if it was sent.
+	Err error
+}
+
+type RateLimitOptions struct {
+	// RateLimit applies to all the endpoint classes without their own limit; they share it.
+	RateLimit
+
+	// Endpoints overrides the limit of some endpoint classes, e.g. with a stricter limit for EndpointClassCluster.
+	// Each overridden class has its own token bucket and in-flight limit, independent of the default ones.
+	Endpoints map[EndpointClass]RateLimit
+
+	// OnThrottle, if set, is called for every delayed request, e.g. to record the delays in metrics. It is called
+	// synchronously, before the request is sent, and must return quickly.
+	OnThrottle func(ThrottleEvent)
+
+	// Logger, if set, logs delayed requests at the debug level, and requests giving up waiting at the warning level.
+	Logger *slog.Logger
+}
+
+// WithRateLimit limits the rate and the concurrency of the requests sent to Reaper, which can be overloaded by bursts
+// of JMX-heavy requests such as GetCluster. Requests over the limit wait for their turn, or until their context is
+// done, in which case they fail with the error of the context.
+func WithRateLimit(options RateLimitOptions) ClientCreateOption {
+	return func(client *client) {
+		client.rateLimiter = newRateLimiter(options)
+	}
+}
+
+type rateLimiter struct {
+	options  RateLimitOptions
+	limiters map[EndpointClass]*limiter
+	fallback *limiter
+}
+
+func newRateLimiter(options RateLimitOptions) *rateLimiter {
+	r := &rateLimiter{
+		options:  options,
+		limiters: map[EndpointClass]*limiter{},
+		fallback: newLimiter(options.RateLimit),
+	}
+	for class, limit := range options.Endpoints {
+		r.limiters[class] = newLimiter(limit)
+	}
+	return r
+}
+
+// classifyEndpoint returns the class of the endpoint at path, relative to the base URL of Reaper.
+func classifyEndpoint(path string) EndpointClass {
+	path = strings.TrimPrefix(path, "/")
+	first, rest, _ := strings.Cut(path, "/")
+	switch {
+	case first == "cluster" && rest != "":
+		return EndpointClassCluster
+	case first == "repair_run":
+		return EndpointClassRepairRun
+	case first == "repair_schedule":
+		return EndpointClassRepairSchedule
+	default:
+		return EndpointClassDefault
+	}
+}
+
+// wait blocks until a request to path is allowed to be sent, and returns a function to call once its response is
+// received. It fails with the error of ctx if ctx is done first.
+func (r *rateLimiter) wait(ctx context.Context, method string, path string) (func(), error) {
+	class := classifyEndpoint(path)
+	l, found := r.limiters[class]
+	if !found {
+		l = r.fallback
+	}
+	start := time.Now()
+	release, err := l.wait(ctx)
+	if delay := time.Since(start); err != nil || delay >= time.Millisecond {
+		r.throttled(ctx, ThrottleEvent{Class: class, Method: method, Path: path, Delay: delay, Err: err})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("rate limit of %s endpoints: %w", class, err)
+	}
+	return release, nil
+}
+
+func (r *rateLimiter) throttled(ctx context.Context, event ThrottleEvent) {
+	if r.options.OnThrottle != nil {
+		r.options.OnThrottle(event)
+	}
+	if logger := r.options.Logger; logger != nil {
+		attrs := []any{"class", event.Class, "method", event.Method, "path", event.Path, "delay", event.Delay}
+		if event.Err != nil {
+			logger.WarnContext(ctx, "gave up waiting for the Reaper rate limit", append(attrs, "error", event.Err)...)
+		} else {
+			logger.DebugContext(ctx, "request to Reaper delayed by the rate limit", attrs...)
+		}
+	}
+}
+
+// limiter combines a token bucket, which limits the rate at which requests start, and a semaphore, which limits how
+// many are in flight.
+type limiter struct {
+	inFlight chan struct{}
+
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(limit RateLimit) *limiter {
+	l := &limiter{}
+	if limit.MaxInFlight > 0 {
+		l.inFlight = make(chan struct{}, limit.MaxInFlight)
+	}
+	if limit.RequestsPerSecond > 0 {
+		l.rate = limit.RequestsPerSecond
+		l.burst = math.Max(1, float64(limit.Burst))
+		l.tokens = l.burst
+	}
+	return l
+}
+
+func (l *limiter) wait(ctx context.Context) (func(), error) {
+	release := func() {}
+	// the slot is acquired first, so that requests released together by the semaphore are still spread by the rate
+	if l.inFlight != nil {
+		select {
+		case l.inFlight <- struct{}{}:
+			release = func() { <-l.inFlight }
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if delay := l.reserve(time.Now()); delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			l.cancel()
+			release()
+			return nil, ctx.Err()
+		}
+	}
+	return release, nil
+}
+
+// reserve takes a token from the bucket, and returns how long to wait until it is actually available. The bucket goes
+// negative when requests wait, so that they are served in turn.
+func (l *limiter) reserve(now time.Time) time.Duration {
+	if l.rate == 0 {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.last.IsZero() {
+		l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// cancel gives back the token taken by a request that gave up waiting.
+func (l *limiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens = math.Min(l.burst, l.tokens+1)
+}
@@ -0,0 +1,145 @@
+package reaper
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyEndpoint(t *testing.T) {
+	for path, expected := range map[string]EndpointClass{
+		"/ping":                       EndpointClassDefault,
+		"/login":                      EndpointClassDefault,
+		"/cluster":                    EndpointClassDefault,
+		"/cluster/cluster-1":          EndpointClassCluster,
+		"/cluster/cluster-1/tables":   EndpointClassCluster,
+		"/repair_run":                 EndpointClassRepairRun,
+		"/repair_run/purge":           EndpointClassRepairRun,
+		"/repair_schedule/cluster/c1": EndpointClassRepairSchedule,
+	} {
+		assert.Equal(t, expected, classifyEndpoint(path), path)
+	}
+}
+
+// newThrottledServer returns a server answering all requests, and counting how many it serves concurrently.
+func newThrottledServer(t *testing.T, delay time.Duration) (*url.URL, *int32) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if current <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, current) {
+				break
+			}
+		}
+		time.Sleep(delay)
+		if r.URL.Path == "/ping" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name": "cluster-1"}`))
+	}))
+	t.Cleanup(server.Close)
+	u, _ := url.Parse(server.URL)
+	return u, &maxInFlight
+}
+
+func TestRateLimit(t *testing.T) {
+	u, _ := newThrottledServer(t, 0)
+	var mu sync.Mutex
+	var events []ThrottleEvent
+	client := NewClient(u, WithRateLimit(RateLimitOptions{
+		RateLimit: RateLimit{RequestsPerSecond: 20},
+		Endpoints: map[EndpointClass]RateLimit{EndpointClassCluster: {RequestsPerSecond: 10, Burst: 2}},
+		OnThrottle: func(event ThrottleEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, event)
+		},
+	}))
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		_, err := client.IsReaperUp(ctx)
+		require.NoError(t, err)
+	}
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(190*time.Millisecond), "4 requests waited 50ms")
+
+	start = time.Now()
+	for i := 0; i < 4; i++ {
+		_, err := client.GetCluster(ctx, "cluster-1")
+		require.NoError(t, err)
+	}
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(190*time.Millisecond), "2 requests waited 100ms")
+
+	mu.Lock()
+	defer mu.Unlock()
+	classes := map[EndpointClass]int{}
+	for _, event := range events {
+		assert.NoError(t, event.Err)
+		assert.Greater(t, int64(event.Delay), int64(0))
+		classes[event.Class]++
+	}
+	assert.Equal(t, map[EndpointClass]int{EndpointClassDefault: 4, EndpointClassCluster: 2}, classes)
+}
+
+func TestMaxInFlight(t *testing.T) {
+	u, maxInFlight := newThrottledServer(t, 20*time.Millisecond)
+	client := NewClient(u, WithRateLimit(RateLimitOptions{RateLimit: RateLimit{MaxInFlight: 2}}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.GetCluster(context.Background(), "cluster-1")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(2), atomic.LoadInt32(maxInFlight))
+}
+
+func TestRateLimitCancel(t *testing.T) {
+	u, _ := newThrottledServer(t, 0)
+	var logs bytes.Buffer
+	var events []ThrottleEvent
+	c := NewClient(u, WithRateLimit(RateLimitOptions{
+		RateLimit:  RateLimit{RequestsPerSecond: 1},
+		OnThrottle: func(event ThrottleEvent) { events = append(events, event) },
+		Logger:     slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	}))
+
+	_, err := c.IsReaperUp(context.Background())
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = c.IsReaperUp(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.EqualError(t, err, "rate limit of default endpoints: context deadline exceeded")
+	require.Len(t, events, 1)
+	assert.Equal(t, "/ping", events[0].Path)
+	assert.True(t, errors.Is(events[0].Err, context.DeadlineExceeded))
+	assert.Contains(t, logs.String(), "level=WARN msg=\"gave up waiting for the Reaper rate limit\" class=default")
+
+	// the token of the canceled request was given back
+	limiter := c.(*client).rateLimiter.fallback
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	assert.GreaterOrEqual(t, limiter.tokens, 0.0)
+	assert.Less(t, limiter.tokens, 1.0)
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -32,6 +33,10 @@ type RepairRun struct {
 	IgnoredTables     []string          `json:"blacklisted_tables"`
 	RepairThreadCount int               `json:"repair_thread_count"`
 	RepairUnitId      uuid.UUID         `json:"repair_unit_id"`
+	Created           time.Time         `json:"creation_time,omitempty"`
+	Started           time.Time         `json:"start_time,omitempty"`
+	Ended             time.Time         `json:"end_time,omitempty"`
+	Paused            time.Time         `json:"pause_time,omitempty"`
 }
 
 func (r RepairRun) String() string {
@@ -192,6 +197,74 @@ type RepairRunCreateOptions struct {
 	RepairThreadCount int `url:"repairThreadCount,omitempty"`
 }
 
+// Validate checks the options against the constraints enforced by Reaper, and returns a *ValidationError listing all
+// the invalid fields, or nil. Zero values stand for Reaper's defaults and are always valid. CreateRepairRun validates
+// its options unless the client was created with WithoutValidation.
+func (o *RepairRunCreateOptions) Validate() error {
+	if o == nil {
+		return nil
+	}
+	var errs []*FieldError
+	if len(o.Tables) > 0 && len(o.IgnoredTables) > 0 {
+		errs = append(errs, &FieldError{Field: "IgnoredTables", Message: "cannot be used together with Tables"})
+	}
+	if o.SegmentCountPerNode != 0 && (o.SegmentCountPerNode < 1 || o.SegmentCountPerNode > 1000) {
+		errs = append(errs, &FieldError{
+			Field:   "SegmentCountPerNode",
+			Message: fmt.Sprintf("must be between 1 and 1000, got %d", o.SegmentCountPerNode),
+		})
+	}
+	// written so that NaN is rejected too
+	if o.Intensity != 0 && !(o.Intensity > 0 && o.Intensity <= 1) {
+		errs = append(errs, &FieldError{Field: "Intensity", Message: fmt.Sprintf("must be in (0, 1], got %v", o.Intensity)})
+	}
+	if o.IncrementalRepair && o.RepairParallelism == RepairParallelismDatacenterAware {
+		errs = append(errs, &FieldError{
+			Field:   "RepairParallelism",
+			Message: "DATACENTER_AWARE cannot be used with incremental repair",
+		})
+	}
+	if len(o.Nodes) > 0 && len(o.Datacenters) > 0 {
+		errs = append(errs, &FieldError{Field: "Datacenters", Message: "cannot be used together with Nodes"})
+	}
+	if o.RepairThreadCount != 0 && (o.RepairThreadCount < 1 || o.RepairThreadCount > 4) {
+		errs = append(errs, &FieldError{
+			Field:   "RepairThreadCount",
+			Message: fmt.Sprintf("must be between 1 and 4, got %d", o.RepairThreadCount),
+		})
+	}
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// FieldError describes an invalid field of RepairRunCreateOptions.
+type FieldError struct {
+	// The name of the invalid field, e.g. "Intensity".
+	Field string
+
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+// ValidationError is the error returned, possibly wrapped, when options are rejected before being sent to Reaper. Use
+// errors.As to inspect the invalid fields.
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return "invalid options: " + strings.Join(messages, "; ")
+}
+
 func (c *client) RepairRuns(ctx context.Context, searchOptions *RepairRunSearchOptions) (map[uuid.UUID]*RepairRun, error) {
 	res, err := c.doGet(ctx, "/repair_run", searchOptions, http.StatusOK)
 	if err == nil {
@@ -222,6 +295,11 @@ func (c *client) RepairRun(ctx context.Context, repairRunId uuid.UUID) (*RepairR
 }
 
 func (c *client) CreateRepairRun(ctx context.Context, cluster string, keyspace string, owner string, options *RepairRunCreateOptions) (uuid.UUID, error) {
+	if !c.skipValidation {
+		if err := options.Validate(); err != nil {
+			return uuid.Nil, fmt.Errorf("failed to create repair run: %w", err)
+		}
+	}
 	queryParams, err := c.mergeParamSources(
 		map[string]string{
 			"clusterName": cluster,
@@ -232,9 +310,12 @@ func (c *client) CreateRepairRun(ctx context.Context, cluster string, keyspace s
 	)
 	if err == nil {
 		if options != nil && options.SegmentCountPerNode > 0 {
-			// Some Reaper versions accept "segmentCount", others "segmentCountPerNode";
-			// make sure we include both in the query string.
-			queryParams.Set("segmentCount", strconv.Itoa(options.SegmentCountPerNode))
+			// Reaper versions before 1.2 only accept "segmentCount": send the parameter of the detected version, or
+			// both of them if it can't be detected.
+			queryParams.Del("segmentCountPerNode")
+			for _, param := range c.probedCapabilities(ctx).segmentCountParams() {
+				queryParams.Set(param, strconv.Itoa(options.SegmentCountPerNode))
+			}
 		}
 		var res *http.Response
 		res, err = c.doPost(ctx, "/repair_run", queryParams, nil, http.StatusCreated)
@@ -251,7 +332,7 @@ func (c *client) CreateRepairRun(ctx context.Context, cluster string, keyspace s
 
 func (c *client) UpdateRepairRun(ctx context.Context, repairRunId uuid.UUID, newIntensity Intensity) error {
 	path := fmt.Sprint("/repair_run/", repairRunId, "/intensity/", newIntensity)
-	_, err := c.doPut(ctx, path, nil, nil, http.StatusOK)
+	err := c.discard(c.doPut(ctx, path, nil, nil, http.StatusOK))
 	if err == nil {
 		return nil
 	}
@@ -260,7 +341,7 @@ func (c *client) UpdateRepairRun(ctx context.Context, repairRunId uuid.UUID, new
 
 func (c *client) StartRepairRun(ctx context.Context, repairRunId uuid.UUID) error {
 	path := fmt.Sprint("/repair_run/", repairRunId, "/state/", RepairRunStateRunning)
-	_, err := c.doPut(ctx, path, nil, nil, http.StatusOK, http.StatusNoContent)
+	err := c.discard(c.doPut(ctx, path, nil, nil, http.StatusOK, http.StatusNoContent))
 	if err == nil {
 		return nil
 	}
@@ -269,7 +350,7 @@ func (c *client) StartRepairRun(ctx context.Context, repairRunId uuid.UUID) erro
 
 func (c *client) PauseRepairRun(ctx context.Context, repairRunId uuid.UUID) error {
 	path := fmt.Sprint("/repair_run/", repairRunId, "/state/", RepairRunStatePaused)
-	_, err := c.doPut(ctx, path, nil, nil, http.StatusOK, http.StatusNoContent)
+	err := c.discard(c.doPut(ctx, path, nil, nil, http.StatusOK, http.StatusNoContent))
 	if err == nil {
 		return nil
 	}
@@ -278,7 +359,7 @@ func (c *client) PauseRepairRun(ctx context.Context, repairRunId uuid.UUID) erro
 
 func (c *client) ResumeRepairRun(ctx context.Context, repairRunId uuid.UUID) error {
 	path := fmt.Sprint("/repair_run/", repairRunId, "/state/", RepairRunStateRunning)
-	_, err := c.doPut(ctx, path, nil, nil, http.StatusOK, http.StatusNoContent)
+	err := c.discard(c.doPut(ctx, path, nil, nil, http.StatusOK, http.StatusNoContent))
 	if err == nil {
 		return nil
 	}
@@ -287,7 +368,7 @@ func (c *client) ResumeRepairRun(ctx context.Context, repairRunId uuid.UUID) err
 
 func (c *client) AbortRepairRun(ctx context.Context, repairRunId uuid.UUID) error {
 	path := fmt.Sprint("/repair_run/", repairRunId, "/state/", RepairRunStateAborted)
-	_, err := c.doPut(ctx, path, nil, nil, http.StatusOK, http.StatusNoContent)
+	err := c.discard(c.doPut(ctx, path, nil, nil, http.StatusOK, http.StatusNoContent))
 	if err == nil {
 		return nil
 	}
@@ -313,7 +394,7 @@ func (c *client) RepairRunSegments(ctx context.Context, repairRunId uuid.UUID) (
 
 func (c *client) AbortRepairRunSegment(ctx context.Context, repairRunId uuid.UUID, segmentId uuid.UUID) error {
 	path := fmt.Sprint("/repair_run/", repairRunId, "/segments/abort/", segmentId)
-	_, err := c.doPost(ctx, path, nil, nil, http.StatusOK)
+	err := c.discard(c.doPost(ctx, path, nil, nil, http.StatusOK))
 	if err == nil {
 		return nil
 	}
@@ -323,13 +404,13 @@ func (c *client) AbortRepairRunSegment(ctx context.Context, repairRunId uuid.UUI
 func (c *client) DeleteRepairRun(ctx context.Context, repairRunId uuid.UUID, owner string) error {
 	path := fmt.Sprint("/repair_run/", repairRunId)
 	queryParams := &url.Values{"owner": {owner}}
-	res, err := c.doDelete(ctx, path, queryParams, http.StatusAccepted)
+	res, err := c.doDelete(expectServerError(ctx), path, queryParams, http.StatusAccepted)
 	if err == nil {
-		return nil
+		return c.discard(res, nil)
 	} else {
 		// FIXME this REST resource currently returns 500 for succeeded deletes
 		if res != nil && res.StatusCode == http.StatusInternalServerError {
-			_, err2 := c.doGet(ctx, path, nil, http.StatusNotFound)
+			err2 := c.discard(c.doGet(ctx, path, nil, http.StatusNotFound))
 			if err2 == nil {
 				return nil
 			}
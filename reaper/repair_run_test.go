@@ -2,15 +2,26 @@ package reaper
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 	"time"
 )
 
+// pollInterval is how often tests poll Reaper while waiting for a repair run to progress.
+var pollInterval = 5 * time.Second
+
+// nonExistentId is a fixed id, so that requests for non-existent resources can be replayed from recordings.
+const nonExistentId = "9b4a3b10-0000-1000-8000-000000000000"
+
 func testGetRepairRun(t *testing.T, client Client) {
 	expected := createRepairRun(t, client, "cluster-1")
 	defer deleteRepairRun(t, client, expected)
@@ -23,7 +34,7 @@ func testGetRepairRun(t *testing.T, client Client) {
 }
 
 func testGetRepairRunNotFound(t *testing.T, client Client) {
-	nonExistentRepairRun, _ := uuid.NewUUID()
+	nonExistentRepairRun := uuid.MustParse(nonExistentId)
 	actual, err := client.RepairRun(
 		context.Background(),
 		nonExistentRepairRun,
@@ -31,6 +42,11 @@ func testGetRepairRunNotFound(t *testing.T, client Client) {
 	assert.Nil(t, actual)
 	assert.NotNil(t, err)
 	assert.Contains(t, err.Error(), fmt.Sprintf("repair run %v doesn't exist", nonExistentRepairRun))
+	var httpErr *HttpError
+	if assert.True(t, errors.As(err, &httpErr)) {
+		assert.Equal(t, http.StatusNotFound, httpErr.StatusCode)
+		assert.Equal(t, fmt.Sprintf("repair run %v doesn't exist", nonExistentRepairRun), httpErr.Message)
+	}
 }
 
 func testGetRepairRunIgnoredTables(t *testing.T, client Client) {
@@ -272,11 +288,12 @@ func testAbortRepairRunSegments(t *testing.T, client Client) {
 }
 
 func testDeleteRepairRunNotFound(t *testing.T, client Client) {
-	nonExistentRepairRun, _ := uuid.NewUUID()
+	nonExistentRepairRun := uuid.MustParse(nonExistentId)
 	err := client.DeleteRepairRun(context.Background(), nonExistentRepairRun, "Alice")
 	assert.NotNil(t, err)
 	// Reaper returns a spurious '%s' in the error message
 	assert.Contains(t, err.Error(), fmt.Sprintf("Repair run %%s%v not found", nonExistentRepairRun))
+	assert.True(t, IsNotFound(err))
 }
 
 func testPurgeRepairRun(t *testing.T, client Client) {
@@ -362,7 +379,7 @@ func waitForRepairRun(t *testing.T, client Client, run *RepairRun, state RepairR
 			return err == nil && actual.State == state
 		},
 		15*time.Minute,
-		5*time.Second,
+		pollInterval,
 	)
 	actual, err := client.RepairRun(context.Background(), run.Id)
 	require.Nil(t, err)
@@ -393,7 +410,7 @@ func waitForSegmentsStarted(t *testing.T, client Client, run *RepairRun) map[uui
 			return true
 		},
 		15*time.Minute,
-		5*time.Second,
+		pollInterval,
 	)
 	segments, err := client.RepairRunSegments(context.Background(), run.Id)
 	require.Nil(t, err)
@@ -403,3 +420,75 @@ func waitForSegmentsStarted(t *testing.T, client Client, run *RepairRun) map[uui
 	t.Fatal("timed out waiting for repair to start")
 	return nil
 }
+
+func TestRepairRunCreateOptionsValidate(t *testing.T) {
+	assert.NoError(t, (*RepairRunCreateOptions)(nil).Validate())
+	assert.NoError(t, (&RepairRunCreateOptions{}).Validate(), "zero values stand for the defaults of Reaper")
+	assert.NoError(t, (&RepairRunCreateOptions{
+		Tables:              []string{"table1"},
+		SegmentCountPerNode: 1000,
+		Intensity:           1,
+		IncrementalRepair:   true,
+		RepairParallelism:   RepairParallelismParallel,
+		Nodes:               []string{"node1"},
+		RepairThreadCount:   4,
+	}).Validate())
+
+	err := (&RepairRunCreateOptions{
+		Tables:              []string{"table1"},
+		IgnoredTables:       []string{"table2"},
+		SegmentCountPerNode: 1001,
+		Intensity:           1.5,
+		IncrementalRepair:   true,
+		RepairParallelism:   RepairParallelismDatacenterAware,
+		Nodes:               []string{"node1"},
+		Datacenters:         []string{"dc1"},
+		RepairThreadCount:   5,
+	}).Validate()
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	fields := make([]string, len(validationErr.Errors))
+	for i, fieldErr := range validationErr.Errors {
+		fields[i] = fieldErr.Field
+	}
+	assert.Equal(t, []string{"IgnoredTables", "SegmentCountPerNode", "Intensity", "RepairParallelism", "Datacenters",
+		"RepairThreadCount"}, fields)
+	assert.Equal(t, "invalid options: IgnoredTables cannot be used together with Tables; "+
+		"SegmentCountPerNode must be between 1 and 1000, got 1001; Intensity must be in (0, 1], got 1.5; "+
+		"RepairParallelism DATACENTER_AWARE cannot be used with incremental repair; "+
+		"Datacenters cannot be used together with Nodes; RepairThreadCount must be between 1 and 4, got 5", err.Error())
+
+	for _, options := range []*RepairRunCreateOptions{
+		{SegmentCountPerNode: -1},
+		{Intensity: -0.5},
+		{Intensity: math.NaN()},
+		{RepairThreadCount: -1},
+	} {
+		assert.Error(t, options.Validate(), "%+v", options)
+	}
+}
+
+func TestCreateRepairRunValidation(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("invalid intensity"))
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	options := &RepairRunCreateOptions{Intensity: 2}
+
+	_, err := NewClient(u).CreateRepairRun(context.Background(), "cluster-1", "ks", "Alice", options)
+	var validationErr *ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.EqualError(t, err, "failed to create repair run: invalid options: Intensity must be in (0, 1], got 2")
+	assert.Equal(t, 0, requests, "invalid options are not sent")
+
+	_, err = NewClient(u, WithoutValidation()).CreateRepairRun(context.Background(), "cluster-1", "ks", "Alice", options)
+	var httpErr *HttpError
+	if assert.True(t, errors.As(err, &httpErr)) {
+		assert.Equal(t, http.StatusBadRequest, httpErr.StatusCode)
+	}
+	assert.Equal(t, 1, requests)
+}
@@ -5,8 +5,55 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RepairScheduleState string
+
+const (
+	RepairScheduleStateActive  = RepairScheduleState("ACTIVE")
+	RepairScheduleStatePaused  = RepairScheduleState("PAUSED")
+	RepairScheduleStateDeleted = RepairScheduleState("DELETED")
 )
 
+type RepairScheduleCreateOptions struct {
+
+	// Allows to specify which tables are targeted by the repair runs. When this parameter is omitted, then the
+	// repair runs will target all the tables in the target keyspace.
+	Tables []string `url:"tables,comma,omitempty"`
+
+	// Allows to specify a list of tables that should not be repaired. Cannot be used in conjunction with Tables.
+	IgnoredTables []string `url:"blacklistedTables,comma,omitempty"`
+
+	// Defines the amount of segments per node to create for the repair runs. The value must be >0 and <=1000.
+	SegmentCountPerNode int `url:"segmentCountPerNode,omitempty"`
+
+	// Defines the used repair parallelism for the repair runs.
+	RepairParallelism RepairParallelism `url:"repairParallelism,omitempty"`
+
+	// Defines the intensity of the repair runs.
+	Intensity Intensity `url:"intensity,omitempty"`
+
+	// Defines if incremental repair should be done.
+	IncrementalRepair bool `url:"incrementalRepair,omitempty"`
+
+	// When to trigger the next repair run. If not specified, defaults to the next day, at start of day.
+	TriggerTime *time.Time `url:"scheduleTriggerTime,omitempty"`
+
+	// Allows to specify a list of nodes whose tokens should be repaired.
+	Nodes []string `url:"nodes,comma,omitempty"`
+
+	// Allows to specify a list of datacenters to repair.
+	Datacenters []string `url:"datacenters,comma,omitempty"`
+
+	// Defines the thread count to use for repair. Since Cassandra 2.2, repairs can be performed with
+	// up to 4 threads in order to parallelize the work on different token ranges.
+	RepairThreadCount int `url:"repairThreadCount,omitempty"`
+}
+
 func (c *client) RepairSchedules(ctx context.Context) ([]RepairSchedule, error) {
 	return c.fetchRepairSchedules(ctx, "/repair_schedule")
 }
@@ -27,3 +74,101 @@ func (c *client) fetchRepairSchedules(ctx context.Context, path string) ([]Repai
 	}
 	return nil, fmt.Errorf("failed to fetch repair schedules: %w", err)
 }
+
+func (c *client) RepairSchedule(ctx context.Context, repairScheduleId uuid.UUID) (*RepairSchedule, error) {
+	path := fmt.Sprint("/repair_schedule/", repairScheduleId)
+	res, err := c.doGet(ctx, path, nil, http.StatusOK)
+	if err == nil {
+		repairSchedule := &RepairSchedule{}
+		err = c.readBodyAsJson(res, repairSchedule)
+		if err == nil {
+			return repairSchedule, nil
+		}
+	}
+	return nil, fmt.Errorf("failed to get repair schedule %v: %w", repairScheduleId, err)
+}
+
+func (c *client) CreateRepairSchedule(
+	ctx context.Context,
+	cluster string,
+	keyspace string,
+	owner string,
+	daysBetween int,
+	options *RepairScheduleCreateOptions,
+) (uuid.UUID, error) {
+	queryParams, err := c.mergeParamSources(
+		map[string]string{
+			"clusterName":         cluster,
+			"keyspace":            keyspace,
+			"owner":               owner,
+			"scheduleDaysBetween": strconv.Itoa(daysBetween),
+		},
+		options,
+	)
+	if err == nil {
+		if options != nil && options.SegmentCountPerNode > 0 {
+			// Reaper versions before 1.2 only accept "segmentCount": send the parameter of the detected version, or
+			// both of them if it can't be detected.
+			queryParams.Del("segmentCountPerNode")
+			for _, param := range c.probedCapabilities(ctx).segmentCountParams() {
+				queryParams.Set(param, strconv.Itoa(options.SegmentCountPerNode))
+			}
+		}
+		var res *http.Response
+		res, err = c.doPost(ctx, "/repair_schedule", queryParams, nil, http.StatusCreated)
+		if err == nil {
+			repairSchedule := &RepairSchedule{}
+			err = c.readBodyAsJson(res, repairSchedule)
+			if err == nil {
+				var id uuid.UUID
+				if id, err = uuid.Parse(repairSchedule.Id); err == nil {
+					return id, nil
+				}
+			}
+		}
+	}
+	return uuid.Nil, fmt.Errorf("failed to create repair schedule: %w", err)
+}
+
+func (c *client) StartRepairSchedule(ctx context.Context, repairScheduleId uuid.UUID) error {
+	path := fmt.Sprint("/repair_schedule/start/", repairScheduleId)
+	err := c.discard(c.doPost(ctx, path, nil, nil, http.StatusOK, http.StatusNoContent))
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("failed to start repair schedule %v: %w", repairScheduleId, err)
+}
+
+func (c *client) PauseRepairSchedule(ctx context.Context, repairScheduleId uuid.UUID) error {
+	err := c.changeRepairScheduleState(ctx, repairScheduleId, RepairScheduleStatePaused)
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("failed to pause repair schedule %v: %w", repairScheduleId, err)
+}
+
+func (c *client) ResumeRepairSchedule(ctx context.Context, repairScheduleId uuid.UUID) error {
+	err := c.changeRepairScheduleState(ctx, repairScheduleId, RepairScheduleStateActive)
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("failed to resume repair schedule %v: %w", repairScheduleId, err)
+}
+
+func (c *client) changeRepairScheduleState(ctx context.Context, repairScheduleId uuid.UUID, state RepairScheduleState) error {
+	path := fmt.Sprint("/repair_schedule/", repairScheduleId)
+	queryParams := &url.Values{"state": {string(state)}}
+	// Reaper returns 304 when the schedule is already in the requested state
+	err := c.discard(c.doPut(ctx, path, queryParams, nil, http.StatusOK, http.StatusNoContent, http.StatusNotModified))
+	return err
+}
+
+func (c *client) DeleteRepairSchedule(ctx context.Context, repairScheduleId uuid.UUID, owner string) error {
+	path := fmt.Sprint("/repair_schedule/", repairScheduleId)
+	queryParams := &url.Values{"owner": {owner}}
+	err := c.discard(c.doDelete(ctx, path, queryParams, http.StatusAccepted, http.StatusOK, http.StatusNoContent))
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("failed to delete repair schedule %v: %w", repairScheduleId, err)
+}
@@ -0,0 +1,101 @@
+package reaper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepairScheduleRequests(t *testing.T) {
+	scheduleId := uuid.MustParse("a2b1f0e0-6d7c-11eb-9439-0242ac130002")
+	scheduleJson := fmt.Sprintf(`{
+		"id": "%v",
+		"owner": "Alice",
+		"state": "ACTIVE",
+		"intensity": 0.5,
+		"cluster_name": "cluster-1",
+		"keyspace_name": "ks1",
+		"column_families": ["table1"],
+		"repair_parallelism": "PARALLEL",
+		"scheduled_days_between": 7,
+		"segment_count_per_node": 16,
+		"next_activation": "2021-02-14T00:00:00Z"
+	}`, scheduleId)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch fmt.Sprint(r.Method, " ", r.URL.Path) {
+		case "POST /repair_schedule":
+			query := r.URL.Query()
+			assert.Equal(t, "cluster-1", query.Get("clusterName"))
+			assert.Equal(t, "ks1", query.Get("keyspace"))
+			assert.Equal(t, "Alice", query.Get("owner"))
+			assert.Equal(t, "7", query.Get("scheduleDaysBetween"))
+			assert.Equal(t, "table1", query.Get("tables"))
+			assert.Equal(t, "16", query.Get("segmentCountPerNode"))
+			assert.Equal(t, "16", query.Get("segmentCount"))
+			assert.Equal(t, "PARALLEL", query.Get("repairParallelism"))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(scheduleJson))
+		case fmt.Sprint("GET /repair_schedule/", scheduleId):
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(scheduleJson))
+		case fmt.Sprint("PUT /repair_schedule/", scheduleId):
+			if r.URL.Query().Get("state") == "ACTIVE" {
+				w.WriteHeader(http.StatusNotModified)
+			} else {
+				assert.Equal(t, "PAUSED", r.URL.Query().Get("state"))
+				w.WriteHeader(http.StatusOK)
+			}
+		case fmt.Sprint("POST /repair_schedule/start/", scheduleId):
+			w.WriteHeader(http.StatusOK)
+		case fmt.Sprint("DELETE /repair_schedule/", scheduleId):
+			if r.URL.Query().Get("owner") != "Alice" {
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte("owner mismatch"))
+				return
+			}
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	reaperClient := NewClient(u)
+	ctx := context.Background()
+
+	id, err := reaperClient.CreateRepairSchedule(ctx, "cluster-1", "ks1", "Alice", 7, &RepairScheduleCreateOptions{
+		Tables:              []string{"table1"},
+		SegmentCountPerNode: 16,
+		RepairParallelism:   RepairParallelismParallel,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, scheduleId, id)
+
+	schedule, err := reaperClient.RepairSchedule(ctx, scheduleId)
+	require.NoError(t, err)
+	assert.Equal(t, string(RepairScheduleStateActive), schedule.State)
+	assert.Equal(t, string(RepairParallelismParallel), schedule.RepairParallelism)
+	assert.Equal(t, []string{"table1"}, schedule.Tables)
+	assert.Equal(t, 16, schedule.SegmentCountPerNode)
+	assert.Equal(t, 7, schedule.DaysBetween)
+
+	assert.NoError(t, reaperClient.PauseRepairSchedule(ctx, scheduleId))
+	assert.NoError(t, reaperClient.ResumeRepairSchedule(ctx, scheduleId), "304 means already active")
+	assert.NoError(t, reaperClient.StartRepairSchedule(ctx, scheduleId))
+	assert.NoError(t, reaperClient.DeleteRepairSchedule(ctx, scheduleId, "Alice"))
+	err = reaperClient.DeleteRepairSchedule(ctx, scheduleId, "Bob")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "owner mismatch")
+
+	_, err = reaperClient.RepairSchedule(ctx, uuid.New())
+	assert.Error(t, err)
+}
@@ -0,0 +1,251 @@
+package reaper
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GossipDisagreement describes an endpoint attribute on which the source nodes of a NodeState do not agree.
+type GossipDisagreement struct {
+	Endpoint string
+
+	// The attribute the source nodes disagree on: one of "Status", "ReleaseVersion", "Tokens", "HostId",
+	// "DataCenter", "Rack", or "Known" when some source nodes do not know about the endpoint at all.
+	Attribute string
+
+	// The value of the attribute as seen by each source node, keyed by source node.
+	Values map[string]string
+}
+
+// IsUp returns true if the endpoint is reported as up. Depending on the Cassandra and Reaper versions, the status is
+// either a gossip status (NORMAL, LEAVING, JOINING...), a liveness state (UP, DOWN), or a combination of both.
+func (e EndpointState) IsUp() bool {
+	status := strings.ToUpper(e.Status)
+	if status == "" || strings.Contains(status, "DOWN") {
+		return false
+	}
+	return strings.Contains(status, "NORMAL") || strings.Contains(status, "UP")
+}
+
+// Endpoints returns the endpoints of the cluster, sorted by name. When several source nodes report different views of
+// the same endpoint, the views are reconciled: the status reported by most source nodes wins, and ties are resolved in
+// favor of the status that is not up.
+func (s NodeState) Endpoints() []EndpointState {
+	views := s.endpointViews()
+	endpoints := make([]EndpointState, 0, len(views))
+	for _, name := range sortedKeys(views) {
+		endpoints = append(endpoints, reconcile(views[name]))
+	}
+	return endpoints
+}
+
+// EndpointsByDatacenter returns the reconciled endpoints of the cluster grouped by datacenter.
+func (s NodeState) EndpointsByDatacenter() map[string][]EndpointState {
+	byDatacenter := make(map[string][]EndpointState)
+	for _, endpoint := range s.Endpoints() {
+		byDatacenter[endpoint.DataCenter] = append(byDatacenter[endpoint.DataCenter], endpoint)
+	}
+	return byDatacenter
+}
+
+// DownEndpoints returns the reconciled endpoints that are not up.
+func (s NodeState) DownEndpoints() []EndpointState {
+	down := make([]EndpointState, 0)
+	for _, endpoint := range s.Endpoints() {
+		if !endpoint.IsUp() {
+			down = append(down, endpoint)
+		}
+	}
+	return down
+}
+
+// Datacenters returns the sorted names of the datacenters seen by any source node.
+func (s NodeState) Datacenters() []string {
+	datacenters := make(map[string]bool)
+	for _, gs := range s.GossipStates {
+		for dc := range gs.DataCenters {
+			datacenters[dc] = true
+		}
+	}
+	return sortedKeys(datacenters)
+}
+
+// ReleaseVersions returns the sorted, distinct Cassandra versions of the reconciled endpoints. More than one version
+// usually means that a rolling upgrade is in progress.
+func (s NodeState) ReleaseVersions() []string {
+	versions := make(map[string]bool)
+	for _, endpoint := range s.Endpoints() {
+		if endpoint.ReleaseVersion != "" {
+			versions[endpoint.ReleaseVersion] = true
+		}
+	}
+	return sortedKeys(versions)
+}
+
+// TotalLoad returns the sum of the loads of the reconciled endpoints. Contrary to GossipState.TotalLoad, each endpoint
+// is counted once even if the source nodes have different views of the cluster.
+func (s NodeState) TotalLoad() float64 {
+	total := 0.0
+	for _, endpoint := range s.Endpoints() {
+		total += endpoint.Load
+	}
+	return total
+}
+
+// IsHealthy returns true if at least minUp reconciled endpoints are up and no source node reports an endpoint the
+// others do not know about.
+func (s NodeState) IsHealthy(minUp int) bool {
+	up := 0
+	for _, endpoint := range s.Endpoints() {
+		if endpoint.IsUp() {
+			up++
+		}
+	}
+	if up < minUp {
+		return false
+	}
+	for _, disagreement := range s.GossipDisagreements() {
+		if disagreement.Attribute == "Known" {
+			return false
+		}
+	}
+	return true
+}
+
+// GossipDisagreements returns the endpoint attributes on which the source nodes disagree, sorted by endpoint and
+// attribute. Load and severity are expected to vary between source nodes and are never reported.
+func (s NodeState) GossipDisagreements() []GossipDisagreement {
+	sources := make([]string, 0, len(s.GossipStates))
+	for _, gs := range s.GossipStates {
+		sources = append(sources, gs.SourceNode)
+	}
+	views := s.endpointViews()
+	disagreements := make([]GossipDisagreement, 0)
+	for _, name := range sortedKeys(views) {
+		byEndpoint := views[name]
+		if len(byEndpoint) < len(sources) {
+			values := make(map[string]string, len(sources))
+			for _, source := range sources {
+				_, known := byEndpoint[source]
+				values[source] = strconv.FormatBool(known)
+			}
+			disagreements = append(disagreements, GossipDisagreement{Endpoint: name, Attribute: "Known", Values: values})
+		}
+		attributes := []struct {
+			name  string
+			value func(EndpointState) string
+		}{
+			{"DataCenter", func(e EndpointState) string { return e.DataCenter }},
+			{"HostId", func(e EndpointState) string { return e.HostId }},
+			{"Rack", func(e EndpointState) string { return e.Rack }},
+			{"ReleaseVersion", func(e EndpointState) string { return e.ReleaseVersion }},
+			{"Status", func(e EndpointState) string { return e.Status }},
+			{"Tokens", func(e EndpointState) string { return e.Tokens }},
+		}
+		for _, attribute := range attributes {
+			values := make(map[string]string, len(byEndpoint))
+			distinct := make(map[string]bool)
+			for source, endpoint := range byEndpoint {
+				value := attribute.value(endpoint)
+				values[source] = value
+				distinct[value] = true
+			}
+			if len(distinct) > 1 {
+				disagreements = append(disagreements, GossipDisagreement{Endpoint: name, Attribute: attribute.name, Values: values})
+			}
+		}
+	}
+	return disagreements
+}
+
+// endpointViews returns, for each endpoint name, the endpoint state as seen by each source node.
+func (s NodeState) endpointViews() map[string]map[string]EndpointState {
+	views := make(map[string]map[string]EndpointState)
+	for _, gs := range s.GossipStates {
+		for _, dc := range gs.DataCenters {
+			for _, rack := range dc.Racks {
+				for _, endpoint := range rack.Endpoints {
+					if views[endpoint.Endpoint] == nil {
+						views[endpoint.Endpoint] = make(map[string]EndpointState)
+					}
+					views[endpoint.Endpoint][gs.SourceNode] = endpoint
+				}
+			}
+		}
+	}
+	return views
+}
+
+func reconcile(views map[string]EndpointState) EndpointState {
+	votes := make(map[string]int)
+	for _, endpoint := range views {
+		votes[endpoint.Status]++
+	}
+	var winner *EndpointState
+	// iterate over sources in order to make the result deterministic
+	for _, source := range sortedKeys(views) {
+		candidate := views[source]
+		if winner == nil {
+			winner = &candidate
+			continue
+		}
+		if candidate.Status == winner.Status {
+			continue
+		}
+		if votes[candidate.Status] > votes[winner.Status] ||
+			(votes[candidate.Status] == votes[winner.Status] && winner.IsUp() && !candidate.IsUp()) {
+			winner = &candidate
+		}
+	}
+	return *winner
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Endpoints returns the reconciled endpoints of the cluster. See NodeState.Endpoints.
+func (c *Cluster) Endpoints() []EndpointState {
+	return c.NodeState.Endpoints()
+}
+
+// EndpointsByDatacenter returns the reconciled endpoints of the cluster grouped by datacenter.
+func (c *Cluster) EndpointsByDatacenter() map[string][]EndpointState {
+	return c.NodeState.EndpointsByDatacenter()
+}
+
+// DownEndpoints returns the reconciled endpoints of the cluster that are not up.
+func (c *Cluster) DownEndpoints() []EndpointState {
+	return c.NodeState.DownEndpoints()
+}
+
+// Datacenters returns the sorted names of the datacenters of the cluster.
+func (c *Cluster) Datacenters() []string {
+	return c.NodeState.Datacenters()
+}
+
+// ReleaseVersions returns the sorted, distinct Cassandra versions running in the cluster.
+func (c *Cluster) ReleaseVersions() []string {
+	return c.NodeState.ReleaseVersions()
+}
+
+// TotalLoad returns the total load of the cluster. See NodeState.TotalLoad.
+func (c *Cluster) TotalLoad() float64 {
+	return c.NodeState.TotalLoad()
+}
+
+// IsHealthy returns true if at least minUp endpoints of the cluster are up. See NodeState.IsHealthy.
+func (c *Cluster) IsHealthy(minUp int) bool {
+	return c.NodeState.IsHealthy(minUp)
+}
+
+// GossipDisagreements returns the endpoint attributes on which the source nodes disagree.
+func (c *Cluster) GossipDisagreements() []GossipDisagreement {
+	return c.NodeState.GossipDisagreements()
+}
@@ -0,0 +1,118 @@
+package reaper
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Two source nodes with different views: node3 is DOWN according to node1, but node2 does not know about it, and
+// node2 sees node1 with another release version.
+const topologyJson = `{
+  "name": "cluster-1",
+  "nodes_status": {
+    "endpointStates": [
+      {
+        "sourceNode": "node1",
+        "endpointNames": ["node1", "node2", "node3"],
+        "totalLoad": 60,
+        "endpoints": {
+          "dc1": {
+            "rack1": [
+              {"endpoint": "node1", "dc": "dc1", "rack": "rack1", "hostId": "h1", "status": "NORMAL - UP", "releaseVersion": "3.11.8", "tokens": "1", "load": 10},
+              {"endpoint": "node2", "dc": "dc1", "rack": "rack1", "hostId": "h2", "status": "NORMAL - UP", "releaseVersion": "3.11.8", "tokens": "2", "load": 20}
+            ]
+          },
+          "dc2": {
+            "rack1": [
+              {"endpoint": "node3", "dc": "dc2", "rack": "rack1", "hostId": "h3", "status": "NORMAL - DOWN", "releaseVersion": "3.11.8", "tokens": "3", "load": 30}
+            ]
+          }
+        }
+      },
+      {
+        "sourceNode": "node2",
+        "endpointNames": ["node1", "node2"],
+        "totalLoad": 31,
+        "endpoints": {
+          "dc1": {
+            "rack1": [
+              {"endpoint": "node1", "dc": "dc1", "rack": "rack1", "hostId": "h1", "status": "NORMAL - DOWN", "releaseVersion": "4.0.0", "tokens": "1", "load": 11},
+              {"endpoint": "node2", "dc": "dc1", "rack": "rack1", "hostId": "h2", "status": "NORMAL - UP", "releaseVersion": "3.11.8", "tokens": "2", "load": 20}
+            ]
+          }
+        }
+      }
+    ]
+  }
+}`
+
+func newTestCluster(t *testing.T) *Cluster {
+	status := &clusterStatus{}
+	require.NoError(t, json.Unmarshal([]byte(topologyJson), status))
+	return newCluster(status)
+}
+
+func endpointNames(endpoints []EndpointState) []string {
+	names := make([]string, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		names = append(names, endpoint.Endpoint)
+	}
+	return names
+}
+
+func TestTopology(t *testing.T) {
+	cluster := newTestCluster(t)
+
+	endpoints := cluster.Endpoints()
+	assert.Equal(t, []string{"node1", "node2", "node3"}, endpointNames(endpoints))
+	// one vote UP and one vote DOWN: DOWN wins
+	assert.Equal(t, "NORMAL - DOWN", endpoints[0].Status)
+	assert.Equal(t, "node2", endpointNames(cluster.EndpointsByDatacenter()["dc1"])[1])
+	assert.Equal(t, []string{"node3"}, endpointNames(cluster.EndpointsByDatacenter()["dc2"]))
+	assert.Equal(t, []string{"node1", "node3"}, endpointNames(cluster.DownEndpoints()))
+	assert.Equal(t, []string{"dc1", "dc2"}, cluster.Datacenters())
+	assert.Equal(t, []string{"3.11.8", "4.0.0"}, cluster.ReleaseVersions())
+	assert.InDelta(t, 61.0, cluster.TotalLoad(), 0.001)
+	assert.False(t, cluster.IsHealthy(1), "node3 is unknown to node2")
+}
+
+func TestGossipDisagreements(t *testing.T) {
+	cluster := newTestCluster(t)
+	disagreements := cluster.GossipDisagreements()
+	require.Len(t, disagreements, 3)
+	assert.Equal(t, GossipDisagreement{
+		Endpoint:  "node1",
+		Attribute: "ReleaseVersion",
+		Values:    map[string]string{"node1": "3.11.8", "node2": "4.0.0"},
+	}, disagreements[0])
+	assert.Equal(t, GossipDisagreement{
+		Endpoint:  "node1",
+		Attribute: "Status",
+		Values:    map[string]string{"node1": "NORMAL - UP", "node2": "NORMAL - DOWN"},
+	}, disagreements[1])
+	assert.Equal(t, GossipDisagreement{
+		Endpoint:  "node3",
+		Attribute: "Known",
+		Values:    map[string]string{"node1": "true", "node2": "false"},
+	}, disagreements[2])
+}
+
+func TestTopologySingleSource(t *testing.T) {
+	cluster := newTestCluster(t)
+	cluster.NodeState.GossipStates = cluster.NodeState.GossipStates[:1]
+	assert.Empty(t, cluster.GossipDisagreements())
+	assert.True(t, cluster.IsHealthy(2))
+	assert.False(t, cluster.IsHealthy(3))
+	assert.InDelta(t, 60.0, cluster.TotalLoad(), 0.001)
+}
+
+func TestEndpointStateIsUp(t *testing.T) {
+	assert.True(t, EndpointState{Status: "NORMAL"}.IsUp())
+	assert.True(t, EndpointState{Status: "UP"}.IsUp())
+	assert.False(t, EndpointState{Status: "NORMAL - DOWN"}.IsUp())
+	assert.False(t, EndpointState{Status: "LEAVING"}.IsUp())
+	assert.False(t, EndpointState{}.IsUp())
+}
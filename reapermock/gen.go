@@ -0,0 +1,25 @@
+//go:build ignore
+
+// This program generates mock_gen.go from the declaration of the reaper.Client interface. Run it with go generate.
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/k8ssandra/reaper-client-go/reapermock/internal/gen"
+)
+
+func main() {
+	source, err := os.ReadFile("../reaper/client.go")
+	if err != nil {
+		log.Fatal(err)
+	}
+	generated, err := gen.Generate(source)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = os.WriteFile("mock_gen.go", generated, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
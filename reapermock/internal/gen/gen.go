@@ -0,0 +1,279 @@
+// Package gen generates the reapermock.Mock methods from the declaration of the reaper.Client interface.
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strings"
+	"text/template"
+)
+
+type method struct {
+	Name    string
+	Params  []field
+	Results []field
+}
+
+type field struct {
+	Name string
+	Type string
+}
+
+// ParamList returns the parameters of the method, as in its signature.
+func (m method) ParamList() string {
+	return joinFields(m.Params, true)
+}
+
+// ArgList returns the parameter names of the method, excluding the context.
+func (m method) ArgList() string {
+	var names []string
+	for _, p := range m.Params[1:] {
+		names = append(names, p.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// CallList returns the parameter names of the method, including the context.
+func (m method) CallList() string {
+	var names []string
+	for _, p := range m.Params {
+		names = append(names, p.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// ArgTypes returns the parameter types of the method, excluding the context.
+func (m method) ArgTypes() []field {
+	return m.Params[1:]
+}
+
+// ResultList returns the result types of the method, as in its signature.
+func (m method) ResultList() string {
+	if len(m.Results) == 1 {
+		return m.Results[0].Type
+	}
+	return "(" + joinFields(m.Results, false) + ")"
+}
+
+// NamedResultList returns the results of the method as parameters, for Return.
+func (m method) NamedResultList() string {
+	return joinFields(m.Results, true)
+}
+
+// Signature returns the type of a function with the same signature as the method.
+func (m method) Signature() string {
+	return "func(" + joinFields(m.Params, false) + ") " + m.ResultList()
+}
+
+func joinFields(fields []field, named bool) string {
+	var list []string
+	for _, f := range fields {
+		if named {
+			list = append(list, f.Name+" "+f.Type)
+		} else {
+			list = append(list, f.Type)
+		}
+	}
+	return strings.Join(list, ", ")
+}
+
+// Generate returns the source of the reapermock methods for the Client interface declared in the given source of
+// package reaper.
+func Generate(source []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "client.go", source, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse source: %w", err)
+	}
+	iface := findInterface(file, "Client")
+	if iface == nil {
+		return nil, fmt.Errorf("interface Client not found")
+	}
+	var methods []method
+	for _, m := range iface.Methods.List {
+		fn, ok := m.Type.(*ast.FuncType)
+		if !ok || len(m.Names) != 1 {
+			return nil, fmt.Errorf("embedded interfaces are not supported")
+		}
+		method := method{Name: m.Names[0].Name}
+		method.Params = fields(fn.Params, "arg")
+		method.Results = fields(fn.Results, "r")
+		if len(method.Params) == 0 || method.Params[0].Type != "context.Context" {
+			return nil, fmt.Errorf("method %s must take a context as first parameter", method.Name)
+		}
+		methods = append(methods, method)
+	}
+	var buf bytes.Buffer
+	if err = mockTemplate.Execute(&buf, methods); err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w\n%s", err, buf.String())
+	}
+	return formatted, nil
+}
+
+func findInterface(file *ast.File, name string) *ast.InterfaceType {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			if ts := spec.(*ast.TypeSpec); ts.Name.Name == name {
+				if iface, ok := ts.Type.(*ast.InterfaceType); ok {
+					return iface
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func fields(list *ast.FieldList, prefix string) []field {
+	if list == nil {
+		return nil
+	}
+	var fields []field
+	for _, f := range list.List {
+		typ := typeString(f.Type)
+		if len(f.Names) == 0 {
+			fields = append(fields, field{Name: fmt.Sprintf("%s%d", prefix, len(fields)), Type: typ})
+		}
+		for _, name := range f.Names {
+			fields = append(fields, field{Name: name.Name, Type: typ})
+		}
+	}
+	if prefix == "r" {
+		// result names are not used in signatures, make sure they don't collide with parameter names
+		for i := range fields {
+			fields[i].Name = fmt.Sprintf("r%d", i)
+		}
+	}
+	return fields
+}
+
+// typeString prints a type expression, qualifying the exported types of package reaper.
+func typeString(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if ast.IsExported(t.Name) {
+			return "reaper." + t.Name
+		}
+		return t.Name
+	case *ast.SelectorExpr:
+		return typeString(t.X.(*ast.Ident)) + "." + t.Sel.Name
+	case *ast.StarExpr:
+		return "*" + typeString(t.X)
+	case *ast.ArrayType:
+		return "[]" + typeString(t.Elt)
+	case *ast.MapType:
+		return "map[" + typeString(t.Key) + "]" + typeString(t.Value)
+	case *ast.ChanType:
+		switch t.Dir {
+		case ast.RECV:
+			return "<-chan " + typeString(t.Value)
+		case ast.SEND:
+			return "chan<- " + typeString(t.Value)
+		}
+		return "chan " + typeString(t.Value)
+	case *ast.InterfaceType:
+		return "interface{}"
+	case *ast.IndexExpr:
+		return typeString(t.X) + "[" + typeString(t.Index) + "]"
+	case *ast.IndexListExpr:
+		var indices []string
+		for _, index := range t.Indices {
+			indices = append(indices, typeString(index))
+		}
+		return typeString(t.X) + "[" + strings.Join(indices, ", ") + "]"
+	}
+	panic(fmt.Sprintf("unsupported type expression %T", expr))
+}
+
+func zeroValue(typ string) string {
+	switch {
+	case strings.HasPrefix(typ, "<-chan "):
+		return "closedChan[" + strings.TrimPrefix(typ, "<-chan ") + "]()"
+	case strings.HasPrefix(typ, "iter.Seq2[") && strings.HasSuffix(typ, ", error]"):
+		return "unexpectedSeq2[" + strings.TrimSuffix(strings.TrimPrefix(typ, "iter.Seq2["), ", error]") + "]()"
+	case typ == "error":
+		return "ErrUnexpectedCall"
+	}
+	return "*new(" + typ + ")"
+}
+
+var mockTemplate = template.Must(template.New("mock").Funcs(template.FuncMap{"zero": zeroValue}).Parse(`// Code generated by reapermock/gen.go from reaper/client.go. DO NOT EDIT.
+
+package reapermock
+
+import (
+	"context"
+	"iter"
+
+	"github.com/google/uuid"
+	"github.com/k8ssandra/reaper-client-go/reaper"
+)
+{{range .}}
+// {{.Name}} implements reaper.Client.
+func (m *Mock) {{.Name}}({{.ParamList}}) {{.ResultList}} {
+	e := m.called("{{.Name}}"{{range .ArgTypes}}, {{.Name}}{{end}})
+	if e == nil {
+		return {{range $i, $r := .Results}}{{if $i}}, {{end}}{{zero $r.Type}}{{end}}
+	}
+	if e.run != nil {
+		return e.run.({{.Signature}})({{.CallList}})
+	}
+	{{range .Results}}var {{.Name}} {{.Type}}
+	{{end -}}
+	if e.results != nil {
+		{{range $i, $r := .Results}}{{$r.Name}}, _ = e.results[{{$i}}].({{$r.Type}})
+		{{end -}}
+	}
+	return {{range $i, $r := .Results}}{{if $i}}, {{end}}{{$r.Name}}{{end}}
+}
+
+// {{.Name}}Expectation is an expectation on calls to {{.Name}}.
+type {{.Name}}Expectation struct {
+	e *expectation
+}
+
+// On{{.Name}} adds an expectation on calls to {{.Name}}. It matches calls with any arguments, unless With is used.
+func (m *Mock) On{{.Name}}() *{{.Name}}Expectation {
+	return &{{.Name}}Expectation{m.expect("{{.Name}}")}
+}
+
+{{if .ArgTypes}}// With restricts the expectation to calls with the given arguments, excluding the context.
+func (x *{{.Name}}Expectation) With({{range $i, $p := .ArgTypes}}{{if $i}}, {{end}}{{$p.Name}} {{$p.Type}}{{end}}) *{{.Name}}Expectation {
+	x.e.args = []interface{}{ {{.ArgList}} }
+	return x
+}
+
+{{end}}// Return sets the values returned by matching calls.
+func (x *{{.Name}}Expectation) Return({{.NamedResultList}}) *{{.Name}}Expectation {
+	x.e.results = []interface{}{ {{range $i, $r := .Results}}{{if $i}}, {{end}}{{$r.Name}}{{end}} }
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *{{.Name}}Expectation) Run(fn {{.Signature}}) *{{.Name}}Expectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *{{.Name}}Expectation) Times(n int) *{{.Name}}Expectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *{{.Name}}Expectation) Once() *{{.Name}}Expectation {
+	return x.Times(1)
+}
+{{end}}`))
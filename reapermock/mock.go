@@ -0,0 +1,182 @@
+// Package reapermock provides a programmable mock of reaper.Client.
+//
+// Expectations are set with the typed On<Method> functions, and matched in the order they were added:
+//
+//	client := reapermock.NewMock(t)
+//	client.OnRepairRun().With(runId).Return(&reaper.RepairRun{Id: runId, State: reaper.RepairRunStateDone}, nil)
+//	client.OnStartRepairRun().Return(nil).Once()
+//	...
+//	client.AssertExpectations(t)
+//
+// Calls without a matching expectation are reported to the test and return zero values along with
+// ErrUnexpectedCall. All calls are recorded and can be inspected with Calls.
+//
+// The methods of Mock are generated from the declaration of reaper.Client, so that the mock never drifts from the
+// interface; run go generate after changing the interface.
+package reapermock
+
+//go:generate go run gen.go
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/k8ssandra/reaper-client-go/reaper"
+)
+
+var _ reaper.Client = (*Mock)(nil)
+
+// ErrUnexpectedCall is returned by calls that match no expectation.
+var ErrUnexpectedCall = errors.New("unexpected call")
+
+// TestingT is the subset of testing.TB used by Mock.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// Call is a recorded call to the mock. Args excludes the context.
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+func (c Call) String() string {
+	args := make([]string, 0, len(c.Args))
+	for _, arg := range c.Args {
+		args = append(args, fmt.Sprintf("%v", arg))
+	}
+	return fmt.Sprintf("%s(%s)", c.Method, strings.Join(args, ", "))
+}
+
+type Mock struct {
+	t            TestingT
+	mu           sync.Mutex
+	expectations []*expectation
+	calls        []Call
+}
+
+type expectation struct {
+	method  string
+	args    []interface{}
+	results []interface{}
+	run     interface{}
+	times   int
+	calls   int
+}
+
+// NewMock returns a mock without any expectation. Unexpected calls are reported to t; if t is nil, they panic.
+func NewMock(t TestingT) *Mock {
+	return &Mock{t: t}
+}
+
+func (m *Mock) expect(method string) *expectation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := &expectation{method: method}
+	m.expectations = append(m.expectations, e)
+	return e
+}
+
+// called records a call and returns the first expectation matching it, or nil if there is none.
+func (m *Mock) called(method string, args ...interface{}) *expectation {
+	m.mu.Lock()
+	call := Call{Method: method, Args: args}
+	m.calls = append(m.calls, call)
+	for _, e := range m.expectations {
+		if e.matches(method, args) {
+			e.calls++
+			m.mu.Unlock()
+			return e
+		}
+	}
+	m.mu.Unlock()
+	if m.t == nil {
+		panic(fmt.Sprintf("reapermock: unexpected call to %v", call))
+	}
+	m.t.Helper()
+	m.t.Errorf("reapermock: unexpected call to %v", call)
+	return nil
+}
+
+func (e *expectation) matches(method string, args []interface{}) bool {
+	if e.method != method || (e.times > 0 && e.calls >= e.times) {
+		return false
+	}
+	return e.args == nil || reflect.DeepEqual(e.args, args)
+}
+
+// Calls returns all the calls recorded so far, in order.
+func (m *Mock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallCount returns how many times the given method was called.
+func (m *Mock) CallCount(method string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	count := 0
+	for _, call := range m.calls {
+		if call.Method == method {
+			count++
+		}
+	}
+	return count
+}
+
+// AssertExpectations checks that every expectation was matched at least once, or exactly n times if Times(n) was
+// used. Returns false and reports to t otherwise.
+func (m *Mock) AssertExpectations(t TestingT) bool {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ok := true
+	for _, e := range m.expectations {
+		if e.calls == 0 || (e.times > 0 && e.calls < e.times) {
+			expected := "at least once"
+			if e.times > 0 {
+				expected = fmt.Sprintf("%d time(s)", e.times)
+			}
+			what := Call{Method: e.method, Args: e.args}.String()
+			if e.args == nil {
+				what = e.method + "(*)"
+			}
+			t.Errorf("reapermock: expected %s to be called %s, but it was called %d time(s)", what, expected, e.calls)
+			ok = false
+		}
+	}
+	return ok
+}
+
+func closedChan[T any]() <-chan T {
+	c := make(chan T)
+	close(c)
+	return c
+}
+
+// unexpectedSeq2 returns a sequence yielding ErrUnexpectedCall, for iterator methods without a matching expectation.
+func unexpectedSeq2[T any]() iter.Seq2[T, error] {
+	return Sequence[T](nil, ErrUnexpectedCall)
+}
+
+// Sequence returns a sequence yielding values, then err if it is not nil, for use with the Return method of the
+// expectations of iterator methods such as AllRepairRuns.
+func Sequence[T any](values []T, err error) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for _, v := range values {
+			if !yield(v, nil) {
+				return
+			}
+		}
+		if err != nil {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
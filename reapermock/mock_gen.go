@@ -0,0 +1,2093 @@
+// Code generated by reapermock/gen.go from reaper/client.go. DO NOT EDIT.
+
+package reapermock
+
+import (
+	"context"
+	"iter"
+
+	"github.com/google/uuid"
+	"github.com/k8ssandra/reaper-client-go/reaper"
+)
+
+// IsReaperUp implements reaper.Client.
+func (m *Mock) IsReaperUp(ctx context.Context) (bool, error) {
+	e := m.called("IsReaperUp")
+	if e == nil {
+		return *new(bool), ErrUnexpectedCall
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context) (bool, error))(ctx)
+	}
+	var r0 bool
+	var r1 error
+	if e.results != nil {
+		r0, _ = e.results[0].(bool)
+		r1, _ = e.results[1].(error)
+	}
+	return r0, r1
+}
+
+// IsReaperUpExpectation is an expectation on calls to IsReaperUp.
+type IsReaperUpExpectation struct {
+	e *expectation
+}
+
+// OnIsReaperUp adds an expectation on calls to IsReaperUp. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnIsReaperUp() *IsReaperUpExpectation {
+	return &IsReaperUpExpectation{m.expect("IsReaperUp")}
+}
+
+// Return sets the values returned by matching calls.
+func (x *IsReaperUpExpectation) Return(r0 bool, r1 error) *IsReaperUpExpectation {
+	x.e.results = []interface{}{r0, r1}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *IsReaperUpExpectation) Run(fn func(context.Context) (bool, error)) *IsReaperUpExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *IsReaperUpExpectation) Times(n int) *IsReaperUpExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *IsReaperUpExpectation) Once() *IsReaperUpExpectation {
+	return x.Times(1)
+}
+
+// GetClusterNames implements reaper.Client.
+func (m *Mock) GetClusterNames(ctx context.Context) ([]string, error) {
+	e := m.called("GetClusterNames")
+	if e == nil {
+		return *new([]string), ErrUnexpectedCall
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context) ([]string, error))(ctx)
+	}
+	var r0 []string
+	var r1 error
+	if e.results != nil {
+		r0, _ = e.results[0].([]string)
+		r1, _ = e.results[1].(error)
+	}
+	return r0, r1
+}
+
+// GetClusterNamesExpectation is an expectation on calls to GetClusterNames.
+type GetClusterNamesExpectation struct {
+	e *expectation
+}
+
+// OnGetClusterNames adds an expectation on calls to GetClusterNames. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnGetClusterNames() *GetClusterNamesExpectation {
+	return &GetClusterNamesExpectation{m.expect("GetClusterNames")}
+}
+
+// Return sets the values returned by matching calls.
+func (x *GetClusterNamesExpectation) Return(r0 []string, r1 error) *GetClusterNamesExpectation {
+	x.e.results = []interface{}{r0, r1}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *GetClusterNamesExpectation) Run(fn func(context.Context) ([]string, error)) *GetClusterNamesExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *GetClusterNamesExpectation) Times(n int) *GetClusterNamesExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *GetClusterNamesExpectation) Once() *GetClusterNamesExpectation {
+	return x.Times(1)
+}
+
+// GetCluster implements reaper.Client.
+func (m *Mock) GetCluster(ctx context.Context, name string) (*reaper.Cluster, error) {
+	e := m.called("GetCluster", name)
+	if e == nil {
+		return *new(*reaper.Cluster), ErrUnexpectedCall
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context, string) (*reaper.Cluster, error))(ctx, name)
+	}
+	var r0 *reaper.Cluster
+	var r1 error
+	if e.results != nil {
+		r0, _ = e.results[0].(*reaper.Cluster)
+		r1, _ = e.results[1].(error)
+	}
+	return r0, r1
+}
+
+// GetClusterExpectation is an expectation on calls to GetCluster.
+type GetClusterExpectation struct {
+	e *expectation
+}
+
+// OnGetCluster adds an expectation on calls to GetCluster. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnGetCluster() *GetClusterExpectation {
+	return &GetClusterExpectation{m.expect("GetCluster")}
+}
+
+// With restricts the expectation to calls with the given arguments, excluding the context.
+func (x *GetClusterExpectation) With(name string) *GetClusterExpectation {
+	x.e.args = []interface{}{name}
+	return x
+}
+
+// Return sets the values returned by matching calls.
+func (x *GetClusterExpectation) Return(r0 *reaper.Cluster, r1 error) *GetClusterExpectation {
+	x.e.results = []interface{}{r0, r1}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *GetClusterExpectation) Run(fn func(context.Context, string) (*reaper.Cluster, error)) *GetClusterExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *GetClusterExpectation) Times(n int) *GetClusterExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *GetClusterExpectation) Once() *GetClusterExpectation {
+	return x.Times(1)
+}
+
+// GetClusters implements reaper.Client.
+func (m *Mock) GetClusters(ctx context.Context) <-chan reaper.GetClusterResult {
+	e := m.called("GetClusters")
+	if e == nil {
+		return closedChan[reaper.GetClusterResult]()
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context) <-chan reaper.GetClusterResult)(ctx)
+	}
+	var r0 <-chan reaper.GetClusterResult
+	if e.results != nil {
+		r0, _ = e.results[0].(<-chan reaper.GetClusterResult)
+	}
+	return r0
+}
+
+// GetClustersExpectation is an expectation on calls to GetClusters.
+type GetClustersExpectation struct {
+	e *expectation
+}
+
+// OnGetClusters adds an expectation on calls to GetClusters. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnGetClusters() *GetClustersExpectation {
+	return &GetClustersExpectation{m.expect("GetClusters")}
+}
+
+// Return sets the values returned by matching calls.
+func (x *GetClustersExpectation) Return(r0 <-chan reaper.GetClusterResult) *GetClustersExpectation {
+	x.e.results = []interface{}{r0}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *GetClustersExpectation) Run(fn func(context.Context) <-chan reaper.GetClusterResult) *GetClustersExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *GetClustersExpectation) Times(n int) *GetClustersExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *GetClustersExpectation) Once() *GetClustersExpectation {
+	return x.Times(1)
+}
+
+// GetClustersSync implements reaper.Client.
+func (m *Mock) GetClustersSync(ctx context.Context) ([]*reaper.Cluster, error) {
+	e := m.called("GetClustersSync")
+	if e == nil {
+		return *new([]*reaper.Cluster), ErrUnexpectedCall
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context) ([]*reaper.Cluster, error))(ctx)
+	}
+	var r0 []*reaper.Cluster
+	var r1 error
+	if e.results != nil {
+		r0, _ = e.results[0].([]*reaper.Cluster)
+		r1, _ = e.results[1].(error)
+	}
+	return r0, r1
+}
+
+// GetClustersSyncExpectation is an expectation on calls to GetClustersSync.
+type GetClustersSyncExpectation struct {
+	e *expectation
+}
+
+// OnGetClustersSync adds an expectation on calls to GetClustersSync. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnGetClustersSync() *GetClustersSyncExpectation {
+	return &GetClustersSyncExpectation{m.expect("GetClustersSync")}
+}
+
+// Return sets the values returned by matching calls.
+func (x *GetClustersSyncExpectation) Return(r0 []*reaper.Cluster, r1 error) *GetClustersSyncExpectation {
+	x.e.results = []interface{}{r0, r1}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *GetClustersSyncExpectation) Run(fn func(context.Context) ([]*reaper.Cluster, error)) *GetClustersSyncExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *GetClustersSyncExpectation) Times(n int) *GetClustersSyncExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *GetClustersSyncExpectation) Once() *GetClustersSyncExpectation {
+	return x.Times(1)
+}
+
+// AddCluster implements reaper.Client.
+func (m *Mock) AddCluster(ctx context.Context, cluster string, seed string) error {
+	e := m.called("AddCluster", cluster, seed)
+	if e == nil {
+		return ErrUnexpectedCall
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context, string, string) error)(ctx, cluster, seed)
+	}
+	var r0 error
+	if e.results != nil {
+		r0, _ = e.results[0].(error)
+	}
+	return r0
+}
+
+// AddClusterExpectation is an expectation on calls to AddCluster.
+type AddClusterExpectation struct {
+	e *expectation
+}
+
+// OnAddCluster adds an expectation on calls to AddCluster. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnAddCluster() *AddClusterExpectation {
+	return &AddClusterExpectation{m.expect("AddCluster")}
+}
+
+// With restricts the expectation to calls with the given arguments, excluding the context.
+func (x *AddClusterExpectation) With(cluster string, seed string) *AddClusterExpectation {
+	x.e.args = []interface{}{cluster, seed}
+	return x
+}
+
+// Return sets the values returned by matching calls.
+func (x *AddClusterExpectation) Return(r0 error) *AddClusterExpectation {
+	x.e.results = []interface{}{r0}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *AddClusterExpectation) Run(fn func(context.Context, string, string) error) *AddClusterExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *AddClusterExpectation) Times(n int) *AddClusterExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *AddClusterExpectation) Once() *AddClusterExpectation {
+	return x.Times(1)
+}
+
+// DeleteCluster implements reaper.Client.
+func (m *Mock) DeleteCluster(ctx context.Context, cluster string) error {
+	e := m.called("DeleteCluster", cluster)
+	if e == nil {
+		return ErrUnexpectedCall
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context, string) error)(ctx, cluster)
+	}
+	var r0 error
+	if e.results != nil {
+		r0, _ = e.results[0].(error)
+	}
+	return r0
+}
+
+// DeleteClusterExpectation is an expectation on calls to DeleteCluster.
+type DeleteClusterExpectation struct {
+	e *expectation
+}
+
+// OnDeleteCluster adds an expectation on calls to DeleteCluster. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnDeleteCluster() *DeleteClusterExpectation {
+	return &DeleteClusterExpectation{m.expect("DeleteCluster")}
+}
+
+// With restricts the expectation to calls with the given arguments, excluding the context.
+func (x *DeleteClusterExpectation) With(cluster string) *DeleteClusterExpectation {
+	x.e.args = []interface{}{cluster}
+	return x
+}
+
+// Return sets the values returned by matching calls.
+func (x *DeleteClusterExpectation) Return(r0 error) *DeleteClusterExpectation {
+	x.e.results = []interface{}{r0}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *DeleteClusterExpectation) Run(fn func(context.Context, string) error) *DeleteClusterExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *DeleteClusterExpectation) Times(n int) *DeleteClusterExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *DeleteClusterExpectation) Once() *DeleteClusterExpectation {
+	return x.Times(1)
+}
+
+// AllClusters implements reaper.Client.
+func (m *Mock) AllClusters(ctx context.Context) iter.Seq2[*reaper.Cluster, error] {
+	e := m.called("AllClusters")
+	if e == nil {
+		return unexpectedSeq2[*reaper.Cluster]()
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context) iter.Seq2[*reaper.Cluster, error])(ctx)
+	}
+	var r0 iter.Seq2[*reaper.Cluster, error]
+	if e.results != nil {
+		r0, _ = e.results[0].(iter.Seq2[*reaper.Cluster, error])
+	}
+	return r0
+}
+
+// AllClustersExpectation is an expectation on calls to AllClusters.
+type AllClustersExpectation struct {
+	e *expectation
+}
+
+// OnAllClusters adds an expectation on calls to AllClusters. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnAllClusters() *AllClustersExpectation {
+	return &AllClustersExpectation{m.expect("AllClusters")}
+}
+
+// Return sets the values returned by matching calls.
+func (x *AllClustersExpectation) Return(r0 iter.Seq2[*reaper.Cluster, error]) *AllClustersExpectation {
+	x.e.results = []interface{}{r0}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *AllClustersExpectation) Run(fn func(context.Context) iter.Seq2[*reaper.Cluster, error]) *AllClustersExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *AllClustersExpectation) Times(n int) *AllClustersExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *AllClustersExpectation) Once() *AllClustersExpectation {
+	return x.Times(1)
+}
+
+// ClusterSchema implements reaper.Client.
+func (m *Mock) ClusterSchema(ctx context.Context, cluster string) (reaper.ClusterSchema, error) {
+	e := m.called("ClusterSchema", cluster)
+	if e == nil {
+		return *new(reaper.ClusterSchema), ErrUnexpectedCall
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context, string) (reaper.ClusterSchema, error))(ctx, cluster)
+	}
+	var r0 reaper.ClusterSchema
+	var r1 error
+	if e.results != nil {
+		r0, _ = e.results[0].(reaper.ClusterSchema)
+		r1, _ = e.results[1].(error)
+	}
+	return r0, r1
+}
+
+// ClusterSchemaExpectation is an expectation on calls to ClusterSchema.
+type ClusterSchemaExpectation struct {
+	e *expectation
+}
+
+// OnClusterSchema adds an expectation on calls to ClusterSchema. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnClusterSchema() *ClusterSchemaExpectation {
+	return &ClusterSchemaExpectation{m.expect("ClusterSchema")}
+}
+
+// With restricts the expectation to calls with the given arguments, excluding the context.
+func (x *ClusterSchemaExpectation) With(cluster string) *ClusterSchemaExpectation {
+	x.e.args = []interface{}{cluster}
+	return x
+}
+
+// Return sets the values returned by matching calls.
+func (x *ClusterSchemaExpectation) Return(r0 reaper.ClusterSchema, r1 error) *ClusterSchemaExpectation {
+	x.e.results = []interface{}{r0, r1}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *ClusterSchemaExpectation) Run(fn func(context.Context, string) (reaper.ClusterSchema, error)) *ClusterSchemaExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *ClusterSchemaExpectation) Times(n int) *ClusterSchemaExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *ClusterSchemaExpectation) Once() *ClusterSchemaExpectation {
+	return x.Times(1)
+}
+
+// RepairRuns implements reaper.Client.
+func (m *Mock) RepairRuns(ctx context.Context, searchOptions *reaper.RepairRunSearchOptions) (map[uuid.UUID]*reaper.RepairRun, error) {
+	e := m.called("RepairRuns", searchOptions)
+	if e == nil {
+		return *new(map[uuid.UUID]*reaper.RepairRun), ErrUnexpectedCall
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context, *reaper.RepairRunSearchOptions) (map[uuid.UUID]*reaper.RepairRun, error))(ctx, searchOptions)
+	}
+	var r0 map[uuid.UUID]*reaper.RepairRun
+	var r1 error
+	if e.results != nil {
+		r0, _ = e.results[0].(map[uuid.UUID]*reaper.RepairRun)
+		r1, _ = e.results[1].(error)
+	}
+	return r0, r1
+}
+
+// RepairRunsExpectation is an expectation on calls to RepairRuns.
+type RepairRunsExpectation struct {
+	e *expectation
+}
+
+// OnRepairRuns adds an expectation on calls to RepairRuns. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnRepairRuns() *RepairRunsExpectation {
+	return &RepairRunsExpectation{m.expect("RepairRuns")}
+}
+
+// With restricts the expectation to calls with the given arguments, excluding the context.
+func (x *RepairRunsExpectation) With(searchOptions *reaper.RepairRunSearchOptions) *RepairRunsExpectation {
+	x.e.args = []interface{}{searchOptions}
+	return x
+}
+
+// Return sets the values returned by matching calls.
+func (x *RepairRunsExpectation) Return(r0 map[uuid.UUID]*reaper.RepairRun, r1 error) *RepairRunsExpectation {
+	x.e.results = []interface{}{r0, r1}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *RepairRunsExpectation) Run(fn func(context.Context, *reaper.RepairRunSearchOptions) (map[uuid.UUID]*reaper.RepairRun, error)) *RepairRunsExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *RepairRunsExpectation) Times(n int) *RepairRunsExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *RepairRunsExpectation) Once() *RepairRunsExpectation {
+	return x.Times(1)
+}
+
+// AllRepairRuns implements reaper.Client.
+func (m *Mock) AllRepairRuns(ctx context.Context, searchOptions *reaper.RepairRunSearchOptions) iter.Seq2[*reaper.RepairRun, error] {
+	e := m.called("AllRepairRuns", searchOptions)
+	if e == nil {
+		return unexpectedSeq2[*reaper.RepairRun]()
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context, *reaper.RepairRunSearchOptions) iter.Seq2[*reaper.RepairRun, error])(ctx, searchOptions)
+	}
+	var r0 iter.Seq2[*reaper.RepairRun, error]
+	if e.results != nil {
+		r0, _ = e.results[0].(iter.Seq2[*reaper.RepairRun, error])
+	}
+	return r0
+}
+
+// AllRepairRunsExpectation is an expectation on calls to AllRepairRuns.
+type AllRepairRunsExpectation struct {
+	e *expectation
+}
+
+// OnAllRepairRuns adds an expectation on calls to AllRepairRuns. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnAllRepairRuns() *AllRepairRunsExpectation {
+	return &AllRepairRunsExpectation{m.expect("AllRepairRuns")}
+}
+
+// With restricts the expectation to calls with the given arguments, excluding the context.
+func (x *AllRepairRunsExpectation) With(searchOptions *reaper.RepairRunSearchOptions) *AllRepairRunsExpectation {
+	x.e.args = []interface{}{searchOptions}
+	return x
+}
+
+// Return sets the values returned by matching calls.
+func (x *AllRepairRunsExpectation) Return(r0 iter.Seq2[*reaper.RepairRun, error]) *AllRepairRunsExpectation {
+	x.e.results = []interface{}{r0}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *AllRepairRunsExpectation) Run(fn func(context.Context, *reaper.RepairRunSearchOptions) iter.Seq2[*reaper.RepairRun, error]) *AllRepairRunsExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *AllRepairRunsExpectation) Times(n int) *AllRepairRunsExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *AllRepairRunsExpectation) Once() *AllRepairRunsExpectation {
+	return x.Times(1)
+}
+
+// RepairRun implements reaper.Client.
+func (m *Mock) RepairRun(ctx context.Context, repairRunId uuid.UUID) (*reaper.RepairRun, error) {
+	e := m.called("RepairRun", repairRunId)
+	if e == nil {
+		return *new(*reaper.RepairRun), ErrUnexpectedCall
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context, uuid.UUID) (*reaper.RepairRun, error))(ctx, repairRunId)
+	}
+	var r0 *reaper.RepairRun
+	var r1 error
+	if e.results != nil {
+		r0, _ = e.results[0].(*reaper.RepairRun)
+		r1, _ = e.results[1].(error)
+	}
+	return r0, r1
+}
+
+// RepairRunExpectation is an expectation on calls to RepairRun.
+type RepairRunExpectation struct {
+	e *expectation
+}
+
+// OnRepairRun adds an expectation on calls to RepairRun. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnRepairRun() *RepairRunExpectation {
+	return &RepairRunExpectation{m.expect("RepairRun")}
+}
+
+// With restricts the expectation to calls with the given arguments, excluding the context.
+func (x *RepairRunExpectation) With(repairRunId uuid.UUID) *RepairRunExpectation {
+	x.e.args = []interface{}{repairRunId}
+	return x
+}
+
+// Return sets the values returned by matching calls.
+func (x *RepairRunExpectation) Return(r0 *reaper.RepairRun, r1 error) *RepairRunExpectation {
+	x.e.results = []interface{}{r0, r1}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *RepairRunExpectation) Run(fn func(context.Context, uuid.UUID) (*reaper.RepairRun, error)) *RepairRunExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *RepairRunExpectation) Times(n int) *RepairRunExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *RepairRunExpectation) Once() *RepairRunExpectation {
+	return x.Times(1)
+}
+
+// CreateRepairRun implements reaper.Client.
+func (m *Mock) CreateRepairRun(ctx context.Context, cluster string, keyspace string, owner string, options *reaper.RepairRunCreateOptions) (uuid.UUID, error) {
+	e := m.called("CreateRepairRun", cluster, keyspace, owner, options)
+	if e == nil {
+		return *new(uuid.UUID), ErrUnexpectedCall
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context, string, string, string, *reaper.RepairRunCreateOptions) (uuid.UUID, error))(ctx, cluster, keyspace, owner, options)
+	}
+	var r0 uuid.UUID
+	var r1 error
+	if e.results != nil {
+		r0, _ = e.results[0].(uuid.UUID)
+		r1, _ = e.results[1].(error)
+	}
+	return r0, r1
+}
+
+// CreateRepairRunExpectation is an expectation on calls to CreateRepairRun.
+type CreateRepairRunExpectation struct {
+	e *expectation
+}
+
+// OnCreateRepairRun adds an expectation on calls to CreateRepairRun. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnCreateRepairRun() *CreateRepairRunExpectation {
+	return &CreateRepairRunExpectation{m.expect("CreateRepairRun")}
+}
+
+// With restricts the expectation to calls with the given arguments, excluding the context.
+func (x *CreateRepairRunExpectation) With(cluster string, keyspace string, owner string, options *reaper.RepairRunCreateOptions) *CreateRepairRunExpectation {
+	x.e.args = []interface{}{cluster, keyspace, owner, options}
+	return x
+}
+
+// Return sets the values returned by matching calls.
+func (x *CreateRepairRunExpectation) Return(r0 uuid.UUID, r1 error) *CreateRepairRunExpectation {
+	x.e.results = []interface{}{r0, r1}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *CreateRepairRunExpectation) Run(fn func(context.Context, string, string, string, *reaper.RepairRunCreateOptions) (uuid.UUID, error)) *CreateRepairRunExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *CreateRepairRunExpectation) Times(n int) *CreateRepairRunExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *CreateRepairRunExpectation) Once() *CreateRepairRunExpectation {
+	return x.Times(1)
+}
+
+// UpdateRepairRun implements reaper.Client.
+func (m *Mock) UpdateRepairRun(ctx context.Context, repairRunId uuid.UUID, newIntensity reaper.Intensity) error {
+	e := m.called("UpdateRepairRun", repairRunId, newIntensity)
+	if e == nil {
+		return ErrUnexpectedCall
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context, uuid.UUID, reaper.Intensity) error)(ctx, repairRunId, newIntensity)
+	}
+	var r0 error
+	if e.results != nil {
+		r0, _ = e.results[0].(error)
+	}
+	return r0
+}
+
+// UpdateRepairRunExpectation is an expectation on calls to UpdateRepairRun.
+type UpdateRepairRunExpectation struct {
+	e *expectation
+}
+
+// OnUpdateRepairRun adds an expectation on calls to UpdateRepairRun. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnUpdateRepairRun() *UpdateRepairRunExpectation {
+	return &UpdateRepairRunExpectation{m.expect("UpdateRepairRun")}
+}
+
+// With restricts the expectation to calls with the given arguments, excluding the context.
+func (x *UpdateRepairRunExpectation) With(repairRunId uuid.UUID, newIntensity reaper.Intensity) *UpdateRepairRunExpectation {
+	x.e.args = []interface{}{repairRunId, newIntensity}
+	return x
+}
+
+// Return sets the values returned by matching calls.
+func (x *UpdateRepairRunExpectation) Return(r0 error) *UpdateRepairRunExpectation {
+	x.e.results = []interface{}{r0}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *UpdateRepairRunExpectation) Run(fn func(context.Context, uuid.UUID, reaper.Intensity) error) *UpdateRepairRunExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *UpdateRepairRunExpectation) Times(n int) *UpdateRepairRunExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *UpdateRepairRunExpectation) Once() *UpdateRepairRunExpectation {
+	return x.Times(1)
+}
+
+// StartRepairRun implements reaper.Client.
+func (m *Mock) StartRepairRun(ctx context.Context, repairRunId uuid.UUID) error {
+	e := m.called("StartRepairRun", repairRunId)
+	if e == nil {
+		return ErrUnexpectedCall
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context, uuid.UUID) error)(ctx, repairRunId)
+	}
+	var r0 error
+	if e.results != nil {
+		r0, _ = e.results[0].(error)
+	}
+	return r0
+}
+
+// StartRepairRunExpectation is an expectation on calls to StartRepairRun.
+type StartRepairRunExpectation struct {
+	e *expectation
+}
+
+// OnStartRepairRun adds an expectation on calls to StartRepairRun. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnStartRepairRun() *StartRepairRunExpectation {
+	return &StartRepairRunExpectation{m.expect("StartRepairRun")}
+}
+
+// With restricts the expectation to calls with the given arguments, excluding the context.
+func (x *StartRepairRunExpectation) With(repairRunId uuid.UUID) *StartRepairRunExpectation {
+	x.e.args = []interface{}{repairRunId}
+	return x
+}
+
+// Return sets the values returned by matching calls.
+func (x *StartRepairRunExpectation) Return(r0 error) *StartRepairRunExpectation {
+	x.e.results = []interface{}{r0}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *StartRepairRunExpectation) Run(fn func(context.Context, uuid.UUID) error) *StartRepairRunExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *StartRepairRunExpectation) Times(n int) *StartRepairRunExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *StartRepairRunExpectation) Once() *StartRepairRunExpectation {
+	return x.Times(1)
+}
+
+// PauseRepairRun implements reaper.Client.
+func (m *Mock) PauseRepairRun(ctx context.Context, repairRunId uuid.UUID) error {
+	e := m.called("PauseRepairRun", repairRunId)
+	if e == nil {
+		return ErrUnexpectedCall
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context, uuid.UUID) error)(ctx, repairRunId)
+	}
+	var r0 error
+	if e.results != nil {
+		r0, _ = e.results[0].(error)
+	}
+	return r0
+}
+
+// PauseRepairRunExpectation is an expectation on calls to PauseRepairRun.
+type PauseRepairRunExpectation struct {
+	e *expectation
+}
+
+// OnPauseRepairRun adds an expectation on calls to PauseRepairRun. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnPauseRepairRun() *PauseRepairRunExpectation {
+	return &PauseRepairRunExpectation{m.expect("PauseRepairRun")}
+}
+
+// With restricts the expectation to calls with the given arguments, excluding the context.
+func (x *PauseRepairRunExpectation) With(repairRunId uuid.UUID) *PauseRepairRunExpectation {
+	x.e.args = []interface{}{repairRunId}
+	return x
+}
+
+// Return sets the values returned by matching calls.
+func (x *PauseRepairRunExpectation) Return(r0 error) *PauseRepairRunExpectation {
+	x.e.results = []interface{}{r0}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *PauseRepairRunExpectation) Run(fn func(context.Context, uuid.UUID) error) *PauseRepairRunExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *PauseRepairRunExpectation) Times(n int) *PauseRepairRunExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *PauseRepairRunExpectation) Once() *PauseRepairRunExpectation {
+	return x.Times(1)
+}
+
+// ResumeRepairRun implements reaper.Client.
+func (m *Mock) ResumeRepairRun(ctx context.Context, repairRunId uuid.UUID) error {
+	e := m.called("ResumeRepairRun", repairRunId)
+	if e == nil {
+		return ErrUnexpectedCall
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context, uuid.UUID) error)(ctx, repairRunId)
+	}
+	var r0 error
+	if e.results != nil {
+		r0, _ = e.results[0].(error)
+	}
+	return r0
+}
+
+// ResumeRepairRunExpectation is an expectation on calls to ResumeRepairRun.
+type ResumeRepairRunExpectation struct {
+	e *expectation
+}
+
+// OnResumeRepairRun adds an expectation on calls to ResumeRepairRun. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnResumeRepairRun() *ResumeRepairRunExpectation {
+	return &ResumeRepairRunExpectation{m.expect("ResumeRepairRun")}
+}
+
+// With restricts the expectation to calls with the given arguments, excluding the context.
+func (x *ResumeRepairRunExpectation) With(repairRunId uuid.UUID) *ResumeRepairRunExpectation {
+	x.e.args = []interface{}{repairRunId}
+	return x
+}
+
+// Return sets the values returned by matching calls.
+func (x *ResumeRepairRunExpectation) Return(r0 error) *ResumeRepairRunExpectation {
+	x.e.results = []interface{}{r0}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *ResumeRepairRunExpectation) Run(fn func(context.Context, uuid.UUID) error) *ResumeRepairRunExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *ResumeRepairRunExpectation) Times(n int) *ResumeRepairRunExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *ResumeRepairRunExpectation) Once() *ResumeRepairRunExpectation {
+	return x.Times(1)
+}
+
+// AbortRepairRun implements reaper.Client.
+func (m *Mock) AbortRepairRun(ctx context.Context, repairRunId uuid.UUID) error {
+	e := m.called("AbortRepairRun", repairRunId)
+	if e == nil {
+		return ErrUnexpectedCall
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context, uuid.UUID) error)(ctx, repairRunId)
+	}
+	var r0 error
+	if e.results != nil {
+		r0, _ = e.results[0].(error)
+	}
+	return r0
+}
+
+// AbortRepairRunExpectation is an expectation on calls to AbortRepairRun.
+type AbortRepairRunExpectation struct {
+	e *expectation
+}
+
+// OnAbortRepairRun adds an expectation on calls to AbortRepairRun. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnAbortRepairRun() *AbortRepairRunExpectation {
+	return &AbortRepairRunExpectation{m.expect("AbortRepairRun")}
+}
+
+// With restricts the expectation to calls with the given arguments, excluding the context.
+func (x *AbortRepairRunExpectation) With(repairRunId uuid.UUID) *AbortRepairRunExpectation {
+	x.e.args = []interface{}{repairRunId}
+	return x
+}
+
+// Return sets the values returned by matching calls.
+func (x *AbortRepairRunExpectation) Return(r0 error) *AbortRepairRunExpectation {
+	x.e.results = []interface{}{r0}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *AbortRepairRunExpectation) Run(fn func(context.Context, uuid.UUID) error) *AbortRepairRunExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *AbortRepairRunExpectation) Times(n int) *AbortRepairRunExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *AbortRepairRunExpectation) Once() *AbortRepairRunExpectation {
+	return x.Times(1)
+}
+
+// RepairRunSegments implements reaper.Client.
+func (m *Mock) RepairRunSegments(ctx context.Context, repairRunId uuid.UUID) (map[uuid.UUID]*reaper.RepairSegment, error) {
+	e := m.called("RepairRunSegments", repairRunId)
+	if e == nil {
+		return *new(map[uuid.UUID]*reaper.RepairSegment), ErrUnexpectedCall
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context, uuid.UUID) (map[uuid.UUID]*reaper.RepairSegment, error))(ctx, repairRunId)
+	}
+	var r0 map[uuid.UUID]*reaper.RepairSegment
+	var r1 error
+	if e.results != nil {
+		r0, _ = e.results[0].(map[uuid.UUID]*reaper.RepairSegment)
+		r1, _ = e.results[1].(error)
+	}
+	return r0, r1
+}
+
+// RepairRunSegmentsExpectation is an expectation on calls to RepairRunSegments.
+type RepairRunSegmentsExpectation struct {
+	e *expectation
+}
+
+// OnRepairRunSegments adds an expectation on calls to RepairRunSegments. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnRepairRunSegments() *RepairRunSegmentsExpectation {
+	return &RepairRunSegmentsExpectation{m.expect("RepairRunSegments")}
+}
+
+// With restricts the expectation to calls with the given arguments, excluding the context.
+func (x *RepairRunSegmentsExpectation) With(repairRunId uuid.UUID) *RepairRunSegmentsExpectation {
+	x.e.args = []interface{}{repairRunId}
+	return x
+}
+
+// Return sets the values returned by matching calls.
+func (x *RepairRunSegmentsExpectation) Return(r0 map[uuid.UUID]*reaper.RepairSegment, r1 error) *RepairRunSegmentsExpectation {
+	x.e.results = []interface{}{r0, r1}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *RepairRunSegmentsExpectation) Run(fn func(context.Context, uuid.UUID) (map[uuid.UUID]*reaper.RepairSegment, error)) *RepairRunSegmentsExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *RepairRunSegmentsExpectation) Times(n int) *RepairRunSegmentsExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *RepairRunSegmentsExpectation) Once() *RepairRunSegmentsExpectation {
+	return x.Times(1)
+}
+
+// AllRepairRunSegments implements reaper.Client.
+func (m *Mock) AllRepairRunSegments(ctx context.Context, repairRunId uuid.UUID) iter.Seq2[*reaper.RepairSegment, error] {
+	e := m.called("AllRepairRunSegments", repairRunId)
+	if e == nil {
+		return unexpectedSeq2[*reaper.RepairSegment]()
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context, uuid.UUID) iter.Seq2[*reaper.RepairSegment, error])(ctx, repairRunId)
+	}
+	var r0 iter.Seq2[*reaper.RepairSegment, error]
+	if e.results != nil {
+		r0, _ = e.results[0].(iter.Seq2[*reaper.RepairSegment, error])
+	}
+	return r0
+}
+
+// AllRepairRunSegmentsExpectation is an expectation on calls to AllRepairRunSegments.
+type AllRepairRunSegmentsExpectation struct {
+	e *expectation
+}
+
+// OnAllRepairRunSegments adds an expectation on calls to AllRepairRunSegments. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnAllRepairRunSegments() *AllRepairRunSegmentsExpectation {
+	return &AllRepairRunSegmentsExpectation{m.expect("AllRepairRunSegments")}
+}
+
+// With restricts the expectation to calls with the given arguments, excluding the context.
+func (x *AllRepairRunSegmentsExpectation) With(repairRunId uuid.UUID) *AllRepairRunSegmentsExpectation {
+	x.e.args = []interface{}{repairRunId}
+	return x
+}
+
+// Return sets the values returned by matching calls.
+func (x *AllRepairRunSegmentsExpectation) Return(r0 iter.Seq2[*reaper.RepairSegment, error]) *AllRepairRunSegmentsExpectation {
+	x.e.results = []interface{}{r0}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *AllRepairRunSegmentsExpectation) Run(fn func(context.Context, uuid.UUID) iter.Seq2[*reaper.RepairSegment, error]) *AllRepairRunSegmentsExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *AllRepairRunSegmentsExpectation) Times(n int) *AllRepairRunSegmentsExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *AllRepairRunSegmentsExpectation) Once() *AllRepairRunSegmentsExpectation {
+	return x.Times(1)
+}
+
+// AbortRepairRunSegment implements reaper.Client.
+func (m *Mock) AbortRepairRunSegment(ctx context.Context, repairRunId uuid.UUID, segmentId uuid.UUID) error {
+	e := m.called("AbortRepairRunSegment", repairRunId, segmentId)
+	if e == nil {
+		return ErrUnexpectedCall
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context, uuid.UUID, uuid.UUID) error)(ctx, repairRunId, segmentId)
+	}
+	var r0 error
+	if e.results != nil {
+		r0, _ = e.results[0].(error)
+	}
+	return r0
+}
+
+// AbortRepairRunSegmentExpectation is an expectation on calls to AbortRepairRunSegment.
+type AbortRepairRunSegmentExpectation struct {
+	e *expectation
+}
+
+// OnAbortRepairRunSegment adds an expectation on calls to AbortRepairRunSegment. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnAbortRepairRunSegment() *AbortRepairRunSegmentExpectation {
+	return &AbortRepairRunSegmentExpectation{m.expect("AbortRepairRunSegment")}
+}
+
+// With restricts the expectation to calls with the given arguments, excluding the context.
+func (x *AbortRepairRunSegmentExpectation) With(repairRunId uuid.UUID, segmentId uuid.UUID) *AbortRepairRunSegmentExpectation {
+	x.e.args = []interface{}{repairRunId, segmentId}
+	return x
+}
+
+// Return sets the values returned by matching calls.
+func (x *AbortRepairRunSegmentExpectation) Return(r0 error) *AbortRepairRunSegmentExpectation {
+	x.e.results = []interface{}{r0}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *AbortRepairRunSegmentExpectation) Run(fn func(context.Context, uuid.UUID, uuid.UUID) error) *AbortRepairRunSegmentExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *AbortRepairRunSegmentExpectation) Times(n int) *AbortRepairRunSegmentExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *AbortRepairRunSegmentExpectation) Once() *AbortRepairRunSegmentExpectation {
+	return x.Times(1)
+}
+
+// DeleteRepairRun implements reaper.Client.
+func (m *Mock) DeleteRepairRun(ctx context.Context, repairRunId uuid.UUID, owner string) error {
+	e := m.called("DeleteRepairRun", repairRunId, owner)
+	if e == nil {
+		return ErrUnexpectedCall
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context, uuid.UUID, string) error)(ctx, repairRunId, owner)
+	}
+	var r0 error
+	if e.results != nil {
+		r0, _ = e.results[0].(error)
+	}
+	return r0
+}
+
+// DeleteRepairRunExpectation is an expectation on calls to DeleteRepairRun.
+type DeleteRepairRunExpectation struct {
+	e *expectation
+}
+
+// OnDeleteRepairRun adds an expectation on calls to DeleteRepairRun. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnDeleteRepairRun() *DeleteRepairRunExpectation {
+	return &DeleteRepairRunExpectation{m.expect("DeleteRepairRun")}
+}
+
+// With restricts the expectation to calls with the given arguments, excluding the context.
+func (x *DeleteRepairRunExpectation) With(repairRunId uuid.UUID, owner string) *DeleteRepairRunExpectation {
+	x.e.args = []interface{}{repairRunId, owner}
+	return x
+}
+
+// Return sets the values returned by matching calls.
+func (x *DeleteRepairRunExpectation) Return(r0 error) *DeleteRepairRunExpectation {
+	x.e.results = []interface{}{r0}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *DeleteRepairRunExpectation) Run(fn func(context.Context, uuid.UUID, string) error) *DeleteRepairRunExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *DeleteRepairRunExpectation) Times(n int) *DeleteRepairRunExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *DeleteRepairRunExpectation) Once() *DeleteRepairRunExpectation {
+	return x.Times(1)
+}
+
+// PurgeRepairRuns implements reaper.Client.
+func (m *Mock) PurgeRepairRuns(ctx context.Context) (int, error) {
+	e := m.called("PurgeRepairRuns")
+	if e == nil {
+		return *new(int), ErrUnexpectedCall
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context) (int, error))(ctx)
+	}
+	var r0 int
+	var r1 error
+	if e.results != nil {
+		r0, _ = e.results[0].(int)
+		r1, _ = e.results[1].(error)
+	}
+	return r0, r1
+}
+
+// PurgeRepairRunsExpectation is an expectation on calls to PurgeRepairRuns.
+type PurgeRepairRunsExpectation struct {
+	e *expectation
+}
+
+// OnPurgeRepairRuns adds an expectation on calls to PurgeRepairRuns. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnPurgeRepairRuns() *PurgeRepairRunsExpectation {
+	return &PurgeRepairRunsExpectation{m.expect("PurgeRepairRuns")}
+}
+
+// Return sets the values returned by matching calls.
+func (x *PurgeRepairRunsExpectation) Return(r0 int, r1 error) *PurgeRepairRunsExpectation {
+	x.e.results = []interface{}{r0, r1}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *PurgeRepairRunsExpectation) Run(fn func(context.Context) (int, error)) *PurgeRepairRunsExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *PurgeRepairRunsExpectation) Times(n int) *PurgeRepairRunsExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *PurgeRepairRunsExpectation) Once() *PurgeRepairRunsExpectation {
+	return x.Times(1)
+}
+
+// RepairSchedules implements reaper.Client.
+func (m *Mock) RepairSchedules(ctx context.Context) ([]reaper.RepairSchedule, error) {
+	e := m.called("RepairSchedules")
+	if e == nil {
+		return *new([]reaper.RepairSchedule), ErrUnexpectedCall
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context) ([]reaper.RepairSchedule, error))(ctx)
+	}
+	var r0 []reaper.RepairSchedule
+	var r1 error
+	if e.results != nil {
+		r0, _ = e.results[0].([]reaper.RepairSchedule)
+		r1, _ = e.results[1].(error)
+	}
+	return r0, r1
+}
+
+// RepairSchedulesExpectation is an expectation on calls to RepairSchedules.
+type RepairSchedulesExpectation struct {
+	e *expectation
+}
+
+// OnRepairSchedules adds an expectation on calls to RepairSchedules. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnRepairSchedules() *RepairSchedulesExpectation {
+	return &RepairSchedulesExpectation{m.expect("RepairSchedules")}
+}
+
+// Return sets the values returned by matching calls.
+func (x *RepairSchedulesExpectation) Return(r0 []reaper.RepairSchedule, r1 error) *RepairSchedulesExpectation {
+	x.e.results = []interface{}{r0, r1}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *RepairSchedulesExpectation) Run(fn func(context.Context) ([]reaper.RepairSchedule, error)) *RepairSchedulesExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *RepairSchedulesExpectation) Times(n int) *RepairSchedulesExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *RepairSchedulesExpectation) Once() *RepairSchedulesExpectation {
+	return x.Times(1)
+}
+
+// RepairSchedulesForCluster implements reaper.Client.
+func (m *Mock) RepairSchedulesForCluster(ctx context.Context, clusterName string) ([]reaper.RepairSchedule, error) {
+	e := m.called("RepairSchedulesForCluster", clusterName)
+	if e == nil {
+		return *new([]reaper.RepairSchedule), ErrUnexpectedCall
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context, string) ([]reaper.RepairSchedule, error))(ctx, clusterName)
+	}
+	var r0 []reaper.RepairSchedule
+	var r1 error
+	if e.results != nil {
+		r0, _ = e.results[0].([]reaper.RepairSchedule)
+		r1, _ = e.results[1].(error)
+	}
+	return r0, r1
+}
+
+// RepairSchedulesForClusterExpectation is an expectation on calls to RepairSchedulesForCluster.
+type RepairSchedulesForClusterExpectation struct {
+	e *expectation
+}
+
+// OnRepairSchedulesForCluster adds an expectation on calls to RepairSchedulesForCluster. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnRepairSchedulesForCluster() *RepairSchedulesForClusterExpectation {
+	return &RepairSchedulesForClusterExpectation{m.expect("RepairSchedulesForCluster")}
+}
+
+// With restricts the expectation to calls with the given arguments, excluding the context.
+func (x *RepairSchedulesForClusterExpectation) With(clusterName string) *RepairSchedulesForClusterExpectation {
+	x.e.args = []interface{}{clusterName}
+	return x
+}
+
+// Return sets the values returned by matching calls.
+func (x *RepairSchedulesForClusterExpectation) Return(r0 []reaper.RepairSchedule, r1 error) *RepairSchedulesForClusterExpectation {
+	x.e.results = []interface{}{r0, r1}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *RepairSchedulesForClusterExpectation) Run(fn func(context.Context, string) ([]reaper.RepairSchedule, error)) *RepairSchedulesForClusterExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *RepairSchedulesForClusterExpectation) Times(n int) *RepairSchedulesForClusterExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *RepairSchedulesForClusterExpectation) Once() *RepairSchedulesForClusterExpectation {
+	return x.Times(1)
+}
+
+// RepairSchedule implements reaper.Client.
+func (m *Mock) RepairSchedule(ctx context.Context, repairScheduleId uuid.UUID) (*reaper.RepairSchedule, error) {
+	e := m.called("RepairSchedule", repairScheduleId)
+	if e == nil {
+		return *new(*reaper.RepairSchedule), ErrUnexpectedCall
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context, uuid.UUID) (*reaper.RepairSchedule, error))(ctx, repairScheduleId)
+	}
+	var r0 *reaper.RepairSchedule
+	var r1 error
+	if e.results != nil {
+		r0, _ = e.results[0].(*reaper.RepairSchedule)
+		r1, _ = e.results[1].(error)
+	}
+	return r0, r1
+}
+
+// RepairScheduleExpectation is an expectation on calls to RepairSchedule.
+type RepairScheduleExpectation struct {
+	e *expectation
+}
+
+// OnRepairSchedule adds an expectation on calls to RepairSchedule. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnRepairSchedule() *RepairScheduleExpectation {
+	return &RepairScheduleExpectation{m.expect("RepairSchedule")}
+}
+
+// With restricts the expectation to calls with the given arguments, excluding the context.
+func (x *RepairScheduleExpectation) With(repairScheduleId uuid.UUID) *RepairScheduleExpectation {
+	x.e.args = []interface{}{repairScheduleId}
+	return x
+}
+
+// Return sets the values returned by matching calls.
+func (x *RepairScheduleExpectation) Return(r0 *reaper.RepairSchedule, r1 error) *RepairScheduleExpectation {
+	x.e.results = []interface{}{r0, r1}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *RepairScheduleExpectation) Run(fn func(context.Context, uuid.UUID) (*reaper.RepairSchedule, error)) *RepairScheduleExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *RepairScheduleExpectation) Times(n int) *RepairScheduleExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *RepairScheduleExpectation) Once() *RepairScheduleExpectation {
+	return x.Times(1)
+}
+
+// CreateRepairSchedule implements reaper.Client.
+func (m *Mock) CreateRepairSchedule(ctx context.Context, cluster string, keyspace string, owner string, daysBetween int, options *reaper.RepairScheduleCreateOptions) (uuid.UUID, error) {
+	e := m.called("CreateRepairSchedule", cluster, keyspace, owner, daysBetween, options)
+	if e == nil {
+		return *new(uuid.UUID), ErrUnexpectedCall
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context, string, string, string, int, *reaper.RepairScheduleCreateOptions) (uuid.UUID, error))(ctx, cluster, keyspace, owner, daysBetween, options)
+	}
+	var r0 uuid.UUID
+	var r1 error
+	if e.results != nil {
+		r0, _ = e.results[0].(uuid.UUID)
+		r1, _ = e.results[1].(error)
+	}
+	return r0, r1
+}
+
+// CreateRepairScheduleExpectation is an expectation on calls to CreateRepairSchedule.
+type CreateRepairScheduleExpectation struct {
+	e *expectation
+}
+
+// OnCreateRepairSchedule adds an expectation on calls to CreateRepairSchedule. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnCreateRepairSchedule() *CreateRepairScheduleExpectation {
+	return &CreateRepairScheduleExpectation{m.expect("CreateRepairSchedule")}
+}
+
+// With restricts the expectation to calls with the given arguments, excluding the context.
+func (x *CreateRepairScheduleExpectation) With(cluster string, keyspace string, owner string, daysBetween int, options *reaper.RepairScheduleCreateOptions) *CreateRepairScheduleExpectation {
+	x.e.args = []interface{}{cluster, keyspace, owner, daysBetween, options}
+	return x
+}
+
+// Return sets the values returned by matching calls.
+func (x *CreateRepairScheduleExpectation) Return(r0 uuid.UUID, r1 error) *CreateRepairScheduleExpectation {
+	x.e.results = []interface{}{r0, r1}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *CreateRepairScheduleExpectation) Run(fn func(context.Context, string, string, string, int, *reaper.RepairScheduleCreateOptions) (uuid.UUID, error)) *CreateRepairScheduleExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *CreateRepairScheduleExpectation) Times(n int) *CreateRepairScheduleExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *CreateRepairScheduleExpectation) Once() *CreateRepairScheduleExpectation {
+	return x.Times(1)
+}
+
+// StartRepairSchedule implements reaper.Client.
+func (m *Mock) StartRepairSchedule(ctx context.Context, repairScheduleId uuid.UUID) error {
+	e := m.called("StartRepairSchedule", repairScheduleId)
+	if e == nil {
+		return ErrUnexpectedCall
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context, uuid.UUID) error)(ctx, repairScheduleId)
+	}
+	var r0 error
+	if e.results != nil {
+		r0, _ = e.results[0].(error)
+	}
+	return r0
+}
+
+// StartRepairScheduleExpectation is an expectation on calls to StartRepairSchedule.
+type StartRepairScheduleExpectation struct {
+	e *expectation
+}
+
+// OnStartRepairSchedule adds an expectation on calls to StartRepairSchedule. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnStartRepairSchedule() *StartRepairScheduleExpectation {
+	return &StartRepairScheduleExpectation{m.expect("StartRepairSchedule")}
+}
+
+// With restricts the expectation to calls with the given arguments, excluding the context.
+func (x *StartRepairScheduleExpectation) With(repairScheduleId uuid.UUID) *StartRepairScheduleExpectation {
+	x.e.args = []interface{}{repairScheduleId}
+	return x
+}
+
+// Return sets the values returned by matching calls.
+func (x *StartRepairScheduleExpectation) Return(r0 error) *StartRepairScheduleExpectation {
+	x.e.results = []interface{}{r0}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *StartRepairScheduleExpectation) Run(fn func(context.Context, uuid.UUID) error) *StartRepairScheduleExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *StartRepairScheduleExpectation) Times(n int) *StartRepairScheduleExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *StartRepairScheduleExpectation) Once() *StartRepairScheduleExpectation {
+	return x.Times(1)
+}
+
+// PauseRepairSchedule implements reaper.Client.
+func (m *Mock) PauseRepairSchedule(ctx context.Context, repairScheduleId uuid.UUID) error {
+	e := m.called("PauseRepairSchedule", repairScheduleId)
+	if e == nil {
+		return ErrUnexpectedCall
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context, uuid.UUID) error)(ctx, repairScheduleId)
+	}
+	var r0 error
+	if e.results != nil {
+		r0, _ = e.results[0].(error)
+	}
+	return r0
+}
+
+// PauseRepairScheduleExpectation is an expectation on calls to PauseRepairSchedule.
+type PauseRepairScheduleExpectation struct {
+	e *expectation
+}
+
+// OnPauseRepairSchedule adds an expectation on calls to PauseRepairSchedule. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnPauseRepairSchedule() *PauseRepairScheduleExpectation {
+	return &PauseRepairScheduleExpectation{m.expect("PauseRepairSchedule")}
+}
+
+// With restricts the expectation to calls with the given arguments, excluding the context.
+func (x *PauseRepairScheduleExpectation) With(repairScheduleId uuid.UUID) *PauseRepairScheduleExpectation {
+	x.e.args = []interface{}{repairScheduleId}
+	return x
+}
+
+// Return sets the values returned by matching calls.
+func (x *PauseRepairScheduleExpectation) Return(r0 error) *PauseRepairScheduleExpectation {
+	x.e.results = []interface{}{r0}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *PauseRepairScheduleExpectation) Run(fn func(context.Context, uuid.UUID) error) *PauseRepairScheduleExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *PauseRepairScheduleExpectation) Times(n int) *PauseRepairScheduleExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *PauseRepairScheduleExpectation) Once() *PauseRepairScheduleExpectation {
+	return x.Times(1)
+}
+
+// ResumeRepairSchedule implements reaper.Client.
+func (m *Mock) ResumeRepairSchedule(ctx context.Context, repairScheduleId uuid.UUID) error {
+	e := m.called("ResumeRepairSchedule", repairScheduleId)
+	if e == nil {
+		return ErrUnexpectedCall
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context, uuid.UUID) error)(ctx, repairScheduleId)
+	}
+	var r0 error
+	if e.results != nil {
+		r0, _ = e.results[0].(error)
+	}
+	return r0
+}
+
+// ResumeRepairScheduleExpectation is an expectation on calls to ResumeRepairSchedule.
+type ResumeRepairScheduleExpectation struct {
+	e *expectation
+}
+
+// OnResumeRepairSchedule adds an expectation on calls to ResumeRepairSchedule. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnResumeRepairSchedule() *ResumeRepairScheduleExpectation {
+	return &ResumeRepairScheduleExpectation{m.expect("ResumeRepairSchedule")}
+}
+
+// With restricts the expectation to calls with the given arguments, excluding the context.
+func (x *ResumeRepairScheduleExpectation) With(repairScheduleId uuid.UUID) *ResumeRepairScheduleExpectation {
+	x.e.args = []interface{}{repairScheduleId}
+	return x
+}
+
+// Return sets the values returned by matching calls.
+func (x *ResumeRepairScheduleExpectation) Return(r0 error) *ResumeRepairScheduleExpectation {
+	x.e.results = []interface{}{r0}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *ResumeRepairScheduleExpectation) Run(fn func(context.Context, uuid.UUID) error) *ResumeRepairScheduleExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *ResumeRepairScheduleExpectation) Times(n int) *ResumeRepairScheduleExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *ResumeRepairScheduleExpectation) Once() *ResumeRepairScheduleExpectation {
+	return x.Times(1)
+}
+
+// DeleteRepairSchedule implements reaper.Client.
+func (m *Mock) DeleteRepairSchedule(ctx context.Context, repairScheduleId uuid.UUID, owner string) error {
+	e := m.called("DeleteRepairSchedule", repairScheduleId, owner)
+	if e == nil {
+		return ErrUnexpectedCall
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context, uuid.UUID, string) error)(ctx, repairScheduleId, owner)
+	}
+	var r0 error
+	if e.results != nil {
+		r0, _ = e.results[0].(error)
+	}
+	return r0
+}
+
+// DeleteRepairScheduleExpectation is an expectation on calls to DeleteRepairSchedule.
+type DeleteRepairScheduleExpectation struct {
+	e *expectation
+}
+
+// OnDeleteRepairSchedule adds an expectation on calls to DeleteRepairSchedule. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnDeleteRepairSchedule() *DeleteRepairScheduleExpectation {
+	return &DeleteRepairScheduleExpectation{m.expect("DeleteRepairSchedule")}
+}
+
+// With restricts the expectation to calls with the given arguments, excluding the context.
+func (x *DeleteRepairScheduleExpectation) With(repairScheduleId uuid.UUID, owner string) *DeleteRepairScheduleExpectation {
+	x.e.args = []interface{}{repairScheduleId, owner}
+	return x
+}
+
+// Return sets the values returned by matching calls.
+func (x *DeleteRepairScheduleExpectation) Return(r0 error) *DeleteRepairScheduleExpectation {
+	x.e.results = []interface{}{r0}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *DeleteRepairScheduleExpectation) Run(fn func(context.Context, uuid.UUID, string) error) *DeleteRepairScheduleExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *DeleteRepairScheduleExpectation) Times(n int) *DeleteRepairScheduleExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *DeleteRepairScheduleExpectation) Once() *DeleteRepairScheduleExpectation {
+	return x.Times(1)
+}
+
+// PauseRepairRuns implements reaper.Client.
+func (m *Mock) PauseRepairRuns(ctx context.Context, searchOptions *reaper.RepairRunSearchOptions) (map[uuid.UUID]*reaper.BulkResult, error) {
+	e := m.called("PauseRepairRuns", searchOptions)
+	if e == nil {
+		return *new(map[uuid.UUID]*reaper.BulkResult), ErrUnexpectedCall
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context, *reaper.RepairRunSearchOptions) (map[uuid.UUID]*reaper.BulkResult, error))(ctx, searchOptions)
+	}
+	var r0 map[uuid.UUID]*reaper.BulkResult
+	var r1 error
+	if e.results != nil {
+		r0, _ = e.results[0].(map[uuid.UUID]*reaper.BulkResult)
+		r1, _ = e.results[1].(error)
+	}
+	return r0, r1
+}
+
+// PauseRepairRunsExpectation is an expectation on calls to PauseRepairRuns.
+type PauseRepairRunsExpectation struct {
+	e *expectation
+}
+
+// OnPauseRepairRuns adds an expectation on calls to PauseRepairRuns. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnPauseRepairRuns() *PauseRepairRunsExpectation {
+	return &PauseRepairRunsExpectation{m.expect("PauseRepairRuns")}
+}
+
+// With restricts the expectation to calls with the given arguments, excluding the context.
+func (x *PauseRepairRunsExpectation) With(searchOptions *reaper.RepairRunSearchOptions) *PauseRepairRunsExpectation {
+	x.e.args = []interface{}{searchOptions}
+	return x
+}
+
+// Return sets the values returned by matching calls.
+func (x *PauseRepairRunsExpectation) Return(r0 map[uuid.UUID]*reaper.BulkResult, r1 error) *PauseRepairRunsExpectation {
+	x.e.results = []interface{}{r0, r1}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *PauseRepairRunsExpectation) Run(fn func(context.Context, *reaper.RepairRunSearchOptions) (map[uuid.UUID]*reaper.BulkResult, error)) *PauseRepairRunsExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *PauseRepairRunsExpectation) Times(n int) *PauseRepairRunsExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *PauseRepairRunsExpectation) Once() *PauseRepairRunsExpectation {
+	return x.Times(1)
+}
+
+// ResumeRepairRuns implements reaper.Client.
+func (m *Mock) ResumeRepairRuns(ctx context.Context, searchOptions *reaper.RepairRunSearchOptions) (map[uuid.UUID]*reaper.BulkResult, error) {
+	e := m.called("ResumeRepairRuns", searchOptions)
+	if e == nil {
+		return *new(map[uuid.UUID]*reaper.BulkResult), ErrUnexpectedCall
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context, *reaper.RepairRunSearchOptions) (map[uuid.UUID]*reaper.BulkResult, error))(ctx, searchOptions)
+	}
+	var r0 map[uuid.UUID]*reaper.BulkResult
+	var r1 error
+	if e.results != nil {
+		r0, _ = e.results[0].(map[uuid.UUID]*reaper.BulkResult)
+		r1, _ = e.results[1].(error)
+	}
+	return r0, r1
+}
+
+// ResumeRepairRunsExpectation is an expectation on calls to ResumeRepairRuns.
+type ResumeRepairRunsExpectation struct {
+	e *expectation
+}
+
+// OnResumeRepairRuns adds an expectation on calls to ResumeRepairRuns. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnResumeRepairRuns() *ResumeRepairRunsExpectation {
+	return &ResumeRepairRunsExpectation{m.expect("ResumeRepairRuns")}
+}
+
+// With restricts the expectation to calls with the given arguments, excluding the context.
+func (x *ResumeRepairRunsExpectation) With(searchOptions *reaper.RepairRunSearchOptions) *ResumeRepairRunsExpectation {
+	x.e.args = []interface{}{searchOptions}
+	return x
+}
+
+// Return sets the values returned by matching calls.
+func (x *ResumeRepairRunsExpectation) Return(r0 map[uuid.UUID]*reaper.BulkResult, r1 error) *ResumeRepairRunsExpectation {
+	x.e.results = []interface{}{r0, r1}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *ResumeRepairRunsExpectation) Run(fn func(context.Context, *reaper.RepairRunSearchOptions) (map[uuid.UUID]*reaper.BulkResult, error)) *ResumeRepairRunsExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *ResumeRepairRunsExpectation) Times(n int) *ResumeRepairRunsExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *ResumeRepairRunsExpectation) Once() *ResumeRepairRunsExpectation {
+	return x.Times(1)
+}
+
+// AbortRepairRuns implements reaper.Client.
+func (m *Mock) AbortRepairRuns(ctx context.Context, searchOptions *reaper.RepairRunSearchOptions) (map[uuid.UUID]*reaper.BulkResult, error) {
+	e := m.called("AbortRepairRuns", searchOptions)
+	if e == nil {
+		return *new(map[uuid.UUID]*reaper.BulkResult), ErrUnexpectedCall
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context, *reaper.RepairRunSearchOptions) (map[uuid.UUID]*reaper.BulkResult, error))(ctx, searchOptions)
+	}
+	var r0 map[uuid.UUID]*reaper.BulkResult
+	var r1 error
+	if e.results != nil {
+		r0, _ = e.results[0].(map[uuid.UUID]*reaper.BulkResult)
+		r1, _ = e.results[1].(error)
+	}
+	return r0, r1
+}
+
+// AbortRepairRunsExpectation is an expectation on calls to AbortRepairRuns.
+type AbortRepairRunsExpectation struct {
+	e *expectation
+}
+
+// OnAbortRepairRuns adds an expectation on calls to AbortRepairRuns. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnAbortRepairRuns() *AbortRepairRunsExpectation {
+	return &AbortRepairRunsExpectation{m.expect("AbortRepairRuns")}
+}
+
+// With restricts the expectation to calls with the given arguments, excluding the context.
+func (x *AbortRepairRunsExpectation) With(searchOptions *reaper.RepairRunSearchOptions) *AbortRepairRunsExpectation {
+	x.e.args = []interface{}{searchOptions}
+	return x
+}
+
+// Return sets the values returned by matching calls.
+func (x *AbortRepairRunsExpectation) Return(r0 map[uuid.UUID]*reaper.BulkResult, r1 error) *AbortRepairRunsExpectation {
+	x.e.results = []interface{}{r0, r1}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *AbortRepairRunsExpectation) Run(fn func(context.Context, *reaper.RepairRunSearchOptions) (map[uuid.UUID]*reaper.BulkResult, error)) *AbortRepairRunsExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *AbortRepairRunsExpectation) Times(n int) *AbortRepairRunsExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *AbortRepairRunsExpectation) Once() *AbortRepairRunsExpectation {
+	return x.Times(1)
+}
+
+// PauseRepairSchedules implements reaper.Client.
+func (m *Mock) PauseRepairSchedules(ctx context.Context, cluster string) (map[uuid.UUID]*reaper.BulkResult, error) {
+	e := m.called("PauseRepairSchedules", cluster)
+	if e == nil {
+		return *new(map[uuid.UUID]*reaper.BulkResult), ErrUnexpectedCall
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context, string) (map[uuid.UUID]*reaper.BulkResult, error))(ctx, cluster)
+	}
+	var r0 map[uuid.UUID]*reaper.BulkResult
+	var r1 error
+	if e.results != nil {
+		r0, _ = e.results[0].(map[uuid.UUID]*reaper.BulkResult)
+		r1, _ = e.results[1].(error)
+	}
+	return r0, r1
+}
+
+// PauseRepairSchedulesExpectation is an expectation on calls to PauseRepairSchedules.
+type PauseRepairSchedulesExpectation struct {
+	e *expectation
+}
+
+// OnPauseRepairSchedules adds an expectation on calls to PauseRepairSchedules. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnPauseRepairSchedules() *PauseRepairSchedulesExpectation {
+	return &PauseRepairSchedulesExpectation{m.expect("PauseRepairSchedules")}
+}
+
+// With restricts the expectation to calls with the given arguments, excluding the context.
+func (x *PauseRepairSchedulesExpectation) With(cluster string) *PauseRepairSchedulesExpectation {
+	x.e.args = []interface{}{cluster}
+	return x
+}
+
+// Return sets the values returned by matching calls.
+func (x *PauseRepairSchedulesExpectation) Return(r0 map[uuid.UUID]*reaper.BulkResult, r1 error) *PauseRepairSchedulesExpectation {
+	x.e.results = []interface{}{r0, r1}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *PauseRepairSchedulesExpectation) Run(fn func(context.Context, string) (map[uuid.UUID]*reaper.BulkResult, error)) *PauseRepairSchedulesExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *PauseRepairSchedulesExpectation) Times(n int) *PauseRepairSchedulesExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *PauseRepairSchedulesExpectation) Once() *PauseRepairSchedulesExpectation {
+	return x.Times(1)
+}
+
+// ResumeRepairSchedules implements reaper.Client.
+func (m *Mock) ResumeRepairSchedules(ctx context.Context, cluster string) (map[uuid.UUID]*reaper.BulkResult, error) {
+	e := m.called("ResumeRepairSchedules", cluster)
+	if e == nil {
+		return *new(map[uuid.UUID]*reaper.BulkResult), ErrUnexpectedCall
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context, string) (map[uuid.UUID]*reaper.BulkResult, error))(ctx, cluster)
+	}
+	var r0 map[uuid.UUID]*reaper.BulkResult
+	var r1 error
+	if e.results != nil {
+		r0, _ = e.results[0].(map[uuid.UUID]*reaper.BulkResult)
+		r1, _ = e.results[1].(error)
+	}
+	return r0, r1
+}
+
+// ResumeRepairSchedulesExpectation is an expectation on calls to ResumeRepairSchedules.
+type ResumeRepairSchedulesExpectation struct {
+	e *expectation
+}
+
+// OnResumeRepairSchedules adds an expectation on calls to ResumeRepairSchedules. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnResumeRepairSchedules() *ResumeRepairSchedulesExpectation {
+	return &ResumeRepairSchedulesExpectation{m.expect("ResumeRepairSchedules")}
+}
+
+// With restricts the expectation to calls with the given arguments, excluding the context.
+func (x *ResumeRepairSchedulesExpectation) With(cluster string) *ResumeRepairSchedulesExpectation {
+	x.e.args = []interface{}{cluster}
+	return x
+}
+
+// Return sets the values returned by matching calls.
+func (x *ResumeRepairSchedulesExpectation) Return(r0 map[uuid.UUID]*reaper.BulkResult, r1 error) *ResumeRepairSchedulesExpectation {
+	x.e.results = []interface{}{r0, r1}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *ResumeRepairSchedulesExpectation) Run(fn func(context.Context, string) (map[uuid.UUID]*reaper.BulkResult, error)) *ResumeRepairSchedulesExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *ResumeRepairSchedulesExpectation) Times(n int) *ResumeRepairSchedulesExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *ResumeRepairSchedulesExpectation) Once() *ResumeRepairSchedulesExpectation {
+	return x.Times(1)
+}
+
+// Login implements reaper.Client.
+func (m *Mock) Login(ctx context.Context, username string, password string) error {
+	e := m.called("Login", username, password)
+	if e == nil {
+		return ErrUnexpectedCall
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context, string, string) error)(ctx, username, password)
+	}
+	var r0 error
+	if e.results != nil {
+		r0, _ = e.results[0].(error)
+	}
+	return r0
+}
+
+// LoginExpectation is an expectation on calls to Login.
+type LoginExpectation struct {
+	e *expectation
+}
+
+// OnLogin adds an expectation on calls to Login. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnLogin() *LoginExpectation {
+	return &LoginExpectation{m.expect("Login")}
+}
+
+// With restricts the expectation to calls with the given arguments, excluding the context.
+func (x *LoginExpectation) With(username string, password string) *LoginExpectation {
+	x.e.args = []interface{}{username, password}
+	return x
+}
+
+// Return sets the values returned by matching calls.
+func (x *LoginExpectation) Return(r0 error) *LoginExpectation {
+	x.e.results = []interface{}{r0}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *LoginExpectation) Run(fn func(context.Context, string, string) error) *LoginExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *LoginExpectation) Times(n int) *LoginExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *LoginExpectation) Once() *LoginExpectation {
+	return x.Times(1)
+}
+
+// Capabilities implements reaper.Client.
+func (m *Mock) Capabilities(ctx context.Context) (*reaper.Capabilities, error) {
+	e := m.called("Capabilities")
+	if e == nil {
+		return *new(*reaper.Capabilities), ErrUnexpectedCall
+	}
+	if e.run != nil {
+		return e.run.(func(context.Context) (*reaper.Capabilities, error))(ctx)
+	}
+	var r0 *reaper.Capabilities
+	var r1 error
+	if e.results != nil {
+		r0, _ = e.results[0].(*reaper.Capabilities)
+		r1, _ = e.results[1].(error)
+	}
+	return r0, r1
+}
+
+// CapabilitiesExpectation is an expectation on calls to Capabilities.
+type CapabilitiesExpectation struct {
+	e *expectation
+}
+
+// OnCapabilities adds an expectation on calls to Capabilities. It matches calls with any arguments, unless With is used.
+func (m *Mock) OnCapabilities() *CapabilitiesExpectation {
+	return &CapabilitiesExpectation{m.expect("Capabilities")}
+}
+
+// Return sets the values returned by matching calls.
+func (x *CapabilitiesExpectation) Return(r0 *reaper.Capabilities, r1 error) *CapabilitiesExpectation {
+	x.e.results = []interface{}{r0, r1}
+	return x
+}
+
+// Run makes matching calls invoke fn and return its results.
+func (x *CapabilitiesExpectation) Run(fn func(context.Context) (*reaper.Capabilities, error)) *CapabilitiesExpectation {
+	x.e.run = fn
+	return x
+}
+
+// Times limits the expectation to n calls. AssertExpectations fails if the expectation was called less often.
+func (x *CapabilitiesExpectation) Times(n int) *CapabilitiesExpectation {
+	x.e.times = n
+	return x
+}
+
+// Once is a shorthand for Times(1).
+func (x *CapabilitiesExpectation) Once() *CapabilitiesExpectation {
+	return x.Times(1)
+}
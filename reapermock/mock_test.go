@@ -0,0 +1,142 @@
+package reapermock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/k8ssandra/reaper-client-go/reaper"
+	"github.com/k8ssandra/reaper-client-go/reapermock/internal/gen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeT struct {
+	errors []string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestGeneratedCodeUpToDate(t *testing.T) {
+	source, err := os.ReadFile("../reaper/client.go")
+	require.NoError(t, err)
+	expected, err := gen.Generate(source)
+	require.NoError(t, err)
+	actual, err := os.ReadFile("mock_gen.go")
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), string(actual), "mock_gen.go is out of date, run go generate ./reapermock")
+}
+
+func TestReturn(t *testing.T) {
+	runId := uuid.New()
+	otherId := uuid.New()
+	m := NewMock(t)
+	m.OnRepairRun().With(runId).Return(&reaper.RepairRun{Id: runId, State: reaper.RepairRunStateDone}, nil)
+	m.OnRepairRun().Return(nil, errors.New("not found"))
+
+	run, err := m.RepairRun(context.Background(), runId)
+	require.NoError(t, err)
+	assert.Equal(t, reaper.RepairRunStateDone, run.State)
+
+	run, err = m.RepairRun(context.Background(), otherId)
+	assert.Nil(t, run)
+	assert.EqualError(t, err, "not found")
+
+	assert.Equal(t, []Call{
+		{Method: "RepairRun", Args: []interface{}{runId}},
+		{Method: "RepairRun", Args: []interface{}{otherId}},
+	}, m.Calls())
+	assert.Equal(t, 2, m.CallCount("RepairRun"))
+	assert.True(t, m.AssertExpectations(t))
+}
+
+func TestRun(t *testing.T) {
+	m := NewMock(t)
+	m.OnCreateRepairRun().Run(func(
+		_ context.Context,
+		cluster string,
+		keyspace string,
+		owner string,
+		_ *reaper.RepairRunCreateOptions,
+	) (uuid.UUID, error) {
+		return uuid.NewSHA1(uuid.Nil, []byte(cluster+keyspace+owner)), nil
+	})
+	id, err := m.CreateRepairRun(context.Background(), "cluster-1", "ks", "Alice", nil)
+	require.NoError(t, err)
+	assert.Equal(t, uuid.NewSHA1(uuid.Nil, []byte("cluster-1ksAlice")), id)
+}
+
+func TestTimes(t *testing.T) {
+	m := NewMock(t)
+	m.OnIsReaperUp().Return(false, nil).Once()
+	m.OnIsReaperUp().Return(true, nil)
+	up, _ := m.IsReaperUp(context.Background())
+	assert.False(t, up)
+	up, _ = m.IsReaperUp(context.Background())
+	assert.True(t, up)
+	up, _ = m.IsReaperUp(context.Background())
+	assert.True(t, up)
+
+	ft := &fakeT{}
+	m = NewMock(ft)
+	m.OnStartRepairRun().Return(nil).Times(2)
+	m.OnLogin()
+	assert.NoError(t, m.StartRepairRun(context.Background(), uuid.New()))
+	assert.False(t, m.AssertExpectations(ft))
+	assert.Equal(t, []string{
+		"reapermock: expected StartRepairRun(*) to be called 2 time(s), but it was called 1 time(s)",
+		"reapermock: expected Login(*) to be called at least once, but it was called 0 time(s)",
+	}, ft.errors)
+}
+
+func TestSequence(t *testing.T) {
+	m := NewMock(t)
+	runs := []*reaper.RepairRun{{Cluster: "cluster-1"}, {Cluster: "cluster-2"}}
+	failure := errors.New("failure")
+	m.OnAllRepairRuns().Return(Sequence(runs, failure))
+
+	var clusters []string
+	var errs []error
+	for run, err := range m.AllRepairRuns(context.Background(), nil) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		clusters = append(clusters, run.Cluster)
+	}
+	assert.Equal(t, []string{"cluster-1", "cluster-2"}, clusters)
+	assert.Equal(t, []error{failure}, errs)
+
+	for run := range m.AllRepairRuns(context.Background(), nil) {
+		assert.Equal(t, "cluster-1", run.Cluster)
+		break
+	}
+}
+
+func TestUnexpectedCall(t *testing.T) {
+	ft := &fakeT{}
+	m := NewMock(ft)
+	err := m.AddCluster(context.Background(), "cluster-1", "seed")
+	assert.True(t, errors.Is(err, ErrUnexpectedCall))
+	assert.Equal(t, []string{"reapermock: unexpected call to AddCluster(cluster-1, seed)"}, ft.errors)
+
+	// asynchronous results must not block
+	for range m.GetClusters(context.Background()) {
+		t.Fatal("expected no results")
+	}
+	// and iterators yield the error
+	for run, err := range m.AllRepairRuns(context.Background(), nil) {
+		assert.Nil(t, run)
+		assert.True(t, errors.Is(err, ErrUnexpectedCall))
+	}
+
+	m = NewMock(nil)
+	assert.Panics(t, func() { _, _ = m.GetClusterNames(context.Background()) })
+}
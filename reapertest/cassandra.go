@@ -0,0 +1,173 @@
+package reapertest
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// CassandraCluster describes a Cassandra cluster reachable by the fake Reaper server. Clusters must be added to the
+// server with Server.AddCassandraCluster before they can be registered in Reaper with Client.AddCluster, using any of
+// their node endpoints as seed.
+type CassandraCluster struct {
+	Name      string
+	Nodes     []Node
+	Keyspaces []Keyspace
+}
+
+type Node struct {
+	Endpoint   string
+	Datacenter string
+	Rack       string
+
+	// Defaults to "NORMAL". Any other value makes the node appear down or joining.
+	Status string
+
+	// Defaults to "4.0.0".
+	ReleaseVersion string
+
+	// The load of the node in bytes. Defaults to 1 MiB.
+	Load float64
+}
+
+type Keyspace struct {
+	Name              string
+	ReplicationFactor int
+	Tables            []string
+}
+
+// NewCassandraCluster returns a cluster of nodeCount nodes in datacenter1/rack1. Nodes are named <name>-node-<i>,
+// which matches the naming used by the docker-compose test environment.
+func NewCassandraCluster(name string, nodeCount int) *CassandraCluster {
+	cluster := &CassandraCluster{Name: name}
+	for i := 0; i < nodeCount; i++ {
+		cluster.Nodes = append(cluster.Nodes, Node{
+			Endpoint:   fmt.Sprintf("%s-node-%d", name, i),
+			Datacenter: "datacenter1",
+			Rack:       "rack1",
+		})
+	}
+	return cluster
+}
+
+// WithKeyspace adds a keyspace to the cluster and returns the cluster, for chaining.
+func (c *CassandraCluster) WithKeyspace(name string, replicationFactor int, tables ...string) *CassandraCluster {
+	c.Keyspaces = append(c.Keyspaces, Keyspace{Name: name, ReplicationFactor: replicationFactor, Tables: tables})
+	return c
+}
+
+// WithReleaseVersion sets the Cassandra version of all nodes and returns the cluster, for chaining.
+func (c *CassandraCluster) WithReleaseVersion(version string) *CassandraCluster {
+	for i := range c.Nodes {
+		c.Nodes[i].ReleaseVersion = version
+	}
+	return c
+}
+
+func (c *CassandraCluster) node(endpoint string) *Node {
+	for i := range c.Nodes {
+		if c.Nodes[i].Endpoint == endpoint {
+			return &c.Nodes[i]
+		}
+	}
+	return nil
+}
+
+func (c *CassandraCluster) keyspace(name string) *Keyspace {
+	for i := range c.Keyspaces {
+		if c.Keyspaces[i].Name == name {
+			return &c.Keyspaces[i]
+		}
+	}
+	return nil
+}
+
+func (c *CassandraCluster) endpoints() []string {
+	endpoints := make([]string, 0, len(c.Nodes))
+	for _, node := range c.Nodes {
+		endpoints = append(endpoints, node.Endpoint)
+	}
+	return endpoints
+}
+
+// tokens returns the token owned by each node: the Murmur3 ring is split evenly between nodes.
+func (c *CassandraCluster) tokens() map[string]*big.Int {
+	tokens := make(map[string]*big.Int, len(c.Nodes))
+	for i, node := range c.Nodes {
+		tokens[node.Endpoint] = ringPosition(i, len(c.Nodes))
+	}
+	return tokens
+}
+
+func (c *CassandraCluster) toJson(jmxUsername string, jmxPasswordSet bool) *clusterJson {
+	tokens := c.tokens()
+	endpoints := make(map[string]map[string][]endpointStateJson)
+	totalLoad := 0.0
+	for i, node := range c.Nodes {
+		node = node.withDefaults()
+		if endpoints[node.Datacenter] == nil {
+			endpoints[node.Datacenter] = make(map[string][]endpointStateJson)
+		}
+		endpoints[node.Datacenter][node.Rack] = append(endpoints[node.Datacenter][node.Rack], endpointStateJson{
+			Endpoint:       node.Endpoint,
+			DataCenter:     node.Datacenter,
+			Rack:           node.Rack,
+			HostId:         fmt.Sprintf("00000000-0000-0000-0000-%012d", i),
+			Status:         node.Status,
+			ReleaseVersion: node.ReleaseVersion,
+			Tokens:         tokens[node.Endpoint].String(),
+			Load:           node.Load,
+		})
+		totalLoad += node.Load
+	}
+	seeds := c.endpoints()
+	sort.Strings(seeds)
+	return &clusterJson{
+		Name:           c.Name,
+		JmxUsername:    jmxUsername,
+		JmxPasswordSet: jmxPasswordSet,
+		Seeds:          seeds,
+		NodesStatus: nodeStatusJson{EndpointStates: []gossipStateJson{{
+			SourceNode:    c.Nodes[0].Endpoint,
+			EndpointNames: c.endpoints(),
+			TotalLoad:     totalLoad,
+			Endpoints:     endpoints,
+		}}},
+	}
+}
+
+func (n Node) withDefaults() Node {
+	if n.Status == "" {
+		n.Status = "NORMAL"
+	}
+	if n.ReleaseVersion == "" {
+		n.ReleaseVersion = "4.0.0"
+	}
+	if n.Load == 0 {
+		n.Load = 1024 * 1024
+	}
+	if n.Datacenter == "" {
+		n.Datacenter = "datacenter1"
+	}
+	if n.Rack == "" {
+		n.Rack = "rack1"
+	}
+	return n
+}
+
+func (n Node) isUp() bool {
+	return strings.EqualFold(n.withDefaults().Status, "NORMAL")
+}
+
+var (
+	murmur3Min  = new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 63))
+	murmur3Size = new(big.Int).Lsh(big.NewInt(1), 64)
+)
+
+// ringPosition returns the i-th of n evenly spaced tokens on the Murmur3 ring.
+func ringPosition(i, n int) *big.Int {
+	offset := new(big.Int).Mul(murmur3Size, big.NewInt(int64(i)))
+	offset.Div(offset, big.NewInt(int64(n)))
+	return offset.Add(offset, murmur3Min)
+}
@@ -0,0 +1,103 @@
+package reapertest
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+func (s *Server) getClusterNames(w http.ResponseWriter, _ *http.Request) {
+	names := make([]string, 0, len(s.clusters))
+	for name := range s.clusters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	writeJson(w, http.StatusOK, names)
+}
+
+func (s *Server) getCluster(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	cluster, found := s.clusters[name]
+	if !found {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("cluster with name %q not found", name))
+		return
+	}
+	writeJson(w, http.StatusOK, cluster.toJson(s.jmxUsername, s.jmxPassword != ""))
+}
+
+func (s *Server) getClusterTables(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	cluster, found := s.clusters[name]
+	if !found {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("cluster with name %q not found", name))
+		return
+	}
+	tables := make(map[string][]string, len(cluster.Keyspaces))
+	for _, keyspace := range cluster.Keyspaces {
+		tables[keyspace.Name] = append([]string{}, keyspace.Tables...)
+	}
+	writeJson(w, http.StatusOK, tables)
+}
+
+func (s *Server) putCluster(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	seedHost := r.URL.Query().Get("seedHost")
+	if seedHost == "" {
+		writeError(w, http.StatusBadRequest, "query parameter \"seedHost\" required")
+		return
+	}
+	var cassandra *CassandraCluster
+	for _, seed := range strings.Split(seedHost, ",") {
+		for _, c := range s.cassandra {
+			if c.node(strings.TrimSpace(seed)) != nil {
+				cassandra = c
+			}
+		}
+	}
+	if cassandra == nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("no seed host could be reached through JMX: %s", seedHost))
+		return
+	}
+	_, existing := s.clusters[name]
+	s.clusters[name] = cassandra
+	if existing {
+		writeJson(w, http.StatusOK, cassandra.toJson(s.jmxUsername, s.jmxPassword != ""))
+	} else {
+		w.Header().Set("Location", s.httpServer.URL+"/cluster/"+name)
+		writeJson(w, http.StatusCreated, cassandra.toJson(s.jmxUsername, s.jmxPassword != ""))
+	}
+}
+
+func (s *Server) deleteCluster(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if _, found := s.clusters[name]; !found {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("cluster with name %q not found", name))
+		return
+	}
+	force := r.URL.Query().Get("force") == "true"
+	for _, schedule := range s.schedules {
+		if schedule.Cluster == name && !force {
+			writeError(w, http.StatusConflict, fmt.Sprintf("cluster %q cannot be deleted, as it has repair schedules", name))
+			return
+		}
+	}
+	for _, run := range s.runs {
+		if run.Cluster == name && !isTerminated(run.State) && !force {
+			writeError(w, http.StatusConflict, fmt.Sprintf("cluster %q cannot be deleted, as it has repair runs", name))
+			return
+		}
+	}
+	for id, schedule := range s.schedules {
+		if schedule.Cluster == name {
+			delete(s.schedules, id)
+		}
+	}
+	for id, run := range s.runs {
+		if run.Cluster == name {
+			s.removeRun(id)
+		}
+	}
+	delete(s.clusters, name)
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,189 @@
+package reapertest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Fault describes how to alter a request to Reaper. The zero value lets the request through unchanged.
+type Fault struct {
+
+	// Delays the request. The delay is interrupted if the request context is canceled.
+	Latency time.Duration
+
+	// Fails the request with a connection reset error, without forwarding it.
+	ResetConnection bool
+
+	// Replaces the response with this status code and Body. The request is not forwarded, unless Forward is set.
+	StatusCode int
+	Body       string
+
+	// Forwards the request before replacing its response with StatusCode. This reproduces endpoints that succeed but
+	// report an error, such as DELETE /repair_run/{id} in some Reaper versions.
+	Forward bool
+
+	// Truncates the response body to half its length, which makes JSON payloads malformed.
+	TruncateBody bool
+}
+
+// FaultRule applies a Fault to a fraction of the requests matching a pattern.
+type FaultRule struct {
+
+	// A pattern of the form "[METHOD ]PATH", where PATH is matched with path.Match, e.g. "DELETE /repair_run/*" or
+	// "/cluster/*". An empty pattern matches all requests.
+	Pattern string
+
+	// The probability of applying the fault to a matching request, in range [0.0, 1.0]. Zero means always.
+	Rate float64
+
+	Fault Fault
+}
+
+// FaultTransport is an http.RoundTripper that injects faults in the requests sent to Reaper. Scripted faults take
+// precedence over rules; rules are evaluated in the order they were added, and the first match wins.
+type FaultTransport struct {
+	transport http.RoundTripper
+
+	mu      sync.Mutex
+	rules   []FaultRule
+	scripts []*faultScript
+	random  *rand.Rand
+}
+
+type faultScript struct {
+	pattern string
+	faults  []Fault
+}
+
+// NewFaultTransport returns a transport forwarding requests to transport, or http.DefaultTransport if nil. The seed
+// makes the application of fault rates reproducible.
+func NewFaultTransport(transport http.RoundTripper, seed int64) *FaultTransport {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &FaultTransport{transport: transport, random: rand.New(rand.NewSource(seed))}
+}
+
+// Inject adds a fault rule and returns the transport, for chaining.
+func (f *FaultTransport) Inject(rule FaultRule) *FaultTransport {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rules = append(f.rules, rule)
+	return f
+}
+
+// Script applies the given faults, one per request, to the next requests matching pattern. Use a zero Fault to let a
+// request through. Once the script is exhausted, matching requests fall back to the rules.
+func (f *FaultTransport) Script(pattern string, faults ...Fault) *FaultTransport {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.scripts = append(f.scripts, &faultScript{pattern: pattern, faults: faults})
+	return f
+}
+
+// Reset removes all rules and scripts.
+func (f *FaultTransport) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rules = nil
+	f.scripts = nil
+}
+
+func (f *FaultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	fault := f.next(req)
+	if fault.Latency > 0 {
+		timer := time.NewTimer(fault.Latency)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+	if fault.ResetConnection {
+		return nil, &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+	}
+	if fault.StatusCode != 0 && !fault.Forward {
+		return newResponse(req, fault.StatusCode, fault.Body), nil
+	}
+	res, err := f.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if fault.StatusCode != 0 {
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+		return newResponse(req, fault.StatusCode, fault.Body), nil
+	}
+	if fault.TruncateBody {
+		body, err := io.ReadAll(res.Body)
+		_ = res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = body[:len(body)/2]
+		res.Body = io.NopCloser(bytes.NewReader(body))
+		res.ContentLength = int64(len(body))
+		res.Header.Del("Content-Length")
+	}
+	return res, nil
+}
+
+func (f *FaultTransport) next(req *http.Request) Fault {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, script := range f.scripts {
+		if len(script.faults) > 0 && matchesPattern(script.pattern, req) {
+			fault := script.faults[0]
+			script.faults = script.faults[1:]
+			return fault
+		}
+	}
+	for _, rule := range f.rules {
+		if matchesPattern(rule.Pattern, req) && (rule.Rate == 0 || f.random.Float64() < rule.Rate) {
+			return rule.Fault
+		}
+	}
+	return Fault{}
+}
+
+func matchesPattern(pattern string, req *http.Request) bool {
+	if pattern == "" {
+		return true
+	}
+	method, pathPattern, found := strings.Cut(pattern, " ")
+	if !found {
+		method, pathPattern = "", pattern
+	}
+	if method != "" && method != req.Method {
+		return false
+	}
+	matched, err := path.Match(pathPattern, req.URL.Path)
+	return err == nil && matched
+}
+
+func newResponse(req *http.Request, status int, body string) *http.Response {
+	if body == "" {
+		body = http.StatusText(status)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"text/plain"}},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,76 @@
+package reapertest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// LeakTransport is an http.RoundTripper that keeps track of the response bodies that were not closed, which leak
+// connections in long-running processes. Call Check at the end of a test:
+//
+//	leaks := reapertest.NewLeakTransport(nil)
+//	client := reaper.NewClient(server.URL(), reaper.WithHttpClient(&http.Client{Transport: leaks}))
+//	defer func() { assert.NoError(t, leaks.Check()) }()
+type LeakTransport struct {
+	transport http.RoundTripper
+
+	mu   sync.Mutex
+	open map[*leakBody]string
+}
+
+// NewLeakTransport returns a transport forwarding requests to transport, or http.DefaultTransport if nil.
+func NewLeakTransport(transport http.RoundTripper) *LeakTransport {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &LeakTransport{transport: transport, open: map[*leakBody]string{}}
+}
+
+func (l *LeakTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := l.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body := &leakBody{ReadCloser: res.Body, transport: l}
+	l.mu.Lock()
+	l.open[body] = fmt.Sprintf("%s %s (%d)", req.Method, req.URL.Path, res.StatusCode)
+	l.mu.Unlock()
+	res.Body = body
+	return res, nil
+}
+
+// Open returns the requests whose response body is still open, sorted.
+func (l *LeakTransport) Open() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	open := make([]string, 0, len(l.open))
+	for _, request := range l.open {
+		open = append(open, request)
+	}
+	sort.Strings(open)
+	return open
+}
+
+// Check returns an error listing the requests whose response body is still open, or nil if all were closed.
+func (l *LeakTransport) Check() error {
+	if open := l.Open(); len(open) > 0 {
+		return fmt.Errorf("%d response bodies left open: %s", len(open), strings.Join(open, ", "))
+	}
+	return nil
+}
+
+type leakBody struct {
+	io.ReadCloser
+	transport *LeakTransport
+}
+
+func (b *leakBody) Close() error {
+	b.transport.mu.Lock()
+	delete(b.transport.open, b)
+	b.transport.mu.Unlock()
+	return b.ReadCloser.Close()
+}
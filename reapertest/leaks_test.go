@@ -0,0 +1,28 @@
+package reapertest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeakTransport(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	leaks := NewLeakTransport(nil)
+	client := &http.Client{Transport: leaks}
+
+	res, err := client.Get(server.URL().String() + "/ping")
+	require.NoError(t, err)
+	other, err := client.Get(server.URL().String() + "/cluster")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"GET /cluster (200)", "GET /ping (204)"}, leaks.Open())
+	assert.EqualError(t, leaks.Check(), "2 response bodies left open: GET /cluster (200), GET /ping (204)")
+
+	require.NoError(t, res.Body.Close())
+	require.NoError(t, other.Body.Close())
+	assert.Empty(t, leaks.Open())
+	assert.NoError(t, leaks.Check())
+}
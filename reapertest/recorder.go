@@ -0,0 +1,236 @@
+package reapertest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+type RecorderMode int
+
+const (
+	// ModeReplay serves responses from a golden file, without network access. Requests that were not recorded fail.
+	ModeReplay RecorderMode = iota
+
+	// ModeRecord forwards requests to a real Reaper and captures the exchanges. Call Recorder.Save to write them.
+	ModeRecord
+)
+
+const redacted = "REDACTED"
+
+// Interaction is a recorded HTTP exchange. Sensitive values, such as credentials, session cookies and JWTs, are
+// replaced with REDACTED.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Recorder is an http.RoundTripper that records HTTP exchanges with Reaper into a golden file, and replays them in
+// later test runs. Pass it to the client with reaper.WithHttpClient(&http.Client{Transport: recorder}).
+//
+// On replay, a request is answered with the first recorded interaction not yet replayed that has the same method, path,
+// query and body; the host is ignored. Recorded exchanges are thus replayed in order, which makes polling loops
+// deterministic.
+type Recorder struct {
+	mode      RecorderMode
+	path      string
+	transport http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []*Interaction
+	replayed     []bool
+}
+
+// NewRecorder returns a recorder for the given golden file. In ModeRecord, requests are sent through transport, or
+// http.DefaultTransport if nil. In ModeReplay, the golden file must exist.
+func NewRecorder(path string, mode RecorderMode, transport http.RoundTripper) (*Recorder, error) {
+	recorder := &Recorder{mode: mode, path: path, transport: transport}
+	if mode == ModeRecord {
+		if recorder.transport == nil {
+			recorder.transport = http.DefaultTransport
+		}
+		return recorder, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recording: %w", err)
+	}
+	if err = json.Unmarshal(data, &recorder.interactions); err != nil {
+		return nil, fmt.Errorf("failed to parse recording %s: %w", path, err)
+	}
+	recorder.replayed = make([]bool, len(recorder.interactions))
+	return recorder, nil
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, err := recordRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if r.mode == ModeReplay {
+		return r.replay(req, recorded)
+	}
+	res, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	r.mu.Lock()
+	r.interactions = append(r.interactions, &Interaction{
+		Request:  *recorded,
+		Response: recordResponse(req.URL.Path, res, body),
+	})
+	r.mu.Unlock()
+	return res, nil
+}
+
+func (r *Recorder) replay(req *http.Request, recorded *RecordedRequest) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, interaction := range r.interactions {
+		if r.replayed[i] || !interaction.Request.matches(recorded) {
+			continue
+		}
+		r.replayed[i] = true
+		header := interaction.Response.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(strings.NewReader(interaction.Response.Body)),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("no recorded interaction left for %s %s in %s", recorded.Method, recorded.URL, r.path)
+}
+
+// Remaining returns the recorded interactions that have not been replayed yet. Always empty in ModeRecord.
+func (r *Recorder) Remaining() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var remaining []Interaction
+	for i, interaction := range r.interactions {
+		if r.mode == ModeReplay && !r.replayed[i] {
+			remaining = append(remaining, *interaction)
+		}
+	}
+	return remaining
+}
+
+// Save writes the recorded interactions to the golden file, creating its directory if needed. Does nothing in
+// ModeReplay.
+func (r *Recorder) Save() error {
+	if r.mode == ModeReplay {
+		return nil
+	}
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.interactions, "", "  ")
+	r.mu.Unlock()
+	if err == nil {
+		if err = os.MkdirAll(filepath.Dir(r.path), 0755); err == nil {
+			if err = os.WriteFile(r.path, append(data, '\n'), 0644); err == nil {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("failed to save recording %s: %w", r.path, err)
+}
+
+func recordRequest(req *http.Request) (*RecordedRequest, error) {
+	recorded := &RecordedRequest{
+		Method: req.Method,
+		URL:    req.URL.Path,
+		Header: req.Header.Clone(),
+	}
+	if query := req.URL.Query(); len(query) > 0 {
+		// Encode sorts the query parameters by key
+		recorded.URL += "?" + query.Encode()
+	}
+	for _, name := range []string{"Authorization", "Cookie"} {
+		if recorded.Header.Get(name) != "" {
+			recorded.Header.Set(name, redacted)
+		}
+	}
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		recorded.Body = scrubForm(string(body))
+	}
+	return recorded, nil
+}
+
+func recordResponse(path string, res *http.Response, body []byte) RecordedResponse {
+	recorded := RecordedResponse{StatusCode: res.StatusCode, Header: http.Header{}, Body: string(body)}
+	for _, name := range []string{"Content-Type", "Location"} {
+		if value := res.Header.Get(name); value != "" {
+			recorded.Header.Set(name, value)
+		}
+	}
+	for _, cookie := range res.Cookies() {
+		recorded.Header.Add("Set-Cookie", (&http.Cookie{Name: cookie.Name, Value: redacted}).String())
+	}
+	if path == "/jwt" && res.StatusCode == http.StatusOK {
+		recorded.Body = redacted
+	}
+	if path == "/login" {
+		var login map[string]interface{}
+		if json.Unmarshal(body, &login) == nil {
+			if _, found := login["token"]; found {
+				login["token"] = redacted
+				scrubbed, _ := json.Marshal(login)
+				recorded.Body = string(scrubbed)
+			}
+		}
+	}
+	return recorded
+}
+
+// scrubForm redacts the password of form-encoded bodies, and returns other bodies unchanged.
+func scrubForm(body string) string {
+	form, err := url.ParseQuery(body)
+	if err != nil || form.Get("password") == "" {
+		return body
+	}
+	form.Set("password", redacted)
+	return form.Encode()
+}
+
+func (r *RecordedRequest) matches(other *RecordedRequest) bool {
+	return r.Method == other.Method && r.URL == other.URL && r.Body == other.Body
+}
@@ -0,0 +1,78 @@
+package reapertest_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/k8ssandra/reaper-client-go/reaper"
+	"github.com/k8ssandra/reaper-client-go/reapertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordReplay(t *testing.T) {
+	for name, options := range map[string][]reapertest.ServerOption{
+		"session": {reapertest.WithCredentials("user", "s3cret")},
+		"jwt":     {reapertest.WithCredentials("user", "s3cret"), reapertest.WithJwtInLoginBody()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "recordings", "test.json")
+			server := reapertest.NewServer(options...)
+			defer server.Close()
+			server.AddCassandraCluster(reapertest.NewCassandraCluster("cluster-1", 3).WithKeyspace("ks", 3, "table1"))
+
+			recorder, err := reapertest.NewRecorder(path, reapertest.ModeRecord, nil)
+			require.NoError(t, err)
+			client := reaper.NewClient(server.URL(), reaper.WithHttpClient(&http.Client{Transport: recorder}))
+			require.NoError(t, client.Login(context.Background(), "user", "s3cret"))
+			require.NoError(t, client.AddCluster(context.Background(), "cluster-1", "cluster-1-node-0"))
+			runId, err := client.CreateRepairRun(context.Background(), "cluster-1", "ks", "Alice", nil)
+			require.NoError(t, err)
+			require.NoError(t, client.StartRepairRun(context.Background(), runId))
+			running, err := client.RepairRun(context.Background(), runId)
+			require.NoError(t, err)
+			require.NoError(t, server.SetRepairRunState(runId.String(), "DONE"))
+			done, err := client.RepairRun(context.Background(), runId)
+			require.NoError(t, err)
+			require.NoError(t, recorder.Save())
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.NotContains(t, string(data), "s3cret")
+			assert.NotContains(t, string(data), "jwt-")
+			assert.NotContains(t, string(data), "session-")
+			assert.Contains(t, string(data), "REDACTED")
+			server.Close()
+
+			recorder, err = reapertest.NewRecorder(path, reapertest.ModeReplay, nil)
+			require.NoError(t, err)
+			client = reaper.NewClient(server.URL(), reaper.WithHttpClient(&http.Client{Transport: recorder}))
+			require.NoError(t, client.Login(context.Background(), "user", "another password"))
+			require.NoError(t, client.AddCluster(context.Background(), "cluster-1", "cluster-1-node-0"))
+			replayedId, err := client.CreateRepairRun(context.Background(), "cluster-1", "ks", "Alice", nil)
+			require.NoError(t, err)
+			assert.Equal(t, runId, replayedId)
+			require.NoError(t, client.StartRepairRun(context.Background(), runId))
+			run, err := client.RepairRun(context.Background(), runId)
+			require.NoError(t, err)
+			assert.Equal(t, running, run)
+			run, err = client.RepairRun(context.Background(), runId)
+			require.NoError(t, err)
+			assert.Equal(t, done, run)
+			assert.Empty(t, recorder.Remaining())
+
+			_, err = client.RepairRun(context.Background(), runId)
+			require.Error(t, err)
+			assert.True(t, strings.Contains(err.Error(), "no recorded interaction left for GET /repair_run/"+runId.String()))
+		})
+	}
+}
+
+func TestReplayMissingRecording(t *testing.T) {
+	_, err := reapertest.NewRecorder(filepath.Join(t.TempDir(), "missing.json"), reapertest.ModeReplay, nil)
+	assert.Error(t, err)
+}
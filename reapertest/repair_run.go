@@ -0,0 +1,617 @@
+package reapertest
+
+import (
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	stateNotStarted = "NOT_STARTED"
+	stateRunning    = "RUNNING"
+	stateError      = "ERROR"
+	stateDone       = "DONE"
+	statePaused     = "PAUSED"
+	stateAborted    = "ABORTED"
+
+	defaultIntensity           = 0.9
+	defaultSegmentCountPerNode = 16
+)
+
+type repairRun struct {
+	Id                string
+	Cluster           string
+	Owner             string
+	Keyspace          string
+	Cause             string
+	Tables            []string
+	IgnoredTables     []string
+	Nodes             []string
+	Datacenters       []string
+	State             string
+	Intensity         float64
+	IncrementalRepair bool
+	RepairParallelism string
+	RepairThreadCount int
+	RepairUnitId      string
+	LastEvent         string
+	Segments          []*repairSegment
+	Created           time.Time
+	Started           time.Time
+	Ended             time.Time
+	Paused            time.Time
+
+	// when the next segment may start
+	nextSegmentAt time.Time
+}
+
+type repairSegment struct {
+	Id          string
+	Start       *big.Int
+	End         *big.Int
+	Replicas    map[string]string
+	State       string
+	Coordinator string
+	FailCount   int
+	StartTime   time.Time
+	EndTime     time.Time
+}
+
+// repairParameters holds the parameters shared by repair runs and repair schedules.
+type repairParameters struct {
+	Cluster             *CassandraCluster
+	Keyspace            string
+	Owner               string
+	Cause               string
+	Tables              []string
+	IgnoredTables       []string
+	Nodes               []string
+	Datacenters         []string
+	Intensity           float64
+	IncrementalRepair   bool
+	RepairParallelism   string
+	RepairThreadCount   int
+	SegmentCountPerNode int
+}
+
+// parseRepairParameters validates the query parameters common to POST /repair_run and POST /repair_schedule. On
+// failure, it returns the HTTP status and message to send back.
+func (s *Server) parseRepairParameters(query url.Values) (*repairParameters, int, string) {
+	clusterName := query.Get("clusterName")
+	if clusterName == "" {
+		return nil, http.StatusBadRequest, "missing query parameter \"clusterName\""
+	}
+	cluster, found := s.clusters[clusterName]
+	if !found {
+		return nil, http.StatusNotFound, fmt.Sprintf("No cluster found with name %q", clusterName)
+	}
+	params := &repairParameters{
+		Cluster:             cluster,
+		Keyspace:            query.Get("keyspace"),
+		Owner:               query.Get("owner"),
+		Cause:               query.Get("cause"),
+		Tables:              splitList(query.Get("tables")),
+		IgnoredTables:       splitList(query.Get("blacklistedTables")),
+		Nodes:               splitList(query.Get("nodes")),
+		Datacenters:         splitList(query.Get("datacenters")),
+		Intensity:           defaultIntensity,
+		IncrementalRepair:   query.Get("incrementalRepair") == "true",
+		RepairParallelism:   query.Get("repairParallelism"),
+		RepairThreadCount:   1,
+		SegmentCountPerNode: defaultSegmentCountPerNode,
+	}
+	if params.Keyspace == "" {
+		return nil, http.StatusBadRequest, "missing query parameter \"keyspace\""
+	}
+	if params.Owner == "" {
+		return nil, http.StatusBadRequest, "missing query parameter \"owner\""
+	}
+	keyspace := cluster.keyspace(params.Keyspace)
+	if keyspace == nil {
+		return nil, http.StatusNotFound, fmt.Sprintf("keyspace %s doesn't exist in cluster %s", params.Keyspace, cluster.Name)
+	}
+	if len(params.Tables) > 0 && len(params.IgnoredTables) > 0 {
+		return nil, http.StatusBadRequest, "Cannot use both tables and blacklistedTables"
+	}
+	for _, table := range append(append([]string(nil), params.Tables...), params.IgnoredTables...) {
+		if !contains(keyspace.Tables, table) {
+			return nil, http.StatusNotFound, fmt.Sprintf("table %s doesn't exist in keyspace %s", table, params.Keyspace)
+		}
+	}
+	if len(params.Nodes) > 0 && len(params.Datacenters) > 0 {
+		return nil, http.StatusBadRequest, "Parameters nodes and datacenters are mutually exclusive"
+	}
+	for _, node := range params.Nodes {
+		if cluster.node(node) == nil {
+			return nil, http.StatusBadRequest, fmt.Sprintf("node %s doesn't exist in cluster %s", node, cluster.Name)
+		}
+	}
+	if value := query.Get("intensity"); value != "" {
+		intensity, err := strconv.ParseFloat(value, 64)
+		if err != nil || intensity <= 0 || intensity > 1 {
+			return nil, http.StatusBadRequest, "query parameter \"intensity\" must be in range (0.0, 1.0]"
+		}
+		params.Intensity = intensity
+	}
+	segmentCount := query.Get("segmentCountPerNode")
+	if segmentCount == "" {
+		segmentCount = query.Get("segmentCount")
+	}
+	if segmentCount != "" {
+		count, err := strconv.Atoi(segmentCount)
+		if err != nil || count < 1 || count > 1000 {
+			return nil, http.StatusBadRequest, "query parameter \"segmentCountPerNode\" must be in range [1, 1000]"
+		}
+		params.SegmentCountPerNode = count
+	}
+	if value := query.Get("repairThreadCount"); value != "" {
+		count, err := strconv.Atoi(value)
+		if err != nil || count < 1 || count > 4 {
+			return nil, http.StatusBadRequest, "query parameter \"repairThreadCount\" must be in range [1, 4]"
+		}
+		params.RepairThreadCount = count
+	}
+	switch params.RepairParallelism {
+	case "":
+		params.RepairParallelism = "DATACENTER_AWARE"
+	case "SEQUENTIAL", "PARALLEL", "DATACENTER_AWARE":
+	default:
+		return nil, http.StatusBadRequest, fmt.Sprintf("invalid repair parallelism %s", params.RepairParallelism)
+	}
+	if params.IncrementalRepair {
+		// incremental repairs are always parallel
+		params.RepairParallelism = "PARALLEL"
+	}
+	return params, 0, ""
+}
+
+// repairedTables returns the tables a run of these parameters will repair.
+func (p *repairParameters) repairedTables() []string {
+	if len(p.Tables) > 0 {
+		return p.Tables
+	}
+	var tables []string
+	for _, table := range p.Cluster.keyspace(p.Keyspace).Tables {
+		if !contains(p.IgnoredTables, table) {
+			tables = append(tables, table)
+		}
+	}
+	return tables
+}
+
+func (s *Server) newRepairRun(params *repairParameters) *repairRun {
+	id, _ := uuid.NewUUID()
+	run := &repairRun{
+		Id:                id.String(),
+		Cluster:           params.Cluster.Name,
+		Owner:             params.Owner,
+		Keyspace:          params.Keyspace,
+		Cause:             params.Cause,
+		Tables:            params.repairedTables(),
+		IgnoredTables:     params.IgnoredTables,
+		Nodes:             params.Nodes,
+		Datacenters:       params.Datacenters,
+		State:             stateNotStarted,
+		Intensity:         params.Intensity,
+		IncrementalRepair: params.IncrementalRepair,
+		RepairParallelism: params.RepairParallelism,
+		RepairThreadCount: params.RepairThreadCount,
+		RepairUnitId:      repairUnitId(params),
+		LastEvent:         "no events",
+		Created:           s.now(),
+	}
+	run.Segments = generateSegments(params)
+	s.runs[run.Id] = run
+	s.runOrder = append(s.runOrder, run.Id)
+	return run
+}
+
+// generateSegments splits the ring evenly in SegmentCountPerNode segments per node, and computes the replicas of each
+// segment with SimpleStrategy-like placement.
+func generateSegments(params *repairParameters) []*repairSegment {
+	cluster := params.Cluster
+	rf := cluster.keyspace(params.Keyspace).ReplicationFactor
+	if rf < 1 || rf > len(cluster.Nodes) {
+		rf = len(cluster.Nodes)
+	}
+	count := params.SegmentCountPerNode * len(cluster.Nodes)
+	tokens := cluster.tokens()
+	var segments []*repairSegment
+	for i := 0; i < count; i++ {
+		start := ringPosition(i, count)
+		end := ringPosition(i+1, count)
+		if i == count-1 {
+			end = new(big.Int).Sub(new(big.Int).Add(murmur3Min, murmur3Size), big.NewInt(1))
+		}
+		// the primary replica owns the first token >= end
+		primary := 0
+		for j := range cluster.Nodes {
+			if tokens[cluster.Nodes[j].Endpoint].Cmp(end) >= 0 {
+				primary = j
+				break
+			}
+		}
+		replicas := make(map[string]string, rf)
+		selected := false
+		for r := 0; r < rf; r++ {
+			node := cluster.Nodes[(primary+r)%len(cluster.Nodes)].withDefaults()
+			replicas[node.Endpoint] = node.Datacenter
+			if contains(params.Nodes, node.Endpoint) || contains(params.Datacenters, node.Datacenter) {
+				selected = true
+			}
+		}
+		if (len(params.Nodes) > 0 || len(params.Datacenters) > 0) && !selected {
+			continue
+		}
+		id, _ := uuid.NewUUID()
+		segments = append(segments, &repairSegment{
+			Id:       id.String(),
+			Start:    start,
+			End:      end,
+			Replicas: replicas,
+			State:    stateNotStarted,
+		})
+	}
+	return segments
+}
+
+func repairUnitId(params *repairParameters) string {
+	key := strings.Join([]string{
+		params.Cluster.Name,
+		params.Keyspace,
+		strings.Join(params.Tables, ","),
+		strings.Join(params.IgnoredTables, ","),
+		strings.Join(params.Nodes, ","),
+		strings.Join(params.Datacenters, ","),
+		strconv.FormatBool(params.IncrementalRepair),
+		strconv.Itoa(params.RepairThreadCount),
+	}, "|")
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(key)).String()
+}
+
+func (s *Server) getRepairRuns(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	cluster := query.Get("cluster_name")
+	keyspace := query.Get("keyspace_name")
+	states := splitList(query.Get("state"))
+	runs := make([]*repairRunJson, 0)
+	for _, id := range s.runOrder {
+		run := s.runs[id]
+		if (cluster == "" || run.Cluster == cluster) &&
+			(keyspace == "" || run.Keyspace == keyspace) &&
+			(len(states) == 0 || contains(states, run.State)) {
+			runs = append(runs, run.toJson())
+		}
+	}
+	writeJson(w, http.StatusOK, runs)
+}
+
+func (s *Server) getRepairRun(w http.ResponseWriter, r *http.Request) {
+	run := s.findRun(w, r)
+	if run != nil {
+		writeJson(w, http.StatusOK, run.toJson())
+	}
+}
+
+func (s *Server) postRepairRun(w http.ResponseWriter, r *http.Request) {
+	params, status, message := s.parseRepairParameters(r.URL.Query())
+	if params == nil {
+		writeError(w, status, message)
+		return
+	}
+	run := s.newRepairRun(params)
+	w.Header().Set("Location", s.httpServer.URL+"/repair_run/"+run.Id)
+	writeJson(w, http.StatusCreated, run.toJson())
+}
+
+func (s *Server) deleteRepairRun(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	owner := r.URL.Query().Get("owner")
+	if owner == "" {
+		writeError(w, http.StatusBadRequest, "required query parameter \"owner\" is missing")
+		return
+	}
+	run, found := s.runs[id]
+	if !found {
+		// Reaper returns a spurious '%s' in the error message
+		writeError(w, http.StatusNotFound, "Repair run %s"+id+" not found")
+		return
+	}
+	if run.Owner != owner {
+		writeError(w, http.StatusConflict, fmt.Sprintf("Repair run %s is not owned by the user you defined: %s", id, owner))
+		return
+	}
+	if run.State == stateRunning {
+		writeError(w, http.StatusConflict, fmt.Sprintf("Repair run %s is currently running", id))
+		return
+	}
+	if run.runningSegment() != nil {
+		writeError(w, http.StatusConflict, fmt.Sprintf("Repair run %s has running segments", id))
+		return
+	}
+	s.removeRun(id)
+	if s.deleteQuirk {
+		writeError(w, http.StatusInternalServerError, "There was an error processing your request.")
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) putRepairRunState(w http.ResponseWriter, r *http.Request) {
+	run := s.findRun(w, r)
+	if run == nil {
+		return
+	}
+	newState := r.PathValue("state")
+	switch newState {
+	case stateRunning, statePaused, stateAborted:
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid state %s", newState))
+		return
+	}
+	if newState == run.State {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	transition := run.State + "->" + newState
+	now := s.now()
+	switch transition {
+	case "NOT_STARTED->RUNNING", "ERROR->RUNNING":
+		run.State = stateRunning
+		if run.Started.IsZero() {
+			run.Started = now
+		}
+		run.Ended = time.Time{}
+		run.nextSegmentAt = now
+		run.LastEvent = "Repair run started"
+	case "PAUSED->RUNNING":
+		run.State = stateRunning
+		run.Paused = time.Time{}
+		run.nextSegmentAt = now
+		run.LastEvent = "Repair run resumed"
+	case "RUNNING->PAUSED":
+		// the running segment is allowed to finish
+		if segment := run.runningSegment(); segment != nil {
+			segment.State = stateDone
+			segment.EndTime = now
+		}
+		run.State = statePaused
+		run.Paused = now
+		run.LastEvent = "Repair run paused"
+	case "NOT_STARTED->ABORTED", "RUNNING->ABORTED", "PAUSED->ABORTED":
+		if segment := run.runningSegment(); segment != nil {
+			segment.reset()
+		}
+		run.State = stateAborted
+		run.Ended = now
+		run.LastEvent = "Repair run aborted"
+	default:
+		writeError(w, http.StatusConflict, fmt.Sprintf("Transition %s not supported.", transition))
+		return
+	}
+	writeJson(w, http.StatusOK, run.toJson())
+}
+
+func (s *Server) putRepairRunIntensity(w http.ResponseWriter, r *http.Request) {
+	run := s.findRun(w, r)
+	if run == nil {
+		return
+	}
+	intensity, err := strconv.ParseFloat(r.PathValue("intensity"), 64)
+	if err != nil || intensity <= 0 || intensity > 1 {
+		writeError(w, http.StatusBadRequest, "intensity must be in range (0.0, 1.0]")
+		return
+	}
+	if run.State != statePaused && run.State != stateNotStarted {
+		writeError(w, http.StatusConflict, fmt.Sprintf("repair run %s must be paused to change its intensity", run.Id))
+		return
+	}
+	run.Intensity = intensity
+	writeJson(w, http.StatusOK, run.toJson())
+}
+
+func (s *Server) getRepairRunSegments(w http.ResponseWriter, r *http.Request) {
+	run := s.findRun(w, r)
+	if run == nil {
+		return
+	}
+	segments := make([]*repairSegmentJson, 0, len(run.Segments))
+	for _, segment := range run.Segments {
+		segments = append(segments, segment.toJson(run))
+	}
+	writeJson(w, http.StatusOK, segments)
+}
+
+func (s *Server) abortRepairRunSegment(w http.ResponseWriter, r *http.Request) {
+	run := s.findRun(w, r)
+	if run == nil {
+		return
+	}
+	segmentId := r.PathValue("segmentId")
+	var segment *repairSegment
+	for _, candidate := range run.Segments {
+		if candidate.Id == segmentId {
+			segment = candidate
+		}
+	}
+	if segment == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("repair segment %s doesn't exist", segmentId))
+		return
+	}
+	if run.State == stateDone {
+		writeError(w, http.StatusConflict, "Cannot abort segment on repair run with status DONE")
+		return
+	}
+	if segment.State == stateRunning {
+		segment.reset()
+		// give Reaper some time before the segment is picked up again
+		s.holdOff(run)
+	}
+	writeJson(w, http.StatusOK, segment.toJson(run))
+}
+
+func (s *Server) purgeRepairRuns(w http.ResponseWriter, _ *http.Request) {
+	purged := 0
+	for _, id := range append([]string(nil), s.runOrder...) {
+		if state := s.runs[id].State; state == stateDone || state == stateError || state == stateAborted {
+			s.removeRun(id)
+			purged++
+		}
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(strconv.Itoa(purged)))
+}
+
+func (s *Server) findRun(w http.ResponseWriter, r *http.Request) *repairRun {
+	id := r.PathValue("id")
+	run, found := s.runs[id]
+	if !found {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("repair run %s doesn't exist", id))
+		return nil
+	}
+	return run
+}
+
+func (s *Server) removeRun(id string) {
+	delete(s.runs, id)
+	for i, candidate := range s.runOrder {
+		if candidate == id {
+			s.runOrder = append(s.runOrder[:i], s.runOrder[i+1:]...)
+			break
+		}
+	}
+}
+
+func (run *repairRun) runningSegment() *repairSegment {
+	for _, segment := range run.Segments {
+		if segment.State == stateRunning {
+			return segment
+		}
+	}
+	return nil
+}
+
+func (run *repairRun) segmentsRepaired() int {
+	repaired := 0
+	for _, segment := range run.Segments {
+		if segment.State == stateDone {
+			repaired++
+		}
+	}
+	return repaired
+}
+
+func (run *repairRun) toJson() *repairRunJson {
+	var duration *string
+	if !run.Started.IsZero() && !run.Ended.IsZero() {
+		d := run.Ended.Sub(run.Started).Round(time.Millisecond).String()
+		duration = &d
+	}
+	return &repairRunJson{
+		Id:                run.Id,
+		Cluster:           run.Cluster,
+		Owner:             run.Owner,
+		Keyspace:          run.Keyspace,
+		Tables:            nonNil(run.Tables),
+		Cause:             run.Cause,
+		State:             run.State,
+		Intensity:         run.Intensity,
+		IncrementalRepair: run.IncrementalRepair,
+		TotalSegments:     len(run.Segments),
+		RepairParallelism: run.RepairParallelism,
+		SegmentsRepaired:  run.segmentsRepaired(),
+		LastEvent:         run.LastEvent,
+		Duration:          duration,
+		Nodes:             nonNil(run.Nodes),
+		Datacenters:       nonNil(run.Datacenters),
+		IgnoredTables:     nonNil(run.IgnoredTables),
+		RepairThreadCount: run.RepairThreadCount,
+		RepairUnitId:      run.RepairUnitId,
+		CreationTime:      formatTime(run.Created),
+		StartTime:         formatTime(run.Started),
+		EndTime:           formatTime(run.Ended),
+		PauseTime:         formatTime(run.Paused),
+	}
+}
+
+func (segment *repairSegment) reset() {
+	segment.State = stateNotStarted
+	segment.Coordinator = ""
+	segment.StartTime = time.Time{}
+	segment.EndTime = time.Time{}
+}
+
+func (segment *repairSegment) toJson(run *repairRun) *repairSegmentJson {
+	tokenRange := tokenRangeJson{Start: segment.Start, End: segment.End}
+	json := &repairSegmentJson{
+		Id:           segment.Id,
+		RunId:        run.Id,
+		RepairUnitId: run.RepairUnitId,
+		TokenRange: segmentJson{
+			BaseRange:   tokenRange,
+			TokenRanges: []tokenRangeJson{tokenRange},
+			Replicas:    segment.Replicas,
+		},
+		FailCount:       segment.FailCount,
+		State:           segment.State,
+		CoordinatorHost: segment.Coordinator,
+		Replicas:        segment.Replicas,
+	}
+	if !segment.StartTime.IsZero() {
+		json.StartTime = segment.StartTime.UnixMilli()
+	}
+	if !segment.EndTime.IsZero() {
+		json.EndTime = segment.EndTime.UnixMilli()
+	}
+	return json
+}
+
+func isTerminated(state string) bool {
+	return state == stateDone || state == stateError || state == stateAborted
+}
+
+func splitList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+func sortedReplicas(replicas map[string]string) []string {
+	endpoints := make([]string, 0, len(replicas))
+	for endpoint := range replicas {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Strings(endpoints)
+	return endpoints
+}
@@ -0,0 +1,221 @@
+package reapertest
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	scheduleActive = "ACTIVE"
+	schedulePaused = "PAUSED"
+)
+
+type repairSchedule struct {
+	Id             string
+	Cluster        string
+	State          string
+	DaysBetween    int
+	Params         *repairParameters
+	RepairUnitId   string
+	Created        time.Time
+	Paused         time.Time
+	NextActivation time.Time
+}
+
+func (s *Server) getRepairSchedules(w http.ResponseWriter, _ *http.Request) {
+	s.writeSchedules(w, "")
+}
+
+func (s *Server) getRepairSchedulesForCluster(w http.ResponseWriter, r *http.Request) {
+	s.writeSchedules(w, r.PathValue("name"))
+}
+
+func (s *Server) writeSchedules(w http.ResponseWriter, cluster string) {
+	schedules := make([]*repairSchedule, 0, len(s.schedules))
+	for _, schedule := range s.schedules {
+		if cluster == "" || schedule.Cluster == cluster {
+			schedules = append(schedules, schedule)
+		}
+	}
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].Created.Before(schedules[j].Created) ||
+			schedules[i].Created.Equal(schedules[j].Created) && schedules[i].Id < schedules[j].Id
+	})
+	json := make([]*repairScheduleJson, 0, len(schedules))
+	for _, schedule := range schedules {
+		json = append(json, schedule.toJson())
+	}
+	writeJson(w, http.StatusOK, json)
+}
+
+func (s *Server) getRepairSchedule(w http.ResponseWriter, r *http.Request) {
+	schedule := s.findSchedule(w, r)
+	if schedule != nil {
+		writeJson(w, http.StatusOK, schedule.toJson())
+	}
+}
+
+func (s *Server) postRepairSchedule(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	params, status, message := s.parseRepairParameters(query)
+	if params == nil {
+		writeError(w, status, message)
+		return
+	}
+	daysBetween, err := strconv.Atoi(query.Get("scheduleDaysBetween"))
+	if err != nil || daysBetween < 0 {
+		writeError(w, http.StatusBadRequest, "query parameter \"scheduleDaysBetween\" must be a positive integer")
+		return
+	}
+	now := s.now()
+	nextActivation := now.Truncate(24 * time.Hour).Add(24 * time.Hour)
+	if value := query.Get("scheduleTriggerTime"); value != "" {
+		if nextActivation, err = parseTriggerTime(value); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid schedule trigger time %s", value))
+			return
+		}
+	}
+	tables := strings.Join(sortedCopy(params.repairedTables()), ",")
+	for _, existing := range s.schedules {
+		if existing.Cluster == params.Cluster.Name &&
+			existing.Params.Keyspace == params.Keyspace &&
+			strings.Join(sortedCopy(existing.Params.repairedTables()), ",") == tables &&
+			existing.Params.IncrementalRepair == params.IncrementalRepair {
+			writeError(w, http.StatusConflict, fmt.Sprintf(
+				"A repair schedule already exists for cluster %q, keyspace %q, and column families: [%s]",
+				params.Cluster.Name,
+				params.Keyspace,
+				tables,
+			))
+			return
+		}
+	}
+	id, _ := uuid.NewUUID()
+	schedule := &repairSchedule{
+		Id:             id.String(),
+		Cluster:        params.Cluster.Name,
+		State:          scheduleActive,
+		DaysBetween:    daysBetween,
+		Params:         params,
+		RepairUnitId:   repairUnitId(params),
+		Created:        now,
+		NextActivation: nextActivation,
+	}
+	s.schedules[schedule.Id] = schedule
+	w.Header().Set("Location", s.httpServer.URL+"/repair_schedule/"+schedule.Id)
+	writeJson(w, http.StatusCreated, schedule.toJson())
+}
+
+func (s *Server) putRepairScheduleState(w http.ResponseWriter, r *http.Request) {
+	schedule := s.findSchedule(w, r)
+	if schedule == nil {
+		return
+	}
+	state := r.URL.Query().Get("state")
+	if state != scheduleActive && state != schedulePaused {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid state %s", state))
+		return
+	}
+	if state == schedule.State {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	schedule.State = state
+	if state == schedulePaused {
+		schedule.Paused = s.now()
+	} else {
+		schedule.Paused = time.Time{}
+	}
+	writeJson(w, http.StatusOK, schedule.toJson())
+}
+
+func (s *Server) startRepairSchedule(w http.ResponseWriter, r *http.Request) {
+	schedule := s.findSchedule(w, r)
+	if schedule == nil {
+		return
+	}
+	run := s.newRepairRun(schedule.Params)
+	run.Cause = fmt.Sprintf("manual start of schedule %s", schedule.Id)
+	s.forceRunState(run, stateRunning)
+	run.LastEvent = "Repair run started"
+	writeJson(w, http.StatusOK, schedule.toJson())
+}
+
+func (s *Server) deleteRepairSchedule(w http.ResponseWriter, r *http.Request) {
+	owner := r.URL.Query().Get("owner")
+	if owner == "" {
+		writeError(w, http.StatusBadRequest, "required query parameter \"owner\" is missing")
+		return
+	}
+	schedule := s.findSchedule(w, r)
+	if schedule == nil {
+		return
+	}
+	if schedule.Params.Owner != owner {
+		writeError(w, http.StatusConflict, fmt.Sprintf(
+			"Repair schedule %s is not owned by the user you defined: %s", schedule.Id, owner))
+		return
+	}
+	if schedule.State == scheduleActive {
+		writeError(w, http.StatusConflict, fmt.Sprintf(
+			"Repair schedule %s currently in ACTIVE state, it must be paused before deletion", schedule.Id))
+		return
+	}
+	delete(s.schedules, schedule.Id)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) findSchedule(w http.ResponseWriter, r *http.Request) *repairSchedule {
+	id := r.PathValue("id")
+	schedule, found := s.schedules[id]
+	if !found {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Repair schedule with id %s doesn't exist", id))
+		return nil
+	}
+	return schedule
+}
+
+func (schedule *repairSchedule) toJson() *repairScheduleJson {
+	params := schedule.Params
+	return &repairScheduleJson{
+		Id:                  schedule.Id,
+		Owner:               params.Owner,
+		State:               schedule.State,
+		Intensity:           params.Intensity,
+		ClusterName:         schedule.Cluster,
+		KeyspaceName:        params.Keyspace,
+		Tables:              nonNil(params.Tables),
+		IgnoredTables:       nonNil(params.IgnoredTables),
+		Nodes:               nonNil(params.Nodes),
+		Datacenters:         nonNil(params.Datacenters),
+		RepairParallelism:   params.RepairParallelism,
+		IncrementalRepair:   params.IncrementalRepair,
+		RepairThreadCount:   params.RepairThreadCount,
+		SegmentCountPerNode: params.SegmentCountPerNode,
+		RepairUnitId:        schedule.RepairUnitId,
+		DaysBetween:         schedule.DaysBetween,
+		CreationTime:        *formatTime(schedule.Created),
+		PauseTime:           formatTime(schedule.Paused),
+		NextActivation:      *formatTime(schedule.NextActivation),
+	}
+}
+
+// parseTriggerTime accepts both RFC 3339 timestamps and the local ISO format used by the Reaper UI.
+func parseTriggerTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02T15:04:05", value)
+}
+
+func sortedCopy(values []string) []string {
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+	return sorted
+}
@@ -0,0 +1,356 @@
+// Package reapertest provides an in-memory fake Reaper server for unit tests.
+//
+// The fake server implements the REST endpoints used by reaper.Client: ping, version, login and JWT authentication,
+// clusters, repair runs and their segments, and repair schedules, along with OPTIONS on the endpoints of optional
+// features. It returns the same status codes and error messages as Reaper for the common error cases, and simulates the
+// progress of repair runs: once started, the segments of a run are repaired one after the other, honoring the run
+// intensity, until the run is DONE.
+//
+// A typical test looks like:
+//
+//	server := reapertest.NewServer()
+//	defer server.Close()
+//	server.AddCassandraCluster(reapertest.NewCassandraCluster("cluster-1", 3).WithKeyspace("ks", 3, "table1"))
+//	client := reaper.NewClient(server.URL())
+//	err := client.AddCluster(ctx, "cluster-1", "cluster-1-node-0")
+//
+// The package also provides http.RoundTripper implementations to use with reaper.WithHttpClient: Recorder records
+// exchanges with a real Reaper into golden files and replays them, FaultTransport injects latency, errors and
+// malformed responses, and LeakTransport detects response bodies left open.
+package reapertest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+)
+
+type Server struct {
+	mu         sync.Mutex
+	httpServer *httptest.Server
+
+	// Cassandra clusters reachable by Reaper, keyed by name
+	cassandra map[string]*CassandraCluster
+
+	// Clusters registered in Reaper, keyed by name
+	clusters map[string]*CassandraCluster
+
+	runs      map[string]*repairRun
+	runOrder  []string
+	schedules map[string]*repairSchedule
+
+	username        string
+	password        string
+	jwtInLoginBody  bool
+	sessions        map[string]bool
+	tokens          map[string]bool
+	jmxUsername     string
+	jmxPassword     string
+	segmentDuration time.Duration
+	deleteQuirk     bool
+	version         string
+	disabled        map[Feature]bool
+	now             func() time.Time
+	sequence        int
+}
+
+type ServerOption func(server *Server)
+
+// WithCredentials enables authentication: all endpoints except /ping and /login require a JWT obtained by logging in
+// with the given credentials.
+func WithCredentials(username, password string) ServerOption {
+	return func(server *Server) {
+		server.username = username
+		server.password = password
+	}
+}
+
+// WithJwtInLoginBody makes /login return the JWT directly in a JSON body, like recent Reaper versions, instead of a
+// JSESSIONID cookie to be exchanged on /jwt.
+func WithJwtInLoginBody() ServerOption {
+	return func(server *Server) {
+		server.jwtInLoginBody = true
+	}
+}
+
+// WithJmxCredentials sets the JMX credentials Reaper reports for all clusters.
+func WithJmxCredentials(username, password string) ServerOption {
+	return func(server *Server) {
+		server.jmxUsername = username
+		server.jmxPassword = password
+	}
+}
+
+// WithSegmentDuration sets how long it takes to repair a segment. Defaults to 10ms. After each segment, the simulated
+// Reaper waits for duration * (1 / intensity - 1) before starting the next one, like the real Reaper does.
+func WithSegmentDuration(duration time.Duration) ServerOption {
+	return func(server *Server) {
+		server.segmentDuration = duration
+	}
+}
+
+// WithDeleteRepairRunQuirk reproduces a bug of some Reaper versions where DELETE /repair_run/{id} returns 500 even
+// though the run was deleted.
+func WithDeleteRepairRunQuirk() ServerOption {
+	return func(server *Server) {
+		server.deleteQuirk = true
+	}
+}
+
+// WithVersion sets the version reported on /reaper/version. Defaults to 3.5.0. An empty version makes the endpoint
+// respond 404, like Reaper versions that don't report their version.
+func WithVersion(version string) ServerOption {
+	return func(server *Server) {
+		server.version = version
+	}
+}
+
+// Feature is an optional feature of Reaper, detected by clients from the existence of its endpoints.
+type Feature string
+
+const (
+	FeatureSnapshots       = Feature("snapshots")
+	FeatureDiagEvents      = Feature("diag_events")
+	FeaturePercentRepaired = Feature("percent_repaired")
+)
+
+// The route of each feature. The fake server only answers OPTIONS requests on them, listing the allowed methods like
+// Reaper does.
+var featureRoutes = map[Feature]string{
+	FeatureSnapshots:       "/snapshot/cluster/{name}",
+	FeatureDiagEvents:      "/diag_event/subscription",
+	FeaturePercentRepaired: "/repair_schedule/{name}/{id}/percent_repaired",
+}
+
+// WithoutFeatures removes the endpoints of the given features, like Reaper versions that predate them. By default,
+// all features are available.
+func WithoutFeatures(features ...Feature) ServerOption {
+	return func(server *Server) {
+		for _, feature := range features {
+			server.disabled[feature] = true
+		}
+	}
+}
+
+// NewServer starts a fake Reaper server. Call Close to shut it down.
+func NewServer(options ...ServerOption) *Server {
+	server := &Server{
+		cassandra:       map[string]*CassandraCluster{},
+		clusters:        map[string]*CassandraCluster{},
+		runs:            map[string]*repairRun{},
+		schedules:       map[string]*repairSchedule{},
+		sessions:        map[string]bool{},
+		tokens:          map[string]bool{},
+		disabled:        map[Feature]bool{},
+		segmentDuration: 10 * time.Millisecond,
+		version:         "3.5.0",
+		now:             time.Now,
+	}
+	for _, option := range options {
+		option(server)
+	}
+	server.httpServer = httptest.NewServer(server.routes())
+	return server
+}
+
+// URL returns the base URL of the server, suitable for reaper.NewClient.
+func (s *Server) URL() *url.URL {
+	u, _ := url.Parse(s.httpServer.URL)
+	return u
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.httpServer.Close()
+}
+
+// AddCassandraCluster makes a Cassandra cluster reachable by the fake Reaper. The cluster is not registered in Reaper:
+// use Client.AddCluster for that.
+func (s *Server) AddCassandraCluster(cluster *CassandraCluster) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cassandra[cluster.Name] = cluster
+}
+
+// AddKeyspace creates a keyspace in a Cassandra cluster previously added with AddCassandraCluster.
+func (s *Server) AddKeyspace(cluster, keyspace string, replicationFactor int, tables ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, found := s.cassandra[cluster]
+	if !found {
+		return fmt.Errorf("unknown cassandra cluster %s", cluster)
+	}
+	if ks := c.keyspace(keyspace); ks != nil {
+		ks.Tables = append(ks.Tables, tables...)
+		return nil
+	}
+	c.WithKeyspace(keyspace, replicationFactor, tables...)
+	return nil
+}
+
+// SetRepairRunState forces the state of a repair run, e.g. to simulate a failure. Setting a run to DONE marks all its
+// segments as repaired.
+func (s *Server) SetRepairRunState(runId string, state string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	run, found := s.runs[runId]
+	if !found {
+		return fmt.Errorf("repair run %s doesn't exist", runId)
+	}
+	s.forceRunState(run, state)
+	return nil
+}
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("HEAD /ping", s.handlePing)
+	mux.HandleFunc("GET /ping", s.handlePing)
+	mux.HandleFunc("POST /login", s.handleLogin)
+	mux.HandleFunc("GET /jwt", s.handleJwt)
+
+	s.handle(mux, "GET /reaper/version", s.getVersion)
+
+	s.handle(mux, "GET /cluster", s.getClusterNames)
+	s.handle(mux, "GET /cluster/{name}", s.getCluster)
+	s.handle(mux, "PUT /cluster/{name}", s.putCluster)
+	s.handle(mux, "DELETE /cluster/{name}", s.deleteCluster)
+	s.handle(mux, "GET /cluster/{name}/tables", s.getClusterTables)
+
+	s.handle(mux, "GET /repair_run", s.getRepairRuns)
+	s.handle(mux, "POST /repair_run", s.postRepairRun)
+	s.handle(mux, "POST /repair_run/purge", s.purgeRepairRuns)
+	s.handle(mux, "GET /repair_run/{id}", s.getRepairRun)
+	s.handle(mux, "DELETE /repair_run/{id}", s.deleteRepairRun)
+	s.handle(mux, "PUT /repair_run/{id}/state/{state}", s.putRepairRunState)
+	s.handle(mux, "PUT /repair_run/{id}/intensity/{intensity}", s.putRepairRunIntensity)
+	s.handle(mux, "GET /repair_run/{id}/segments", s.getRepairRunSegments)
+	s.handle(mux, "POST /repair_run/{id}/segments/abort/{segmentId}", s.abortRepairRunSegment)
+
+	s.handle(mux, "GET /repair_schedule", s.getRepairSchedules)
+	s.handle(mux, "POST /repair_schedule", s.postRepairSchedule)
+	s.handle(mux, "GET /repair_schedule/cluster/{name}", s.getRepairSchedulesForCluster)
+	s.handle(mux, "POST /repair_schedule/start/{id}", s.startRepairSchedule)
+	s.handle(mux, "GET /repair_schedule/{id}", s.getRepairSchedule)
+	s.handle(mux, "PUT /repair_schedule/{id}", s.putRepairScheduleState)
+	s.handle(mux, "DELETE /repair_schedule/{id}", s.deleteRepairSchedule)
+
+	for feature, route := range featureRoutes {
+		if !s.disabled[feature] {
+			s.handle(mux, "OPTIONS "+route, s.handleOptions)
+		}
+	}
+	return mux
+}
+
+// handle registers an authenticated handler. Handlers run with the server lock held, after the simulated repairs
+// have been brought up to date.
+func (s *Server) handle(mux *http.ServeMux, pattern string, handler http.HandlerFunc) {
+	mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if !s.authenticated(r) {
+			writeError(w, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+		s.simulate()
+		handler(w, r)
+	})
+}
+
+func (s *Server) handlePing(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleOptions(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Allow", "GET,OPTIONS")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getVersion(w http.ResponseWriter, _ *http.Request) {
+	if s.version == "" {
+		writeError(w, http.StatusNotFound, "HTTP 404 Not Found")
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = w.Write([]byte(s.version))
+}
+
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	username := r.PostForm.Get("username")
+	if s.username != "" && (username != s.username || r.PostForm.Get("password") != s.password) {
+		writeError(w, http.StatusUnauthorized, "Invalid credentials")
+		return
+	}
+	if s.jwtInLoginBody {
+		writeJson(w, http.StatusOK, &loginJson{Token: s.newToken(), Username: username, Roles: []string{"operator"}})
+		return
+	}
+	session := s.nextId("session")
+	s.sessions[session] = true
+	http.SetCookie(w, &http.Cookie{Name: "JSESSIONID", Value: session})
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) handleJwt(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cookie, err := r.Cookie("JSESSIONID")
+	if err != nil || !s.sessions[cookie.Value] {
+		writeError(w, http.StatusUnauthorized, "No valid session")
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(s.newToken()))
+}
+
+func (s *Server) newToken() string {
+	token := s.nextId("jwt")
+	s.tokens[token] = true
+	return token
+}
+
+func (s *Server) authenticated(r *http.Request) bool {
+	if s.username == "" {
+		return true
+	}
+	var token string
+	_, err := fmt.Sscanf(r.Header.Get("Authorization"), "Bearer %s", &token)
+	return err == nil && s.tokens[token]
+}
+
+// nextId returns a unique, deterministic identifier.
+func (s *Server) nextId(prefix string) string {
+	s.sequence++
+	return fmt.Sprintf("%s-%d", prefix, s.sequence)
+}
+
+func writeJson(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(message))
+}
+
+func formatTime(t time.Time) *string {
+	if t.IsZero() {
+		return nil
+	}
+	s := t.UTC().Format(time.RFC3339Nano)
+	return &s
+}
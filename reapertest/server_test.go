@@ -0,0 +1,185 @@
+package reapertest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/k8ssandra/reaper-client-go/reaper"
+	"github.com/k8ssandra/reaper-client-go/reapertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newServer(t *testing.T, options ...reapertest.ServerOption) (*reapertest.Server, reaper.Client) {
+	server := reapertest.NewServer(options...)
+	t.Cleanup(server.Close)
+	server.AddCassandraCluster(reapertest.NewCassandraCluster("cluster-1", 3).WithKeyspace("ks", 3, "table1", "table2"))
+	client := reaper.NewClient(server.URL())
+	require.NoError(t, client.AddCluster(context.Background(), "cluster-1", "cluster-1-node-1"))
+	return server, client
+}
+
+func TestAuthentication(t *testing.T) {
+	for name, options := range map[string][]reapertest.ServerOption{
+		"session": {reapertest.WithCredentials("user", "pass")},
+		"jwt":     {reapertest.WithCredentials("user", "pass"), reapertest.WithJwtInLoginBody()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			server := reapertest.NewServer(options...)
+			defer server.Close()
+			client := reaper.NewClient(server.URL())
+			_, err := client.GetClusterNames(context.Background())
+			assert.Error(t, err)
+			assert.Error(t, client.Login(context.Background(), "user", "wrong"))
+			require.NoError(t, client.Login(context.Background(), "user", "pass"))
+			names, err := client.GetClusterNames(context.Background())
+			assert.NoError(t, err)
+			assert.Empty(t, names)
+		})
+	}
+}
+
+func TestAddClusterUnknownSeed(t *testing.T) {
+	_, client := newServer(t)
+	err := client.AddCluster(context.Background(), "cluster-2", "cluster-2-node-0")
+	assert.Error(t, err)
+}
+
+func TestRepairRunSegments(t *testing.T) {
+	_, client := newServer(t)
+	runId, err := client.CreateRepairRun(
+		context.Background(),
+		"cluster-1",
+		"ks",
+		"Alice",
+		&reaper.RepairRunCreateOptions{SegmentCountPerNode: 2, IgnoredTables: []string{"table2"}},
+	)
+	require.NoError(t, err)
+	run, err := client.RepairRun(context.Background(), runId)
+	require.NoError(t, err)
+	assert.Equal(t, 6, run.TotalSegments)
+	assert.Equal(t, []string{"table1"}, run.Tables)
+	segments, err := client.RepairRunSegments(context.Background(), runId)
+	require.NoError(t, err)
+	assert.Len(t, segments, 6)
+	for _, segment := range segments {
+		assert.Len(t, segment.Replicas, 3)
+		assert.Equal(t, reaper.RepairSegmentStateNotStarted, segment.State)
+	}
+}
+
+func TestRepairRunValidation(t *testing.T) {
+	server, _ := newServer(t)
+	// the server validates options on its own
+	client := reaper.NewClient(server.URL(), reaper.WithoutValidation())
+	_, err := client.CreateRepairRun(context.Background(), "cluster-1", "ks", "Alice", &reaper.RepairRunCreateOptions{
+		Tables:        []string{"table1"},
+		IgnoredTables: []string{"table2"},
+	})
+	assert.Error(t, err)
+	_, err = client.CreateRepairRun(context.Background(), "cluster-1", "unknown", "Alice", nil)
+	assert.Error(t, err)
+	_, err = client.CreateRepairRun(context.Background(), "cluster-2", "ks", "Alice", nil)
+	assert.Error(t, err)
+}
+
+func TestRepairRunSimulation(t *testing.T) {
+	_, client := newServer(t, reapertest.WithSegmentDuration(time.Millisecond))
+	runId, err := client.CreateRepairRun(context.Background(), "cluster-1", "ks", "Alice", &reaper.RepairRunCreateOptions{
+		SegmentCountPerNode: 2,
+		Intensity:           1,
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.StartRepairRun(context.Background(), runId))
+	assert.Eventually(t, func() bool {
+		run, err := client.RepairRun(context.Background(), runId)
+		return err == nil && run.State == reaper.RepairRunStateDone && run.SegmentsRepaired == 6
+	}, 5*time.Second, 5*time.Millisecond)
+	err = client.PauseRepairRun(context.Background(), runId)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Transition DONE->PAUSED not supported")
+	purged, err := client.PurgeRepairRuns(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+}
+
+func TestSetRepairRunState(t *testing.T) {
+	server, client := newServer(t)
+	runId, err := client.CreateRepairRun(context.Background(), "cluster-1", "ks", "Alice", nil)
+	require.NoError(t, err)
+	require.NoError(t, server.SetRepairRunState(runId.String(), "ERROR"))
+	run, err := client.RepairRun(context.Background(), runId)
+	require.NoError(t, err)
+	assert.Equal(t, reaper.RepairRunStateError, run.State)
+	assert.Error(t, server.SetRepairRunState("unknown", "DONE"))
+}
+
+func TestDeleteRepairRun(t *testing.T) {
+	_, client := newServer(t)
+	runId, err := client.CreateRepairRun(context.Background(), "cluster-1", "ks", "Alice", nil)
+	require.NoError(t, err)
+	err = client.DeleteRepairRun(context.Background(), runId, "Bob")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not owned by the user you defined")
+	require.NoError(t, client.StartRepairRun(context.Background(), runId))
+	err = client.DeleteRepairRun(context.Background(), runId, "Alice")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is currently running")
+	require.NoError(t, client.PauseRepairRun(context.Background(), runId))
+	assert.NoError(t, client.DeleteRepairRun(context.Background(), runId, "Alice"))
+}
+
+func TestDeleteRepairRunQuirk(t *testing.T) {
+	_, client := newServer(t, reapertest.WithDeleteRepairRunQuirk())
+	runId, err := client.CreateRepairRun(context.Background(), "cluster-1", "ks", "Alice", nil)
+	require.NoError(t, err)
+	// the client tolerates the 500 as long as the run is gone
+	assert.NoError(t, client.DeleteRepairRun(context.Background(), runId, "Alice"))
+}
+
+func TestRepairSchedules(t *testing.T) {
+	_, client := newServer(t)
+	ctx := context.Background()
+	id, err := client.CreateRepairSchedule(ctx, "cluster-1", "ks", "Alice", 7, &reaper.RepairScheduleCreateOptions{
+		Tables: []string{"table1"},
+	})
+	require.NoError(t, err)
+	_, err = client.CreateRepairSchedule(ctx, "cluster-1", "ks", "Alice", 7, &reaper.RepairScheduleCreateOptions{
+		Tables: []string{"table1"},
+	})
+	assert.Error(t, err)
+
+	schedule, err := client.RepairSchedule(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, string(reaper.RepairScheduleStateActive), schedule.State)
+	assert.Equal(t, 7, schedule.DaysBetween)
+	assert.Equal(t, []string{"table1"}, schedule.Tables)
+
+	schedules, err := client.RepairSchedulesForCluster(ctx, "cluster-1")
+	require.NoError(t, err)
+	assert.Len(t, schedules, 1)
+
+	require.NoError(t, client.StartRepairSchedule(ctx, id))
+	runs, err := client.RepairRuns(ctx, &reaper.RepairRunSearchOptions{Cluster: "cluster-1"})
+	require.NoError(t, err)
+	assert.Len(t, runs, 1)
+
+	assert.Error(t, client.DeleteRepairSchedule(ctx, id, "Alice"))
+	require.NoError(t, client.PauseRepairSchedule(ctx, id))
+	require.NoError(t, client.PauseRepairSchedule(ctx, id))
+	require.NoError(t, client.DeleteRepairSchedule(ctx, id, "Alice"))
+	_, err = client.RepairSchedule(ctx, id)
+	assert.Error(t, err)
+}
+
+func TestDeleteClusterWithSchedules(t *testing.T) {
+	_, client := newServer(t)
+	ctx := context.Background()
+	_, err := client.CreateRepairSchedule(ctx, "cluster-1", "ks", "Alice", 7, nil)
+	require.NoError(t, err)
+	assert.Error(t, client.DeleteCluster(ctx, "cluster-1"))
+	names, err := client.GetClusterNames(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cluster-1"}, names)
+}
@@ -0,0 +1,121 @@
+package reapertest
+
+import (
+	"fmt"
+	"time"
+)
+
+// simulate brings all running repair runs up to date. Progress is computed lazily from the server clock, so that the
+// fake server needs no background goroutine: segments are repaired one after the other, each taking segmentDuration,
+// and after each segment the run waits for segmentDuration * (1 / intensity - 1) before starting the next one.
+func (s *Server) simulate() {
+	now := s.now()
+	for _, id := range s.runOrder {
+		if run := s.runs[id]; run.State == stateRunning {
+			s.advance(run, now)
+		}
+	}
+}
+
+func (s *Server) advance(run *repairRun, now time.Time) {
+	for {
+		if segment := run.runningSegment(); segment != nil {
+			end := segment.StartTime.Add(s.segmentDuration)
+			if now.Before(end) {
+				return
+			}
+			segment.State = stateDone
+			segment.EndTime = end
+			run.nextSegmentAt = end.Add(s.backoff(run))
+			run.LastEvent = fmt.Sprintf("Repair of segment %s completed", segment.Id)
+			continue
+		}
+		next := run.nextPendingSegment()
+		if next == nil {
+			run.State = stateDone
+			run.Ended = run.lastSegmentEnd(now)
+			run.LastEvent = "All done"
+			return
+		}
+		if now.Before(run.nextSegmentAt) {
+			return
+		}
+		next.State = stateRunning
+		next.StartTime = run.nextSegmentAt
+		next.Coordinator = sortedReplicas(next.Replicas)[0]
+		run.LastEvent = fmt.Sprintf("Triggered repair of segment %s via host %s", next.Id, next.Coordinator)
+	}
+}
+
+func (s *Server) backoff(run *repairRun) time.Duration {
+	return time.Duration(float64(s.segmentDuration) * (1/run.Intensity - 1))
+}
+
+// holdOff delays the start of the next segment of a run, like Reaper does after a segment was aborted.
+func (s *Server) holdOff(run *repairRun) {
+	run.nextSegmentAt = s.now().Add(time.Duration(float64(s.segmentDuration) / run.Intensity))
+}
+
+// forceRunState sets the state of a run regardless of the allowed transitions.
+func (s *Server) forceRunState(run *repairRun, state string) {
+	now := s.now()
+	switch state {
+	case stateDone:
+		for _, segment := range run.Segments {
+			if segment.State != stateDone {
+				if segment.StartTime.IsZero() {
+					segment.StartTime = now
+				}
+				segment.State = stateDone
+				segment.EndTime = now
+			}
+		}
+		if run.Started.IsZero() {
+			run.Started = now
+		}
+		run.Ended = now
+	case stateError:
+		if segment := run.runningSegment(); segment != nil {
+			segment.reset()
+			segment.FailCount++
+		}
+		run.Ended = now
+	case stateRunning:
+		if run.Started.IsZero() {
+			run.Started = now
+		}
+		run.nextSegmentAt = now
+	case statePaused:
+		run.Paused = now
+	case stateAborted:
+		if segment := run.runningSegment(); segment != nil {
+			segment.reset()
+		}
+		run.Ended = now
+	}
+	run.State = state
+	run.LastEvent = fmt.Sprintf("Repair run state forced to %s", state)
+}
+
+func (run *repairRun) nextPendingSegment() *repairSegment {
+	for _, segment := range run.Segments {
+		if segment.State == stateNotStarted {
+			return segment
+		}
+	}
+	return nil
+}
+
+// lastSegmentEnd returns when the last segment of the run was repaired, or now if the run has no segments.
+func (run *repairRun) lastSegmentEnd(now time.Time) time.Time {
+	var end time.Time
+	for _, segment := range run.Segments {
+		if segment.EndTime.After(end) {
+			end = segment.EndTime
+		}
+	}
+	if end.IsZero() {
+		return now
+	}
+	return end
+}
@@ -0,0 +1,117 @@
+package reapertest
+
+import (
+	"math/big"
+)
+
+// The following types mirror the JSON payloads of the Reaper REST API. They are deliberately independent of the types
+// in package reaper so that the fake server describes the wire format, not the client's view of it.
+
+type clusterJson struct {
+	Name           string         `json:"name"`
+	JmxUsername    string         `json:"jmx_username,omitempty"`
+	JmxPasswordSet bool           `json:"jmx_password_is_set"`
+	Seeds          []string       `json:"seed_hosts"`
+	NodesStatus    nodeStatusJson `json:"nodes_status"`
+}
+
+type nodeStatusJson struct {
+	EndpointStates []gossipStateJson `json:"endpointStates"`
+}
+
+type gossipStateJson struct {
+	SourceNode    string                                    `json:"sourceNode"`
+	EndpointNames []string                                  `json:"endpointNames"`
+	TotalLoad     float64                                   `json:"totalLoad"`
+	Endpoints     map[string]map[string][]endpointStateJson `json:"endpoints"`
+}
+
+type endpointStateJson struct {
+	Endpoint       string  `json:"endpoint"`
+	DataCenter     string  `json:"dc"`
+	Rack           string  `json:"rack"`
+	HostId         string  `json:"hostId"`
+	Status         string  `json:"status"`
+	Severity       float64 `json:"severity"`
+	ReleaseVersion string  `json:"releaseVersion"`
+	Tokens         string  `json:"tokens"`
+	Load           float64 `json:"load"`
+}
+
+type repairRunJson struct {
+	Id                string   `json:"id"`
+	Cluster           string   `json:"cluster_name"`
+	Owner             string   `json:"owner"`
+	Keyspace          string   `json:"keyspace_name"`
+	Tables            []string `json:"column_families"`
+	Cause             string   `json:"cause"`
+	State             string   `json:"state"`
+	Intensity         float64  `json:"intensity"`
+	IncrementalRepair bool     `json:"incremental_repair"`
+	TotalSegments     int      `json:"total_segments"`
+	RepairParallelism string   `json:"repair_parallelism"`
+	SegmentsRepaired  int      `json:"segments_repaired"`
+	LastEvent         string   `json:"last_event"`
+	Duration          *string  `json:"duration"`
+	Nodes             []string `json:"nodes"`
+	Datacenters       []string `json:"datacenters"`
+	IgnoredTables     []string `json:"blacklisted_tables"`
+	RepairThreadCount int      `json:"repair_thread_count"`
+	RepairUnitId      string   `json:"repair_unit_id"`
+	CreationTime      *string  `json:"creation_time"`
+	StartTime         *string  `json:"start_time"`
+	EndTime           *string  `json:"end_time"`
+	PauseTime         *string  `json:"pause_time"`
+}
+
+type repairSegmentJson struct {
+	Id              string            `json:"id"`
+	RunId           string            `json:"runId"`
+	RepairUnitId    string            `json:"repairUnitId"`
+	TokenRange      segmentJson       `json:"tokenRange"`
+	FailCount       int               `json:"failCount"`
+	State           string            `json:"state"`
+	CoordinatorHost string            `json:"coordinatorHost,omitempty"`
+	Replicas        map[string]string `json:"replicas"`
+	StartTime       int64             `json:"startTime,omitempty"`
+	EndTime         int64             `json:"endTime,omitempty"`
+}
+
+type segmentJson struct {
+	BaseRange   tokenRangeJson    `json:"baseRange"`
+	TokenRanges []tokenRangeJson  `json:"tokenRanges"`
+	Replicas    map[string]string `json:"replicas"`
+}
+
+type tokenRangeJson struct {
+	Start *big.Int `json:"start"`
+	End   *big.Int `json:"end"`
+}
+
+type repairScheduleJson struct {
+	Id                  string   `json:"id"`
+	Owner               string   `json:"owner"`
+	State               string   `json:"state"`
+	Intensity           float64  `json:"intensity"`
+	ClusterName         string   `json:"cluster_name"`
+	KeyspaceName        string   `json:"keyspace_name"`
+	Tables              []string `json:"column_families"`
+	IgnoredTables       []string `json:"blacklisted_tables"`
+	Nodes               []string `json:"nodes"`
+	Datacenters         []string `json:"datacenters"`
+	RepairParallelism   string   `json:"repair_parallelism"`
+	IncrementalRepair   bool     `json:"incremental_repair"`
+	RepairThreadCount   int      `json:"repair_thread_count"`
+	SegmentCountPerNode int      `json:"segment_count_per_node"`
+	RepairUnitId        string   `json:"repair_unit_id"`
+	DaysBetween         int      `json:"scheduled_days_between"`
+	CreationTime        string   `json:"creation_time"`
+	PauseTime           *string  `json:"pause_time"`
+	NextActivation      string   `json:"next_activation"`
+}
+
+type loginJson struct {
+	Token    string   `json:"token"`
+	Username string   `json:"username"`
+	Roles    []string `json:"roles"`
+}
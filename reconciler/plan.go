@@ -0,0 +1,85 @@
+package reconciler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/k8ssandra/reaper-client-go/reaper"
+)
+
+type ActionType string
+
+const (
+	ActionCreate = ActionType("CREATE")
+	ActionUpdate = ActionType("UPDATE")
+	ActionPause  = ActionType("PAUSE")
+	ActionResume = ActionType("RESUME")
+	ActionDelete = ActionType("DELETE")
+)
+
+// Action is a single change needed to converge a cluster towards its desired state.
+type Action struct {
+	Type    ActionType
+	Cluster string
+
+	// The desired spec. Nil for DELETE actions.
+	Spec *ScheduleSpec
+
+	// The existing schedule. Nil for CREATE actions.
+	Current *reaper.RepairSchedule
+
+	// Why the action is needed, e.g. which settings differ.
+	Reason string
+}
+
+func (a Action) String() string {
+	var target string
+	switch {
+	case a.Spec != nil:
+		target = fmt.Sprintf("%s/%s", a.Cluster, a.Spec.key())
+	case a.Current != nil:
+		target = fmt.Sprintf("%s/%s", a.Cluster, scheduleKey(a.Current.KeyspaceName, a.Current.Tables))
+	}
+	s := fmt.Sprintf("%-6s %s", a.Type, target)
+	if a.Current != nil {
+		s += fmt.Sprintf(" (schedule %v)", a.Current.Id)
+	}
+	if a.Reason != "" {
+		s += ": " + a.Reason
+	}
+	return s
+}
+
+// Plan is the list of actions needed to converge the clusters towards their desired state. A plan can be printed for
+// dry-run purposes, then applied with Reconciler.Apply.
+type Plan struct {
+	Actions []Action
+
+	// Schedules found in the reconciled clusters that are not owned by the reconciler. They are never modified.
+	Unmanaged []reaper.RepairSchedule
+
+	// Potential problems detected while planning, e.g. a spec clashing with an unmanaged schedule.
+	Warnings []string
+}
+
+// IsEmpty returns true if the clusters are already in their desired state.
+func (p *Plan) IsEmpty() bool {
+	return len(p.Actions) == 0
+}
+
+func (p *Plan) String() string {
+	var sb strings.Builder
+	if p.IsEmpty() {
+		sb.WriteString("No changes: repair schedules are up to date.\n")
+	}
+	for _, action := range p.Actions {
+		sb.WriteString(action.String())
+		sb.WriteString("\n")
+	}
+	for _, warning := range p.Warnings {
+		sb.WriteString("WARNING: ")
+		sb.WriteString(warning)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,203 @@
+// Package reconciler converges the repair schedules of Reaper clusters towards a declarative desired state.
+//
+// The reconciler only manages the schedules it owns, i.e. the schedules whose owner matches the owner the reconciler
+// was created with. Schedules created by hand, or by other tools, are reported in the plan but never touched.
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/k8ssandra/reaper-client-go/reaper"
+)
+
+type Reconciler struct {
+	client reaper.Client
+	owner  string
+}
+
+// NewReconciler creates a reconciler managing the repair schedules owned by owner. The owner is stored in the Owner
+// field of every schedule created by the reconciler.
+func NewReconciler(client reaper.Client, owner string) *Reconciler {
+	return &Reconciler{client: client, owner: owner}
+}
+
+// Plan computes the actions needed to converge the clusters in the desired state, without modifying anything.
+func (r *Reconciler) Plan(ctx context.Context, desired DesiredState) (*Plan, error) {
+	plan := &Plan{}
+	clusters := make([]string, 0, len(desired))
+	for cluster := range desired {
+		clusters = append(clusters, cluster)
+	}
+	sort.Strings(clusters)
+	for _, cluster := range clusters {
+		if err := r.planCluster(ctx, cluster, desired[cluster], plan); err != nil {
+			return nil, err
+		}
+	}
+	return plan, nil
+}
+
+func (r *Reconciler) planCluster(ctx context.Context, cluster string, specs []ScheduleSpec, plan *Plan) error {
+	desired := make(map[string]*ScheduleSpec, len(specs))
+	var keys []string
+	for i := range specs {
+		spec := &specs[i]
+		if err := spec.Validate(); err != nil {
+			return fmt.Errorf("invalid schedule spec for cluster %s: %w", cluster, err)
+		}
+		if _, found := desired[spec.key()]; found {
+			return fmt.Errorf("duplicate schedule spec for cluster %s: %s", cluster, spec.key())
+		}
+		desired[spec.key()] = spec
+		keys = append(keys, spec.key())
+	}
+	sort.Strings(keys)
+
+	schedules, err := r.client.RepairSchedulesForCluster(ctx, cluster)
+	if err != nil {
+		return fmt.Errorf("failed to plan repair schedules for cluster %s: %w", cluster, err)
+	}
+	owned := make(map[string]*reaper.RepairSchedule)
+	unmanaged := make(map[string]bool)
+	for i := range schedules {
+		schedule := &schedules[i]
+		key := scheduleKey(schedule.KeyspaceName, schedule.Tables)
+		if schedule.Owner != r.owner {
+			plan.Unmanaged = append(plan.Unmanaged, *schedule)
+			unmanaged[key] = true
+			continue
+		}
+		if _, found := owned[key]; found || desired[key] == nil {
+			plan.Actions = append(plan.Actions, Action{
+				Type:    ActionDelete,
+				Cluster: cluster,
+				Current: schedule,
+				Reason:  "not in desired state",
+			})
+			continue
+		}
+		owned[key] = schedule
+	}
+
+	for _, key := range keys {
+		spec := desired[key]
+		current, found := owned[key]
+		if !found {
+			if unmanaged[key] {
+				plan.Warnings = append(plan.Warnings, fmt.Sprintf(
+					"%s/%s is already covered by a schedule not owned by %s; creation will likely be rejected",
+					cluster, key, r.owner))
+			}
+			plan.Actions = append(plan.Actions, Action{Type: ActionCreate, Cluster: cluster, Spec: spec})
+			continue
+		}
+		if diffs := spec.diff(current); len(diffs) > 0 {
+			plan.Actions = append(plan.Actions, Action{
+				Type:    ActionUpdate,
+				Cluster: cluster,
+				Spec:    spec,
+				Current: current,
+				Reason:  strings.Join(diffs, ", "),
+			})
+		} else if spec.Paused && current.State == string(reaper.RepairScheduleStateActive) {
+			plan.Actions = append(plan.Actions, Action{Type: ActionPause, Cluster: cluster, Spec: spec, Current: current})
+		} else if !spec.Paused && current.State == string(reaper.RepairScheduleStatePaused) {
+			plan.Actions = append(plan.Actions, Action{Type: ActionResume, Cluster: cluster, Spec: spec, Current: current})
+		}
+	}
+	return nil
+}
+
+// Apply executes the actions of a plan. A failed action does not prevent the remaining actions from being applied;
+// all errors are returned together.
+//
+// Reaper cannot modify the settings of an existing schedule, so UPDATE actions delete the schedule and create it
+// again. The new schedule gets a new id.
+func (r *Reconciler) Apply(ctx context.Context, plan *Plan) error {
+	var errs []error
+	for _, action := range plan.Actions {
+		if err := r.apply(ctx, action); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", action, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to apply %d of %d actions: %w", len(errs), len(plan.Actions), errors.Join(errs...))
+	}
+	return nil
+}
+
+// Reconcile computes a plan for the desired state and applies it, unless dryRun is true. The plan is returned in
+// both cases.
+func (r *Reconciler) Reconcile(ctx context.Context, desired DesiredState, dryRun bool) (*Plan, error) {
+	plan, err := r.Plan(ctx, desired)
+	if err != nil || dryRun {
+		return plan, err
+	}
+	return plan, r.Apply(ctx, plan)
+}
+
+func (r *Reconciler) apply(ctx context.Context, action Action) error {
+	switch action.Type {
+	case ActionCreate:
+		return r.create(ctx, action.Cluster, action.Spec)
+	case ActionUpdate:
+		if err := r.delete(ctx, action.Current); err != nil {
+			return err
+		}
+		return r.create(ctx, action.Cluster, action.Spec)
+	case ActionPause:
+		id, err := scheduleId(action.Current)
+		if err != nil {
+			return err
+		}
+		return r.client.PauseRepairSchedule(ctx, id)
+	case ActionResume:
+		id, err := scheduleId(action.Current)
+		if err != nil {
+			return err
+		}
+		return r.client.ResumeRepairSchedule(ctx, id)
+	case ActionDelete:
+		return r.delete(ctx, action.Current)
+	default:
+		return fmt.Errorf("unknown action type %s", action.Type)
+	}
+}
+
+func (r *Reconciler) create(ctx context.Context, cluster string, spec *ScheduleSpec) error {
+	id, err := r.client.CreateRepairSchedule(ctx, cluster, spec.Keyspace, r.owner, spec.DaysBetween, spec.createOptions())
+	if err != nil {
+		return err
+	}
+	if spec.Paused {
+		return r.client.PauseRepairSchedule(ctx, id)
+	}
+	return nil
+}
+
+func (r *Reconciler) delete(ctx context.Context, schedule *reaper.RepairSchedule) error {
+	id, err := scheduleId(schedule)
+	if err != nil {
+		return err
+	}
+	// Reaper refuses to delete active schedules
+	if schedule.State == string(reaper.RepairScheduleStateActive) {
+		if err := r.client.PauseRepairSchedule(ctx, id); err != nil {
+			return err
+		}
+	}
+	return r.client.DeleteRepairSchedule(ctx, id, r.owner)
+}
+
+func scheduleId(schedule *reaper.RepairSchedule) (uuid.UUID, error) {
+	id, err := uuid.Parse(schedule.Id)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid id of repair schedule %q: %w", schedule.Id, err)
+	}
+	return id, nil
+}
@@ -0,0 +1,202 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/k8ssandra/reaper-client-go/reaper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient keeps repair schedules in memory. Methods not used by the reconciler are not implemented and panic.
+type fakeClient struct {
+	reaper.Client
+	schedules []reaper.RepairSchedule
+	calls     []string
+}
+
+func (c *fakeClient) RepairSchedulesForCluster(_ context.Context, cluster string) ([]reaper.RepairSchedule, error) {
+	var schedules []reaper.RepairSchedule
+	for _, s := range c.schedules {
+		if s.ClusterName == cluster {
+			schedules = append(schedules, s)
+		}
+	}
+	return schedules, nil
+}
+
+func (c *fakeClient) CreateRepairSchedule(
+	_ context.Context,
+	cluster, keyspace, owner string,
+	daysBetween int,
+	options *reaper.RepairScheduleCreateOptions,
+) (uuid.UUID, error) {
+	c.calls = append(c.calls, fmt.Sprintf("create %s/%s", cluster, keyspace))
+	for _, s := range c.schedules {
+		if s.ClusterName == cluster && s.KeyspaceName == keyspace && sameSet(s.Tables, options.Tables) {
+			return uuid.Nil, fmt.Errorf("repair schedule already exists (HTTP status 409)")
+		}
+	}
+	id := uuid.New()
+	c.schedules = append(c.schedules, reaper.RepairSchedule{
+		Id:                  id.String(),
+		Owner:               owner,
+		State:               string(reaper.RepairScheduleStateActive),
+		ClusterName:         cluster,
+		KeyspaceName:        keyspace,
+		Tables:              options.Tables,
+		DaysBetween:         daysBetween,
+		Intensity:           options.Intensity,
+		RepairParallelism:   string(options.RepairParallelism),
+		SegmentCountPerNode: options.SegmentCountPerNode,
+	})
+	return id, nil
+}
+
+func (c *fakeClient) PauseRepairSchedule(_ context.Context, id uuid.UUID) error {
+	c.calls = append(c.calls, "pause")
+	return c.setState(id, reaper.RepairScheduleStatePaused)
+}
+
+func (c *fakeClient) ResumeRepairSchedule(_ context.Context, id uuid.UUID) error {
+	c.calls = append(c.calls, "resume")
+	return c.setState(id, reaper.RepairScheduleStateActive)
+}
+
+func (c *fakeClient) DeleteRepairSchedule(_ context.Context, id uuid.UUID, owner string) error {
+	c.calls = append(c.calls, "delete")
+	for i, s := range c.schedules {
+		if s.Id == id.String() {
+			if s.Owner != owner {
+				return fmt.Errorf("owner mismatch")
+			}
+			if s.State == string(reaper.RepairScheduleStateActive) {
+				return fmt.Errorf("schedule is active")
+			}
+			c.schedules = append(c.schedules[:i], c.schedules[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("schedule %v not found", id)
+}
+
+func (c *fakeClient) setState(id uuid.UUID, state reaper.RepairScheduleState) error {
+	for i := range c.schedules {
+		if c.schedules[i].Id == id.String() {
+			c.schedules[i].State = string(state)
+			return nil
+		}
+	}
+	return fmt.Errorf("schedule %v not found", id)
+}
+
+func schedule(cluster, keyspace, owner string, state reaper.RepairScheduleState, daysBetween int) reaper.RepairSchedule {
+	return reaper.RepairSchedule{
+		Id:           uuid.New().String(),
+		Owner:        owner,
+		State:        string(state),
+		ClusterName:  cluster,
+		KeyspaceName: keyspace,
+		DaysBetween:  daysBetween,
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	client := &fakeClient{schedules: []reaper.RepairSchedule{
+		schedule("cluster-1", "unchanged", "gitops", reaper.RepairScheduleStateActive, 7),
+		schedule("cluster-1", "updated", "gitops", reaper.RepairScheduleStateActive, 7),
+		schedule("cluster-1", "paused", "gitops", reaper.RepairScheduleStateActive, 7),
+		schedule("cluster-1", "resumed", "gitops", reaper.RepairScheduleStatePaused, 7),
+		schedule("cluster-1", "removed", "gitops", reaper.RepairScheduleStateActive, 7),
+		schedule("cluster-1", "manual", "alice", reaper.RepairScheduleStateActive, 3),
+		schedule("cluster-2", "other-cluster", "gitops", reaper.RepairScheduleStateActive, 7),
+	}}
+	desired := DesiredState{
+		"cluster-1": {
+			{Keyspace: "unchanged", DaysBetween: 7},
+			{Keyspace: "updated", DaysBetween: 14, Intensity: 0.5},
+			{Keyspace: "paused", DaysBetween: 7, Paused: true},
+			{Keyspace: "resumed", DaysBetween: 7},
+			{Keyspace: "created", Tables: []string{"t2", "t1"}, DaysBetween: 1},
+			{Keyspace: "manual", DaysBetween: 1},
+		},
+	}
+	reconciler := NewReconciler(client, "gitops")
+
+	plan, err := reconciler.Reconcile(context.Background(), desired, true)
+	require.NoError(t, err)
+	assert.Empty(t, client.calls, "dry run must not modify anything")
+
+	types := map[string]ActionType{}
+	for _, action := range plan.Actions {
+		if action.Spec != nil {
+			types[action.Spec.Keyspace] = action.Type
+		} else {
+			types[action.Current.KeyspaceName] = action.Type
+		}
+	}
+	assert.Equal(t, map[string]ActionType{
+		"created": ActionCreate,
+		"manual":  ActionCreate,
+		"paused":  ActionPause,
+		"removed": ActionDelete,
+		"resumed": ActionResume,
+		"updated": ActionUpdate,
+	}, types)
+	require.Len(t, plan.Unmanaged, 1)
+	assert.Equal(t, "manual", plan.Unmanaged[0].KeyspaceName)
+	require.Len(t, plan.Warnings, 1)
+	assert.Contains(t, plan.Warnings[0], "cluster-1/manual/")
+	assert.Contains(t, plan.String(), "UPDATE cluster-1/updated/")
+	assert.Contains(t, plan.String(), "daysBetween: 7 -> 14, intensity: 0 -> 0.5")
+	assert.Contains(t, plan.String(), "CREATE cluster-1/created/t1,t2")
+
+	_, err = reconciler.Reconcile(context.Background(), desired, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to apply 1 of 6 actions")
+	assert.Contains(t, err.Error(), "CREATE cluster-1/manual/")
+
+	plan, err = reconciler.Plan(context.Background(), desired)
+	require.NoError(t, err)
+	require.Len(t, plan.Actions, 1, "only the conflicting creation should remain: %s", plan)
+	assert.Equal(t, "manual", plan.Actions[0].Spec.Keyspace)
+
+	plan, err = reconciler.Plan(context.Background(), DesiredState{})
+	require.NoError(t, err)
+	assert.True(t, plan.IsEmpty())
+	assert.Contains(t, plan.String(), "No changes")
+
+	plan, err = reconciler.Plan(context.Background(), DesiredState{"cluster-2": {}})
+	require.NoError(t, err)
+	require.Len(t, plan.Actions, 1)
+	assert.Equal(t, ActionDelete, plan.Actions[0].Type)
+	assert.Equal(t, "other-cluster", plan.Actions[0].Current.KeyspaceName)
+
+	for _, s := range client.schedules {
+		if s.KeyspaceName == "manual" {
+			assert.Equal(t, "alice", s.Owner)
+			assert.Equal(t, 3, s.DaysBetween, "unmanaged schedules must not be touched")
+		}
+		if s.KeyspaceName == "other-cluster" {
+			assert.Equal(t, string(reaper.RepairScheduleStateActive), s.State, "clusters absent from the desired state must not be touched")
+		}
+	}
+}
+
+func TestPlanRejectsInvalidSpecs(t *testing.T) {
+	reconciler := NewReconciler(&fakeClient{}, "gitops")
+	_, err := reconciler.Plan(context.Background(), DesiredState{"cluster-1": {{Keyspace: "ks"}}})
+	assert.Error(t, err)
+	_, err = reconciler.Plan(context.Background(), DesiredState{"cluster-1": {
+		{Keyspace: "ks", DaysBetween: 1},
+		{Keyspace: "ks", DaysBetween: 2},
+	}})
+	assert.Error(t, err)
+	_, err = reconciler.Plan(context.Background(), DesiredState{"cluster-1": {
+		{Keyspace: "ks", DaysBetween: 1, Tables: []string{"t1"}, IgnoredTables: []string{"t2"}},
+	}})
+	assert.Error(t, err)
+}
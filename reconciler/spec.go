@@ -0,0 +1,138 @@
+package reconciler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/k8ssandra/reaper-client-go/reaper"
+)
+
+// DesiredState maps cluster names to the repair schedules that should exist in each cluster. Clusters that are not
+// present in the map are left untouched; a cluster mapped to an empty list will have all its owned schedules deleted.
+type DesiredState map[string][]ScheduleSpec
+
+// ScheduleSpec describes the desired state of a repair schedule. Zero values mean "use Reaper's default" and are not
+// compared against the existing schedules.
+type ScheduleSpec struct {
+	Keyspace string `json:"keyspace"`
+
+	// The tables to repair. When empty, all the tables of the keyspace are repaired. A keyspace can have several
+	// schedules as long as they target different tables.
+	Tables []string `json:"tables,omitempty"`
+
+	// Tables that should not be repaired. Cannot be used in conjunction with Tables.
+	IgnoredTables []string `json:"ignoredTables,omitempty"`
+
+	// The number of days between two repair runs. Mandatory.
+	DaysBetween int `json:"daysBetween"`
+
+	Intensity           reaper.Intensity         `json:"intensity,omitempty"`
+	RepairParallelism   reaper.RepairParallelism `json:"repairParallelism,omitempty"`
+	IncrementalRepair   bool                     `json:"incrementalRepair,omitempty"`
+	SegmentCountPerNode int                      `json:"segmentCountPerNode,omitempty"`
+	RepairThreadCount   int                      `json:"repairThreadCount,omitempty"`
+	Nodes               []string                 `json:"nodes,omitempty"`
+	Datacenters         []string                 `json:"datacenters,omitempty"`
+
+	// Whether the schedule should exist in PAUSED state.
+	Paused bool `json:"paused,omitempty"`
+}
+
+// Validate checks that the spec can be used to create a repair schedule.
+func (s *ScheduleSpec) Validate() error {
+	if s.Keyspace == "" {
+		return fmt.Errorf("keyspace is mandatory")
+	}
+	if s.DaysBetween <= 0 {
+		return fmt.Errorf("daysBetween must be > 0 for keyspace %s", s.Keyspace)
+	}
+	if len(s.Tables) > 0 && len(s.IgnoredTables) > 0 {
+		return fmt.Errorf("tables and ignoredTables cannot be used together for keyspace %s", s.Keyspace)
+	}
+	return nil
+}
+
+// key identifies the schedule a spec corresponds to: a keyspace and a set of tables.
+func (s *ScheduleSpec) key() string {
+	return scheduleKey(s.Keyspace, s.Tables)
+}
+
+func (s *ScheduleSpec) createOptions() *reaper.RepairScheduleCreateOptions {
+	return &reaper.RepairScheduleCreateOptions{
+		Tables:              s.Tables,
+		IgnoredTables:       s.IgnoredTables,
+		SegmentCountPerNode: s.SegmentCountPerNode,
+		RepairParallelism:   s.RepairParallelism,
+		Intensity:           s.Intensity,
+		IncrementalRepair:   s.IncrementalRepair,
+		Nodes:               s.Nodes,
+		Datacenters:         s.Datacenters,
+		RepairThreadCount:   s.RepairThreadCount,
+	}
+}
+
+// diff returns a human-readable description of the settings of the current schedule that do not match the spec. The
+// paused state is not considered here.
+func (s *ScheduleSpec) diff(current *reaper.RepairSchedule) []string {
+	var diffs []string
+	add := func(field string, from, to interface{}) {
+		diffs = append(diffs, fmt.Sprintf("%s: %v -> %v", field, from, to))
+	}
+	if s.DaysBetween != current.DaysBetween {
+		add("daysBetween", current.DaysBetween, s.DaysBetween)
+	}
+	if s.Intensity != 0 && !intensityEqual(s.Intensity, current.Intensity) {
+		add("intensity", current.Intensity, s.Intensity)
+	}
+	if s.RepairParallelism != "" && string(s.RepairParallelism) != current.RepairParallelism {
+		add("repairParallelism", current.RepairParallelism, s.RepairParallelism)
+	}
+	if s.IncrementalRepair != current.IncrementalRepair {
+		add("incrementalRepair", current.IncrementalRepair, s.IncrementalRepair)
+	}
+	if s.SegmentCountPerNode != 0 && s.SegmentCountPerNode != current.SegmentCountPerNode {
+		add("segmentCountPerNode", current.SegmentCountPerNode, s.SegmentCountPerNode)
+	}
+	if s.RepairThreadCount != 0 && s.RepairThreadCount != current.RepairThreadCount {
+		add("repairThreadCount", current.RepairThreadCount, s.RepairThreadCount)
+	}
+	if !sameSet(s.IgnoredTables, current.IgnoredTables) {
+		add("ignoredTables", current.IgnoredTables, s.IgnoredTables)
+	}
+	if !sameSet(s.Nodes, current.Nodes) {
+		add("nodes", current.Nodes, s.Nodes)
+	}
+	if !sameSet(s.Datacenters, current.Datacenters) {
+		add("datacenters", current.Datacenters, s.Datacenters)
+	}
+	return diffs
+}
+
+func scheduleKey(keyspace string, tables []string) string {
+	return keyspace + "/" + strings.Join(sorted(tables), ",")
+}
+
+func intensityEqual(a, b reaper.Intensity) bool {
+	const epsilon = 0.0001
+	return a-b < epsilon && b-a < epsilon
+}
+
+func sameSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	sa, sb := sorted(a), sorted(b)
+	for i := range sa {
+		if sa[i] != sb[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func sorted(values []string) []string {
+	s := append([]string(nil), values...)
+	sort.Strings(s)
+	return s
+}
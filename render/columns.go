@@ -0,0 +1,269 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/k8ssandra/reaper-client-go/reaper"
+)
+
+var clusterKind = &kind[*reaper.Cluster]{
+	columns: []column[*reaper.Cluster]{
+		{name: "NAME", value: func(c *reaper.Cluster) string { return c.Name }},
+		{name: "NODES", value: func(c *reaper.Cluster) string { return strconv.Itoa(len(c.Endpoints())) }},
+		{name: "UP", value: func(c *reaper.Cluster) string {
+			return strconv.Itoa(len(c.Endpoints()) - len(c.DownEndpoints()))
+		}},
+		{name: "DATACENTERS", value: func(c *reaper.Cluster) string { return strings.Join(c.Datacenters(), ",") }},
+		{name: "VERSION", value: func(c *reaper.Cluster) string { return strings.Join(c.ReleaseVersions(), ",") }},
+		{
+			name:  "LOAD",
+			value: func(c *reaper.Cluster) string { return formatBytes(c.TotalLoad()) },
+			less:  func(a, b *reaper.Cluster) bool { return a.TotalLoad() < b.TotalLoad() },
+		},
+		{name: "SEEDS", wide: true, value: func(c *reaper.Cluster) string { return strings.Join(c.Seeds, ",") }},
+		{name: "JMX USERNAME", wide: true, value: func(c *reaper.Cluster) string { return c.JmxUsername }},
+	},
+	defaultSort: []string{"name"},
+}
+
+var endpointKind = &kind[reaper.EndpointState]{
+	columns: []column[reaper.EndpointState]{
+		{name: "ENDPOINT", value: func(e reaper.EndpointState) string { return e.Endpoint }},
+		{name: "DATACENTER", value: func(e reaper.EndpointState) string { return e.DataCenter }},
+		{name: "RACK", value: func(e reaper.EndpointState) string { return e.Rack }},
+		{name: "STATUS", value: func(e reaper.EndpointState) string { return e.Status }},
+		{name: "VERSION", value: func(e reaper.EndpointState) string { return e.ReleaseVersion }},
+		{
+			name:  "LOAD",
+			value: func(e reaper.EndpointState) string { return formatBytes(e.Load) },
+			less:  func(a, b reaper.EndpointState) bool { return a.Load < b.Load },
+		},
+		{name: "HOST ID", wide: true, value: func(e reaper.EndpointState) string { return e.HostId }},
+		{name: "TOKENS", wide: true, value: func(e reaper.EndpointState) string { return e.Tokens }},
+		{name: "SEVERITY", wide: true, value: func(e reaper.EndpointState) string { return formatFloat(e.Severity) }},
+	},
+	defaultSort: []string{"datacenter", "rack", "endpoint"},
+}
+
+var repairRunKind = &kind[*reaper.RepairRun]{
+	columns: []column[*reaper.RepairRun]{
+		{name: "ID", value: func(r *reaper.RepairRun) string { return r.Id.String() }},
+		{name: "CLUSTER", value: func(r *reaper.RepairRun) string { return r.Cluster }},
+		{name: "KEYSPACE", value: func(r *reaper.RepairRun) string { return r.Keyspace }},
+		{name: "STATE", value: func(r *reaper.RepairRun) string { return string(r.State) }},
+		{
+			name:  "PROGRESS",
+			value: Progress,
+			less:  func(a, b *reaper.RepairRun) bool { return completion(a) < completion(b) },
+		},
+		{name: "INTENSITY", value: func(r *reaper.RepairRun) string { return formatFloat(r.Intensity) }},
+		{name: "OWNER", value: func(r *reaper.RepairRun) string { return r.Owner }},
+		{name: "TABLES", wide: true, value: func(r *reaper.RepairRun) string { return strings.Join(r.Tables, ",") }},
+		{name: "PARALLELISM", wide: true, value: func(r *reaper.RepairRun) string { return string(r.RepairParallelism) }},
+		{name: "INCREMENTAL", wide: true, value: func(r *reaper.RepairRun) string {
+			return strconv.FormatBool(r.IncrementalRepair)
+		}},
+		{name: "THREADS", wide: true, value: func(r *reaper.RepairRun) string { return strconv.Itoa(r.RepairThreadCount) }},
+		{name: "NODES", wide: true, value: func(r *reaper.RepairRun) string { return strings.Join(r.Nodes, ",") }},
+		{name: "DATACENTERS", wide: true, value: func(r *reaper.RepairRun) string {
+			return strings.Join(r.Datacenters, ",")
+		}},
+		{name: "DURATION", wide: true, value: func(r *reaper.RepairRun) string { return r.Duration }},
+		{name: "CAUSE", wide: true, value: func(r *reaper.RepairRun) string { return r.Cause }},
+		{name: "LAST EVENT", wide: true, value: func(r *reaper.RepairRun) string { return r.LastEvent }},
+	},
+	defaultSort: []string{"cluster", "keyspace", "id"},
+}
+
+var repairSegmentKind = &kind[*reaper.RepairSegment]{
+	columns: []column[*reaper.RepairSegment]{
+		{name: "ID", value: func(s *reaper.RepairSegment) string { return s.Id.String() }},
+		{name: "STATE", value: func(s *reaper.RepairSegment) string { return string(s.State) }},
+		{
+			name:  "START TOKEN",
+			value: func(s *reaper.RepairSegment) string { return formatToken(startToken(s)) },
+			less:  func(a, b *reaper.RepairSegment) bool { return compareTokens(startToken(a), startToken(b)) < 0 },
+		},
+		{
+			name:  "END TOKEN",
+			value: func(s *reaper.RepairSegment) string { return formatToken(endToken(s)) },
+			less:  func(a, b *reaper.RepairSegment) bool { return compareTokens(endToken(a), endToken(b)) < 0 },
+		},
+		{name: "COORDINATOR", value: func(s *reaper.RepairSegment) string { return s.Coordinator }},
+		{name: "FAILS", value: func(s *reaper.RepairSegment) string { return strconv.Itoa(s.FailCount) }},
+		{name: "REPLICAS", wide: true, value: func(s *reaper.RepairSegment) string {
+			replicas := make([]string, 0, len(s.Replicas))
+			for replica := range s.Replicas {
+				replicas = append(replicas, replica)
+			}
+			sort.Strings(replicas)
+			return strings.Join(replicas, ",")
+		}},
+		{
+			name:  "STARTED",
+			wide:  true,
+			value: func(s *reaper.RepairSegment) string { return formatTime(s.StartTime) },
+		},
+		{
+			name:  "ENDED",
+			wide:  true,
+			value: func(s *reaper.RepairSegment) string { return formatTime(s.EndTime) },
+		},
+		{name: "RUN ID", wide: true, value: func(s *reaper.RepairSegment) string { return s.RunId.String() }},
+	},
+	defaultSort: []string{"start-token"},
+}
+
+var repairScheduleKind = &kind[reaper.RepairSchedule]{
+	columns: []column[reaper.RepairSchedule]{
+		{name: "ID", value: func(s reaper.RepairSchedule) string { return s.Id }},
+		{name: "CLUSTER", value: func(s reaper.RepairSchedule) string { return s.ClusterName }},
+		{name: "KEYSPACE", value: func(s reaper.RepairSchedule) string { return s.KeyspaceName }},
+		{name: "STATE", value: func(s reaper.RepairSchedule) string { return s.State }},
+		{name: "DAYS BETWEEN", value: func(s reaper.RepairSchedule) string { return strconv.Itoa(s.DaysBetween) }},
+		{
+			name:  "NEXT ACTIVATION",
+			value: func(s reaper.RepairSchedule) string { return formatTime(&s.NextActivation) },
+			less:  func(a, b reaper.RepairSchedule) bool { return a.NextActivation.Before(b.NextActivation) },
+		},
+		{name: "OWNER", value: func(s reaper.RepairSchedule) string { return s.Owner }},
+		{name: "TABLES", wide: true, value: func(s reaper.RepairSchedule) string { return strings.Join(s.Tables, ",") }},
+		{name: "INTENSITY", wide: true, value: func(s reaper.RepairSchedule) string { return formatFloat(s.Intensity) }},
+		{name: "PARALLELISM", wide: true, value: func(s reaper.RepairSchedule) string { return s.RepairParallelism }},
+		{name: "INCREMENTAL", wide: true, value: func(s reaper.RepairSchedule) string {
+			return strconv.FormatBool(s.IncrementalRepair)
+		}},
+		{name: "SEGMENTS PER NODE", wide: true, value: func(s reaper.RepairSchedule) string {
+			return strconv.Itoa(s.SegmentCountPerNode)
+		}},
+		{name: "THREADS", wide: true, value: func(s reaper.RepairSchedule) string {
+			return strconv.Itoa(s.RepairThreadCount)
+		}},
+		{
+			name:  "CREATED",
+			wide:  true,
+			value: func(s reaper.RepairSchedule) string { return formatTime(&s.Created) },
+			less:  func(a, b reaper.RepairSchedule) bool { return a.Created.Before(b.Created) },
+		},
+	},
+	defaultSort: []string{"cluster", "keyspace", "id"},
+}
+
+// Clusters writes a list of clusters.
+func (p *Printer) Clusters(w io.Writer, clusters []*reaper.Cluster) error {
+	return render(p, w, clusterKind, clusters, false)
+}
+
+// Cluster writes a single cluster.
+func (p *Printer) Cluster(w io.Writer, cluster *reaper.Cluster) error {
+	return render(p, w, clusterKind, []*reaper.Cluster{cluster}, true)
+}
+
+// Endpoints writes the state of the nodes of a cluster, as returned by reaper.Cluster.Endpoints.
+func (p *Printer) Endpoints(w io.Writer, endpoints []reaper.EndpointState) error {
+	return render(p, w, endpointKind, endpoints, false)
+}
+
+// RepairRuns writes a list of repair runs.
+func (p *Printer) RepairRuns(w io.Writer, runs []*reaper.RepairRun) error {
+	return render(p, w, repairRunKind, runs, false)
+}
+
+// RepairRun writes a single repair run.
+func (p *Printer) RepairRun(w io.Writer, run *reaper.RepairRun) error {
+	return render(p, w, repairRunKind, []*reaper.RepairRun{run}, true)
+}
+
+// RepairSegments writes a list of repair segments.
+func (p *Printer) RepairSegments(w io.Writer, segments []*reaper.RepairSegment) error {
+	return render(p, w, repairSegmentKind, segments, false)
+}
+
+// RepairSchedules writes a list of repair schedules.
+func (p *Printer) RepairSchedules(w io.Writer, schedules []reaper.RepairSchedule) error {
+	return render(p, w, repairScheduleKind, schedules, false)
+}
+
+// RepairSchedule writes a single repair schedule.
+func (p *Printer) RepairSchedule(w io.Writer, schedule *reaper.RepairSchedule) error {
+	return render(p, w, repairScheduleKind, []reaper.RepairSchedule{*schedule}, true)
+}
+
+// Progress returns the number of repaired segments of a repair run out of its total, e.g. "12/40", or "" if the
+// segments of the run are not known yet.
+func Progress(run *reaper.RepairRun) string {
+	if run.TotalSegments == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d", run.SegmentsRepaired, run.TotalSegments)
+}
+
+func completion(run *reaper.RepairRun) float64 {
+	if run.TotalSegments == 0 {
+		return 0
+	}
+	return float64(run.SegmentsRepaired) / float64(run.TotalSegments)
+}
+
+func startToken(segment *reaper.RepairSegment) *big.Int {
+	if segment.TokenRange == nil || segment.TokenRange.BaseRange == nil {
+		return nil
+	}
+	return segment.TokenRange.BaseRange.Start
+}
+
+func endToken(segment *reaper.RepairSegment) *big.Int {
+	if segment.TokenRange == nil || segment.TokenRange.BaseRange == nil {
+		return nil
+	}
+	return segment.TokenRange.BaseRange.End
+}
+
+// compareTokens compares tokens, unknown tokens first.
+func compareTokens(a, b *big.Int) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	return a.Cmp(b)
+}
+
+func formatToken(token *big.Int) string {
+	if token == nil {
+		return ""
+	}
+	return token.String()
+}
+
+// formatTime formats times in UTC, so that the output does not depend on the time zone of the machine.
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// formatBytes formats a size in bytes with binary units, e.g. "1.5 GiB".
+func formatBytes(bytes float64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+	i := 0
+	for bytes >= 1024 && i < len(units)-1 {
+		bytes /= 1024
+		i++
+	}
+	return fmt.Sprintf("%.1f %s", bytes, units[i])
+}
@@ -0,0 +1,376 @@
+// Package render writes clusters, repair runs, repair segments and repair schedules in human or machine readable
+// formats, for command-line tools built on the client:
+//
+//   - table: aligned columns, one row per object;
+//   - wide: like table, with additional columns;
+//   - json and yaml: the objects themselves, as stable machine output;
+//   - template=<text>: a Go template executed for each object, e.g. template={{.Id}} {{.State}}.
+//
+// Tables can be restricted to a selection of columns, and rows sorted by any column:
+//
+//	options, err := render.ParseOutput("wide")
+//	options.SortBy = []string{"-progress"}
+//	printer, err := render.NewPrinter(options)
+//	err = printer.RepairRuns(os.Stdout, runs)
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"gopkg.in/yaml.v2"
+)
+
+type Format string
+
+const (
+	FormatTable    = Format("table")
+	FormatWide     = Format("wide")
+	FormatJson     = Format("json")
+	FormatYaml     = Format("yaml")
+	FormatTemplate = Format("template")
+)
+
+// ErrUnknownColumn is returned when Options.Columns or Options.SortBy name a column that does not exist for the kind of
+// objects being rendered.
+var ErrUnknownColumn = errors.New("unknown column")
+
+// Options controls how objects are rendered.
+type Options struct {
+	// Defaults to FormatTable.
+	Format Format
+
+	// The Go template to execute for each object when Format is FormatTemplate. Besides the standard functions, the
+	// template can use json, to render a value as JSON, and join, to join a list of strings with a separator.
+	Template string
+
+	// The columns of tables, by name. Names are case-insensitive, and may use dashes or underscores instead of spaces,
+	// e.g. "last-event". Any column can be selected, including those only shown in wide tables. Defaults to the
+	// columns of the format.
+	Columns []string
+
+	// The columns to sort objects by, by name. Prefix a name with "-" to sort in descending order. Defaults to a
+	// natural order for each kind of object, e.g. repair runs are sorted by cluster, keyspace and id. Sorting applies
+	// to all formats.
+	SortBy []string
+
+	// Omits the header line of tables.
+	NoHeaders bool
+}
+
+// ParseOutput parses an output format as given on a command line: table, wide, json, yaml, or template=<text>.
+// "go-template=<text>" is accepted as a synonym of the latter, for users of kubectl.
+func ParseOutput(output string) (Options, error) {
+	if text, found := cutPrefixes(output, "template=", "go-template="); found {
+		return Options{Format: FormatTemplate, Template: text}, nil
+	}
+	switch format := Format(strings.ToLower(output)); format {
+	case "", FormatTable, FormatWide, FormatJson, FormatYaml:
+		return Options{Format: format}, nil
+	case FormatTemplate:
+		return Options{}, fmt.Errorf("missing template: use template=<text>")
+	default:
+		return Options{}, fmt.Errorf("unknown output format %q: use table, wide, json, yaml or template=<text>", output)
+	}
+}
+
+// Printer renders objects according to Options.
+type Printer struct {
+	options  Options
+	template *template.Template
+}
+
+// NewPrinter returns a printer for the given options, or an error if they are invalid.
+func NewPrinter(options Options) (*Printer, error) {
+	printer := &Printer{options: options}
+	switch options.Format {
+	case "":
+		printer.options.Format = FormatTable
+	case FormatTable, FormatWide, FormatJson, FormatYaml:
+	case FormatTemplate:
+		t, err := template.New("output").Funcs(templateFuncs).Option("missingkey=error").Parse(options.Template)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template: %w", err)
+		}
+		printer.template = t
+	default:
+		return nil, fmt.Errorf("unknown output format %q", options.Format)
+	}
+	return printer, nil
+}
+
+// Format returns the output format of the printer.
+func (p *Printer) Format() Format {
+	return p.options.Format
+}
+
+// Machine returns true if the printer renders machine readable output, in which case commands should not print
+// anything else on stdout.
+func (p *Printer) Machine() bool {
+	return p.options.Format != FormatTable && p.options.Format != FormatWide
+}
+
+// Result writes the result of a command that is not one of the objects the package knows about, e.g. the id of a
+// created repair run. Tables show message, on a line of its own, while the other formats render v.
+func (p *Printer) Result(w io.Writer, v interface{}, message string) error {
+	switch p.options.Format {
+	case FormatJson:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case FormatYaml:
+		return writeYaml(w, v)
+	case FormatTemplate:
+		return p.execute(w, v)
+	default:
+		_, err := fmt.Fprintln(w, message)
+		return err
+	}
+}
+
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"join": func(separator string, items []string) string {
+		return strings.Join(items, separator)
+	},
+}
+
+// column describes a column of the tables of objects of type T.
+type column[T any] struct {
+	name string
+
+	// Whether the column is only shown in wide tables.
+	wide bool
+
+	value func(T) string
+
+	// Optional; by default, values are compared as numbers if they are both numeric, and as strings otherwise.
+	less func(a, b T) bool
+}
+
+// kind describes how to render objects of type T.
+type kind[T any] struct {
+	columns     []column[T]
+	defaultSort []string
+}
+
+// render writes items in the format of the printer. If single is true, there is exactly one item, which JSON and YAML
+// render as an object rather than as a list.
+func render[T any](p *Printer, w io.Writer, k *kind[T], items []T, single bool) error {
+	items = append([]T(nil), items...)
+	if err := k.sort(items, p.options.SortBy); err != nil {
+		return err
+	}
+	var v interface{} = items
+	if single {
+		v = items[0]
+	}
+	switch p.options.Format {
+	case FormatJson, FormatYaml:
+		return p.Result(w, v, "")
+	case FormatTemplate:
+		for _, item := range items {
+			if err := p.execute(w, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return k.table(w, items, p.options)
+	}
+}
+
+// execute executes the template for v, and terminates its output with a newline if needed.
+func (p *Printer) execute(w io.Writer, v interface{}) error {
+	var buf bytes.Buffer
+	if err := p.template.Execute(&buf, v); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+	if buf.Len() > 0 && buf.Bytes()[buf.Len()-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (k *kind[T]) table(w io.Writer, items []T, options Options) error {
+	columns, err := k.selectColumns(options)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	if !options.NoHeaders {
+		names := make([]string, len(columns))
+		for i, c := range columns {
+			names[i] = c.name
+		}
+		_, _ = fmt.Fprintln(tw, strings.Join(names, "\t"))
+	}
+	for _, item := range items {
+		values := make([]string, len(columns))
+		for i, c := range columns {
+			values[i] = c.value(item)
+			if values[i] == "" {
+				values[i] = "-"
+			}
+		}
+		_, _ = fmt.Fprintln(tw, strings.Join(values, "\t"))
+	}
+	return tw.Flush()
+}
+
+func (k *kind[T]) selectColumns(options Options) ([]column[T], error) {
+	var columns []column[T]
+	if len(options.Columns) == 0 {
+		for _, c := range k.columns {
+			if !c.wide || options.Format == FormatWide {
+				columns = append(columns, c)
+			}
+		}
+		return columns, nil
+	}
+	for _, name := range options.Columns {
+		c, err := k.column(name)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, *c)
+	}
+	return columns, nil
+}
+
+func (k *kind[T]) column(name string) (*column[T], error) {
+	normalized := strings.NewReplacer("-", " ", "_", " ").Replace(strings.TrimSpace(name))
+	for i := range k.columns {
+		if strings.EqualFold(k.columns[i].name, normalized) {
+			return &k.columns[i], nil
+		}
+	}
+	names := make([]string, len(k.columns))
+	for i, c := range k.columns {
+		names[i] = strings.ToLower(strings.ReplaceAll(c.name, " ", "-"))
+	}
+	return nil, fmt.Errorf("%w %q, expected one of %s", ErrUnknownColumn, name, strings.Join(names, ", "))
+}
+
+func (k *kind[T]) sort(items []T, sortBy []string) error {
+	if len(sortBy) == 0 {
+		sortBy = k.defaultSort
+	}
+	type key struct {
+		column     *column[T]
+		descending bool
+	}
+	keys := make([]key, 0, len(sortBy))
+	for _, name := range sortBy {
+		descending := strings.HasPrefix(name, "-")
+		c, err := k.column(strings.TrimPrefix(name, "-"))
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key{column: c, descending: descending})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		for _, key := range keys {
+			a, b := items[i], items[j]
+			if key.descending {
+				a, b = b, a
+			}
+			if key.column.compare(a, b) {
+				return true
+			}
+			if key.column.compare(b, a) {
+				return false
+			}
+		}
+		return false
+	})
+	return nil
+}
+
+func (c *column[T]) compare(a, b T) bool {
+	if c.less != nil {
+		return c.less(a, b)
+	}
+	va, vb := c.value(a), c.value(b)
+	na, okA := new(big.Float).SetString(va)
+	nb, okB := new(big.Float).SetString(vb)
+	if okA && okB {
+		return na.Cmp(nb) < 0
+	}
+	return va < vb
+}
+
+// writeYaml writes v as YAML, with the same property names as its JSON encoding.
+func writeYaml(w io.Writer, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return err
+	}
+	out, err := yaml.Marshal(yamlValue(generic))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// yamlValue converts JSON numbers, which yaml.v2 would quote, to Go numbers. Integers that don't fit in an int64, such
+// as tokens of the RandomPartitioner, are kept as strings.
+func yamlValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(yaml.MapSlice, 0, len(v))
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			m = append(m, yaml.MapItem{Key: key, Value: yamlValue(v[key])})
+		}
+		return m
+	case []interface{}:
+		for i := range v {
+			v[i] = yamlValue(v[i])
+		}
+		return v
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if strings.ContainsAny(string(v), ".eE") {
+			if f, err := v.Float64(); err == nil {
+				return f
+			}
+		}
+		return string(v)
+	}
+	return v
+}
+
+func cutPrefixes(s string, prefixes ...string) (string, bool) {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return s[len(prefix):], true
+		}
+	}
+	return s, false
+}
@@ -0,0 +1,349 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/k8ssandra/reaper-client-go/reaper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The expected outputs are golden files in testdata. To update them after a deliberate change of the output, run the
+// tests with REAPER_RENDER_UPDATE=1 and review the diff.
+var update = os.Getenv("REAPER_RENDER_UPDATE") != ""
+
+func TestParseOutput(t *testing.T) {
+	for _, test := range []struct {
+		output   string
+		expected Options
+		err      string
+	}{
+		{output: "", expected: Options{}},
+		{output: "table", expected: Options{Format: FormatTable}},
+		{output: "WIDE", expected: Options{Format: FormatWide}},
+		{output: "json", expected: Options{Format: FormatJson}},
+		{output: "yaml", expected: Options{Format: FormatYaml}},
+		{output: "template={{.Id}}", expected: Options{Format: FormatTemplate, Template: "{{.Id}}"}},
+		{output: "go-template={{.Id}}", expected: Options{Format: FormatTemplate, Template: "{{.Id}}"}},
+		{output: "template", err: "missing template: use template=<text>"},
+		{output: "xml", err: `unknown output format "xml": use table, wide, json, yaml or template=<text>`},
+	} {
+		t.Run(test.output, func(t *testing.T) {
+			options, err := ParseOutput(test.output)
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected, options)
+			}
+		})
+	}
+}
+
+func TestNewPrinter(t *testing.T) {
+	printer, err := NewPrinter(Options{})
+	require.NoError(t, err)
+	assert.Equal(t, FormatTable, printer.Format())
+	assert.False(t, printer.Machine())
+
+	_, err = NewPrinter(Options{Format: FormatTemplate, Template: "{{.Id"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid template")
+
+	_, err = NewPrinter(Options{Format: "xml"})
+	assert.EqualError(t, err, `unknown output format "xml"`)
+}
+
+func TestGolden(t *testing.T) {
+	clusters, runs, segments, schedules := fixtures()
+	for _, test := range []struct {
+		name    string
+		options Options
+		print   func(p *Printer, w *bytes.Buffer) error
+	}{
+		{"clusters.table", Options{}, printClusters(clusters)},
+		{"clusters.wide", Options{Format: FormatWide}, printClusters(clusters)},
+		{"clusters.json", Options{Format: FormatJson}, printClusters(clusters)},
+		{"cluster.yaml", Options{Format: FormatYaml}, func(p *Printer, w *bytes.Buffer) error {
+			return p.Cluster(w, clusters[0])
+		}},
+		{"endpoints.table", Options{}, func(p *Printer, w *bytes.Buffer) error {
+			return p.Endpoints(w, clusters[0].Endpoints())
+		}},
+		{"endpoints.wide", Options{Format: FormatWide}, func(p *Printer, w *bytes.Buffer) error {
+			return p.Endpoints(w, clusters[0].Endpoints())
+		}},
+		{"runs.table", Options{}, printRepairRuns(runs)},
+		{"runs.wide", Options{Format: FormatWide}, printRepairRuns(runs)},
+		{"runs.json", Options{Format: FormatJson}, printRepairRuns(runs)},
+		{"runs.yaml", Options{Format: FormatYaml}, printRepairRuns(runs)},
+		{
+			"runs.columns",
+			Options{Columns: []string{"id", "Last-Event", "progress"}, SortBy: []string{"-progress"}},
+			printRepairRuns(runs),
+		},
+		{"runs.no-headers", Options{NoHeaders: true, SortBy: []string{"owner"}}, printRepairRuns(runs)},
+		{
+			"runs.template",
+			Options{Format: FormatTemplate, Template: `{{.Id}} {{.State}} {{join "," .Tables}} {{json .Nodes}}`},
+			printRepairRuns(runs),
+		},
+		{"run.json", Options{Format: FormatJson}, func(p *Printer, w *bytes.Buffer) error {
+			return p.RepairRun(w, runs[0])
+		}},
+		{"segments.table", Options{}, printRepairSegments(segments)},
+		{"segments.wide", Options{Format: FormatWide}, printRepairSegments(segments)},
+		{"segments.yaml", Options{Format: FormatYaml}, printRepairSegments(segments)},
+		{"segments.sorted", Options{SortBy: []string{"state", "-end-token"}}, printRepairSegments(segments)},
+		{"schedules.table", Options{}, printRepairSchedules(schedules)},
+		{"schedules.wide", Options{Format: FormatWide}, printRepairSchedules(schedules)},
+		{"schedules.json", Options{Format: FormatJson}, printRepairSchedules(schedules)},
+		{"schedule.yaml", Options{Format: FormatYaml}, func(p *Printer, w *bytes.Buffer) error {
+			return p.RepairSchedule(w, &schedules[0])
+		}},
+		{
+			"schedules.template",
+			Options{Format: FormatTemplate, Template: "{{.KeyspaceName}}: every {{.DaysBetween}} days\n"},
+			printRepairSchedules(schedules),
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			printer, err := NewPrinter(test.options)
+			require.NoError(t, err)
+			var out bytes.Buffer
+			require.NoError(t, test.print(printer, &out))
+			assertGolden(t, test.name+".golden", out.Bytes())
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	_, runs, _, _ := fixtures()
+	var out bytes.Buffer
+
+	printer, err := NewPrinter(Options{Columns: []string{"id", "bogus"}})
+	require.NoError(t, err)
+	err = printer.RepairRuns(&out, runs)
+	assert.EqualError(t, err, `unknown column "bogus", expected one of id, cluster, keyspace, state, progress, `+
+		`intensity, owner, tables, parallelism, incremental, threads, nodes, datacenters, duration, cause, last-event`)
+
+	printer, err = NewPrinter(Options{Format: FormatJson, SortBy: []string{"-bogus"}})
+	require.NoError(t, err)
+	err = printer.RepairRuns(&out, runs)
+	assert.True(t, errors.Is(err, ErrUnknownColumn))
+
+	printer, err = NewPrinter(Options{Format: FormatTemplate, Template: "{{.Bogus}}"})
+	require.NoError(t, err)
+	err = printer.RepairRuns(&out, runs)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to execute template")
+
+	assert.Empty(t, out.String())
+}
+
+func TestResult(t *testing.T) {
+	result := map[string]interface{}{"purged": 3}
+	for _, test := range []struct {
+		output   string
+		expected string
+	}{
+		{"table", "3 repair run(s) purged\n"},
+		{"wide", "3 repair run(s) purged\n"},
+		{"json", "{\n  \"purged\": 3\n}\n"},
+		{"yaml", "purged: 3\n"},
+		{"template={{.purged}}", "3\n"},
+	} {
+		t.Run(test.output, func(t *testing.T) {
+			options, err := ParseOutput(test.output)
+			require.NoError(t, err)
+			printer, err := NewPrinter(options)
+			require.NoError(t, err)
+			var out bytes.Buffer
+			require.NoError(t, printer.Result(&out, result, "3 repair run(s) purged"))
+			assert.Equal(t, test.expected, out.String())
+		})
+	}
+}
+
+func TestSortIsStableAndDoesNotModifyInput(t *testing.T) {
+	_, runs, _, _ := fixtures()
+	original := append([]*reaper.RepairRun(nil), runs...)
+	printer, err := NewPrinter(Options{Columns: []string{"id"}, SortBy: []string{"cluster"}, NoHeaders: true})
+	require.NoError(t, err)
+	var out bytes.Buffer
+	require.NoError(t, printer.RepairRuns(&out, runs))
+	assert.Equal(t, original, runs)
+	// runs of the same cluster keep their relative order
+	assert.Equal(t, runs[0].Id.String()+"\n"+runs[2].Id.String()+"\n"+runs[1].Id.String()+"\n", out.String())
+}
+
+func printClusters(clusters []*reaper.Cluster) func(*Printer, *bytes.Buffer) error {
+	return func(p *Printer, w *bytes.Buffer) error { return p.Clusters(w, clusters) }
+}
+
+func printRepairRuns(runs []*reaper.RepairRun) func(*Printer, *bytes.Buffer) error {
+	return func(p *Printer, w *bytes.Buffer) error { return p.RepairRuns(w, runs) }
+}
+
+func printRepairSegments(segments []*reaper.RepairSegment) func(*Printer, *bytes.Buffer) error {
+	return func(p *Printer, w *bytes.Buffer) error { return p.RepairSegments(w, segments) }
+}
+
+func printRepairSchedules(schedules []reaper.RepairSchedule) func(*Printer, *bytes.Buffer) error {
+	return func(p *Printer, w *bytes.Buffer) error { return p.RepairSchedules(w, schedules) }
+}
+
+func assertGolden(t *testing.T, name string, actual []byte) {
+	path := filepath.Join("testdata", name)
+	if update {
+		require.NoError(t, os.WriteFile(path, actual, 0644))
+		return
+	}
+	expected, err := os.ReadFile(path)
+	require.NoError(t, err, "run the tests with REAPER_RENDER_UPDATE=1 to create the golden file")
+	assert.Equal(t, string(expected), string(actual))
+}
+
+func fixtures() ([]*reaper.Cluster, []*reaper.RepairRun, []*reaper.RepairSegment, []reaper.RepairSchedule) {
+	endpoint := func(name, dc, rack, status string, load float64, host int) reaper.EndpointState {
+		return reaper.EndpointState{
+			Endpoint:       name,
+			DataCenter:     dc,
+			Rack:           rack,
+			HostId:         uuid.MustParse("00000000-0000-0000-0000-00000000000" + string(rune('0'+host))).String(),
+			Status:         status,
+			ReleaseVersion: "4.0.6",
+			Tokens:         "-9223372036854775808",
+			Load:           load,
+		}
+	}
+	dc := func(name string, endpoints ...reaper.EndpointState) reaper.DataCenterState {
+		racks := map[string]reaper.RackState{}
+		for _, e := range endpoints {
+			rack := racks[e.Rack]
+			rack.Name = e.Rack
+			rack.Endpoints = append(rack.Endpoints, e)
+			racks[e.Rack] = rack
+		}
+		return reaper.DataCenterState{Name: name, Racks: racks}
+	}
+	clusters := []*reaper.Cluster{
+		{
+			Name:        "production",
+			JmxUsername: "cassandra",
+			Seeds:       []string{"10.0.0.1", "10.0.1.1"},
+			NodeState: reaper.NodeState{GossipStates: []reaper.GossipState{{
+				SourceNode:    "10.0.0.1",
+				EndpointNames: []string{"10.0.0.1", "10.0.0.2", "10.0.1.1"},
+				TotalLoad:     3.5 * 1024 * 1024 * 1024,
+				DataCenters: map[string]reaper.DataCenterState{
+					"dc1": dc("dc1",
+						endpoint("10.0.0.2", "dc1", "rack2", "NORMAL - DOWN", 1024*1024*1024, 2),
+						endpoint("10.0.0.1", "dc1", "rack1", "NORMAL - UP", 1.5*1024*1024*1024, 1)),
+					"dc2": dc("dc2", endpoint("10.0.1.1", "dc2", "rack1", "NORMAL - UP", 1024*1024*1024, 3)),
+				},
+			}}},
+		},
+		{Name: "empty", Seeds: []string{"192.168.0.1"}},
+	}
+
+	run := func(id, cluster, keyspace string, state reaper.RepairRunState, repaired, total int, owner string) *reaper.RepairRun {
+		return &reaper.RepairRun{
+			Id:                uuid.MustParse(id),
+			Cluster:           cluster,
+			Owner:             owner,
+			Keyspace:          keyspace,
+			Tables:            []string{"users", "events"},
+			Cause:             "scheduled",
+			State:             state,
+			Intensity:         0.9,
+			TotalSegments:     total,
+			RepairParallelism: reaper.RepairParallelismDatacenterAware,
+			SegmentsRepaired:  repaired,
+			Duration:          "1 hour 2 minutes",
+			Nodes:             []string{},
+			Datacenters:       []string{"dc1"},
+			RepairThreadCount: 1,
+			RepairUnitId:      uuid.MustParse("10000000-0000-0000-0000-000000000001"),
+		}
+	}
+	runs := []*reaper.RepairRun{
+		run("20000000-0000-0000-0000-000000000002", "production", "shop", reaper.RepairRunStateRunning, 30, 120, "alice"),
+		run("20000000-0000-0000-0000-000000000001", "staging", "shop", reaper.RepairRunStateDone, 64, 64, "bob"),
+		run("20000000-0000-0000-0000-000000000003", "production", "analytics", reaper.RepairRunStateNotStarted, 0, 0, "carol"),
+	}
+	runs[0].LastEvent = "Triggered segment 10"
+	runs[1].IncrementalRepair = true
+
+	started := time.Date(2021, 3, 4, 5, 6, 7, 0, time.FixedZone("CET", 3600))
+	ended := started.Add(90 * time.Second)
+	segment := func(id string, state reaper.RepairSegmentState, start, end string, fails int) *reaper.RepairSegment {
+		startToken, _ := new(big.Int).SetString(start, 10)
+		endToken, _ := new(big.Int).SetString(end, 10)
+		return &reaper.RepairSegment{
+			Id:           uuid.MustParse(id),
+			RunId:        runs[0].Id,
+			RepairUnitId: runs[0].RepairUnitId,
+			TokenRange: &reaper.Segment{
+				BaseRange:   &reaper.TokenRange{Start: startToken, End: endToken},
+				TokenRanges: []*reaper.TokenRange{{Start: startToken, End: endToken}},
+			},
+			FailCount: fails,
+			State:     state,
+		}
+	}
+	segments := []*reaper.RepairSegment{
+		segment("30000000-0000-0000-0000-000000000003", reaper.RepairSegmentStateNotStarted,
+			"85070591730234615865843651857942052864", "170141183460469231731687303715884105727", 0),
+		segment("30000000-0000-0000-0000-000000000001", reaper.RepairSegmentStateDone,
+			"-9223372036854775808", "-3074457345618258603", 0),
+		segment("30000000-0000-0000-0000-000000000002", reaper.RepairSegmentStateRunning,
+			"-3074457345618258603", "3074457345618258602", 2),
+	}
+	segments[1].Coordinator = "10.0.0.1"
+	segments[1].Replicas = map[string]string{"10.0.1.1": "dc2", "10.0.0.1": "dc1"}
+	segments[1].StartTime = &started
+	segments[1].EndTime = &ended
+	segments[2].Coordinator = "10.0.0.2"
+	segments[2].StartTime = &started
+
+	schedules := []reaper.RepairSchedule{
+		{
+			Id:                  "40000000-0000-0000-0000-000000000002",
+			Owner:               "alice",
+			State:               string(reaper.RepairScheduleStateActive),
+			Intensity:           0.5,
+			ClusterName:         "production",
+			KeyspaceName:        "shop",
+			Tables:              []string{"users"},
+			RepairParallelism:   string(reaper.RepairParallelismParallel),
+			RepairThreadCount:   2,
+			SegmentCountPerNode: 16,
+			DaysBetween:         7,
+			Created:             started,
+			NextActivation:      started.Add(7 * 24 * time.Hour),
+		},
+		{
+			Id:                "40000000-0000-0000-0000-000000000001",
+			Owner:             "bob",
+			State:             string(reaper.RepairScheduleStatePaused),
+			Intensity:         1,
+			ClusterName:       "production",
+			KeyspaceName:      "analytics",
+			RepairParallelism: string(reaper.RepairParallelismSequential),
+			IncrementalRepair: true,
+			DaysBetween:       1,
+			Created:           started,
+			Paused:            ended,
+			NextActivation:    started.Add(24 * time.Hour),
+		},
+	}
+	return clusters, runs, segments, schedules
+}
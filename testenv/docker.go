@@ -0,0 +1,154 @@
+package testenv
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+)
+
+var cassandraReadyStatusRegex = regexp.MustCompile(`\nUN `)
+
+// DockerCompose is an Environment backed by the services declared in docker-compose.yaml.
+type DockerCompose struct {
+	Timeouts
+
+	// The command used to invoke docker-compose. Defaults to "docker-compose".
+	Command []string
+
+	// Returns the name of the container running a service. Defaults to "reaper-client-go_<service>_1".
+	ContainerName func(service string) string
+
+	// The directory where Cassandra nodes store their data, purged by Reset. Defaults to ../data/cassandra.
+	CassandraDataDir string
+
+	// Credentials used by nodetool and cqlsh. Default to reaperUser/reaperPass.
+	Username string
+	Password string
+
+	// Defaults to http://localhost:8080.
+	Reaper *url.URL
+}
+
+// NewDockerCompose returns an Environment backed by docker-compose, with default settings.
+func NewDockerCompose() *DockerCompose {
+	reaperURL, _ := url.Parse("http://localhost:8080")
+	return &DockerCompose{
+		Command: []string{"docker-compose"},
+		ContainerName: func(service string) string {
+			return "reaper-client-go_" + service + "_1"
+		},
+		CassandraDataDir: "../data/cassandra",
+		Username:         "reaperUser",
+		Password:         "reaperPass",
+		Reaper:           reaperURL,
+	}
+}
+
+func (d *DockerCompose) Start(ctx context.Context) error {
+	return d.compose(ctx, "up", "-d").Run()
+}
+
+func (d *DockerCompose) Stop(ctx context.Context) error {
+	return d.compose(ctx, "down").Run()
+}
+
+func (d *DockerCompose) Reset(ctx context.Context) error {
+	if err := d.Stop(ctx); err != nil {
+		return fmt.Errorf("failed to stop services: %w", err)
+	}
+	if err := d.purgeCassandraDataDir(); err != nil {
+		return fmt.Errorf("failed to purge cassandra data dir: %w", err)
+	}
+	if err := d.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start services: %w", err)
+	}
+	return nil
+}
+
+func (d *DockerCompose) ReaperURL() *url.URL {
+	return d.Reaper
+}
+
+func (d *DockerCompose) purgeCassandraDataDir() error {
+	cassandraDataDir, err := filepath.Abs(d.CassandraDataDir)
+	if err != nil {
+		return fmt.Errorf("failed to get path of cassandra data dir: %w", err)
+	}
+	if err := os.RemoveAll(cassandraDataDir); err != nil {
+		return fmt.Errorf("failed to purge %s: %w", cassandraDataDir, err)
+	}
+	return nil
+}
+
+func (d *DockerCompose) WaitForClusterReady(ctx context.Context, seed string, numNodes int) error {
+	err := d.poll(ctx, func(ctx context.Context) error {
+		b, err := d.compose(ctx, "exec", "-T", seed, "nodetool", "-u", d.Username, "-pw", d.Password, "status").Output()
+		if err != nil {
+			return fmt.Errorf("failed to check cassandra status with seed node (%s): %w", seed, err)
+		}
+		if matches := cassandraReadyStatusRegex.FindAll(b, -1); len(matches) != numNodes {
+			return fmt.Errorf("%d nodes out of %d are up", len(matches), numNodes)
+		}
+		return nil
+	})
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("timed out waiting for nodetool status with seed (%s): %w", seed, err)
+}
+
+func (d *DockerCompose) WaitForCqlReady(ctx context.Context, node string) error {
+	err := d.poll(ctx, func(ctx context.Context) error {
+		return d.cqlsh(ctx, node, "-e", "SELECT release_version FROM system.local").Run()
+	})
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("timed out waiting for CQL readiness with seed (%s): %w", node, err)
+}
+
+func (d *DockerCompose) CreateKeyspace(ctx context.Context, node string, keyspace string, rf int) error {
+	stmt := fmt.Sprintf(
+		"CREATE KEYSPACE IF NOT EXISTS \"%s\" "+
+			"WITH replication = {'class':'NetworkTopologyStrategy', 'datacenter1':%d} "+
+			"AND durable_writes = true",
+		keyspace,
+		rf,
+	)
+	return d.cqlsh(ctx, node, "-e", stmt).Run()
+}
+
+func (d *DockerCompose) CreateTable(ctx context.Context, node string, keyspace string, table string) error {
+	stmt := fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS \"%s\".\"%s\" "+
+			"(pk int, cc timeuuid, v text, "+
+			"PRIMARY KEY (pk, cc))",
+		keyspace,
+		table,
+	)
+	return d.cqlsh(ctx, node, "-e", stmt).Run()
+}
+
+func (d *DockerCompose) ExecuteCqlScript(ctx context.Context, node string, script *os.File) error {
+	remotePath := "/tmp/" + path.Base(script.Name())
+	copyScript := exec.CommandContext(ctx, "docker", "cp", script.Name(), d.ContainerName(node)+":"+remotePath)
+	if err := copyScript.Run(); err != nil {
+		return err
+	}
+	return d.cqlsh(ctx, node, "-f", remotePath).Run()
+}
+
+func (d *DockerCompose) compose(ctx context.Context, args ...string) *exec.Cmd {
+	args = append(append([]string(nil), d.Command[1:]...), args...)
+	return exec.CommandContext(ctx, d.Command[0], args...)
+}
+
+func (d *DockerCompose) cqlsh(ctx context.Context, node string, args ...string) *exec.Cmd {
+	args = append([]string{"exec", "-T", node, "cqlsh", "-u", d.Username, "-p", d.Password}, args...)
+	return d.compose(ctx, append(args, node, "9042")...)
+}
@@ -0,0 +1,107 @@
+package testenv
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// Environment is a test environment made of Cassandra clusters and a Reaper instance. Nodes are designated by their
+// hostname, e.g. "cluster-1-node-0".
+type Environment interface {
+
+	// Start starts all services.
+	Start(ctx context.Context) error
+
+	// Stop stops all services.
+	Stop(ctx context.Context) error
+
+	// Reset stops all services, purges their data and starts them again.
+	Reset(ctx context.Context) error
+
+	// ReaperURL returns the URL of the Reaper REST API. Only valid after Start or Reset.
+	ReaperURL() *url.URL
+
+	// WaitForClusterReady blocks until numNodes nodes report a status of UN to the seed node.
+	WaitForClusterReady(ctx context.Context, seed string, numNodes int) error
+
+	// WaitForCqlReady blocks until the node accepts CQL connections.
+	WaitForCqlReady(ctx context.Context, node string) error
+
+	// CreateKeyspace creates a keyspace replicated in datacenter1, if it doesn't exist.
+	CreateKeyspace(ctx context.Context, node string, keyspace string, rf int) error
+
+	// CreateTable creates a table in an existing keyspace, if it doesn't exist.
+	CreateTable(ctx context.Context, node string, keyspace string, table string) error
+
+	// ExecuteCqlScript executes a CQL script, such as one created with CreateCqlInsertScript.
+	ExecuteCqlScript(ctx context.Context, node string, script *os.File) error
+}
+
+// Timeouts configures how long and how often an Environment polls while waiting for services to be ready.
+type Timeouts struct {
+
+	// How long WaitForClusterReady and WaitForCqlReady wait before giving up. Defaults to 60 seconds.
+	Ready time.Duration
+
+	// How often WaitForClusterReady and WaitForCqlReady check the services. Defaults to 1 second.
+	PollInterval time.Duration
+}
+
+// BackendEnvVar is the environment variable selecting the backend returned by FromEnv: "docker-compose" or "fake".
+const BackendEnvVar = "TESTENV_BACKEND"
+
+// Default is the environment used by the package-level functions.
+var Default Environment = NewDockerCompose()
+
+// FromEnv returns the environment selected by the TESTENV_BACKEND environment variable. When the variable is not set,
+// it returns a docker-compose environment if docker-compose is installed, and a fake environment otherwise.
+func FromEnv() (Environment, error) {
+	switch backend := os.Getenv(BackendEnvVar); backend {
+	case "docker-compose":
+		return NewDockerCompose(), nil
+	case "fake":
+		return NewFake(), nil
+	case "":
+		docker := NewDockerCompose()
+		if _, err := exec.LookPath(docker.Command[0]); err == nil {
+			return docker, nil
+		}
+		return NewFake(), nil
+	default:
+		return nil, fmt.Errorf("unknown %s: %s", BackendEnvVar, backend)
+	}
+}
+
+func (t Timeouts) withDefaults() Timeouts {
+	if t.Ready <= 0 {
+		t.Ready = 60 * time.Second
+	}
+	if t.PollInterval <= 0 {
+		t.PollInterval = time.Second
+	}
+	return t
+}
+
+// poll calls check until it succeeds, the Ready timeout expires or ctx is done.
+func (t Timeouts) poll(ctx context.Context, check func(ctx context.Context) error) error {
+	t = t.withDefaults()
+	ctx, cancel := context.WithTimeout(ctx, t.Ready)
+	defer cancel()
+	ticker := time.NewTicker(t.PollInterval)
+	defer ticker.Stop()
+	for {
+		err := check(ctx)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("timed out after %v: %w", t.Ready, err)
+		}
+	}
+}
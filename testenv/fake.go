@@ -0,0 +1,186 @@
+package testenv
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/k8ssandra/reaper-client-go/reapertest"
+)
+
+// Fake is an in-process Environment backed by the fake Reaper server of package reapertest. Keyspaces and tables
+// created through the environment become visible to Reaper; CQL scripts are checked for existence but not executed.
+type Fake struct {
+	Timeouts
+
+	// Options passed to the fake Reaper server when it starts.
+	ServerOptions []reapertest.ServerOption
+
+	mu        sync.Mutex
+	clusters  []*reapertest.CassandraCluster
+	server    *reapertest.Server
+	keyspaces map[string]bool
+}
+
+// NewFake returns a fake environment with the given Cassandra clusters. Without clusters, it mirrors the topology of
+// docker-compose.yaml: cluster-1 and cluster-2 with 2 nodes, and cluster-3 with 1 node, all running Cassandra 3.11.8.
+// Reaper and JMX credentials are reaperUser/reaperPass in both cases.
+func NewFake(clusters ...*reapertest.CassandraCluster) *Fake {
+	if len(clusters) == 0 {
+		for i, nodes := range []int{2, 2, 1} {
+			cluster := reapertest.NewCassandraCluster(fmt.Sprintf("cluster-%d", i+1), nodes)
+			clusters = append(clusters, cluster.WithReleaseVersion("3.11.8"))
+		}
+	}
+	return &Fake{
+		Timeouts: Timeouts{Ready: 5 * time.Second, PollInterval: 10 * time.Millisecond},
+		ServerOptions: []reapertest.ServerOption{
+			reapertest.WithCredentials("reaperUser", "reaperPass"),
+			reapertest.WithJmxCredentials("reaperUser", "reaperPass"),
+		},
+		clusters: clusters,
+	}
+}
+
+func (f *Fake) Start(_ context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.server != nil {
+		return nil
+	}
+	f.server = reapertest.NewServer(f.ServerOptions...)
+	f.keyspaces = map[string]bool{}
+	for _, cluster := range f.clusters {
+		// copy the cluster, so that keyspaces created during a run don't survive a Reset
+		started := &reapertest.CassandraCluster{Name: cluster.Name, Nodes: cluster.Nodes}
+		for _, keyspace := range cluster.Keyspaces {
+			started.WithKeyspace(keyspace.Name, keyspace.ReplicationFactor, keyspace.Tables...)
+			f.keyspaces[cluster.Name+"/"+keyspace.Name] = true
+		}
+		f.server.AddCassandraCluster(started)
+	}
+	return nil
+}
+
+func (f *Fake) Stop(_ context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.server != nil {
+		f.server.Close()
+		f.server = nil
+	}
+	return nil
+}
+
+func (f *Fake) Reset(ctx context.Context) error {
+	if err := f.Stop(ctx); err != nil {
+		return fmt.Errorf("failed to stop services: %w", err)
+	}
+	if err := f.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start services: %w", err)
+	}
+	return nil
+}
+
+func (f *Fake) ReaperURL() *url.URL {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.server == nil {
+		return nil
+	}
+	return f.server.URL()
+}
+
+// Server returns the fake Reaper server, or nil if the environment is not started.
+func (f *Fake) Server() *reapertest.Server {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.server
+}
+
+func (f *Fake) WaitForClusterReady(ctx context.Context, seed string, numNodes int) error {
+	err := f.poll(ctx, func(context.Context) error {
+		cluster, err := f.clusterOf(seed)
+		if err != nil {
+			return err
+		}
+		up := 0
+		for _, node := range cluster.Nodes {
+			if node.Status == "" || node.Status == "NORMAL" {
+				up++
+			}
+		}
+		if up != numNodes {
+			return fmt.Errorf("%d nodes out of %d are up", up, numNodes)
+		}
+		return nil
+	})
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("timed out waiting for nodetool status with seed (%s): %w", seed, err)
+}
+
+func (f *Fake) WaitForCqlReady(ctx context.Context, node string) error {
+	err := f.poll(ctx, func(context.Context) error {
+		_, err := f.clusterOf(node)
+		return err
+	})
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("timed out waiting for CQL readiness with seed (%s): %w", node, err)
+}
+
+func (f *Fake) CreateKeyspace(_ context.Context, node string, keyspace string, rf int) error {
+	cluster, err := f.clusterOf(node)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err = f.server.AddKeyspace(cluster.Name, keyspace, rf); err == nil {
+		f.keyspaces[cluster.Name+"/"+keyspace] = true
+	}
+	return err
+}
+
+func (f *Fake) CreateTable(_ context.Context, node string, keyspace string, table string) error {
+	cluster, err := f.clusterOf(node)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.keyspaces[cluster.Name+"/"+keyspace] {
+		return fmt.Errorf("keyspace %s does not exist in cluster %s", keyspace, cluster.Name)
+	}
+	return f.server.AddKeyspace(cluster.Name, keyspace, 0, table)
+}
+
+func (f *Fake) ExecuteCqlScript(_ context.Context, node string, script *os.File) error {
+	if _, err := f.clusterOf(node); err != nil {
+		return err
+	}
+	_, err := os.Stat(script.Name())
+	return err
+}
+
+func (f *Fake) clusterOf(node string) (*reapertest.CassandraCluster, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.server == nil {
+		return nil, fmt.Errorf("environment is not started")
+	}
+	for _, cluster := range f.clusters {
+		for _, n := range cluster.Nodes {
+			if n.Endpoint == node {
+				return cluster, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("unknown node %s", node)
+}
@@ -0,0 +1,64 @@
+package testenv
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/k8ssandra/reaper-client-go/reaper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFake(t *testing.T) {
+	ctx := context.Background()
+	env := NewFake()
+	env.Timeouts = Timeouts{Ready: 50 * time.Millisecond, PollInterval: time.Millisecond}
+	assert.Error(t, env.WaitForClusterReady(ctx, "cluster-1-node-0", 2))
+
+	require.NoError(t, env.Reset(ctx))
+	defer func() { _ = env.Stop(ctx) }()
+	assert.NoError(t, env.WaitForClusterReady(ctx, "cluster-1-node-0", 2))
+	assert.NoError(t, env.WaitForClusterReady(ctx, "cluster-3-node-0", 1))
+	err := env.WaitForClusterReady(ctx, "cluster-1-node-0", 3)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2 nodes out of 3 are up")
+	assert.NoError(t, env.WaitForCqlReady(ctx, "cluster-2-node-1"))
+	assert.Error(t, env.WaitForCqlReady(ctx, "unknown"))
+
+	assert.Error(t, env.CreateTable(ctx, "cluster-1-node-0", "ks", "table1"))
+	require.NoError(t, env.CreateKeyspace(ctx, "cluster-1-node-0", "ks", 2))
+	require.NoError(t, env.CreateTable(ctx, "cluster-1-node-0", "ks", "table1"))
+	script, err := CreateCqlInsertScript("ks", "table1")
+	require.NoError(t, err)
+	defer func() { _ = os.Remove(script.Name()) }()
+	assert.NoError(t, env.ExecuteCqlScript(ctx, "cluster-1-node-0", script))
+
+	client := reaper.NewClient(env.ReaperURL())
+	require.NoError(t, client.Login(ctx, "reaperUser", "reaperPass"))
+	require.NoError(t, client.AddCluster(ctx, "cluster-1", "cluster-1-node-0"))
+	runId, err := client.CreateRepairRun(ctx, "cluster-1", "ks", "Alice", nil)
+	require.NoError(t, err)
+	run, err := client.RepairRun(ctx, runId)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"table1"}, run.Tables)
+
+	// keyspaces don't survive a reset
+	require.NoError(t, env.Reset(ctx))
+	assert.Error(t, env.CreateTable(ctx, "cluster-1-node-0", "ks", "table1"))
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv(BackendEnvVar, "fake")
+	env, err := FromEnv()
+	require.NoError(t, err)
+	assert.IsType(t, &Fake{}, env)
+	t.Setenv(BackendEnvVar, "docker-compose")
+	env, err = FromEnv()
+	require.NoError(t, err)
+	assert.IsType(t, &DockerCompose{}, env)
+	t.Setenv(BackendEnvVar, "kubernetes")
+	_, err = FromEnv()
+	assert.Error(t, err)
+}
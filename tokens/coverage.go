@@ -0,0 +1,69 @@
+package tokens
+
+import (
+	"github.com/google/uuid"
+	"github.com/k8ssandra/reaper-client-go/reaper"
+)
+
+// CoverageReport describes which parts of the ring have been repaired by a set of repair segments, which parts are
+// being repaired, and which parts have not been touched yet. The three lists are merged, sorted, never wrap and never
+// overlap each other; together they cover the whole ring.
+type CoverageReport struct {
+	Partitioner Partitioner
+
+	// Ranges covered by at least one segment in state DONE.
+	Repaired []*reaper.TokenRange
+
+	// Ranges covered by at least one segment in state RUNNING or STARTED, and not already repaired.
+	Running []*reaper.TokenRange
+
+	// Ranges covered only by segments in state NOT_STARTED, or not covered by any segment at all.
+	Untouched []*reaper.TokenRange
+
+	// Subset of Untouched that is not covered by any segment at all. For a single repair run of a keyspace with
+	// replicas on every node, this should be empty.
+	Uncovered []*reaper.TokenRange
+}
+
+// NewCoverageReport computes the ring coverage of the given segments, typically the ones returned by
+// Client.RepairRunSegments. Segments from several repair runs can be combined: a range repaired by any of them counts
+// as repaired.
+func NewCoverageReport(p Partitioner, segments map[uuid.UUID]*reaper.RepairSegment) *CoverageReport {
+	var done, running, all []*reaper.TokenRange
+	for _, segment := range segments {
+		ranges := SegmentRanges(segment.TokenRange)
+		all = append(all, ranges...)
+		switch segment.State {
+		case reaper.RepairSegmentStateDone:
+			done = append(done, ranges...)
+		case reaper.RepairSegmentStateRunning, reaper.RepairSegmentStateStarted:
+			running = append(running, ranges...)
+		}
+	}
+	report := &CoverageReport{Partitioner: p}
+	report.Repaired = Merge(done, p)
+	report.Running = Subtract(running, report.Repaired, p)
+	report.Untouched = Complement(append(report.Repaired, report.Running...), p)
+	report.Uncovered = Complement(all, p)
+	return report
+}
+
+// RepairedFraction returns the fraction of the ring that has been repaired.
+func (r *CoverageReport) RepairedFraction() float64 {
+	return Fraction(r.Repaired, r.Partitioner)
+}
+
+// RunningFraction returns the fraction of the ring currently being repaired.
+func (r *CoverageReport) RunningFraction() float64 {
+	return Fraction(r.Running, r.Partitioner)
+}
+
+// UntouchedFraction returns the fraction of the ring that has neither been repaired nor is being repaired.
+func (r *CoverageReport) UntouchedFraction() float64 {
+	return Fraction(r.Untouched, r.Partitioner)
+}
+
+// IsComplete returns true if the whole ring has been repaired.
+func (r *CoverageReport) IsComplete() bool {
+	return len(r.Running) == 0 && len(r.Untouched) == 0
+}
@@ -0,0 +1,58 @@
+package tokens
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/k8ssandra/reaper-client-go/reaper"
+	"github.com/stretchr/testify/assert"
+)
+
+func segment(state reaper.RepairSegmentState, ranges ...*reaper.TokenRange) *reaper.RepairSegment {
+	return &reaper.RepairSegment{
+		Id:         uuid.New(),
+		State:      state,
+		TokenRange: &reaper.Segment{BaseRange: ranges[0], TokenRanges: ranges},
+	}
+}
+
+func segments(segments ...*reaper.RepairSegment) map[uuid.UUID]*reaper.RepairSegment {
+	m := make(map[uuid.UUID]*reaper.RepairSegment, len(segments))
+	for _, s := range segments {
+		m[s.Id] = s
+	}
+	return m
+}
+
+func TestCoverageReport(t *testing.T) {
+	p := Murmur3Partitioner
+	min, max := p.MinToken(), p.MaxToken()
+	report := NewCoverageReport(p, segments(
+		segment(reaper.RepairSegmentStateDone, tr(0, 100)),
+		segment(reaper.RepairSegmentStateRunning, tr(100, 200)),
+		segment(reaper.RepairSegmentStateNotStarted, tr(200, 300)),
+		// wrapping segment made of two vnodes
+		segment(reaper.RepairSegmentStateDone, tr(1000, -1000), tr(-1000, -500)),
+	))
+	assertRanges(t, []*reaper.TokenRange{{Start: min, End: big.NewInt(-500)}, tr(0, 100), {Start: big.NewInt(1000), End: max}}, report.Repaired)
+	assertRanges(t, []*reaper.TokenRange{tr(100, 200)}, report.Running)
+	assertRanges(t, []*reaper.TokenRange{tr(-500, 0), tr(200, 1000)}, report.Untouched)
+	assertRanges(t, []*reaper.TokenRange{tr(-500, 0), tr(300, 1000)}, report.Uncovered)
+	assert.False(t, report.IsComplete())
+	total := report.RepairedFraction() + report.RunningFraction() + report.UntouchedFraction()
+	assert.InDelta(t, 1.0, total, 0.0001)
+}
+
+func TestCoverageReportRepairedWinsOverRunning(t *testing.T) {
+	p := Murmur3Partitioner
+	report := NewCoverageReport(p, segments(
+		segment(reaper.RepairSegmentStateDone, tr(1, 1)),
+		segment(reaper.RepairSegmentStateStarted, tr(0, 100)),
+	))
+	assertRanges(t, []*reaper.TokenRange{p.FullRing()}, report.Repaired)
+	assert.Empty(t, report.Running)
+	assert.Empty(t, report.Untouched)
+	assert.True(t, report.IsComplete())
+	assert.InDelta(t, 1.0, report.RepairedFraction(), 0.0001)
+}
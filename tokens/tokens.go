@@ -0,0 +1,239 @@
+// Package tokens provides arithmetic over the token ranges carried by repair segments: range sizes, containment,
+// overlap, normalisation of wrapping ranges, merging and ring coverage.
+//
+// Token ranges follow Cassandra's semantics: a range (start, end] excludes its start and includes its end. A range
+// whose start is greater than or equal to its end wraps around the ring; a range whose start equals its end covers the
+// whole ring.
+package tokens
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/k8ssandra/reaper-client-go/reaper"
+)
+
+// Partitioner identifies the token ring bounds of a Cassandra partitioner.
+type Partitioner string
+
+const (
+	Murmur3Partitioner = Partitioner("Murmur3Partitioner")
+	RandomPartitioner  = Partitioner("RandomPartitioner")
+)
+
+var (
+	murmur3Min = new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 63))
+	murmur3Max = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 63), big.NewInt(1))
+	randomMin  = big.NewInt(-1)
+	randomMax  = new(big.Int).Lsh(big.NewInt(1), 127)
+)
+
+// MinToken returns the minimum token of the partitioner. The minimum token is never owned by any node; it only acts
+// as the exclusive lower bound of the ring.
+func (p Partitioner) MinToken() *big.Int {
+	switch p {
+	case RandomPartitioner:
+		return new(big.Int).Set(randomMin)
+	default:
+		return new(big.Int).Set(murmur3Min)
+	}
+}
+
+// MaxToken returns the maximum token of the partitioner.
+func (p Partitioner) MaxToken() *big.Int {
+	switch p {
+	case RandomPartitioner:
+		return new(big.Int).Set(randomMax)
+	default:
+		return new(big.Int).Set(murmur3Max)
+	}
+}
+
+// RingSize returns the number of tokens in the ring.
+func (p Partitioner) RingSize() *big.Int {
+	return new(big.Int).Sub(p.MaxToken(), p.MinToken())
+}
+
+// FullRing returns a non-wrapping range covering the whole ring.
+func (p Partitioner) FullRing() *reaper.TokenRange {
+	return &reaper.TokenRange{Start: p.MinToken(), End: p.MaxToken()}
+}
+
+// DetectPartitioner guesses the partitioner from a set of token ranges: tokens beyond the Murmur3 maximum can only
+// come from RandomPartitioner. Otherwise, including when the ranges are ambiguous, it returns Murmur3Partitioner.
+func DetectPartitioner(ranges ...*reaper.TokenRange) Partitioner {
+	for _, r := range ranges {
+		for _, token := range []*big.Int{r.Start, r.End} {
+			if token.Cmp(murmur3Max) > 0 {
+				return RandomPartitioner
+			}
+		}
+	}
+	return Murmur3Partitioner
+}
+
+// Validate checks that the range has both bounds and that they lie within the partitioner's ring.
+func Validate(r *reaper.TokenRange, p Partitioner) error {
+	if r == nil || r.Start == nil || r.End == nil {
+		return fmt.Errorf("token range must have a start and an end")
+	}
+	min, max := p.MinToken(), p.MaxToken()
+	for _, token := range []*big.Int{r.Start, r.End} {
+		if token.Cmp(min) < 0 || token.Cmp(max) > 0 {
+			return fmt.Errorf("token %v is outside of the %s ring [%v, %v]", token, p, min, max)
+		}
+	}
+	return nil
+}
+
+// IsWrapping returns true if the range wraps around the end of the ring, or covers the whole ring.
+func IsWrapping(r *reaper.TokenRange) bool {
+	return r.Start.Cmp(r.End) >= 0
+}
+
+// Size returns the number of tokens in the range.
+func Size(r *reaper.TokenRange, p Partitioner) *big.Int {
+	if !IsWrapping(r) {
+		return new(big.Int).Sub(r.End, r.Start)
+	}
+	// (start, max] + (min, end]
+	size := new(big.Int).Sub(p.MaxToken(), r.Start)
+	return size.Add(size, new(big.Int).Sub(r.End, p.MinToken()))
+}
+
+// TotalSize returns the number of distinct tokens covered by the given ranges; overlapping parts are counted once.
+func TotalSize(ranges []*reaper.TokenRange, p Partitioner) *big.Int {
+	total := new(big.Int)
+	for _, r := range Merge(ranges, p) {
+		total.Add(total, Size(r, p))
+	}
+	return total
+}
+
+// Fraction returns the fraction of the ring covered by the given ranges, in range [0.0, 1.0].
+func Fraction(ranges []*reaper.TokenRange, p Partitioner) float64 {
+	f, _ := new(big.Rat).SetFrac(TotalSize(ranges, p), p.RingSize()).Float64()
+	return f
+}
+
+// Contains returns true if the token belongs to the range.
+func Contains(r *reaper.TokenRange, token *big.Int) bool {
+	if !IsWrapping(r) {
+		return token.Cmp(r.Start) > 0 && token.Cmp(r.End) <= 0
+	}
+	return token.Cmp(r.Start) > 0 || token.Cmp(r.End) <= 0
+}
+
+// Overlaps returns true if the two ranges share at least one token.
+func Overlaps(a, b *reaper.TokenRange, p Partitioner) bool {
+	for _, x := range Unwrap(a, p) {
+		for _, y := range Unwrap(b, p) {
+			if x.Start.Cmp(y.End) < 0 && y.Start.Cmp(x.End) < 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Unwrap normalises a range into one or two non-wrapping ranges. A wrapping range (start, end] is split into
+// (start, max] and (min, end]; empty parts are dropped.
+func Unwrap(r *reaper.TokenRange, p Partitioner) []*reaper.TokenRange {
+	if !IsWrapping(r) {
+		return []*reaper.TokenRange{copyRange(r)}
+	}
+	min, max := p.MinToken(), p.MaxToken()
+	unwrapped := make([]*reaper.TokenRange, 0, 2)
+	if r.Start.Cmp(max) < 0 {
+		unwrapped = append(unwrapped, &reaper.TokenRange{Start: new(big.Int).Set(r.Start), End: max})
+	}
+	if r.End.Cmp(min) > 0 {
+		unwrapped = append(unwrapped, &reaper.TokenRange{Start: min, End: new(big.Int).Set(r.End)})
+	}
+	return unwrapped
+}
+
+// Normalize unwraps all the given ranges and sorts them by start token. The returned ranges may still overlap; use
+// Merge to coalesce them.
+func Normalize(ranges []*reaper.TokenRange, p Partitioner) []*reaper.TokenRange {
+	normalized := make([]*reaper.TokenRange, 0, len(ranges))
+	for _, r := range ranges {
+		normalized = append(normalized, Unwrap(r, p)...)
+	}
+	sort.Slice(normalized, func(i, j int) bool {
+		if c := normalized[i].Start.Cmp(normalized[j].Start); c != 0 {
+			return c < 0
+		}
+		return normalized[i].End.Cmp(normalized[j].End) < 0
+	})
+	return normalized
+}
+
+// Merge normalises the given ranges and coalesces the ones that overlap or are adjacent. The result is sorted by start
+// token and never contains wrapping ranges.
+func Merge(ranges []*reaper.TokenRange, p Partitioner) []*reaper.TokenRange {
+	merged := make([]*reaper.TokenRange, 0, len(ranges))
+	for _, r := range Normalize(ranges, p) {
+		if len(merged) > 0 {
+			last := merged[len(merged)-1]
+			if r.Start.Cmp(last.End) <= 0 {
+				if r.End.Cmp(last.End) > 0 {
+					last.End = r.End
+				}
+				continue
+			}
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// Subtract returns the parts of the ranges in from that are not covered by any range in ranges. The result is merged.
+func Subtract(from []*reaper.TokenRange, ranges []*reaper.TokenRange, p Partitioner) []*reaper.TokenRange {
+	result := make([]*reaper.TokenRange, 0)
+	holes := Merge(ranges, p)
+	for _, r := range Merge(from, p) {
+		start := new(big.Int).Set(r.Start)
+		for _, hole := range holes {
+			if hole.End.Cmp(start) <= 0 {
+				continue
+			}
+			if hole.Start.Cmp(r.End) >= 0 {
+				break
+			}
+			if hole.Start.Cmp(start) > 0 {
+				result = append(result, &reaper.TokenRange{Start: start, End: new(big.Int).Set(hole.Start)})
+			}
+			start = new(big.Int).Set(hole.End)
+		}
+		if start.Cmp(r.End) < 0 {
+			result = append(result, &reaper.TokenRange{Start: start, End: new(big.Int).Set(r.End)})
+		}
+	}
+	return result
+}
+
+// Complement returns the parts of the ring that are not covered by any of the given ranges.
+func Complement(ranges []*reaper.TokenRange, p Partitioner) []*reaper.TokenRange {
+	return Subtract([]*reaper.TokenRange{p.FullRing()}, ranges, p)
+}
+
+// SegmentRanges returns the token ranges of a segment. Segments spanning several vnodes list them in TokenRanges;
+// older Reaper versions only provide the BaseRange.
+func SegmentRanges(s *reaper.Segment) []*reaper.TokenRange {
+	if s == nil {
+		return nil
+	}
+	if len(s.TokenRanges) > 0 {
+		return s.TokenRanges
+	}
+	if s.BaseRange != nil {
+		return []*reaper.TokenRange{s.BaseRange}
+	}
+	return nil
+}
+
+func copyRange(r *reaper.TokenRange) *reaper.TokenRange {
+	return &reaper.TokenRange{Start: new(big.Int).Set(r.Start), End: new(big.Int).Set(r.End)}
+}
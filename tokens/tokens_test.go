@@ -0,0 +1,127 @@
+package tokens
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/k8ssandra/reaper-client-go/reaper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tr(start, end int64) *reaper.TokenRange {
+	return &reaper.TokenRange{Start: big.NewInt(start), End: big.NewInt(end)}
+}
+
+func assertRanges(t *testing.T, expected []*reaper.TokenRange, actual []*reaper.TokenRange) {
+	require.Len(t, actual, len(expected), "ranges %v", actual)
+	for i := range expected {
+		assert.Zero(t, expected[i].Start.Cmp(actual[i].Start), "start of range %d: expected %v, got %v", i, expected[i].Start, actual[i].Start)
+		assert.Zero(t, expected[i].End.Cmp(actual[i].End), "end of range %d: expected %v, got %v", i, expected[i].End, actual[i].End)
+	}
+}
+
+func TestPartitionerBounds(t *testing.T) {
+	assert.Equal(t, "-9223372036854775808", Murmur3Partitioner.MinToken().String())
+	assert.Equal(t, "9223372036854775807", Murmur3Partitioner.MaxToken().String())
+	assert.Equal(t, "18446744073709551615", Murmur3Partitioner.RingSize().String())
+	assert.Equal(t, "-1", RandomPartitioner.MinToken().String())
+	assert.Equal(t, "170141183460469231731687303715884105728", RandomPartitioner.MaxToken().String())
+	// returned values must be copies
+	Murmur3Partitioner.MinToken().SetInt64(0)
+	assert.Equal(t, "-9223372036854775808", Murmur3Partitioner.MinToken().String())
+}
+
+func TestDetectPartitioner(t *testing.T) {
+	assert.Equal(t, Murmur3Partitioner, DetectPartitioner(tr(-10, 10)))
+	assert.Equal(t, RandomPartitioner, DetectPartitioner(&reaper.TokenRange{
+		Start: big.NewInt(0),
+		End:   new(big.Int).Lsh(big.NewInt(1), 100),
+	}))
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(tr(-10, 10), Murmur3Partitioner))
+	assert.Error(t, Validate(tr(-10, 10), RandomPartitioner))
+	assert.Error(t, Validate(&reaper.TokenRange{Start: big.NewInt(1)}, Murmur3Partitioner))
+}
+
+func TestSize(t *testing.T) {
+	assert.Equal(t, "20", Size(tr(-10, 10), Murmur3Partitioner).String())
+	// wrapping: (10, max] + (min, -10]
+	expected := new(big.Int).Sub(Murmur3Partitioner.RingSize(), big.NewInt(20))
+	assert.Equal(t, expected.String(), Size(tr(10, -10), Murmur3Partitioner).String())
+	// start == end covers the whole ring
+	assert.Equal(t, Murmur3Partitioner.RingSize().String(), Size(tr(42, 42), Murmur3Partitioner).String())
+	assert.Equal(t, RandomPartitioner.RingSize().String(), Size(tr(42, 42), RandomPartitioner).String())
+}
+
+func TestContains(t *testing.T) {
+	r := tr(-10, 10)
+	assert.False(t, Contains(r, big.NewInt(-10)), "start is exclusive")
+	assert.True(t, Contains(r, big.NewInt(-9)))
+	assert.True(t, Contains(r, big.NewInt(10)), "end is inclusive")
+	assert.False(t, Contains(r, big.NewInt(11)))
+	wrapping := tr(10, -10)
+	assert.True(t, Contains(wrapping, big.NewInt(11)))
+	assert.True(t, Contains(wrapping, big.NewInt(-10)))
+	assert.False(t, Contains(wrapping, big.NewInt(0)))
+}
+
+func TestOverlaps(t *testing.T) {
+	p := Murmur3Partitioner
+	assert.True(t, Overlaps(tr(0, 10), tr(5, 15), p))
+	assert.False(t, Overlaps(tr(0, 10), tr(10, 20), p), "adjacent ranges do not overlap")
+	assert.True(t, Overlaps(tr(100, -100), tr(-200, -150), p))
+	assert.True(t, Overlaps(tr(100, -100), tr(150, 200), p))
+	assert.False(t, Overlaps(tr(100, -100), tr(-50, 50), p))
+}
+
+func TestUnwrap(t *testing.T) {
+	p := Murmur3Partitioner
+	assertRanges(t, []*reaper.TokenRange{tr(-5, 5)}, Unwrap(tr(-5, 5), p))
+	assertRanges(t,
+		[]*reaper.TokenRange{
+			{Start: big.NewInt(5), End: p.MaxToken()},
+			{Start: p.MinToken(), End: big.NewInt(-5)},
+		},
+		Unwrap(tr(5, -5), p),
+	)
+	// empty parts are dropped
+	assertRanges(t, []*reaper.TokenRange{p.FullRing()}, Unwrap(&reaper.TokenRange{Start: p.MinToken(), End: p.MinToken()}, p))
+}
+
+func TestMerge(t *testing.T) {
+	p := Murmur3Partitioner
+	merged := Merge([]*reaper.TokenRange{tr(20, 30), tr(0, 10), tr(10, 15), tr(25, 40), tr(50, 60)}, p)
+	assertRanges(t, []*reaper.TokenRange{tr(0, 15), tr(20, 40), tr(50, 60)}, merged)
+	merged = Merge([]*reaper.TokenRange{tr(100, -100), tr(-100, 100)}, p)
+	assertRanges(t, []*reaper.TokenRange{p.FullRing()}, merged)
+}
+
+func TestSubtractAndComplement(t *testing.T) {
+	p := Murmur3Partitioner
+	assertRanges(t,
+		[]*reaper.TokenRange{tr(0, 10), tr(20, 30), tr(40, 50)},
+		Subtract([]*reaper.TokenRange{tr(0, 50)}, []*reaper.TokenRange{tr(10, 20), tr(30, 40)}, p),
+	)
+	assertRanges(t,
+		[]*reaper.TokenRange{{Start: p.MinToken(), End: big.NewInt(-100)}, {Start: big.NewInt(100), End: p.MaxToken()}},
+		Complement([]*reaper.TokenRange{tr(-100, 100)}, p),
+	)
+	assert.Empty(t, Complement([]*reaper.TokenRange{tr(7, 7)}, p))
+}
+
+func TestTotalSizeAndFraction(t *testing.T) {
+	p := Murmur3Partitioner
+	assert.Equal(t, "30", TotalSize([]*reaper.TokenRange{tr(0, 20), tr(10, 30)}, p).String())
+	assert.InDelta(t, 0.5, Fraction([]*reaper.TokenRange{tr(-1, p.MaxToken().Int64())}, p), 0.0001)
+}
+
+func TestSegmentRanges(t *testing.T) {
+	assert.Nil(t, SegmentRanges(nil))
+	base := &reaper.Segment{BaseRange: tr(0, 10)}
+	assertRanges(t, []*reaper.TokenRange{tr(0, 10)}, SegmentRanges(base))
+	vnodes := &reaper.Segment{BaseRange: tr(0, 10), TokenRanges: []*reaper.TokenRange{tr(0, 5), tr(5, 10)}}
+	assertRanges(t, []*reaper.TokenRange{tr(0, 5), tr(5, 10)}, SegmentRanges(vnodes))
+}
@@ -0,0 +1,57 @@
+package watch
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/k8ssandra/reaper-client-go/reaper"
+)
+
+// estimator estimates the remaining time of repair runs from the rate at which their segments were repaired during a
+// sliding window. The estimate adapts to changes of intensity and to pauses: samples are discarded whenever a run is
+// not RUNNING, so that the time spent paused does not slow the rate down.
+type estimator struct {
+	window  time.Duration
+	samples map[uuid.UUID][]sample
+}
+
+type sample struct {
+	time     time.Time
+	repaired int
+}
+
+func newEstimator(window time.Duration) *estimator {
+	return &estimator{window: window, samples: map[uuid.UUID][]sample{}}
+}
+
+// observe records the progress of a run at the given time, and returns its estimated remaining time, or 0 if unknown.
+func (e *estimator) observe(run *reaper.RepairRun, now time.Time) time.Duration {
+	if run.State != reaper.RepairRunStateRunning || run.TotalSegments == 0 {
+		delete(e.samples, run.Id)
+		return 0
+	}
+	samples := append(e.samples[run.Id], sample{time: now, repaired: run.SegmentsRepaired})
+	// keep the most recent sample out of the window, so that the rate is computed over the full window
+	for len(samples) > 2 && now.Sub(samples[1].time) >= e.window {
+		samples = samples[1:]
+	}
+	e.samples[run.Id] = samples
+	first := samples[0]
+	elapsed := now.Sub(first.time)
+	repaired := run.SegmentsRepaired - first.repaired
+	if elapsed <= 0 || repaired <= 0 {
+		return 0
+	}
+	remaining := run.TotalSegments - run.SegmentsRepaired
+	return time.Duration(float64(elapsed) / float64(repaired) * float64(remaining))
+}
+
+// retain drops the samples of the runs not in ids, such as deleted runs or runs no longer matching the search, so that
+// a long-lived watch doesn't accumulate them.
+func (e *estimator) retain(ids map[uuid.UUID]bool) {
+	for id := range e.samples {
+		if !ids[id] {
+			delete(e.samples, id)
+		}
+	}
+}
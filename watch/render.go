@@ -0,0 +1,266 @@
+package watch
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/k8ssandra/reaper-client-go/reaper"
+)
+
+const (
+	clearScreen = "\x1b[H\x1b[2J"
+	reset       = "\x1b[0m"
+	bold        = "\x1b[1m"
+	red         = "\x1b[31m"
+	green       = "\x1b[32m"
+	yellow      = "\x1b[33m"
+	blue        = "\x1b[34m"
+
+	barWidth = 30
+)
+
+// IsTerminal returns true if w is a terminal, as opposed to a pipe or a regular file.
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+type renderer interface {
+	render(w io.Writer, snapshot *Snapshot) error
+	renderError(w io.Writer, now time.Time, err error) error
+}
+
+// ansiRenderer redraws the whole screen on every poll.
+type ansiRenderer struct {
+	maxSegments int
+	interval    time.Duration
+}
+
+func (r *ansiRenderer) render(w io.Writer, snapshot *Snapshot) error {
+	// the frame is written at once to avoid flickering
+	var buf bytes.Buffer
+	buf.WriteString(clearScreen)
+	_, _ = fmt.Fprintf(&buf, "%sRepair runs at %s%s, refreshed every %v\n\n", bold,
+		snapshot.Time.Format("15:04:05"), reset, r.interval)
+	if len(snapshot.Runs) == 0 {
+		buf.WriteString("No repair runs.\n")
+	}
+	nameWidth := len("CLUSTER/KEYSPACE")
+	for _, run := range snapshot.Runs {
+		nameWidth = max(nameWidth, len(runName(run.RepairRun)))
+	}
+	if len(snapshot.Runs) > 0 {
+		_, _ = fmt.Fprintf(&buf, "%-8s  %-*s  %-11s  %-*s  %-9s  %s\n", "ID", nameWidth, "CLUSTER/KEYSPACE", "STATE",
+			barWidth+17, "PROGRESS", "INTENSITY", "ETA")
+	}
+	for _, run := range snapshot.Runs {
+		_, _ = fmt.Fprintf(&buf, "%-8s  %-*s  %s  %-*s  %-9s  %s\n", run.Id.String()[:8], nameWidth, runName(run.RepairRun),
+			colored(stateColor(run.State), fmt.Sprintf("%-11s", run.State)), barWidth+17, progressBar(run.RepairRun),
+			formatFloat(run.Intensity), formatETA(run))
+	}
+	if snapshot.Segments != nil {
+		r.renderSegments(&buf, snapshot.Segments)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (r *ansiRenderer) renderSegments(buf *bytes.Buffer, segments []*reaper.RepairSegment) {
+	done, running, failed := 0, 0, 0
+	for _, segment := range segments {
+		switch {
+		case segment.State == reaper.RepairSegmentStateDone:
+			done++
+		case isRunning(segment):
+			running++
+		}
+		if segment.FailCount > 0 {
+			failed++
+		}
+	}
+	_, _ = fmt.Fprintf(buf, "\n%sSegments%s: %d/%d done, %d running, %d failed at least once\n\n", bold, reset, done,
+		len(segments), running, failed)
+
+	// running and failed segments are the interesting ones
+	shown := append([]*reaper.RepairSegment(nil), segments...)
+	sort.SliceStable(shown, func(i, j int) bool {
+		a, b := shown[i], shown[j]
+		if isRunning(a) != isRunning(b) {
+			return isRunning(a)
+		}
+		return a.FailCount > b.FailCount
+	})
+	hidden := 0
+	if len(shown) > r.maxSegments {
+		hidden = len(shown) - r.maxSegments
+		shown = shown[:r.maxSegments]
+	}
+	tw := tabwriter.NewWriter(buf, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "ID\tSTATE\tSTART TOKEN\tEND TOKEN\tCOORDINATOR\tREPLICAS\tFAILS\tDURATION")
+	for _, segment := range shown {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\n", segment.Id.String()[:8], segment.State,
+			formatStartToken(segment), formatEndToken(segment), orDash(segment.Coordinator), orDash(replicas(segment)),
+			segment.FailCount, segmentDuration(segment))
+	}
+	_ = tw.Flush()
+	if hidden > 0 {
+		_, _ = fmt.Fprintf(buf, "... and %d more segments\n", hidden)
+	}
+}
+
+func (r *ansiRenderer) renderError(w io.Writer, now time.Time, err error) error {
+	_, werr := fmt.Fprintf(w, "%s%s: %v%s\n", red, now.Format("15:04:05"), err, reset)
+	return werr
+}
+
+// lineRenderer prints a line for every run or segment that changed since the previous poll.
+type lineRenderer struct {
+	runs     map[string]string
+	segments map[string]string
+}
+
+func (r *lineRenderer) render(w io.Writer, snapshot *Snapshot) error {
+	if r.runs == nil {
+		r.runs, r.segments = map[string]string{}, map[string]string{}
+	}
+	timestamp := snapshot.Time.UTC().Format(time.RFC3339)
+	var buf bytes.Buffer
+	for _, run := range snapshot.Runs {
+		// the ETA changes on every poll and is not worth a line on its own
+		key := fmt.Sprintf("%s %d/%d %v", run.State, run.SegmentsRepaired, run.TotalSegments, run.Intensity)
+		if r.runs[run.Id.String()] == key {
+			continue
+		}
+		r.runs[run.Id.String()] = key
+		_, _ = fmt.Fprintf(&buf, "%s run %v %s %s %s intensity %s", timestamp, run.Id, runName(run.RepairRun), run.State,
+			progress(run.RepairRun), formatFloat(run.Intensity))
+		if run.ETA > 0 {
+			_, _ = fmt.Fprintf(&buf, " eta %s", formatETA(run))
+		}
+		buf.WriteByte('\n')
+	}
+	for _, segment := range snapshot.Segments {
+		key := fmt.Sprintf("%s %s %d", segment.State, segment.Coordinator, segment.FailCount)
+		if previous, seen := r.segments[segment.Id.String()]; previous == key || !seen && isPristine(segment) {
+			r.segments[segment.Id.String()] = key
+			continue
+		}
+		r.segments[segment.Id.String()] = key
+		_, _ = fmt.Fprintf(&buf, "%s segment %v (%s, %s] %s coordinator %s replicas %s fails %d\n", timestamp,
+			segment.Id, formatStartToken(segment), formatEndToken(segment), segment.State, orDash(segment.Coordinator),
+			orDash(replicas(segment)), segment.FailCount)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (r *lineRenderer) renderError(w io.Writer, now time.Time, err error) error {
+	_, werr := fmt.Fprintf(w, "%s error %v\n", now.UTC().Format(time.RFC3339), err)
+	return werr
+}
+
+// isPristine returns true for segments that were never attempted, which are not worth a line when first seen.
+func isPristine(segment *reaper.RepairSegment) bool {
+	return segment.State == reaper.RepairSegmentStateNotStarted && segment.FailCount == 0
+}
+
+func isRunning(segment *reaper.RepairSegment) bool {
+	return segment.State == reaper.RepairSegmentStateRunning || segment.State == reaper.RepairSegmentStateStarted
+}
+
+func runName(run *reaper.RepairRun) string {
+	return run.Cluster + "/" + run.Keyspace
+}
+
+func progress(run *reaper.RepairRun) string {
+	if run.TotalSegments == 0 {
+		return "-/-"
+	}
+	return fmt.Sprintf("%d/%d (%d%%)", run.SegmentsRepaired, run.TotalSegments,
+		run.SegmentsRepaired*100/run.TotalSegments)
+}
+
+// progressBar returns e.g. "[#########.....................]  30%  36/120".
+func progressBar(run *reaper.RepairRun) string {
+	if run.TotalSegments == 0 {
+		return "[" + strings.Repeat(".", barWidth) + "]   -"
+	}
+	filled := run.SegmentsRepaired * barWidth / run.TotalSegments
+	return fmt.Sprintf("[%s%s] %3d%%  %d/%d", strings.Repeat("#", filled), strings.Repeat(".", barWidth-filled),
+		run.SegmentsRepaired*100/run.TotalSegments, run.SegmentsRepaired, run.TotalSegments)
+}
+
+func formatETA(run RunStatus) string {
+	if run.ETA <= 0 {
+		return "-"
+	}
+	return run.ETA.Round(time.Second).String()
+}
+
+func formatFloat(f float64) string {
+	return fmt.Sprintf("%g", f)
+}
+
+func stateColor(state reaper.RepairRunState) string {
+	switch state {
+	case reaper.RepairRunStateRunning:
+		return blue
+	case reaper.RepairRunStateDone:
+		return green
+	case reaper.RepairRunStatePaused, reaper.RepairRunStateNotStarted:
+		return yellow
+	default:
+		return red
+	}
+}
+
+func colored(color, s string) string {
+	return color + s + reset
+}
+
+func formatStartToken(segment *reaper.RepairSegment) string {
+	if segment.TokenRange == nil || segment.TokenRange.BaseRange == nil || segment.TokenRange.BaseRange.Start == nil {
+		return "-"
+	}
+	return segment.TokenRange.BaseRange.Start.String()
+}
+
+func formatEndToken(segment *reaper.RepairSegment) string {
+	if segment.TokenRange == nil || segment.TokenRange.BaseRange == nil || segment.TokenRange.BaseRange.End == nil {
+		return "-"
+	}
+	return segment.TokenRange.BaseRange.End.String()
+}
+
+func replicas(segment *reaper.RepairSegment) string {
+	names := make([]string, 0, len(segment.Replicas))
+	for name := range segment.Replicas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+func segmentDuration(segment *reaper.RepairSegment) string {
+	if segment.StartTime == nil || segment.EndTime == nil || segment.EndTime.Before(*segment.StartTime) {
+		return "-"
+	}
+	return segment.EndTime.Sub(*segment.StartTime).Round(time.Second).String()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
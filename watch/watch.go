@@ -0,0 +1,224 @@
+// Package watch shows the progress of repair runs live, in a terminal.
+//
+// A Watcher polls Reaper for repair runs and renders, on every poll, a progress bar per run with its state, intensity
+// and estimated time of completion. When watching a single run, it also drills down into its segments, showing the
+// coordinator, replicas and fail count of the segments being repaired or having failed.
+//
+// On terminals, every poll redraws the screen with ANSI escape sequences. When the output is not a terminal, e.g. when
+// it is piped to a file, the watcher degrades to plain lines, printed only when a run or segment changes:
+//
+//	watcher := watch.NewWatcher(client, os.Stdout, watch.Options{RunId: id, ExitWhenDone: true})
+//	err := watcher.Run(ctx)
+package watch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/k8ssandra/reaper-client-go/reaper"
+)
+
+type Mode int
+
+const (
+	// ModeAuto uses ModeAnsi if the output is a terminal, and ModeLines otherwise.
+	ModeAuto Mode = iota
+
+	// ModeAnsi redraws the whole view on every poll.
+	ModeAnsi
+
+	// ModeLines prints a line for every change of a run or segment.
+	ModeLines
+)
+
+// Options controls what a Watcher watches and how.
+type Options struct {
+	// The repair runs to watch. Ignored if RunId is set. Defaults to all repair runs.
+	Search *reaper.RepairRunSearchOptions
+
+	// Watch a single repair run, and drill down into its segments.
+	RunId uuid.UUID
+
+	// Defaults to 2s.
+	Interval time.Duration
+
+	// Stop watching once all the watched runs are DONE, in ERROR, ABORTED or DELETED.
+	ExitWhenDone bool
+
+	// The maximum number of segments shown by ModeAnsi, running and failed segments first. Defaults to 20.
+	MaxSegments int
+
+	Mode Mode
+}
+
+// RunStatus is the progress of a repair run at the time of a poll.
+type RunStatus struct {
+	*reaper.RepairRun
+
+	// The estimated remaining time until the run is DONE, or 0 if unknown.
+	ETA time.Duration
+}
+
+// Snapshot is the result of a poll.
+type Snapshot struct {
+	Time time.Time
+
+	// The watched runs, sorted by cluster, keyspace and id.
+	Runs []RunStatus
+
+	// The segments of the run designated by Options.RunId, sorted by start token. Nil when watching several runs.
+	Segments []*reaper.RepairSegment
+}
+
+// Done returns true if all the runs of the snapshot are terminated.
+func (s *Snapshot) Done() bool {
+	for _, run := range s.Runs {
+		if !isTerminated(run.State) {
+			return false
+		}
+	}
+	return true
+}
+
+// Watcher polls repair runs and renders their progress.
+type Watcher struct {
+	client   reaper.Client
+	out      io.Writer
+	options  Options
+	now      func() time.Time
+	renderer renderer
+	eta      *estimator
+}
+
+func NewWatcher(client reaper.Client, out io.Writer, options Options) *Watcher {
+	if options.Interval <= 0 {
+		options.Interval = 2 * time.Second
+	}
+	if options.MaxSegments <= 0 {
+		options.MaxSegments = 20
+	}
+	if options.Mode == ModeAuto {
+		options.Mode = ModeLines
+		if IsTerminal(out) {
+			options.Mode = ModeAnsi
+		}
+	}
+	watcher := &Watcher{
+		client:  client,
+		out:     out,
+		options: options,
+		now:     time.Now,
+		eta:     newEstimator(10 * time.Minute),
+	}
+	if options.Mode == ModeAnsi {
+		watcher.renderer = &ansiRenderer{maxSegments: options.MaxSegments, interval: options.Interval}
+	} else {
+		watcher.renderer = &lineRenderer{}
+	}
+	return watcher
+}
+
+// Run polls and renders the watched runs until the context is cancelled, or until they are all terminated if
+// Options.ExitWhenDone is set. Errors while polling are rendered and tolerated; only the cancellation of the context is
+// returned as an error.
+func (w *Watcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.options.Interval)
+	defer ticker.Stop()
+	for {
+		snapshot, err := w.Poll(ctx)
+		if err == nil {
+			err = w.renderer.render(w.out, snapshot)
+			if err != nil {
+				return err
+			}
+			if w.options.ExitWhenDone && snapshot.Done() {
+				return nil
+			}
+		} else if ctx.Err() == nil {
+			if err := w.renderer.renderError(w.out, w.now(), err); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Poll fetches the watched runs, and the segments of the run designated by Options.RunId, without rendering them.
+func (w *Watcher) Poll(ctx context.Context) (*Snapshot, error) {
+	snapshot := &Snapshot{}
+	if w.options.RunId != uuid.Nil {
+		run, err := w.client.RepairRun(ctx, w.options.RunId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get repair run: %w", err)
+		}
+		segments, err := w.client.RepairRunSegments(ctx, w.options.RunId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get repair run segments: %w", err)
+		}
+		snapshot.Runs = []RunStatus{{RepairRun: run}}
+		snapshot.Segments = sortedSegments(segments)
+	} else {
+		runs, err := w.client.RepairRuns(ctx, w.options.Search)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repair runs: %w", err)
+		}
+		for _, run := range runs {
+			snapshot.Runs = append(snapshot.Runs, RunStatus{RepairRun: run})
+		}
+		sort.Slice(snapshot.Runs, func(i, j int) bool {
+			a, b := snapshot.Runs[i], snapshot.Runs[j]
+			if a.Cluster != b.Cluster {
+				return a.Cluster < b.Cluster
+			}
+			if a.Keyspace != b.Keyspace {
+				return a.Keyspace < b.Keyspace
+			}
+			return a.Id.String() < b.Id.String()
+		})
+	}
+	snapshot.Time = w.now()
+	polled := make(map[uuid.UUID]bool, len(snapshot.Runs))
+	for i := range snapshot.Runs {
+		snapshot.Runs[i].ETA = w.eta.observe(snapshot.Runs[i].RepairRun, snapshot.Time)
+		polled[snapshot.Runs[i].Id] = true
+	}
+	w.eta.retain(polled)
+	return snapshot, nil
+}
+
+func sortedSegments(segments map[uuid.UUID]*reaper.RepairSegment) []*reaper.RepairSegment {
+	sorted := make([]*reaper.RepairSegment, 0, len(segments))
+	for _, segment := range segments {
+		sorted = append(sorted, segment)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if c := startToken(sorted[i]).Cmp(startToken(sorted[j])); c != 0 {
+			return c < 0
+		}
+		return sorted[i].Id.String() < sorted[j].Id.String()
+	})
+	return sorted
+}
+
+func startToken(segment *reaper.RepairSegment) *big.Int {
+	if segment.TokenRange == nil || segment.TokenRange.BaseRange == nil || segment.TokenRange.BaseRange.Start == nil {
+		return new(big.Int)
+	}
+	return segment.TokenRange.BaseRange.Start
+}
+
+func isTerminated(state reaper.RepairRunState) bool {
+	return state == reaper.RepairRunStateDone ||
+		state == reaper.RepairRunStateError ||
+		state == reaper.RepairRunStateAborted ||
+		state == reaper.RepairRunStateDeleted
+}
@@ -0,0 +1,188 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/k8ssandra/reaper-client-go/reaper"
+	"github.com/k8ssandra/reaper-client-go/reapertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newServer(t *testing.T) (*reapertest.Server, reaper.Client) {
+	server := reapertest.NewServer(reapertest.WithSegmentDuration(5 * time.Millisecond))
+	t.Cleanup(server.Close)
+	server.AddCassandraCluster(reapertest.NewCassandraCluster("cluster-1", 3).WithKeyspace("ks", 3, "table1"))
+	client := reaper.NewClient(server.URL())
+	require.NoError(t, client.AddCluster(context.Background(), "cluster-1", "cluster-1-node-0"))
+	return server, client
+}
+
+func TestWatchRunLines(t *testing.T) {
+	_, client := newServer(t)
+	ctx := context.Background()
+	id, err := client.CreateRepairRun(ctx, "cluster-1", "ks", "alice", &reaper.RepairRunCreateOptions{SegmentCountPerNode: 2})
+	require.NoError(t, err)
+	require.NoError(t, client.StartRepairRun(ctx, id))
+
+	var out bytes.Buffer
+	watcher := NewWatcher(client, &out, Options{RunId: id, Interval: 5 * time.Millisecond, ExitWhenDone: true})
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	require.NoError(t, watcher.Run(ctx))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	runLine := regexp.MustCompile(`^\S+ run ` + id.String() + ` cluster-1/ks (RUNNING|DONE) \d+/6 \(\d+%\) intensity 0.9`)
+	for _, line := range lines {
+		if strings.Contains(line, " run ") {
+			assert.Regexp(t, runLine, line)
+		} else {
+			assert.Regexp(t, `^\S+ segment \S+ \(-?\d+, -?\d+\] (RUNNING|STARTED|DONE) coordinator \S+ replicas \S+ fails 0$`, line)
+		}
+	}
+	assert.Contains(t, lines[len(lines)-1], "DONE")
+	assert.Equal(t, 1, strings.Count(out.String(), "cluster-1/ks DONE 6/6 (100%)"), "unchanged runs are not printed again")
+	assert.GreaterOrEqual(t, strings.Count(out.String(), " DONE coordinator "), 6)
+}
+
+func TestWatchSearch(t *testing.T) {
+	_, client := newServer(t)
+	ctx := context.Background()
+	_, err := client.CreateRepairRun(ctx, "cluster-1", "ks", "alice", &reaper.RepairRunCreateOptions{})
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	watcher := NewWatcher(client, &out, Options{
+		Search:   &reaper.RepairRunSearchOptions{Cluster: "cluster-1"},
+		Interval: 5 * time.Millisecond,
+		Mode:     ModeAnsi,
+	})
+	snapshot, err := watcher.Poll(ctx)
+	require.NoError(t, err)
+	require.Len(t, snapshot.Runs, 1)
+	assert.Nil(t, snapshot.Segments)
+	assert.False(t, snapshot.Done())
+
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, watcher.Run(ctx), "runs that are not started never end")
+	assert.Contains(t, out.String(), clearScreen)
+	assert.Contains(t, out.String(), "cluster-1/ks")
+	assert.Contains(t, out.String(), "NOT_STARTED")
+}
+
+func TestWatchErrors(t *testing.T) {
+	server, client := newServer(t)
+	server.Close()
+
+	var out bytes.Buffer
+	watcher := NewWatcher(client, &out, Options{Interval: 5 * time.Millisecond})
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, watcher.Run(ctx))
+	assert.Contains(t, out.String(), " error failed to list repair runs: ")
+	assert.Greater(t, strings.Count(out.String(), "\n"), 1, "errors are tolerated")
+}
+
+func TestAnsiRenderer(t *testing.T) {
+	start := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	end := start.Add(42 * time.Second)
+	token := func(s string) *big.Int {
+		i, _ := new(big.Int).SetString(s, 10)
+		return i
+	}
+	segment := func(state reaper.RepairSegmentState, from, to string, fails int) *reaper.RepairSegment {
+		return &reaper.RepairSegment{
+			Id:         uuid.New(),
+			State:      state,
+			TokenRange: &reaper.Segment{BaseRange: &reaper.TokenRange{Start: token(from), End: token(to)}},
+			FailCount:  fails,
+		}
+	}
+	segments := []*reaper.RepairSegment{
+		segment(reaper.RepairSegmentStateDone, "-100", "0", 0),
+		segment(reaper.RepairSegmentStateNotStarted, "0", "100", 2),
+		segment(reaper.RepairSegmentStateRunning, "100", "200", 0),
+		segment(reaper.RepairSegmentStateNotStarted, "200", "300", 0),
+	}
+	segments[0].StartTime, segments[0].EndTime = &start, &end
+	segments[2].Coordinator = "10.0.0.1"
+	segments[2].Replicas = map[string]string{"10.0.0.2": "dc1", "10.0.0.1": "dc1"}
+	snapshot := &Snapshot{
+		Time: start,
+		Runs: []RunStatus{{
+			RepairRun: &reaper.RepairRun{
+				Id:               uuid.MustParse("12345678-0000-0000-0000-000000000000"),
+				Cluster:          "production",
+				Keyspace:         "shop",
+				State:            reaper.RepairRunStateRunning,
+				Intensity:        0.5,
+				SegmentsRepaired: 1,
+				TotalSegments:    4,
+			},
+			ETA: 90*time.Second + 300*time.Millisecond,
+		}},
+		Segments: segments,
+	}
+
+	var out bytes.Buffer
+	renderer := &ansiRenderer{maxSegments: 3, interval: 2 * time.Second}
+	require.NoError(t, renderer.render(&out, snapshot))
+	lines := strings.Split(out.String(), "\n")
+	assert.Equal(t, clearScreen+bold+"Repair runs at 05:06:07"+reset+", refreshed every 2s", lines[0])
+	assert.Equal(t, "12345678  production/shop   "+blue+"RUNNING    "+reset+"  "+
+		"[#######.......................]  25%  1/4       0.5        1m30s", lines[3])
+	assert.Equal(t, bold+"Segments"+reset+": 1/4 done, 1 running, 1 failed at least once", lines[5])
+	assert.Regexp(t, `^ID\s+STATE\s+START TOKEN\s+END TOKEN\s+COORDINATOR\s+REPLICAS\s+FAILS\s+DURATION$`, lines[7])
+	// running segments first, then failed ones
+	assert.Regexp(t, `^\S+\s+RUNNING\s+100\s+200\s+10.0.0.1\s+10.0.0.1,10.0.0.2\s+0\s+-$`, lines[8])
+	assert.Regexp(t, `^\S+\s+NOT_STARTED\s+0\s+100\s+-\s+-\s+2\s+-$`, lines[9])
+	assert.Regexp(t, `^\S+\s+DONE\s+-100\s+0\s+-\s+-\s+0\s+42s$`, lines[10])
+	assert.Equal(t, "... and 1 more segments", lines[11])
+}
+
+func TestEstimator(t *testing.T) {
+	estimator := newEstimator(time.Minute)
+	now := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	run := &reaper.RepairRun{Id: uuid.New(), State: reaper.RepairRunStateRunning, TotalSegments: 100}
+
+	assert.Equal(t, time.Duration(0), estimator.observe(run, now), "unknown rate")
+	run.SegmentsRepaired = 10
+	assert.Equal(t, 9*time.Minute, estimator.observe(run, now.Add(time.Minute)))
+	run.SegmentsRepaired = 40
+	// the window now starts with the second sample: 30 segments in a minute
+	assert.Equal(t, 2*time.Minute, estimator.observe(run, now.Add(2*time.Minute)))
+
+	// pauses reset the estimate
+	run.State = reaper.RepairRunStatePaused
+	assert.Equal(t, time.Duration(0), estimator.observe(run, now.Add(time.Hour)))
+	run.State = reaper.RepairRunStateRunning
+	assert.Equal(t, time.Duration(0), estimator.observe(run, now.Add(2*time.Hour)))
+	run.SegmentsRepaired = 70
+	assert.Equal(t, 30*time.Second, estimator.observe(run, now.Add(2*time.Hour+30*time.Second)))
+
+	// runs that are no longer polled are forgotten
+	other := &reaper.RepairRun{Id: uuid.New(), State: reaper.RepairRunStateRunning, TotalSegments: 100}
+	estimator.observe(other, now)
+	estimator.retain(map[uuid.UUID]bool{run.Id: true})
+	assert.Len(t, estimator.samples, 1)
+	assert.Contains(t, estimator.samples, run.Id)
+}
+
+func TestIsTerminal(t *testing.T) {
+	assert.False(t, IsTerminal(&bytes.Buffer{}))
+	f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	require.NoError(t, err)
+	defer f.Close()
+	assert.False(t, IsTerminal(f))
+	assert.Equal(t, ModeLines, NewWatcher(nil, f, Options{}).options.Mode)
+}